@@ -2,16 +2,26 @@ package arbnode
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcastclients"
+	"github.com/offchainlabs/nitro/broadcaster"
 	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
 
 type BlockValidatorAPI struct {
@@ -57,3 +67,285 @@ func (a *BlockValidatorDebugAPI) ValidateMessageNumber(
 	result.Valid = valid
 	return result, err
 }
+
+type StalenessAPI struct {
+	batchPoster    *BatchPoster
+	blockValidator *staker.BlockValidator
+	config         ConfigFetcher
+	startTime      time.Time
+}
+
+func NewStalenessAPI(batchPoster *BatchPoster, blockValidator *staker.BlockValidator, config ConfigFetcher) *StalenessAPI {
+	return &StalenessAPI{
+		batchPoster:    batchPoster,
+		blockValidator: blockValidator,
+		config:         config,
+		startTime:      time.Now(),
+	}
+}
+
+type BatchPostingStaleness struct {
+	LastPosted *LastPostedBatchInfo `json:"lastPosted"`
+	AgeSeconds float64              `json:"ageSeconds"`
+	Stale      bool                 `json:"stale"`
+}
+
+type ValidationStaleness struct {
+	LastValidated *staker.GlobalStateValidatedInfo `json:"lastValidated"`
+	AgeSeconds    float64                          `json:"ageSeconds"`
+	Stale         bool                             `json:"stale"`
+}
+
+type StalenessResult struct {
+	BatchPosting *BatchPostingStaleness `json:"batchPosting,omitempty"`
+	Validation   *ValidationStaleness   `json:"validation,omitempty"`
+}
+
+// age is measured from startup if nothing happened yet
+func (a *StalenessAPI) age(last time.Time) time.Duration {
+	if last.IsZero() {
+		last = a.startTime
+	}
+	return time.Since(last)
+}
+
+func isStale(age time.Duration, threshold time.Duration) bool {
+	return threshold > 0 && age > threshold
+}
+
+// Staleness reports how long ago the last batch was posted and the last block was validated.
+func (a *StalenessAPI) Staleness(ctx context.Context) (*StalenessResult, error) {
+	config := a.config.Get()
+	result := &StalenessResult{}
+	if a.batchPoster != nil {
+		lastPosted := a.batchPoster.LastPosted()
+		var lastTime time.Time
+		if lastPosted != nil {
+			lastTime = lastPosted.Time
+		}
+		age := a.age(lastTime)
+		result.BatchPosting = &BatchPostingStaleness{
+			LastPosted: lastPosted,
+			AgeSeconds: age.Seconds(),
+			Stale:      isStale(age, config.BatchPoster.StaleThreshold),
+		}
+	}
+	if a.blockValidator != nil {
+		lastValidated, err := a.blockValidator.ReadLastValidatedInfo()
+		if err != nil {
+			return nil, err
+		}
+		age := a.age(a.blockValidator.LastValidatedTime())
+		result.Validation = &ValidationStaleness{
+			LastValidated: lastValidated,
+			AgeSeconds:    age.Seconds(),
+			Stale:         isStale(age, config.BlockValidator.StaleThreshold),
+		}
+	}
+	return result, nil
+}
+
+type BatchReplayAPI struct {
+	inboxReader *InboxReader
+}
+
+func (a *BatchReplayAPI) ReplayBatch(ctx context.Context, seqNum hexutil.Uint64) (*BatchReplayResult, error) {
+	return a.inboxReader.ReplayBatch(ctx, uint64(seqNum))
+}
+
+func (a *BatchReplayAPI) ReplayBatchByTxHash(ctx context.Context, txHash common.Hash) (*BatchReplayResult, error) {
+	return a.inboxReader.ReplayBatchByTxHash(ctx, txHash)
+}
+
+type BatchInfoAPI struct {
+	inboxReader *InboxReader
+}
+
+// BatchInfo returns where the batch with the given sequence number was posted on the parent chain, and its blocks
+func (a *BatchInfoAPI) BatchInfo(ctx context.Context, seqNum hexutil.Uint64) (*BatchInfo, error) {
+	return a.inboxReader.BatchInfo(ctx, uint64(seqNum))
+}
+
+// BatchInfoForBlock is like BatchInfo, for the batch containing the given block
+func (a *BatchInfoAPI) BatchInfoForBlock(ctx context.Context, blockNum hexutil.Uint64) (*BatchInfo, error) {
+	return a.inboxReader.BatchInfoForBlock(ctx, uint64(blockNum))
+}
+
+type DelayedMessageAPI struct {
+	inboxReader *InboxReader
+}
+
+func (a *DelayedMessageAPI) DelayedMessage(ctx context.Context, index hexutil.Uint64) (*DelayedMessageResult, error) {
+	return a.inboxReader.InspectDelayedMessage(ctx, uint64(index))
+}
+
+type FeedInputAPI struct {
+	clients  *broadcastclients.BroadcastClients
+	streamer *TransactionStreamer
+}
+
+type FeedInputLagResult struct {
+	Connected                   int32           `json:"connected"`
+	LastReceivedSequenceNumber  *hexutil.Uint64 `json:"lastReceivedSequenceNumber,omitempty"`
+	LastProcessedSequenceNumber *hexutil.Uint64 `json:"lastProcessedSequenceNumber,omitempty"`
+	MessagesBehind              uint64          `json:"messagesBehind"`
+	SecondsSinceLastMessage     *float64        `json:"secondsSinceLastMessage,omitempty"`
+}
+
+// FeedInputLag compares the last message received from the feed to the last one the node processed.
+// If few messages are behind but it's long since the last message, the feed relay is slow, not the node.
+func (a *FeedInputAPI) FeedInputLag(ctx context.Context) (*FeedInputLagResult, error) {
+	result := &FeedInputLagResult{
+		Connected: a.clients.Connected(),
+	}
+	// messages executed, as messages received from the feed are stored right away
+	msgCount, err := a.streamer.GetProcessedMessageCount()
+	if err != nil {
+		return nil, err
+	}
+	if msgCount > 0 {
+		processed := hexutil.Uint64(msgCount - 1)
+		result.LastProcessedSequenceNumber = &processed
+	}
+	received, receivedTime, ok := a.clients.LastReceived()
+	if ok {
+		receivedSeqNum := hexutil.Uint64(received)
+		result.LastReceivedSequenceNumber = &receivedSeqNum
+		if received >= msgCount {
+			result.MessagesBehind = uint64(received - msgCount + 1)
+		}
+		sinceLast := time.Since(receivedTime).Seconds()
+		result.SecondsSinceLastMessage = &sinceLast
+	}
+	return result, nil
+}
+
+type FeedOutputAPI struct {
+	broadcaster *broadcaster.Broadcaster
+	config      wsbroadcastserver.BroadcasterConfigFetcher
+}
+
+func NewFeedOutputAPI(broadcaster *broadcaster.Broadcaster, config wsbroadcastserver.BroadcasterConfigFetcher) *FeedOutputAPI {
+	return &FeedOutputAPI{broadcaster: broadcaster, config: config}
+}
+
+func (a *FeedOutputAPI) FeedOutputStats(ctx context.Context) (wsbroadcastserver.FeedStats, error) {
+	return a.broadcaster.Stats(), nil
+}
+
+// FeedSignerRotation is the result of rotating the feed signer
+type FeedSignerRotation struct {
+	Signer common.Address `json:"signer"`
+	// always false, the node signs with its configured key again once it restarts
+	Persisted bool   `json:"persisted"`
+	Note      string `json:"note"`
+}
+
+const feedSignerRotationNote = "the rotation only lasts until the node restarts, update the node's configured signing key to keep it"
+
+// RotateFeedSignerFromKeystore switches the signed feed output to sign with the key of an encrypted keystore file
+// in the configured key directory, decrypted with the password in the allowed environment variable passwordEnv
+func (a *FeedOutputAPI) RotateFeedSignerFromKeystore(ctx context.Context, keystoreFile string, passwordEnv string) (*FeedSignerRotation, error) {
+	path, err := a.keyFilePath(keystoreFile)
+	if err != nil {
+		return nil, err
+	}
+	password, err := a.keyEnv(passwordEnv)
+	if err != nil {
+		return nil, err
+	}
+	keyJson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed signer keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJson, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt feed signer keystore: %w", err)
+	}
+	return a.rotateFeedSigner(key.PrivateKey)
+}
+
+// RotateFeedSignerFromEnv switches the signed feed output to sign with the hex encoded private key
+// in the allowed environment variable privateKeyEnv
+func (a *FeedOutputAPI) RotateFeedSignerFromEnv(ctx context.Context, privateKeyEnv string) (*FeedSignerRotation, error) {
+	privateKey, err := a.keyEnv(privateKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed signer private key in %v: %w", privateKeyEnv, err)
+	}
+	return a.rotateFeedSigner(key)
+}
+
+// RotateFeedSignerFromFile switches the signed feed output to sign with the hex encoded private key
+// in a file in the configured key directory
+func (a *FeedOutputAPI) RotateFeedSignerFromFile(ctx context.Context, keyFile string) (*FeedSignerRotation, error) {
+	path, err := a.keyFilePath(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.LoadECDSA(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed signer private key: %w", err)
+	}
+	return a.rotateFeedSigner(key)
+}
+
+// keyFilePath resolves file inside the configured key directory, refusing paths (or symlinks) leading out of it
+func (a *FeedOutputAPI) keyFilePath(file string) (string, error) {
+	keyDir := a.config().SignerRotation.KeyDir
+	if keyDir == "" {
+		return "", errors.New("no feed signer key directory configured")
+	}
+	keyDir, err := filepath.Abs(keyDir)
+	if err != nil {
+		return "", err
+	}
+	keyDir, err = filepath.EvalSymlinks(keyDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve feed signer key directory: %w", err)
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(keyDir, path)
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve feed signer key file %v: %w", file, err)
+	}
+	rel, err := filepath.Rel(keyDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("feed signer key file %v is not in the configured key directory", file)
+	}
+	return path, nil
+}
+
+// keyEnv reads the environment variable name if it's allowed to hold feed signer keys or passwords
+func (a *FeedOutputAPI) keyEnv(name string) (string, error) {
+	allowed := false
+	for _, env := range a.config().SignerRotation.KeyEnvs {
+		if env == name {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("environment variable %v is not allowed for feed signer keys", name)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("feed signer environment variable %v not set", name)
+	}
+	return value, nil
+}
+
+func (a *FeedOutputAPI) rotateFeedSigner(key *ecdsa.PrivateKey) (*FeedSignerRotation, error) {
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	err := a.broadcaster.RotateDataSigner(signature.DataSignerFromPrivateKey(key), address)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedSignerRotation{Signer: address, Persisted: false, Note: feedSignerRotationNote}, nil
+}
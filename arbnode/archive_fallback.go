@@ -0,0 +1,141 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	archiveFallbackRequestsCounter = metrics.NewRegisteredCounter("arb/rpc/archivefallback/requests", nil)
+	archiveFallbackErrorsCounter   = metrics.NewRegisteredCounter("arb/rpc/archivefallback/errors", nil)
+	prunedStateErrorsCounter       = metrics.NewRegisteredCounter("arb/rpc/prunedstate/errors", nil)
+)
+
+// errors returned by the local node when the requested state isn't stored
+var missingStateErrors = []string{
+	"missing trie node",
+	"historical state",
+	"state is not available",
+}
+
+func isMissingStateError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, msg := range missingStateErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHistorical returns whether blockNrOrHash refers to a specific past block rather than the chain head
+func isHistorical(blockNrOrHash *json.RawMessage) bool {
+	if blockNrOrHash == nil {
+		return false
+	}
+	var parsed rpc.BlockNumberOrHash
+	if err := json.Unmarshal(*blockNrOrHash, &parsed); err != nil {
+		return false
+	}
+	if _, ok := parsed.Hash(); ok {
+		return true
+	}
+	number, ok := parsed.Number()
+	return ok && number >= 0
+}
+
+// HistoricalStateAPI overrides read-only state methods of the eth namespace. Calls are served locally,
+// and only if they're for a historical block whose state isn't stored locally, they're forwarded to the
+// archive node if there is one, or else fail with a PrunedStateError if stateRange is set.
+type HistoricalStateAPI struct {
+	local      *rpc.Client
+	archive    *rpc.Client
+	stateRange *StateRangeFinder
+}
+
+// NewHistoricalStateAPI serves the calls locally from the eth namespace apis of ethAPIs,
+// which should be the apis already registered for the eth namespace.
+// Both archiveUrl and stateRange are optional.
+func NewHistoricalStateAPI(archiveUrl string, stateRange *StateRangeFinder, ethAPIs []rpc.API) (*HistoricalStateAPI, error) {
+	server := rpc.NewServer()
+	for _, api := range ethAPIs {
+		if api.Namespace != "eth" {
+			continue
+		}
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	api := &HistoricalStateAPI{
+		local:      rpc.DialInProc(server),
+		stateRange: stateRange,
+	}
+	if archiveUrl != "" {
+		archive, err := rpc.DialContext(context.Background(), archiveUrl)
+		if err != nil {
+			return nil, err
+		}
+		api.archive = archive
+	}
+	return api, nil
+}
+
+func (a *HistoricalStateAPI) forward(ctx context.Context, method string, blockNrOrHash *json.RawMessage, args ...*json.RawMessage) (json.RawMessage, error) {
+	result, err := callRaw(ctx, a.local, method, args...)
+	if !isMissingStateError(err) || !isHistorical(blockNrOrHash) {
+		return result, err
+	}
+	if a.archive != nil {
+		archiveFallbackRequestsCounter.Inc(1)
+		log.Debug("forwarding historical state request to archive node", "method", method, "block", string(*blockNrOrHash))
+		result, archiveErr := callRaw(ctx, a.archive, method, args...)
+		if archiveErr == nil {
+			return result, nil
+		}
+		archiveFallbackErrorsCounter.Inc(1)
+		log.Warn("archive fallback request failed", "method", method, "err", archiveErr)
+	}
+	if a.stateRange != nil {
+		if prunedErr := a.stateRange.prunedStateError(*blockNrOrHash); prunedErr != nil {
+			prunedStateErrorsCounter.Inc(1)
+			return nil, prunedErr
+		}
+	}
+	return nil, err
+}
+
+func (a *HistoricalStateAPI) GetBalance(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getBalance", blockNrOrHash, &address, blockNrOrHash)
+}
+
+func (a *HistoricalStateAPI) GetCode(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getCode", blockNrOrHash, &address, blockNrOrHash)
+}
+
+func (a *HistoricalStateAPI) GetStorageAt(ctx context.Context, address json.RawMessage, key json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getStorageAt", blockNrOrHash, &address, &key, blockNrOrHash)
+}
+
+func (a *HistoricalStateAPI) GetTransactionCount(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getTransactionCount", blockNrOrHash, &address, blockNrOrHash)
+}
+
+func (a *HistoricalStateAPI) Call(ctx context.Context, args json.RawMessage, blockNrOrHash *json.RawMessage, overrides *json.RawMessage, blockOverrides *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_call", blockNrOrHash, &args, blockNrOrHash, overrides, blockOverrides)
+}
+
+func (a *HistoricalStateAPI) Close() {
+	if a.archive != nil {
+		a.archive.Close()
+	}
+}
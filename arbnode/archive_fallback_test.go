@@ -0,0 +1,56 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestArchiveFallbackIsHistorical(t *testing.T) {
+	for param, expected := range map[string]bool{
+		`"latest"`:    false,
+		`"pending"`:   false,
+		`"safe"`:      false,
+		`"finalized"`: false,
+		`"earliest"`:  true,
+		`"0x10"`:      true,
+		`{"blockHash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`: true,
+	} {
+		raw := json.RawMessage(param)
+		if isHistorical(&raw) != expected {
+			t.Error("unexpected isHistorical result for", param, "expected", expected)
+		}
+	}
+	if isHistorical(nil) {
+		t.Error("unset block should not be historical")
+	}
+}
+
+func TestArchiveFallbackIsMissingStateError(t *testing.T) {
+	if !isMissingStateError(errors.New("missing trie node 1a2b (path ) <nil>")) {
+		t.Error("expected missing trie node to be a missing state error")
+	}
+	if isMissingStateError(errors.New("execution reverted")) {
+		t.Error("expected revert not to be a missing state error")
+	}
+	if isMissingStateError(nil) {
+		t.Error("expected nil not to be a missing state error")
+	}
+}
+
+func TestPrunedStateError(t *testing.T) {
+	err := &PrunedStateError{Block: 10, OldestAvailable: 900, Latest: 1000}
+	if err.Error() != "state pruned at block 10, oldest available 900 (latest 1000)" {
+		t.Error("unexpected pruned state error message", err.Error())
+	}
+	data, marshalErr := json.Marshal(err.ErrorData())
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	if string(data) != `{"block":10,"oldestAvailable":900,"latest":1000}` {
+		t.Error("unexpected pruned state error data", string(data))
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/staker"
+)
+
+var ErrBlockNotInBatch = errors.New("block not yet posted in a batch")
+
+// BatchInfo describes where a sequencer batch was posted on the parent chain, and the blocks it contains.
+type BatchInfo struct {
+	SequenceNumber   uint64      `json:"sequenceNumber"`
+	ParentChainBlock uint64      `json:"parentChainBlock"`
+	TxHash           common.Hash `json:"txHash"`
+	FirstBlock       uint64      `json:"firstBlock"`
+	BlockCount       uint64      `json:"blockCount"`
+}
+
+// BatchInfo returns the batch with the given sequence number, reading the posting transaction from the parent chain.
+func (r *InboxReader) BatchInfo(ctx context.Context, seqNum uint64) (*BatchInfo, error) {
+	metadata, err := r.tracker.GetBatchMetadata(seqNum)
+	if err != nil {
+		return nil, err
+	}
+	var prevMessageCount arbutil.MessageIndex
+	if seqNum > 0 {
+		prevMessageCount, err = r.tracker.GetBatchMessageCount(seqNum - 1)
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata of previous batch: %w", err)
+		}
+	}
+	batch, err := r.lookupBatch(ctx, metadata.ParentChainBlock, func(batch *SequencerInboxBatch) bool {
+		return batch.SequenceNumber == seqNum
+	})
+	if err != nil {
+		return nil, err
+	}
+	genesis := r.tracker.txStreamer.GenesisBlockNumber()
+	return &BatchInfo{
+		SequenceNumber:   seqNum,
+		ParentChainBlock: metadata.ParentChainBlock,
+		TxHash:           batch.rawLog.TxHash,
+		FirstBlock:       uint64(arbutil.MessageCountToBlockNumber(prevMessageCount, genesis) + 1),
+		BlockCount:       uint64(metadata.MessageCount - prevMessageCount),
+	}, nil
+}
+
+// BatchInfoForBlock returns the batch which contains the given block, see BatchInfo.
+func (r *InboxReader) BatchInfoForBlock(ctx context.Context, blockNum uint64) (*BatchInfo, error) {
+	genesis := r.tracker.txStreamer.GenesisBlockNumber()
+	if blockNum <= genesis {
+		return nil, fmt.Errorf("block %v is part of genesis", blockNum)
+	}
+	batchCount, err := r.tracker.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+	if batchCount == 0 {
+		return nil, fmt.Errorf("%w: block %v, no batches read yet", ErrBlockNotInBatch, blockNum)
+	}
+	postedCount, err := r.tracker.GetBatchMessageCount(batchCount - 1)
+	if err != nil {
+		return nil, err
+	}
+	pos := arbutil.BlockNumberToMessageCount(blockNum, genesis) - 1
+	if pos >= postedCount {
+		return nil, fmt.Errorf("%w: block %v, last posted block %v", ErrBlockNotInBatch, blockNum, arbutil.MessageCountToBlockNumber(postedCount, genesis))
+	}
+	seqNum, err := staker.FindBatchContainingMessageIndex(r.tracker, pos, batchCount-1)
+	if err != nil {
+		return nil, err
+	}
+	return r.BatchInfo(ctx, seqNum)
+}
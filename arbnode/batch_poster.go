@@ -34,6 +34,7 @@ import (
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster"
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/das"
@@ -47,6 +48,7 @@ import (
 var (
 	batchPosterWalletBalance      = metrics.NewRegisteredGaugeFloat64("arb/batchposter/wallet/balanceether", nil)
 	batchPosterGasRefunderBalance = metrics.NewRegisteredGaugeFloat64("arb/batchposter/gasrefunder/balanceether", nil)
+	batchPosterLastPostedAgeGauge = metrics.NewRegisteredGauge("arb/batchposter/last_posted_age", nil)
 )
 
 type batchPosterPosition struct {
@@ -77,6 +79,18 @@ type BatchPoster struct {
 	lastHitL1Bounds time.Time // The last time we wanted to post a message but hit the L1 bounds
 
 	batchReverted atomic.Bool // indicates whether data poster batch was reverted
+	lastPosted    atomic.Pointer[LastPostedBatchInfo]
+
+	// only set if batches are checked against the feed output before being posted
+	feed     *broadcaster.Broadcaster
+	fatalErr chan<- error
+}
+
+// LastPostedBatchInfo describes the most recent batch sent by this batch poster.
+type LastPostedBatchInfo struct {
+	Time           time.Time   `json:"time"`
+	SequenceNumber uint64      `json:"sequenceNumber"`
+	TxHash         common.Hash `json:"txHash"`
 }
 
 type l1BlockBound int
@@ -114,6 +128,10 @@ type BatchPosterConfig struct {
 	ParentChainWallet  genericconf.WalletConfig    `koanf:"parent-chain-wallet"`
 	L1BlockBound       string                      `koanf:"l1-block-bound" reload:"hot"`
 	L1BlockBoundBypass time.Duration               `koanf:"l1-block-bound-bypass" reload:"hot"`
+	StaleThreshold     time.Duration               `koanf:"stale-threshold" reload:"hot"`
+	// Decode batches before posting them and compare them to the feed output.
+	CheckFeedConsistency      bool `koanf:"check-feed-consistency"`
+	FeedConsistencyRecordSize int  `koanf:"feed-consistency-record-size"`
 
 	gasRefunder  common.Address
 	l1BlockBound l1BlockBound
@@ -140,7 +158,10 @@ func (c *BatchPosterConfig) Validate() error {
 	} else {
 		return fmt.Errorf("invalid L1 block bound tag \"%v\" (see --help for options)", c.L1BlockBound)
 	}
-	return nil
+	if c.CheckFeedConsistency && c.FeedConsistencyRecordSize <= 0 {
+		return errors.New("feed-consistency-record-size must be positive when checking feed consistency")
+	}
+	return c.DataPoster.Validate()
 }
 
 type BatchPosterConfigFetcher func() *BatchPosterConfig
@@ -160,6 +181,9 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".redis-url", DefaultBatchPosterConfig.RedisUrl, "if non-empty, the Redis URL to store queued transactions in")
 	f.String(prefix+".l1-block-bound", DefaultBatchPosterConfig.L1BlockBound, "only post messages to batches when they're within the max future block/timestamp as of this L1 block tag (\"safe\", \"finalized\", \"latest\", or \"ignore\" to ignore this check)")
 	f.Duration(prefix+".l1-block-bound-bypass", DefaultBatchPosterConfig.L1BlockBoundBypass, "post batches even if not within the layer 1 future bounds if we're within this margin of the max delay")
+	f.Duration(prefix+".stale-threshold", DefaultBatchPosterConfig.StaleThreshold, "report batch posting as stale, and the status page /healthz as unhealthy, if no batch was posted for this long (0 to disable)")
+	f.Bool(prefix+".check-feed-consistency", DefaultBatchPosterConfig.CheckFeedConsistency, "decode each batch before posting it and stop the node if its messages don't match the ones sent out over the feed (requires the feed output)")
+	f.Int(prefix+".feed-consistency-record-size", DefaultBatchPosterConfig.FeedConsistencyRecordSize, "how many of the latest feed messages to keep for the feed consistency check")
 	redislock.AddConfigOptions(prefix+".redis-lock", f)
 	dataposter.DataPosterConfigAddOptions(prefix+".data-poster", f)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultBatchPosterConfig.ParentChainWallet.Pathname)
@@ -182,6 +206,9 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	ParentChainWallet:  DefaultBatchPosterL1WalletConfig,
 	L1BlockBound:       "",
 	L1BlockBoundBypass: time.Hour,
+
+	CheckFeedConsistency:      false,
+	FeedConsistencyRecordSize: 50_000,
 }
 
 var DefaultBatchPosterL1WalletConfig = genericconf.WalletConfig{
@@ -190,6 +217,7 @@ var DefaultBatchPosterL1WalletConfig = genericconf.WalletConfig{
 	PrivateKey:    genericconf.WalletConfigDefault.PrivateKey,
 	Account:       genericconf.WalletConfigDefault.Account,
 	OnlyCreateKey: genericconf.WalletConfigDefault.OnlyCreateKey,
+	BackupDir:     genericconf.WalletConfigDefault.BackupDir,
 }
 
 var TestBatchPosterConfig = BatchPosterConfig{
@@ -207,6 +235,9 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	ParentChainWallet:  DefaultBatchPosterL1WalletConfig,
 	L1BlockBound:       "",
 	L1BlockBoundBypass: time.Hour,
+
+	CheckFeedConsistency:      false,
+	FeedConsistencyRecordSize: 50_000,
 }
 
 func NewBatchPoster(dataPosterDB ethdb.Database, l1Reader *headerreader.HeaderReader, inbox *InboxTracker, streamer *TransactionStreamer, syncMonitor *SyncMonitor, config BatchPosterConfigFetcher, deployInfo *chaininfo.RollupAddresses, transactOpts *bind.TransactOpts, daWriter das.DataAvailabilityServiceWriter) (*BatchPoster, error) {
@@ -693,7 +724,7 @@ func (b *BatchPoster) estimateGas(ctx context.Context, sequencerMessage []byte,
 		)
 		return 0, fmt.Errorf("error estimating gas for batch: %w", err)
 	}
-	return gas + config.ExtraBatchGas, nil
+	return b.dataPoster.BufferGasEstimate(gas) + config.ExtraBatchGas, nil
 }
 
 const ethPosBlockTime = 12 * time.Second
@@ -868,6 +899,10 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		return false, nil
 	}
 
+	if err := b.checkFeedConsistency(ctx, batchPosition, sequencerMsg); err != nil {
+		return false, err
+	}
+
 	if b.daWriter != nil {
 		cert, err := b.daWriter.Store(ctx, sequencerMsg, uint64(time.Now().Add(config.DASRetentionPeriod).Unix()), []byte{}) // b.daWriter will append signature if enabled
 		if errors.Is(err, das.BatchToDasFailed) {
@@ -902,6 +937,12 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if err != nil {
 		return false, err
 	}
+	b.lastPosted.Store(&LastPostedBatchInfo{
+		Time:           time.Now(),
+		SequenceNumber: batchPosition.NextSeqNum,
+		TxHash:         tx.Hash(),
+	})
+	batchPosterLastPostedAgeGauge.Update(0)
 	log.Info(
 		"BatchPoster: batch sent",
 		"sequence nr.", batchPosition.NextSeqNum,
@@ -951,6 +992,11 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	return true, nil
 }
 
+// LastPosted returns the most recent batch sent since startup, or nil if none was sent yet.
+func (b *BatchPoster) LastPosted() *LastPostedBatchInfo {
+	return b.lastPosted.Load()
+}
+
 func (b *BatchPoster) Start(ctxIn context.Context) {
 	b.dataPoster.Start(ctxIn)
 	b.redisLock.Start(ctxIn)
@@ -958,6 +1004,9 @@ func (b *BatchPoster) Start(ctxIn context.Context) {
 	b.LaunchThread(b.pollForReverts)
 	b.CallIteratively(func(ctx context.Context) time.Duration {
 		var err error
+		if lastPosted := b.LastPosted(); lastPosted != nil {
+			batchPosterLastPostedAgeGauge.Update(int64(time.Since(lastPosted.Time).Seconds()))
+		}
 		if common.HexToAddress(b.config().GasRefunderAddress) != (common.Address{}) {
 			gasRefunderBalance, err := b.l1Reader.Client().BalanceAt(ctx, common.HexToAddress(b.config().GasRefunderAddress), nil)
 			if err != nil {
@@ -0,0 +1,124 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type BatchReplayResult struct {
+	SequenceNumber   uint64                           `json:"sequenceNumber"`
+	ParentChainBlock uint64                           `json:"parentChainBlock"`
+	TxHash           common.Hash                      `json:"txHash"`
+	DataLength       int                              `json:"dataLength"`
+	DelayedRead      uint64                           `json:"delayedMessagesRead"`
+	Messages         []arbostypes.MessageWithMetadata `json:"messages"`
+	Errors           []string                         `json:"errors"`
+}
+
+func (r *InboxReader) lookupBatch(ctx context.Context, parentChainBlock uint64, match func(*SequencerInboxBatch) bool) (*SequencerInboxBatch, error) {
+	blockNum := arbmath.UintToBig(parentChainBlock)
+	seqBatches, err := r.sequencerInbox.LookupBatchesInRange(ctx, blockNum, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	for _, batch := range seqBatches {
+		if match(batch) {
+			return batch, nil
+		}
+	}
+	return nil, fmt.Errorf("sequencer batch not found in parent chain block %v", parentChainBlock)
+}
+
+// ReplayBatch re-reads the batch with the given sequence number from the parent chain
+// and decodes it again without touching any stored state.
+func (r *InboxReader) ReplayBatch(ctx context.Context, seqNum uint64) (*BatchReplayResult, error) {
+	metadata, err := r.tracker.GetBatchMetadata(seqNum)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := r.lookupBatch(ctx, metadata.ParentChainBlock, func(batch *SequencerInboxBatch) bool {
+		return batch.SequenceNumber == seqNum
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.replayBatch(ctx, batch)
+}
+
+// ReplayBatchByTxHash is like ReplayBatch, but finds the batch by the parent chain transaction that posted it.
+func (r *InboxReader) ReplayBatchByTxHash(ctx context.Context, txHash common.Hash) (*BatchReplayResult, error) {
+	receipt, err := r.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.BlockNumber.IsUint64() {
+		return nil, fmt.Errorf("transaction %v has non-uint64 block number %v", txHash, receipt.BlockNumber)
+	}
+	batch, err := r.lookupBatch(ctx, receipt.BlockNumber.Uint64(), func(batch *SequencerInboxBatch) bool {
+		return batch.rawLog.TxHash == txHash
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.replayBatch(ctx, batch)
+}
+
+func (r *InboxReader) replayBatch(ctx context.Context, batch *SequencerInboxBatch) (*BatchReplayResult, error) {
+	result := &BatchReplayResult{
+		SequenceNumber:   batch.SequenceNumber,
+		ParentChainBlock: batch.ParentChainBlockNumber,
+		TxHash:           batch.rawLog.TxHash,
+		Messages:         []arbostypes.MessageWithMetadata{},
+		Errors:           []string{},
+	}
+	var prevDelayed uint64
+	if batch.SequenceNumber > 0 {
+		prevMetadata, err := r.tracker.GetBatchMetadata(batch.SequenceNumber - 1)
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata of previous batch: %w", err)
+		}
+		prevDelayed = prevMetadata.DelayedMessageCount
+	}
+	log.Info("replaying sequencer batch", "seqNum", batch.SequenceNumber, "parentChainBlock", batch.ParentChainBlockNumber, "txHash", result.TxHash, "prevDelayed", prevDelayed, "afterDelayed", batch.AfterDelayedCount)
+	data, err := batch.Serialize(ctx, r.client)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to read batch data: %v", err))
+		return result, nil
+	}
+	result.DataLength = len(data)
+	log.Info("replay: read batch data", "seqNum", batch.SequenceNumber, "length", len(data))
+
+	backend := &multiplexerBackend{
+		batchSeqNum: batch.SequenceNumber,
+		batches:     []*SequencerInboxBatch{batch},
+
+		inbox:  r.tracker,
+		ctx:    ctx,
+		client: r.client,
+	}
+	multiplexer := arbstate.NewInboxMultiplexer(backend, prevDelayed, r.tracker.das, arbstate.KeysetValidate)
+	for len(backend.batches) > 0 {
+		msg, err := multiplexer.Pop(ctx)
+		if err != nil {
+			log.Warn("replay: failed to extract message", "seqNum", batch.SequenceNumber, "index", len(result.Messages), "err", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("message %v: %v", len(result.Messages), err))
+			break
+		}
+		if msg.Message.Header.Kind == arbostypes.L1MessageType_Invalid {
+			result.Errors = append(result.Errors, fmt.Sprintf("message %v: invalid message", len(result.Messages)))
+		}
+		log.Info("replay: extracted message", "seqNum", batch.SequenceNumber, "index", len(result.Messages), "kind", msg.Message.Header.Kind, "timestamp", msg.Message.Header.Timestamp, "l2MsgLength", len(msg.Message.L2msg), "delayedMessagesRead", msg.DelayedMessagesRead)
+		result.Messages = append(result.Messages, *msg)
+		result.DelayedRead = msg.DelayedMessagesRead
+	}
+	return result, nil
+}
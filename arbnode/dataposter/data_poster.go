@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
 	"sync"
@@ -18,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/go-redis/redis/v8"
@@ -35,6 +37,11 @@ import (
 	redisstorage "github.com/offchainlabs/nitro/arbnode/dataposter/redis"
 )
 
+var (
+	gasUsedPercentHistogram = metrics.NewRegisteredHistogram("arb/dataposter/gas/usedpercent", nil, metrics.NewBoundedHistogramSample())
+	gasUnusedCounter        = metrics.NewRegisteredCounter("arb/dataposter/gas/unused", nil)
+)
+
 // Dataposter implements functionality to post transactions on the chain. It
 // is initialized with specified sender/signer and keeps nonce of that address
 // as it posts transactions.
@@ -64,6 +71,11 @@ type DataPoster struct {
 	nonce      uint64
 	queue      QueueStorage
 	errorCount map[uint64]int // number of consecutive intermittent errors rbf-ing or sending, per nonce
+	// the tx-type in use, a reloaded one is only switched to once the parent chain is checked to support it
+	txType         string
+	rejectedTxType string // the last reloaded tx-type the parent chain didn't support, to only log it once
+	// confirmed transactions whose gas usage is still to be recorded, outside the mutex
+	unrecordedGasUsage []*storage.QueuedTransaction
 }
 
 type AttemptLocker interface {
@@ -133,9 +145,23 @@ func NewDataPoster(db ethdb.Database, headerReader *headerreader.HeaderReader, a
 		queue:             queue,
 		redisLock:         redisLock,
 		errorCount:        make(map[uint64]int),
+		txType:            initConfig.TxType,
 	}, nil
 }
 
+// BufferGasEstimate scales a parent chain gas estimate by the configured gas-estimate-multiplier.
+func (p *DataPoster) BufferGasEstimate(gas uint64) uint64 {
+	multiplier := p.config().GasEstimateMultiplier
+	if multiplier <= 1 {
+		return gas
+	}
+	buffered := float64(gas) * multiplier
+	if buffered >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return uint64(buffered)
+}
+
 func (p *DataPoster) Sender() common.Address {
 	return p.sender
 }
@@ -228,26 +254,38 @@ func (p *DataPoster) GetNextNonceAndMeta(ctx context.Context) (uint64, []byte, e
 
 const minRbfIncrease = arbmath.OneInBips * 11 / 10
 
-func (p *DataPoster) feeAndTipCaps(ctx context.Context, nonce uint64, gasLimit uint64, lastFeeCap *big.Int, lastTipCap *big.Int, dataCreatedAt time.Time, backlogOfBatches uint64) (*big.Int, *big.Int, error) {
+// feeAndTipCaps returns the fee cap and tip cap of a new transaction, and the base fee they were computed for
+func (p *DataPoster) feeAndTipCaps(ctx context.Context, nonce uint64, gasLimit uint64, lastFeeCap *big.Int, lastTipCap *big.Int, dataCreatedAt time.Time, backlogOfBatches uint64) (*big.Int, *big.Int, *big.Int, error) {
 	config := p.config()
 	latestHeader, err := p.headerReader.LastHeader(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	if latestHeader.BaseFee == nil {
-		return nil, nil, fmt.Errorf("latest parent chain block %v missing BaseFee (either the parent chain does not have EIP-1559 or the parent chain node is not synced)", latestHeader.Number)
+	baseFee := latestHeader.BaseFee
+	if baseFee == nil {
+		if p.txType != TxTypeLegacy {
+			return nil, nil, nil, fmt.Errorf("latest parent chain block %v missing BaseFee (either the parent chain does not have EIP-1559 or the parent chain node is not synced, consider tx-type %q)", latestHeader.Number, TxTypeLegacy)
+		}
+		// without EIP-1559 legacy transactions fall back to the suggested gas price
+		baseFee, err = p.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 	}
 	softConfBlock := arbmath.BigSubByUint(latestHeader.Number, config.NonceRbfSoftConfs)
 	softConfNonce, err := p.client.NonceAt(ctx, p.sender, softConfBlock)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get latest nonce %v blocks ago (block %v): %w", config.NonceRbfSoftConfs, softConfBlock, err)
+		return nil, nil, nil, fmt.Errorf("failed to get latest nonce %v blocks ago (block %v): %w", config.NonceRbfSoftConfs, softConfBlock, err)
 	}
-	newFeeCap := new(big.Int).Mul(latestHeader.BaseFee, big.NewInt(2))
+	newFeeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
 	newFeeCap = arbmath.BigMax(newFeeCap, arbmath.FloatToBig(config.MinFeeCapGwei*params.GWei))
 
-	newTipCap, err := p.client.SuggestGasTipCap(ctx)
-	if err != nil {
-		return nil, nil, err
+	newTipCap := new(big.Int)
+	if p.txType != TxTypeLegacy {
+		newTipCap, err = p.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 	}
 	newTipCap = arbmath.BigMax(newTipCap, arbmath.FloatToBig(config.MinTipCapGwei*params.GWei))
 	newTipCap = arbmath.BigMin(newTipCap, arbmath.FloatToBig(config.MaxTipCapGwei*params.GWei))
@@ -320,7 +358,7 @@ func (p *DataPoster) feeAndTipCaps(ctx context.Context, nonce uint64, gasLimit u
 		newTipCap = new(big.Int).Set(newFeeCap)
 	}
 
-	return newFeeCap, newTipCap, nil
+	return newFeeCap, newTipCap, baseFee, nil
 }
 
 func (p *DataPoster) PostTransaction(ctx context.Context, dataCreatedAt time.Time, nonce uint64, meta []byte, to common.Address, calldata []byte, gasLimit uint64, value *big.Int) (*types.Transaction, error) {
@@ -340,7 +378,7 @@ func (p *DataPoster) PostTransaction(ctx context.Context, dataCreatedAt time.Tim
 		return nil, fmt.Errorf("failed to update data poster balance: %w", err)
 	}
 
-	feeCap, tipCap, err := p.feeAndTipCaps(ctx, nonce, gasLimit, nil, nil, dataCreatedAt, 0)
+	feeCap, tipCap, baseFee, err := p.feeAndTipCaps(ctx, nonce, gasLimit, nil, nil, dataCreatedAt, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -353,7 +391,7 @@ func (p *DataPoster) PostTransaction(ctx context.Context, dataCreatedAt time.Tim
 		Value:     value,
 		Data:      calldata,
 	}
-	fullTx, err := p.signer(p.sender, types.NewTx(&inner))
+	fullTx, err := p.signer(p.sender, types.NewTx(p.txData(&inner, baseFee, nil)))
 	if err != nil {
 		return nil, fmt.Errorf("signing transaction: %w", err)
 	}
@@ -368,6 +406,29 @@ func (p *DataPoster) PostTransaction(ctx context.Context, dataCreatedAt time.Tim
 	return fullTx, p.sendTx(ctx, nil, &queuedTx)
 }
 
+// txData converts the queued dynamic fee transaction data into the transaction type in use.
+// Legacy transactions pay what the dynamic fee transaction would pay at baseFee, the base fee plus the tip up to the fee cap,
+// and at least minRbfIncrease more than a legacy transaction they replace.
+// the mutex must be held by the caller
+func (p *DataPoster) txData(inner *types.DynamicFeeTx, baseFee *big.Int, replacing *types.Transaction) types.TxData {
+	if p.txType == TxTypeLegacy {
+		gasPrice := arbmath.BigMin(arbmath.BigAdd(baseFee, inner.GasTipCap), inner.GasFeeCap)
+		if replacing != nil && replacing.Type() == types.LegacyTxType {
+			// can't exceed the fee cap, which was raised by at least as much over the replaced one's
+			gasPrice = arbmath.BigMax(gasPrice, arbmath.BigMulByBips(replacing.GasPrice(), minRbfIncrease))
+		}
+		return &types.LegacyTx{
+			Nonce:    inner.Nonce,
+			GasPrice: gasPrice,
+			Gas:      inner.Gas,
+			To:       inner.To,
+			Value:    inner.Value,
+			Data:     inner.Data,
+		}
+	}
+	return inner
+}
+
 // the mutex must be held by the caller
 func (p *DataPoster) saveTx(ctx context.Context, prevTx, newTx *storage.QueuedTransaction) error {
 	if prevTx != nil && prevTx.Data.Nonce != newTx.Data.Nonce {
@@ -401,7 +462,7 @@ func (p *DataPoster) sendTx(ctx context.Context, prevTx *storage.QueuedTransacti
 
 // The mutex must be held by the caller.
 func (p *DataPoster) replaceTx(ctx context.Context, prevTx *storage.QueuedTransaction, backlogOfBatches uint64) error {
-	newFeeCap, newTipCap, err := p.feeAndTipCaps(ctx, prevTx.Data.Nonce, prevTx.Data.Gas, prevTx.Data.GasFeeCap, prevTx.Data.GasTipCap, prevTx.Created, backlogOfBatches)
+	newFeeCap, newTipCap, baseFee, err := p.feeAndTipCaps(ctx, prevTx.Data.Nonce, prevTx.Data.Gas, prevTx.Data.GasFeeCap, prevTx.Data.GasTipCap, prevTx.Created, backlogOfBatches)
 	if err != nil {
 		return err
 	}
@@ -432,7 +493,7 @@ func (p *DataPoster) replaceTx(ctx context.Context, prevTx *storage.QueuedTransa
 	newTx.Sent = false
 	newTx.Data.GasFeeCap = newFeeCap
 	newTx.Data.GasTipCap = newTipCap
-	newTx.FullTx, err = p.signer(p.sender, types.NewTx(&newTx.Data))
+	newTx.FullTx, err = p.signer(p.sender, types.NewTx(p.txData(&newTx.Data, baseFee, prevTx.FullTx)))
 	if err != nil {
 		return err
 	}
@@ -472,6 +533,9 @@ func (p *DataPoster) updateNonce(ctx context.Context) error {
 		return nil
 	}
 	log.Info("Data poster transactions confirmed", "previousNonce", p.nonce, "newNonce", nonce, "previousL1Block", p.lastBlock, "newL1Block", header.Number)
+	if p.lastBlock != nil {
+		p.queueGasUsage(ctx, p.nonce, nonce)
+	}
 	if len(p.errorCount) > 0 {
 		for x := p.nonce; x < nonce; x++ {
 			delete(p.errorCount, x)
@@ -489,6 +553,44 @@ func (p *DataPoster) updateNonce(ctx context.Context) error {
 	return nil
 }
 
+const maxGasUsageReceipts = 16
+
+// Keeps the newly confirmed transactions to record their gas usage later, without holding the mutex.
+// The queue still contains them as it's pruned after this. The mutex must be held by the caller.
+func (p *DataPoster) queueGasUsage(ctx context.Context, fromNonce uint64, toNonce uint64) {
+	confirmed, err := p.queue.FetchContents(ctx, fromNonce, arbmath.MinInt(toNonce-fromNonce, maxGasUsageReceipts))
+	if err != nil {
+		log.Debug("Failed to fetch confirmed transactions to record gas usage", "err", err)
+		return
+	}
+	p.unrecordedGasUsage = append(p.unrecordedGasUsage, confirmed...)
+	if len(p.unrecordedGasUsage) > maxGasUsageReceipts {
+		p.unrecordedGasUsage = p.unrecordedGasUsage[len(p.unrecordedGasUsage)-maxGasUsageReceipts:]
+	}
+}
+
+// Compares the gas limits of the confirmed transactions queued by queueGasUsage to the gas they used,
+// to measure the gas estimation accuracy. The mutex must not be held, receipts are fetched without it.
+func (p *DataPoster) recordGasUsage(ctx context.Context) {
+	p.mutex.Lock()
+	confirmed := p.unrecordedGasUsage
+	p.unrecordedGasUsage = nil
+	p.mutex.Unlock()
+	for _, tx := range confirmed {
+		if tx.FullTx == nil || tx.Data.Gas == 0 {
+			continue
+		}
+		receipt, err := p.client.TransactionReceipt(ctx, tx.FullTx.Hash())
+		if err != nil {
+			// an earlier version of the transaction may have been included instead
+			log.Debug("Failed to get receipt to record gas usage", "nonce", tx.Data.Nonce, "txHash", tx.FullTx.Hash(), "err", err)
+			continue
+		}
+		gasUsedPercentHistogram.Update(int64(receipt.GasUsed * 100 / tx.Data.Gas))
+		gasUnusedCounter.Inc(int64(arbmath.SaturatingUSub(tx.Data.Gas, receipt.GasUsed)))
+	}
+}
+
 // Updates dataposter balance to balance at pending block.
 func (p *DataPoster) updateBalance(ctx context.Context) error {
 	// Use the pending (representated as -1) balance because we're looking at batches we'd post,
@@ -523,12 +625,68 @@ func (p *DataPoster) maybeLogError(err error, tx *storage.QueuedTransaction, msg
 
 const minWait = time.Second * 10
 
+// checkParentChainTxType fails if a parent chain with the given latest header can't include transactions of txType
+func checkParentChainTxType(txType string, header *types.Header) error {
+	switch txType {
+	case TxTypeLegacy:
+		return nil
+	case TxTypeDynamicFee:
+		if header.BaseFee == nil {
+			return fmt.Errorf("parent chain block %v has no BaseFee, the parent chain doesn't support EIP-1559 (consider tx-type %q)", header.Number, TxTypeLegacy)
+		}
+		return nil
+	default:
+		// blob transactions are rejected by config validation, the parent chain header carries no blob fee we could check
+		return fmt.Errorf("tx-type %q is not supported by the parent chain", txType)
+	}
+}
+
+// CheckParentChainTxType fails if the connected parent chain doesn't support the tx-type in use
+func (p *DataPoster) CheckParentChainTxType(ctx context.Context) error {
+	p.mutex.Lock()
+	txType := p.txType
+	p.mutex.Unlock()
+	latestHeader, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest parent chain header to check the data poster tx-type: %w", err)
+	}
+	return checkParentChainTxType(txType, latestHeader)
+}
+
+// updateTxType switches to a reloaded tx-type once it's checked to be supported by the parent chain,
+// and keeps using the current one otherwise.
+// the mutex must be held by the caller
+func (p *DataPoster) updateTxType(ctx context.Context) {
+	txType := p.config().TxType
+	if txType == p.txType {
+		return
+	}
+	latestHeader, err := p.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Warn("failed to get latest parent chain header to check the reloaded data poster tx-type", "err", err)
+		return
+	}
+	if err := checkParentChainTxType(txType, latestHeader); err != nil {
+		if txType != p.rejectedTxType {
+			log.Error("reloaded data poster tx-type isn't supported by the parent chain, keeping the current one", "txType", p.txType, "reloaded", txType, "err", err)
+			p.rejectedTxType = txType
+		}
+		return
+	}
+	log.Info("switching data poster tx-type", "previous", p.txType, "txType", txType)
+	p.txType = txType
+	p.rejectedTxType = ""
+}
+
 // Tries to acquire redis lock, updates balance and nonce,
 func (p *DataPoster) Start(ctxIn context.Context) {
 	p.StopWaiter.Start(ctxIn, p)
 	p.CallIteratively(func(ctx context.Context) time.Duration {
+		// deferred before the mutex unlock, so receipts are fetched once the mutex is unlocked
+		defer p.recordGasUsage(ctx)
 		p.mutex.Lock()
 		defer p.mutex.Unlock()
+		p.updateTxType(ctx)
 		if !p.redisLock.AttemptLock(ctx) {
 			return minWait
 		}
@@ -631,6 +789,28 @@ type DataPosterConfig struct {
 	UseLevelDB             bool    `koanf:"use-leveldb"`
 	UseNoOpStorage         bool    `koanf:"use-noop-storage"`
 	LegacyStorageEncoding  bool    `koanf:"legacy-storage-encoding" reload:"hot"`
+	TxType                 string  `koanf:"tx-type" reload:"hot"`
+	GasEstimateMultiplier  float64 `koanf:"gas-estimate-multiplier" reload:"hot"`
+}
+
+const (
+	TxTypeLegacy     = "legacy"
+	TxTypeDynamicFee = "dynamic-fee"
+	TxTypeBlob       = "blob"
+)
+
+func (c *DataPosterConfig) Validate() error {
+	if c.GasEstimateMultiplier < 1 {
+		return fmt.Errorf("invalid data poster gas-estimate-multiplier %v, must be at least 1", c.GasEstimateMultiplier)
+	}
+	switch c.TxType {
+	case TxTypeLegacy, TxTypeDynamicFee:
+		return nil
+	case TxTypeBlob:
+		return errors.New("blob transactions are not supported by this version of the data poster")
+	default:
+		return fmt.Errorf("invalid data poster tx-type \"%v\" (see --help for options)", c.TxType)
+	}
 }
 
 // ConfigFetcher function type is used instead of directly passing config so
@@ -652,6 +832,8 @@ func DataPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".use-leveldb", DefaultDataPosterConfig.UseLevelDB, "uses leveldb when enabled")
 	f.Bool(prefix+".use-noop-storage", DefaultDataPosterConfig.UseNoOpStorage, "uses noop storage, it doesn't store anything")
 	f.Bool(prefix+".legacy-storage-encoding", DefaultDataPosterConfig.LegacyStorageEncoding, "encodes items in a legacy way (as it was before dropping generics)")
+	f.String(prefix+".tx-type", DefaultDataPosterConfig.TxType, "type of parent chain transactions to post (\"dynamic-fee\" or \"legacy\"), the node refuses to start if the parent chain doesn't support it and keeps the previous type if a reloaded one isn't supported")
+	f.Float64(prefix+".gas-estimate-multiplier", DefaultDataPosterConfig.GasEstimateMultiplier, "multiply parent chain gas estimates by this before adding any extra gas, to buffer against changes in state between estimation and inclusion")
 	signature.SimpleHmacConfigAddOptions(prefix+".redis-signer", f)
 }
 
@@ -668,6 +850,8 @@ var DefaultDataPosterConfig = DataPosterConfig{
 	UseLevelDB:             true,
 	UseNoOpStorage:         false,
 	LegacyStorageEncoding:  true,
+	TxType:                 TxTypeDynamicFee,
+	GasEstimateMultiplier:  1,
 }
 
 var DefaultDataPosterConfigForValidator = func() DataPosterConfig {
@@ -689,6 +873,8 @@ var TestDataPosterConfig = DataPosterConfig{
 	AllocateMempoolBalance: true,
 	UseLevelDB:             false,
 	UseNoOpStorage:         false,
+	TxType:                 TxTypeDynamicFee,
+	GasEstimateMultiplier:  1,
 }
 
 var TestDataPosterConfigForValidator = func() DataPosterConfig {
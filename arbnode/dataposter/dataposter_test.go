@@ -1,10 +1,19 @@
 package dataposter
 
 import (
+	"context"
+	"math"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
+	"github.com/offchainlabs/nitro/arbutil"
 )
 
 func TestParseReplacementTimes(t *testing.T) {
@@ -41,3 +50,177 @@ func TestParseReplacementTimes(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTxType(t *testing.T) {
+	for _, tc := range []struct {
+		txType  string
+		wantErr bool
+	}{
+		{txType: TxTypeDynamicFee},
+		{txType: TxTypeLegacy},
+		{txType: TxTypeBlob, wantErr: true},
+		{txType: "", wantErr: true},
+		{txType: "type-2", wantErr: true},
+	} {
+		t.Run(tc.txType, func(t *testing.T) {
+			config := DefaultDataPosterConfig
+			config.TxType = tc.txType
+			err := config.Validate()
+			if gotErr := (err != nil); gotErr != tc.wantErr {
+				t.Errorf("Validate() for tx-type %q got error: %v, want error: %t", tc.txType, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckParentChainTxType(t *testing.T) {
+	london := &types.Header{Number: big.NewInt(100), BaseFee: big.NewInt(params.GWei)}
+	preLondon := &types.Header{Number: big.NewInt(100)}
+	for _, tc := range []struct {
+		desc    string
+		txType  string
+		header  *types.Header
+		wantErr bool
+	}{
+		{desc: "dynamic fee with base fee", txType: TxTypeDynamicFee, header: london},
+		{desc: "dynamic fee without base fee", txType: TxTypeDynamicFee, header: preLondon, wantErr: true},
+		{desc: "legacy with base fee", txType: TxTypeLegacy, header: london},
+		{desc: "legacy without base fee", txType: TxTypeLegacy, header: preLondon},
+		{desc: "blob", txType: TxTypeBlob, header: london, wantErr: true},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := checkParentChainTxType(tc.txType, tc.header)
+			if gotErr := (err != nil); gotErr != tc.wantErr {
+				t.Errorf("checkParentChainTxType(%q) got error: %v, want error: %t", tc.txType, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBufferGasEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		multiplier float64
+		gas        uint64
+		want       uint64
+	}{
+		{multiplier: 1, gas: 100_000, want: 100_000},
+		{multiplier: 1.5, gas: 100_000, want: 150_000},
+		{multiplier: 2, gas: 21_000, want: 42_000},
+		{multiplier: 2, gas: math.MaxUint64, want: math.MaxUint64},
+	} {
+		config := DefaultDataPosterConfig
+		config.GasEstimateMultiplier = tc.multiplier
+		p := &DataPoster{config: func() *DataPosterConfig { return &config }}
+		if got := p.BufferGasEstimate(tc.gas); got != tc.want {
+			t.Errorf("BufferGasEstimate(%v) with multiplier %v got: %v, want: %v", tc.gas, tc.multiplier, got, tc.want)
+		}
+	}
+}
+
+type headerTestClient struct {
+	arbutil.L1Interface
+	header *types.Header
+}
+
+func (c *headerTestClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.header, nil
+}
+
+func TestTxTypeCheckedOnStartAndReload(t *testing.T) {
+	ctx := context.Background()
+	client := &headerTestClient{header: &types.Header{Number: big.NewInt(100)}}
+	config := DefaultDataPosterConfig
+	config.TxType = TxTypeLegacy
+	p := &DataPoster{client: client, config: func() *DataPosterConfig { return &config }, txType: config.TxType}
+	if err := p.CheckParentChainTxType(ctx); err != nil {
+		t.Fatalf("CheckParentChainTxType() for legacy without base fee got error: %v", err)
+	}
+
+	// reloading a tx-type the parent chain doesn't support keeps the current one
+	config.TxType = TxTypeDynamicFee
+	p.updateTxType(ctx)
+	if p.txType != TxTypeLegacy {
+		t.Fatalf("switched to unsupported reloaded tx-type %q", p.txType)
+	}
+
+	// it's switched to once the parent chain supports it
+	client.header = &types.Header{Number: big.NewInt(101), BaseFee: big.NewInt(params.GWei)}
+	p.updateTxType(ctx)
+	if p.txType != TxTypeDynamicFee {
+		t.Fatalf("didn't switch to supported reloaded tx-type, using %q", p.txType)
+	}
+
+	client.header = &types.Header{Number: big.NewInt(102)}
+	if err := p.CheckParentChainTxType(ctx); err == nil {
+		t.Fatalf("CheckParentChainTxType() for dynamic fee without base fee got no error")
+	}
+}
+
+func TestLegacyTxDataGasPrice(t *testing.T) {
+	p := &DataPoster{txType: TxTypeLegacy}
+	inner := &types.DynamicFeeTx{
+		Nonce:     1,
+		GasFeeCap: big.NewInt(200),
+		GasTipCap: big.NewInt(10),
+		Gas:       21000,
+	}
+	for _, tc := range []struct {
+		desc      string
+		baseFee   int64
+		replacing *types.Transaction
+		want      int64
+	}{
+		{desc: "base fee plus tip", baseFee: 100, want: 110},
+		{desc: "capped at the fee cap", baseFee: 195, want: 200},
+		{desc: "bumped over a replaced legacy tx", baseFee: 100, replacing: types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(150)}), want: 165},
+		{desc: "not bumped over a cheaper replaced legacy tx", baseFee: 100, replacing: types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(50)}), want: 110},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			data, ok := p.txData(inner, big.NewInt(tc.baseFee), tc.replacing).(*types.LegacyTx)
+			if !ok {
+				t.Fatal("legacy data poster didn't make a legacy transaction")
+			}
+			if data.GasPrice.Cmp(big.NewInt(tc.want)) != 0 {
+				t.Errorf("got gas price %v, want %v", data.GasPrice, tc.want)
+			}
+		})
+	}
+	dynamic := &DataPoster{txType: TxTypeDynamicFee}
+	if data := dynamic.txData(inner, big.NewInt(100), nil); data != types.TxData(inner) {
+		t.Error("dynamic fee data poster didn't keep the dynamic fee transaction", data)
+	}
+}
+
+type receiptTestClient struct {
+	arbutil.L1Interface
+	p             *DataPoster
+	receipts      int
+	lockedReceipt bool
+}
+
+func (c *receiptTestClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.receipts++
+	if c.p.mutex.TryLock() {
+		c.p.mutex.Unlock()
+	} else {
+		c.lockedReceipt = true
+	}
+	return &types.Receipt{GasUsed: 50000}, nil
+}
+
+func TestGasUsageRecordedOutsideMutex(t *testing.T) {
+	client := &receiptTestClient{}
+	p := &DataPoster{client: client}
+	client.p = p
+	for nonce := uint64(0); nonce < maxGasUsageReceipts+2; nonce++ {
+		inner := types.DynamicFeeTx{Nonce: nonce, Gas: 100000}
+		p.unrecordedGasUsage = append(p.unrecordedGasUsage, &storage.QueuedTransaction{FullTx: types.NewTx(&inner), Data: inner})
+	}
+	p.recordGasUsage(context.Background())
+	if client.lockedReceipt {
+		t.Fatal("receipt fetched while holding the data poster mutex")
+	}
+	if client.receipts != maxGasUsageReceipts+2 || len(p.unrecordedGasUsage) != 0 {
+		t.Fatalf("got %v receipts fetched and %v transactions left unrecorded", client.receipts, len(p.unrecordedGasUsage))
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type DelayedMessageResult struct {
+	Index            uint64                        `json:"index"`
+	ParentChainBlock uint64                        `json:"parentChainBlock"`
+	BlockHash        *common.Hash                  `json:"blockHash,omitempty"`
+	Kind             uint8                         `json:"kind"`
+	Sender           common.Address                `json:"sender"`
+	Message          *arbostypes.L1IncomingMessage `json:"message"`
+	Data             hexutil.Bytes                 `json:"data"`
+	Raw              hexutil.Bytes                 `json:"raw"`
+	Accumulator      common.Hash                   `json:"accumulator"`
+	ParentChain      *arbostypes.L1IncomingMessage `json:"parentChainMessage,omitempty"`
+	Matches          bool                          `json:"matchesParentChain"`
+	Errors           []string                      `json:"errors"`
+}
+
+// InspectDelayedMessage returns the stored delayed message with the given index, and
+// looks it up again in the delayed bridge to check it against the parent chain.
+func (r *InboxReader) InspectDelayedMessage(ctx context.Context, index uint64) (*DelayedMessageResult, error) {
+	msg, acc, parentChainBlock, err := r.tracker.GetDelayedMessageAccumulatorAndParentChainBlockNumber(index)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := msg.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	result := &DelayedMessageResult{
+		Index:            index,
+		ParentChainBlock: parentChainBlock,
+		Kind:             msg.Header.Kind,
+		Sender:           msg.Header.Poster,
+		Message:          msg,
+		Data:             msg.L2msg,
+		Raw:              raw,
+		Accumulator:      acc,
+		Errors:           []string{},
+	}
+	if parentChainBlock == 0 {
+		result.Errors = append(result.Errors, "parent chain block of message unknown, not checking against the delayed bridge")
+		return result, nil
+	}
+	blockNum := arbmath.UintToBig(parentChainBlock)
+	messages, err := r.delayedBridge.LookupMessagesInRange(ctx, blockNum, blockNum, func(batchNum uint64) ([]byte, error) {
+		return r.GetSequencerMessageBytes(ctx, batchNum)
+	})
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to look up delayed messages in parent chain block %v: %v", parentChainBlock, err))
+		return result, nil
+	}
+	for _, delayed := range messages {
+		seqNum, err := delayed.Message.Header.SeqNum()
+		if err != nil || seqNum != index {
+			continue
+		}
+		blockHash := delayed.BlockHash
+		result.BlockHash = &blockHash
+		result.ParentChain = delayed.Message
+		if delayed.AfterInboxAcc() != acc {
+			result.Errors = append(result.Errors, fmt.Sprintf("parent chain accumulator %v differs from stored accumulator %v", delayed.AfterInboxAcc(), acc))
+		} else {
+			result.Matches = true
+		}
+		return result, nil
+	}
+	result.Errors = append(result.Errors, fmt.Sprintf("delayed message not found in parent chain block %v", parentChainBlock))
+	return result, nil
+}
@@ -9,10 +9,12 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	flag "github.com/spf13/pflag"
 
 	"github.com/offchainlabs/nitro/arbnode/execution"
@@ -21,6 +23,12 @@ import (
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+var (
+	delayedBacklogGauge    = metrics.NewRegisteredGauge("arb/sequencer/delayed/backlog", nil)
+	delayedSequencedMeter  = metrics.NewRegisteredMeter("arb/sequencer/delayed/sequenced", nil)
+	delayedChunkLimitedCnt = metrics.NewRegisteredCounter("arb/sequencer/delayed/chunk_limited", nil)
+)
+
 type DelayedSequencer struct {
 	stopwaiter.StopWaiter
 	l1Reader                 *headerreader.HeaderReader
@@ -34,10 +42,12 @@ type DelayedSequencer struct {
 }
 
 type DelayedSequencerConfig struct {
-	Enable              bool  `koanf:"enable" reload:"hot"`
-	FinalizeDistance    int64 `koanf:"finalize-distance" reload:"hot"`
-	RequireFullFinality bool  `koanf:"require-full-finality" reload:"hot"`
-	UseMergeFinality    bool  `koanf:"use-merge-finality" reload:"hot"`
+	Enable              bool          `koanf:"enable" reload:"hot"`
+	FinalizeDistance    int64         `koanf:"finalize-distance" reload:"hot"`
+	RequireFullFinality bool          `koanf:"require-full-finality" reload:"hot"`
+	UseMergeFinality    bool          `koanf:"use-merge-finality" reload:"hot"`
+	MaxMessagesPerChunk uint64        `koanf:"max-messages-per-chunk" reload:"hot"`
+	ChunkInterval       time.Duration `koanf:"chunk-interval" reload:"hot"`
 }
 
 type DelayedSequencerConfigFetcher func() *DelayedSequencerConfig
@@ -47,6 +57,8 @@ func DelayedSequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int64(prefix+".finalize-distance", DefaultDelayedSequencerConfig.FinalizeDistance, "how many blocks in the past L1 block is considered final (ignored when using Merge finality)")
 	f.Bool(prefix+".require-full-finality", DefaultDelayedSequencerConfig.RequireFullFinality, "whether to wait for full finality before sequencing delayed messages")
 	f.Bool(prefix+".use-merge-finality", DefaultDelayedSequencerConfig.UseMergeFinality, "whether to use The Merge's notion of finality before sequencing delayed messages")
+	f.Uint64(prefix+".max-messages-per-chunk", DefaultDelayedSequencerConfig.MaxMessagesPerChunk, "maximum number of delayed messages to sequence at once, the rest are sequenced in later chunks (0 = no limit)")
+	f.Duration(prefix+".chunk-interval", DefaultDelayedSequencerConfig.ChunkInterval, "how long to wait between chunks of delayed messages when there are more than max-messages-per-chunk, leaving room for other sequencing")
 }
 
 var DefaultDelayedSequencerConfig = DelayedSequencerConfig{
@@ -54,6 +66,8 @@ var DefaultDelayedSequencerConfig = DelayedSequencerConfig{
 	FinalizeDistance:    20,
 	RequireFullFinality: false,
 	UseMergeFinality:    true,
+	MaxMessagesPerChunk: 0,
+	ChunkInterval:       100 * time.Millisecond,
 }
 
 var TestDelayedSequencerConfig = DelayedSequencerConfig{
@@ -61,6 +75,8 @@ var TestDelayedSequencerConfig = DelayedSequencerConfig{
 	FinalizeDistance:    20,
 	RequireFullFinality: false,
 	UseMergeFinality:    true,
+	MaxMessagesPerChunk: 0,
+	ChunkInterval:       100 * time.Millisecond,
 }
 
 func NewDelayedSequencer(l1Reader *headerreader.HeaderReader, reader *InboxReader, exec *execution.ExecutionEngine, coordinator *SeqCoordinator, config DelayedSequencerConfigFetcher) (*DelayedSequencer, error) {
@@ -82,21 +98,22 @@ func (d *DelayedSequencer) getDelayedMessagesRead() (uint64, error) {
 	return d.exec.NextDelayedMessageNumber()
 }
 
-func (d *DelayedSequencer) trySequence(ctx context.Context, lastBlockHeader *types.Header) error {
+// trySequence returns true if it stopped at max-messages-per-chunk with more finalized messages left to sequence
+func (d *DelayedSequencer) trySequence(ctx context.Context, lastBlockHeader *types.Header) (bool, error) {
 	if d.coordinator != nil && !d.coordinator.CurrentlyChosen() {
-		return nil
+		return false, nil
 	}
 
 	return d.sequenceWithoutLockout(ctx, lastBlockHeader)
 }
 
-func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlockHeader *types.Header) error {
+func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlockHeader *types.Header) (bool, error) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	config := d.config()
 	if !config.Enable {
-		return nil
+		return false, nil
 	}
 
 	var finalized uint64
@@ -108,18 +125,18 @@ func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlock
 			finalized, err = d.l1Reader.LatestSafeBlockNr(ctx)
 		}
 		if err != nil {
-			return err
+			return false, err
 		}
 	} else {
 		currentNum := lastBlockHeader.Number.Int64()
 		if currentNum < config.FinalizeDistance {
-			return nil
+			return false, nil
 		}
 		finalized = uint64(currentNum - config.FinalizeDistance)
 	}
 
 	if d.waitingForFinalizedBlock > finalized {
-		return nil
+		return false, nil
 	}
 
 	// Unless we find an unfinalized message (which sets waitingForBlock),
@@ -128,21 +145,28 @@ func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlock
 
 	dbDelayedCount, err := d.inbox.GetDelayedCount()
 	if err != nil {
-		return err
+		return false, err
 	}
 	startPos, err := d.getDelayedMessagesRead()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Retrieve all finalized delayed messages
 	pos := startPos
 	var lastDelayedAcc common.Hash
 	var messages []*arbostypes.L1IncomingMessage
+	chunkLimited := false
 	for pos < dbDelayedCount {
+		if config.MaxMessagesPerChunk > 0 && uint64(len(messages)) >= config.MaxMessagesPerChunk {
+			// Leave the rest for the next chunk, which mustn't wait for a new finalized block
+			d.waitingForFinalizedBlock = finalized
+			chunkLimited = true
+			break
+		}
 		msg, acc, parentChainBlockNumber, err := d.inbox.GetDelayedMessageAccumulatorAndParentChainBlockNumber(pos)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if parentChainBlockNumber > finalized {
 			// Message isn't finalized yet; stop here
@@ -157,7 +181,7 @@ func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlock
 				ParentChainBlockNumber: parentChainBlockNumber,
 			}
 			if fullMsg.AfterInboxAcc() != acc {
-				return errors.New("delayed message accumulator mismatch while sequencing")
+				return false, errors.New("delayed message accumulator mismatch while sequencing")
 			}
 		}
 		lastDelayedAcc = acc
@@ -169,22 +193,37 @@ func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlock
 	if len(messages) > 0 {
 		delayedBridgeAcc, err := d.bridge.GetAccumulator(ctx, pos-1, new(big.Int).SetUint64(finalized))
 		if err != nil {
-			return err
+			return false, err
 		}
 		if delayedBridgeAcc != lastDelayedAcc {
 			// Probably a reorg that hasn't been picked up by the inbox reader
-			return fmt.Errorf("inbox reader at delayed message %v db accumulator %v doesn't match delayed bridge accumulator %v at L1 block %v", pos-1, lastDelayedAcc, delayedBridgeAcc, finalized)
+			return false, fmt.Errorf("inbox reader at delayed message %v db accumulator %v doesn't match delayed bridge accumulator %v at L1 block %v", pos-1, lastDelayedAcc, delayedBridgeAcc, finalized)
 		}
 		for i, msg := range messages {
 			err = d.exec.SequenceDelayedMessage(msg, startPos+uint64(i))
 			if err != nil {
-				return err
+				updateDelayedBacklog(dbDelayedCount, startPos+uint64(i))
+				return false, err
 			}
+			delayedSequencedMeter.Mark(1)
 		}
 		log.Info("DelayedSequencer: Sequenced", "msgnum", len(messages), "startpos", startPos)
 	}
+	updateDelayedBacklog(dbDelayedCount, pos)
+	if chunkLimited {
+		delayedChunkLimitedCnt.Inc(1)
+		log.Info("DelayedSequencer: more delayed messages waiting", "backlog", dbDelayedCount-pos, "maxMessagesPerChunk", config.MaxMessagesPerChunk)
+	}
 
-	return nil
+	return chunkLimited, nil
+}
+
+func updateDelayedBacklog(delayedCount uint64, sequencedCount uint64) {
+	if delayedCount > sequencedCount {
+		delayedBacklogGauge.Update(int64(delayedCount - sequencedCount))
+	} else {
+		delayedBacklogGauge.Update(0)
+	}
 }
 
 // Dangerous: bypasses lockout check!
@@ -193,13 +232,32 @@ func (d *DelayedSequencer) ForceSequenceDelayed(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return d.sequenceWithoutLockout(ctx, lastBlockHeader)
+	_, err = d.sequenceWithoutLockout(ctx, lastBlockHeader)
+	return err
 }
 
 func (d *DelayedSequencer) run(ctx context.Context) {
 	headerChan, cancel := d.l1Reader.Subscribe(false)
 	defer cancel()
 
+	d.sequenceOnHeaders(ctx, headerChan, d.trySequence)
+}
+
+// sequenceOnHeaders calls trySequence for each new header, and again after chunk-interval while it reports delayed messages left over
+func (d *DelayedSequencer) sequenceOnHeaders(ctx context.Context, headerChan <-chan *types.Header, trySequence func(context.Context, *types.Header) (bool, error)) {
+	var lastHeader *types.Header
+	// set while delayed messages are left over from a chunk, to sequence them before the next header arrives
+	var nextChunk <-chan time.Time
+	sequence := func() {
+		more, err := trySequence(ctx, lastHeader)
+		if err != nil {
+			log.Error("Delayed sequencer error", "err", err)
+		}
+		nextChunk = nil
+		if more {
+			nextChunk = time.After(d.config().ChunkInterval)
+		}
+	}
 	for {
 		select {
 		case nextHeader, ok := <-headerChan:
@@ -207,9 +265,10 @@ func (d *DelayedSequencer) run(ctx context.Context) {
 				log.Info("delayed sequencer: header channel close")
 				return
 			}
-			if err := d.trySequence(ctx, nextHeader); err != nil {
-				log.Error("Delayed sequencer error", "err", err)
-			}
+			lastHeader = nextHeader
+			sequence()
+		case <-nextChunk:
+			sequence()
 		case <-ctx.Done():
 			log.Info("delayed sequencer: context done", "err", ctx.Err())
 			return
@@ -0,0 +1,64 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDelayedSequencerChunksWithoutNewHeaders(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	config := TestDelayedSequencerConfig
+	config.MaxMessagesPerChunk = 2
+	config.ChunkInterval = 10 * time.Millisecond
+	d := &DelayedSequencer{config: func() *DelayedSequencerConfig { return &config }}
+
+	header := &types.Header{Number: big.NewInt(1)}
+	headerChan := make(chan *types.Header, 1)
+	headerChan <- header
+	// five delayed messages, sequenced two at a time
+	leftOver := 5
+	calls := make(chan *types.Header, 10)
+	trySequence := func(ctx context.Context, lastHeader *types.Header) (bool, error) {
+		if leftOver > 2 {
+			leftOver -= 2
+		} else {
+			leftOver = 0
+		}
+		calls <- lastHeader
+		return leftOver > 0, nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.sequenceOnHeaders(ctx, headerChan, trySequence)
+	}()
+
+	// all chunks are sequenced after the single header, by the chunk timer
+	for i := 0; i < 3; i++ {
+		select {
+		case lastHeader := <-calls:
+			if lastHeader != header {
+				Fail(t, "chunk sequenced with unexpected header", lastHeader)
+			}
+		case <-ctx.Done():
+			Fail(t, "chunk", i, "not sequenced without a new header")
+		}
+	}
+	// nothing left over, so nothing more is sequenced until the next header
+	select {
+	case <-calls:
+		Fail(t, "sequenced again without delayed messages left over")
+	case <-time.After(10 * config.ChunkInterval):
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,46 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+// WaitForDeploymentConfirmations waits until the rollup deployment block has the given number of
+// confirmations on the parent chain, so that a shallow reorg can no longer move it.
+func WaitForDeploymentConfirmations(ctx context.Context, client arbutil.L1Interface, deployedAt uint64, confirmations uint64, pollInterval time.Duration) error {
+	if confirmations == 0 {
+		return nil
+	}
+	target := deployedAt + confirmations
+	logged := false
+	for {
+		current, err := client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		if current >= target {
+			if logged {
+				log.Info("rollup deployment block confirmed", "deployedAt", deployedAt, "confirmations", current-deployedAt)
+			}
+			return nil
+		}
+		confirmed := uint64(0)
+		if current > deployedAt {
+			confirmed = current - deployedAt
+		}
+		log.Info("waiting for rollup deployment block confirmations before trusting it", "deployedAt", deployedAt, "parentChainBlock", current, "confirmations", confirmed, "required", confirmations)
+		logged = true
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+// blockNumberTestClient is a parent chain whose head advances by one block each time it's queried
+type blockNumberTestClient struct {
+	arbutil.L1Interface
+	head atomic.Uint64
+}
+
+func (c *blockNumberTestClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head.Add(1) - 1, nil
+}
+
+func TestWaitForDeploymentConfirmations(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client := &blockNumberTestClient{}
+	client.head.Store(100)
+	Require(t, WaitForDeploymentConfirmations(ctx, client, 98, 5, time.Millisecond))
+	// returns once the deployment block at 98 is 5 blocks deep
+	if head := client.head.Load(); head != 104 {
+		Fail(t, "returned at unexpected parent chain head", head-1)
+	}
+
+	// no confirmations needed, no parent chain queries
+	Require(t, WaitForDeploymentConfirmations(ctx, client, 1000, 0, time.Millisecond))
+	if head := client.head.Load(); head != 104 {
+		Fail(t, "parent chain queried without confirmations required")
+	}
+
+	// gives up when the context ends before the deployment block is confirmed
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer timeoutCancel()
+	err := WaitForDeploymentConfirmations(timeoutCtx, client, 1000, 5, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		Fail(t, "unexpected error waiting past the timeout", err)
+	}
+}
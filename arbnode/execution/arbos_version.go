@@ -0,0 +1,133 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+)
+
+const (
+	// ArbOSVersionCheckRefuse refuses to start a node that doesn't support the chain's ArbOS version
+	ArbOSVersionCheckRefuse = "refuse"
+	// ArbOSVersionCheckWarn only logs an error at startup. ArbOS itself refuses to execute upgrades
+	// it doesn't support, so the node still stops once it reaches a message it can't execute.
+	ArbOSVersionCheckWarn = "warn"
+)
+
+var ErrUnsupportedArbOSVersion = errors.New("unsupported ArbOS version, you need to update your node to the latest version")
+
+type ArbOSVersionCheckConfig struct {
+	Action      string        `koanf:"action"`
+	ErrorBefore time.Duration `koanf:"error-before" reload:"hot"`
+}
+
+type ArbOSVersionCheckConfigFetcher func() *ArbOSVersionCheckConfig
+
+var DefaultArbOSVersionCheckConfig = ArbOSVersionCheckConfig{
+	Action:      ArbOSVersionCheckRefuse,
+	ErrorBefore: 24 * time.Hour,
+}
+
+func ArbOSVersionCheckConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".action", DefaultArbOSVersionCheckConfig.Action, "what to do at startup when the chain requires a newer ArbOS version than the node supports, \""+ArbOSVersionCheckRefuse+"\" refuses to start, \""+ArbOSVersionCheckWarn+"\" only logs an error and stops once a message can't be executed")
+	f.Duration(prefix+".error-before", DefaultArbOSVersionCheckConfig.ErrorBefore, "log scheduled ArbOS upgrades the node doesn't support as errors instead of warnings once they're this close")
+}
+
+func (c *ArbOSVersionCheckConfig) Validate() error {
+	if c.Action != ArbOSVersionCheckRefuse && c.Action != ArbOSVersionCheckWarn {
+		return fmt.Errorf("invalid arbos-version-check.action %q, must be %q or %q", c.Action, ArbOSVersionCheckRefuse, ArbOSVersionCheckWarn)
+	}
+	if c.ErrorBefore < 0 {
+		return fmt.Errorf("invalid arbos-version-check.error-before %v, must not be negative", c.ErrorBefore)
+	}
+	return nil
+}
+
+// MaxSupportedArbOSVersion is the newest ArbOS version this node can execute
+func MaxSupportedArbOSVersion() uint64 {
+	return params.ArbitrumDevTestChainConfig().ArbitrumChainParams.InitialArbOSVersion
+}
+
+// checkArbOSVersion fails if a block with the given header requires an ArbOS version this node doesn't support
+func checkArbOSVersion(header *types.Header) error {
+	version := types.DeserializeHeaderExtraInformation(header).ArbOSFormatVersion
+	if version > MaxSupportedArbOSVersion() {
+		return fmt.Errorf("%w: block %v requires ArbOS version %v, this node supports up to %v", ErrUnsupportedArbOSVersion, header.Number, version, MaxSupportedArbOSVersion())
+	}
+	return nil
+}
+
+// CheckChainArbOSVersion fails if the chain config or the current head require an ArbOS version
+// the node doesn't support, and logs any unsupported upgrade scheduled at the head.
+func CheckChainArbOSVersion(bc *core.BlockChain, config *ArbOSVersionCheckConfig) error {
+	if initial := bc.Config().ArbitrumChainParams.InitialArbOSVersion; initial > MaxSupportedArbOSVersion() {
+		return fmt.Errorf("%w: the chain config requires ArbOS version %v, this node supports up to %v", ErrUnsupportedArbOSVersion, initial, MaxSupportedArbOSVersion())
+	}
+	head := bc.CurrentBlock()
+	if err := checkArbOSVersion(head); err != nil {
+		return err
+	}
+	statedb, err := bc.StateAt(head.Root)
+	if err != nil {
+		// the scheduled upgrade is checked again once blocks are produced
+		log.Debug("unable to read scheduled ArbOS upgrade at startup", "err", err)
+		return nil
+	}
+	return logScheduledArbOSUpgrade(statedb, config.ErrorBefore)
+}
+
+// logScheduledArbOSUpgrade warns about a scheduled ArbOS upgrade the node doesn't support,
+// as an error once it's less than errorBefore away
+func logScheduledArbOSUpgrade(statedb *state.StateDB, errorBefore time.Duration) error {
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return err
+	}
+	version, timestampInt, err := arbState.GetScheduledUpgrade()
+	if err != nil {
+		return err
+	}
+	if version <= MaxSupportedArbOSVersion() {
+		return nil
+	}
+	var timeUntilUpgrade time.Duration
+	var timestamp time.Time
+	if timestampInt == 0 {
+		// This upgrade will take effect in the next block
+		timestamp = time.Now()
+	} else {
+		// This upgrade is scheduled for the future
+		timestamp = time.Unix(int64(timestampInt), 0)
+		timeUntilUpgrade = time.Until(timestamp)
+	}
+	logLevel := log.Warn
+	if scheduledUpgradeIsUrgent(timeUntilUpgrade, errorBefore) {
+		logLevel = log.Error
+	}
+	logLevel(
+		"you need to update your node to the latest version before this scheduled ArbOS upgrade",
+		"timeUntilUpgrade", timeUntilUpgrade,
+		"upgradeScheduledFor", timestamp,
+		"maxSupportedArbosVersion", MaxSupportedArbOSVersion(),
+		"pendingArbosUpgradeVersion", version,
+	)
+	return nil
+}
+
+// scheduledUpgradeIsUrgent is whether an unsupported upgrade timeUntilUpgrade away is logged as an error
+func scheduledUpgradeIsUrgent(timeUntilUpgrade time.Duration, errorBefore time.Duration) bool {
+	return timeUntilUpgrade < errorBefore
+}
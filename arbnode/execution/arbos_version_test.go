@@ -0,0 +1,99 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/statetransfer"
+)
+
+func TestArbOSVersionCheckConfig(t *testing.T) {
+	for _, action := range []string{ArbOSVersionCheckRefuse, ArbOSVersionCheckWarn} {
+		config := DefaultArbOSVersionCheckConfig
+		config.Action = action
+		if err := config.Validate(); err != nil {
+			t.Fatal("action", action, "rejected", err)
+		}
+	}
+	config := DefaultArbOSVersionCheckConfig
+	config.Action = "ignore"
+	if config.Validate() == nil {
+		t.Fatal("unknown action accepted")
+	}
+	config = DefaultArbOSVersionCheckConfig
+	config.ErrorBefore = -time.Second
+	if config.Validate() == nil {
+		t.Fatal("negative error-before accepted")
+	}
+}
+
+func TestCheckArbOSVersion(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(10)}
+	info := types.HeaderInfo{ArbOSFormatVersion: MaxSupportedArbOSVersion()}
+	info.UpdateHeaderWithInfo(header)
+	if err := checkArbOSVersion(header); err != nil {
+		t.Fatal("supported ArbOS version rejected", err)
+	}
+	info.ArbOSFormatVersion = MaxSupportedArbOSVersion() + 1
+	info.UpdateHeaderWithInfo(header)
+	if err := checkArbOSVersion(header); !errors.Is(err, ErrUnsupportedArbOSVersion) {
+		t.Fatal("unsupported ArbOS version not rejected", err)
+	}
+}
+
+func TestCheckChainArbOSVersion(t *testing.T) {
+	chainConfig := params.ArbitrumDevTestChainConfig()
+	initReader := statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{})
+	bc, err := WriteOrTestBlockChain(rawdb.NewMemoryDatabase(), nil, initReader, chainConfig, arbostypes.TestInitMessage, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	for _, action := range []string{ArbOSVersionCheckRefuse, ArbOSVersionCheckWarn} {
+		config := DefaultArbOSVersionCheckConfig
+		config.Action = action
+		if err := CheckChainArbOSVersion(bc, &config); err != nil {
+			t.Fatal("chain with a supported ArbOS version rejected with action", action, err)
+		}
+	}
+
+	// the chain config of a chain that started on a newer ArbOS version than the node supports
+	bc.Config().ArbitrumChainParams.InitialArbOSVersion = MaxSupportedArbOSVersion() + 1
+	if err := CheckChainArbOSVersion(bc, &DefaultArbOSVersionCheckConfig); !errors.Is(err, ErrUnsupportedArbOSVersion) {
+		t.Fatal("chain with an unsupported ArbOS version not rejected", err)
+	}
+}
+
+func TestScheduledUpgradeErrorBefore(t *testing.T) {
+	execEngine, err := NewExecutionEngine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeUntilUpgrade := 30 * time.Hour
+	if scheduledUpgradeIsUrgent(timeUntilUpgrade, execEngine.arbosVersionCheck().ErrorBefore) {
+		t.Fatal("upgrade more than the default error-before away logged as an error")
+	}
+
+	config := DefaultArbOSVersionCheckConfig
+	config.ErrorBefore = 48 * time.Hour
+	execEngine.arbosVersionCheck = func() *ArbOSVersionCheckConfig { return &config }
+	if !scheduledUpgradeIsUrgent(timeUntilUpgrade, execEngine.arbosVersionCheck().ErrorBefore) {
+		t.Fatal("upgrade less than the configured error-before away not logged as an error")
+	}
+	// error-before is hot reloadable
+	config.ErrorBefore = 0
+	if scheduledUpgradeIsUrgent(time.Hour, execEngine.arbosVersionCheck().ErrorBefore) {
+		t.Fatal("upgrade logged as an error with error-before disabled")
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nats-io/nats.go"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	eventPublisherQueuedGauge       = metrics.NewRegisteredGauge("arb/execution/events/queued", nil)
+	eventPublisherDroppedCounter    = metrics.NewRegisteredCounter("arb/execution/events/dropped", nil)
+	eventPublisherPublishedCounter  = metrics.NewRegisteredCounter("arb/execution/events/published", nil)
+	eventPublisherPublishErrCounter = metrics.NewRegisteredCounter("arb/execution/events/publish_errors", nil)
+)
+
+const (
+	EventBrokerRedis = "redis"
+	EventBrokerNats  = "nats"
+)
+
+const (
+	EventTypeBlock       = "block"
+	EventTypeTransaction = "transaction"
+)
+
+type EventPublisherConfig struct {
+	Enable    bool   `koanf:"enable"`
+	Broker    string `koanf:"broker"`
+	Url       string `koanf:"url"`
+	Topic     string `koanf:"topic"`
+	QueueSize int    `koanf:"queue-size"`
+	MaxLen    int64  `koanf:"max-len"`
+}
+
+var DefaultEventPublisherConfig = EventPublisherConfig{
+	Enable:    false,
+	Broker:    EventBrokerRedis,
+	Url:       "",
+	Topic:     "nitro-chain-events",
+	QueueSize: 1024,
+	MaxLen:    100_000,
+}
+
+func EventPublisherConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultEventPublisherConfig.Enable, "publish new block and transaction events to a message bus")
+	f.String(prefix+".broker", DefaultEventPublisherConfig.Broker, "message bus type (\"redis\" to publish to a redis stream or \"nats\" to publish to nats subjects)")
+	f.String(prefix+".url", DefaultEventPublisherConfig.Url, "message bus url")
+	f.String(prefix+".topic", DefaultEventPublisherConfig.Topic, "topic to publish events to (the redis stream key, or the nats subject prefix with events published to <topic>.block and <topic>.transaction)")
+	f.Int(prefix+".queue-size", DefaultEventPublisherConfig.QueueSize, "number of blocks buffered for publishing before new blocks' events are dropped")
+	f.Int64(prefix+".max-len", DefaultEventPublisherConfig.MaxLen, "approximate maximum number of events kept in the redis stream (0 = unlimited)")
+}
+
+func (c *EventPublisherConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Broker != EventBrokerRedis && c.Broker != EventBrokerNats {
+		return fmt.Errorf("unsupported event publisher broker %q", c.Broker)
+	}
+	if c.Url == "" {
+		return errors.New("event publisher enabled without a url")
+	}
+	if c.Topic == "" {
+		return errors.New("event publisher enabled without a topic")
+	}
+	if c.QueueSize <= 0 {
+		return errors.New("event publisher queue-size must be positive")
+	}
+	return nil
+}
+
+// TransactionEvent is published once for every transaction in a new block, after that block's BlockEvent.
+type TransactionEvent struct {
+	Hash        common.Hash     `json:"hash"`
+	BlockNumber uint64          `json:"blockNumber"`
+	BlockHash   common.Hash     `json:"blockHash"`
+	Index       uint64          `json:"index"`
+	Type        uint8           `json:"type"`
+	To          *common.Address `json:"to"`
+	Nonce       uint64          `json:"nonce"`
+	Value       *hexutil.Big    `json:"value"`
+	Gas         uint64          `json:"gas"`
+}
+
+// BlockEvent is published once for every new block, listing the hashes of its transactions.
+type BlockEvent struct {
+	Number       uint64        `json:"number"`
+	Hash         common.Hash   `json:"hash"`
+	ParentHash   common.Hash   `json:"parentHash"`
+	Timestamp    uint64        `json:"timestamp"`
+	GasUsed      uint64        `json:"gasUsed"`
+	Transactions []common.Hash `json:"transactions"`
+}
+
+// blockEvents holds the events of a single block, queued together so a block is either published or dropped as a whole.
+type blockEvents struct {
+	block        BlockEvent
+	transactions []TransactionEvent
+}
+
+func newBlockEvents(block *types.Block) *blockEvents {
+	events := &blockEvents{
+		block: BlockEvent{
+			Number:       block.NumberU64(),
+			Hash:         block.Hash(),
+			ParentHash:   block.ParentHash(),
+			Timestamp:    block.Time(),
+			GasUsed:      block.GasUsed(),
+			Transactions: make([]common.Hash, 0, len(block.Transactions())),
+		},
+		transactions: make([]TransactionEvent, 0, len(block.Transactions())),
+	}
+	for i, tx := range block.Transactions() {
+		events.block.Transactions = append(events.block.Transactions, tx.Hash())
+		events.transactions = append(events.transactions, TransactionEvent{
+			Hash:        tx.Hash(),
+			BlockNumber: events.block.Number,
+			BlockHash:   events.block.Hash,
+			Index:       uint64(i),
+			Type:        tx.Type(),
+			To:          tx.To(),
+			Nonce:       tx.Nonce(),
+			Value:       (*hexutil.Big)(tx.Value()),
+			Gas:         tx.Gas(),
+		})
+	}
+	return events
+}
+
+// eventSink delivers encoded events of the given event type to a message bus.
+type eventSink interface {
+	// connect is retried until it succeeds before the first event is published
+	connect(ctx context.Context) error
+	publish(ctx context.Context, eventType string, data []byte) error
+	close() error
+}
+
+// redisEventSink appends events to a single redis stream, tagging each entry with its event type.
+type redisEventSink struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+func (s *redisEventSink) connect(context.Context) error {
+	// the client connects on first use
+	return nil
+}
+
+func (s *redisEventSink) publish(ctx context.Context, eventType string, data []byte) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": eventType, "event": data},
+	}).Err()
+}
+
+func (s *redisEventSink) close() error {
+	return s.client.Close()
+}
+
+// natsEventSink publishes each event type to its own subject under the configured prefix.
+// It connects once the publisher is started, so an unreachable server doesn't keep the node from starting.
+type natsEventSink struct {
+	url    string
+	conn   *nats.Conn
+	prefix string
+}
+
+func (s *natsEventSink) connect(context.Context) error {
+	conn, err := nats.Connect(s.url, nats.Name("nitro event publisher"), nats.MaxReconnects(-1))
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats at %v: %w", s.url, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *natsEventSink) publish(_ context.Context, eventType string, data []byte) error {
+	return s.conn.Publish(s.prefix+"."+eventType, data)
+}
+
+func (s *natsEventSink) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Drain()
+}
+
+func newEventSink(config *EventPublisherConfig) (eventSink, error) {
+	switch config.Broker {
+	case EventBrokerRedis:
+		client, err := redisutil.RedisClientFromURL(config.Url)
+		if err != nil {
+			return nil, err
+		}
+		return &redisEventSink{client: client, stream: config.Topic, maxLen: config.MaxLen}, nil
+	case EventBrokerNats:
+		return &natsEventSink{url: config.Url, prefix: config.Topic}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event publisher broker %q", config.Broker)
+	}
+}
+
+// EventPublisher publishes a block event for every new block and a transaction event for each of its transactions, on a best-effort basis.
+// Events are dropped rather than slowing down block production if the message bus can't keep up.
+type EventPublisher struct {
+	stopwaiter.StopWaiter
+
+	bc                *core.BlockChain
+	sink              eventSink
+	queue             chan *blockEvents
+	connectRetryDelay time.Duration
+}
+
+// the delay between attempts to connect to the message bus doubles up to this
+const eventPublisherMaxConnectRetryDelay = time.Minute
+
+func NewEventPublisher(bc *core.BlockChain, config *EventPublisherConfig) (*EventPublisher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	sink, err := newEventSink(config)
+	if err != nil {
+		return nil, err
+	}
+	return &EventPublisher{
+		bc:                bc,
+		sink:              sink,
+		queue:             make(chan *blockEvents, config.QueueSize),
+		connectRetryDelay: time.Second,
+	}, nil
+}
+
+func (p *EventPublisher) Start(ctxIn context.Context) {
+	p.StopWaiter.Start(ctxIn, p)
+	chainEvents := make(chan core.ChainEvent, 128)
+	subscription := p.bc.SubscribeChainEvent(chainEvents)
+	p.LaunchThread(func(ctx context.Context) {
+		defer subscription.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-subscription.Err():
+				if err != nil {
+					log.Error("event publisher chain subscription failed", "err", err)
+				}
+				return
+			case event := <-chainEvents:
+				p.enqueue(newBlockEvents(event.Block))
+			}
+		}
+	})
+	p.LaunchThread(func(ctx context.Context) {
+		// events are queued, and dropped once the queue is full, until connected
+		if !p.connect(ctx) {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-p.queue:
+				eventPublisherQueuedGauge.Update(int64(len(p.queue)))
+				p.publish(ctx, event)
+			}
+		}
+	})
+}
+
+// connect connects the sink, retrying with a growing delay until it succeeds or ctx is done
+func (p *EventPublisher) connect(ctx context.Context) bool {
+	delay := p.connectRetryDelay
+	for {
+		err := p.sink.connect(ctx)
+		if err == nil {
+			return true
+		}
+		log.Error("event publisher failed to connect to the message bus", "err", err, "retryIn", delay)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > eventPublisherMaxConnectRetryDelay {
+			delay = eventPublisherMaxConnectRetryDelay
+		}
+	}
+}
+
+func (p *EventPublisher) enqueue(events *blockEvents) {
+	select {
+	case p.queue <- events:
+		eventPublisherQueuedGauge.Update(int64(len(p.queue)))
+	default:
+		eventPublisherDroppedCounter.Inc(int64(1 + len(events.transactions)))
+		log.Warn("event publisher queue full, dropping block events", "block", events.block.Number)
+	}
+}
+
+func (p *EventPublisher) publish(ctx context.Context, events *blockEvents) {
+	p.publishEvent(ctx, EventTypeBlock, events.block.Number, &events.block)
+	for i := range events.transactions {
+		p.publishEvent(ctx, EventTypeTransaction, events.block.Number, &events.transactions[i])
+	}
+}
+
+func (p *EventPublisher) publishEvent(ctx context.Context, eventType string, blockNumber uint64, event interface{}) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		eventPublisherPublishErrCounter.Inc(1)
+		log.Error("failed to encode event", "type", eventType, "block", blockNumber, "err", err)
+		return
+	}
+	if err := p.sink.publish(ctx, eventType, data); err != nil {
+		eventPublisherPublishErrCounter.Inc(1)
+		log.Warn("failed to publish event", "type", eventType, "block", blockNumber, "err", err)
+		return
+	}
+	eventPublisherPublishedCounter.Inc(1)
+}
+
+func (p *EventPublisher) StopAndWait() {
+	p.StopWaiter.StopAndWait()
+	if err := p.sink.close(); err != nil {
+		log.Warn("failed to close event publisher sink", "err", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+type recordedEvent struct {
+	eventType string
+	data      []byte
+}
+
+type recordingEventSink struct {
+	events          []recordedEvent
+	connectFailures int
+	connectAttempts int
+}
+
+func (s *recordingEventSink) connect(context.Context) error {
+	s.connectAttempts++
+	if s.connectAttempts <= s.connectFailures {
+		return errors.New("message bus unreachable")
+	}
+	return nil
+}
+
+func (s *recordingEventSink) publish(_ context.Context, eventType string, data []byte) error {
+	s.events = append(s.events, recordedEvent{eventType, data})
+	return nil
+}
+
+func (s *recordingEventSink) close() error {
+	return nil
+}
+
+func TestEventPublisherPublishesBlockAndTransactionEvents(t *testing.T) {
+	to := common.HexToAddress("0x1234")
+	txs := types.Transactions{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(5), Gas: 21000, GasPrice: big.NewInt(1)}),
+		types.NewTx(&types.LegacyTx{Nonce: 2, To: &to, Value: big.NewInt(6), Gas: 21000, GasPrice: big.NewInt(1)}),
+	}
+	block := types.NewBlock(&types.Header{Number: big.NewInt(7), Time: 100}, txs, nil, nil, trie.NewStackTrie(nil))
+
+	sink := &recordingEventSink{}
+	publisher := &EventPublisher{sink: sink}
+	publisher.publish(context.Background(), newBlockEvents(block))
+
+	if len(sink.events) != 1+len(txs) {
+		t.Fatalf("expected %v events, got %v", 1+len(txs), len(sink.events))
+	}
+	if sink.events[0].eventType != EventTypeBlock {
+		t.Fatalf("expected first event to be a block event, got %v", sink.events[0].eventType)
+	}
+	var blockEvent BlockEvent
+	if err := json.Unmarshal(sink.events[0].data, &blockEvent); err != nil {
+		t.Fatal(err)
+	}
+	if blockEvent.Number != 7 || blockEvent.Hash != block.Hash() || len(blockEvent.Transactions) != len(txs) {
+		t.Fatalf("unexpected block event %+v", blockEvent)
+	}
+	for i, tx := range txs {
+		event := sink.events[1+i]
+		if event.eventType != EventTypeTransaction {
+			t.Fatalf("expected event %v to be a transaction event, got %v", 1+i, event.eventType)
+		}
+		var txEvent TransactionEvent
+		if err := json.Unmarshal(event.data, &txEvent); err != nil {
+			t.Fatal(err)
+		}
+		if txEvent.Hash != tx.Hash() || txEvent.BlockHash != block.Hash() || txEvent.Index != uint64(i) || txEvent.Nonce != tx.Nonce() {
+			t.Fatalf("unexpected transaction event %+v", txEvent)
+		}
+		if blockEvent.Transactions[i] != tx.Hash() {
+			t.Fatalf("block event lists transaction %v as %v, expected %v", i, blockEvent.Transactions[i], tx.Hash())
+		}
+	}
+}
+
+func TestEventPublisherRetriesConnecting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := &recordingEventSink{connectFailures: 2}
+	publisher := &EventPublisher{sink: sink, connectRetryDelay: time.Millisecond}
+	if !publisher.connect(ctx) {
+		t.Fatal("failed to connect")
+	}
+	if sink.connectAttempts != 3 {
+		t.Fatalf("expected 3 connection attempts, got %v", sink.connectAttempts)
+	}
+
+	// gives up once stopped
+	sink = &recordingEventSink{connectFailures: 1_000_000}
+	publisher = &EventPublisher{sink: sink, connectRetryDelay: time.Millisecond}
+	cancel()
+	if publisher.connect(ctx) {
+		t.Fatal("connected although the message bus is unreachable")
+	}
+}
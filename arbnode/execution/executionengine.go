@@ -14,7 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/nitro/arbos"
 	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
@@ -24,6 +24,8 @@ import (
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+var blockGasLimitGauge = metrics.NewRegisteredGauge("arb/execution/block_gas_limit", nil)
+
 type TransactionStreamerInterface interface {
 	WriteMessageFromSequencer(pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata) error
 	ExpectChosenSequencer() error
@@ -45,15 +47,19 @@ type ExecutionEngine struct {
 	latestBlock      *types.Block
 
 	nextScheduledVersionCheck time.Time // protected by the createBlocksMutex
+	blockGasLimit             uint64    // protected by the createBlocksMutex
+
+	arbosVersionCheck ArbOSVersionCheckConfigFetcher
 
 	reorgSequencing bool
 }
 
 func NewExecutionEngine(bc *core.BlockChain) (*ExecutionEngine, error) {
 	return &ExecutionEngine{
-		bc:               bc,
-		resequenceChan:   make(chan []*arbostypes.MessageWithMetadata),
-		newBlockNotifier: make(chan struct{}, 1),
+		bc:                bc,
+		resequenceChan:    make(chan []*arbostypes.MessageWithMetadata),
+		newBlockNotifier:  make(chan struct{}, 1),
+		arbosVersionCheck: func() *ArbOSVersionCheckConfig { return &DefaultArbOSVersionCheckConfig },
 	}, nil
 }
 
@@ -469,6 +475,8 @@ func (s *ExecutionEngine) appendBlock(block *types.Block, statedb *state.StateDB
 	for _, receipt := range receipts {
 		logs = append(logs, receipt.Logs...)
 	}
+	// read before the state is committed
+	blockGasLimit, gasLimitErr := readBlockGasLimit(statedb)
 	status, err := s.bc.WriteBlockAndSetHeadWithTime(block, receipts, logs, statedb, true, duration)
 	if err != nil {
 		return err
@@ -476,9 +484,42 @@ func (s *ExecutionEngine) appendBlock(block *types.Block, statedb *state.StateDB
 	if status == core.SideStatTy {
 		return errors.New("geth rejected block as non-canonical")
 	}
+	if gasLimitErr != nil {
+		log.Warn("failed to read per-block gas limit", "block", block.Number(), "err", gasLimitErr)
+	} else {
+		s.updateBlockGasLimit(block.NumberU64(), blockGasLimit)
+	}
 	return nil
 }
 
+func readBlockGasLimit(statedb *state.StateDB) (uint64, error) {
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return 0, err
+	}
+	return arbState.L2PricingState().PerBlockGasLimit()
+}
+
+// updateBlockGasLimit tracks the per-block gas limit ArbOS applies to the blocks after blockNum.
+// The chain owner can change it at any time, and ArbOS reads it for every block, so it takes effect without a restart.
+func (s *ExecutionEngine) updateBlockGasLimit(blockNum uint64, limit uint64) {
+	if limit == s.blockGasLimit {
+		return
+	}
+	if s.blockGasLimit != 0 {
+		log.Info("per-block gas limit changed", "block", blockNum, "previous", s.blockGasLimit, "limit", limit)
+	}
+	s.blockGasLimit = limit
+	blockGasLimitGauge.Update(int64(limit))
+}
+
+// BlockGasLimit returns the per-block gas limit as of the latest block created by this engine, or 0 if none was created yet.
+func (s *ExecutionEngine) BlockGasLimit() uint64 {
+	s.createBlocksMutex.Lock()
+	defer s.createBlocksMutex.Unlock()
+	return s.blockGasLimit
+}
+
 type MessageResult struct {
 	BlockHash common.Hash
 	SendRoot  common.Hash
@@ -530,38 +571,9 @@ func (s *ExecutionEngine) digestMessageWithBlockMutex(num arbutil.MessageIndex,
 
 	if time.Now().After(s.nextScheduledVersionCheck) {
 		s.nextScheduledVersionCheck = time.Now().Add(time.Minute)
-		arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
-		if err != nil {
+		if err := logScheduledArbOSUpgrade(statedb, s.arbosVersionCheck().ErrorBefore); err != nil {
 			return err
 		}
-		version, timestampInt, err := arbState.GetScheduledUpgrade()
-		if err != nil {
-			return err
-		}
-		var timeUntilUpgrade time.Duration
-		var timestamp time.Time
-		if timestampInt == 0 {
-			// This upgrade will take effect in the next block
-			timestamp = time.Now()
-		} else {
-			// This upgrade is scheduled for the future
-			timestamp = time.Unix(int64(timestampInt), 0)
-			timeUntilUpgrade = time.Until(timestamp)
-		}
-		maxSupportedVersion := params.ArbitrumDevTestChainConfig().ArbitrumChainParams.InitialArbOSVersion
-		logLevel := log.Warn
-		if timeUntilUpgrade < time.Hour*24 {
-			logLevel = log.Error
-		}
-		if version > maxSupportedVersion {
-			logLevel(
-				"you need to update your node to the latest version before this scheduled ArbOS upgrade",
-				"timeUntilUpgrade", timeUntilUpgrade,
-				"upgradeScheduledFor", timestamp,
-				"maxSupportedArbosVersion", maxSupportedVersion,
-				"pendingArbosUpgradeVersion", version,
-			)
-		}
 	}
 
 	sharedmetrics.UpdateSequenceNumberInBlockGauge(num)
@@ -12,14 +12,16 @@ import (
 )
 
 type ExecutionNode struct {
-	ChainDB      ethdb.Database
-	Backend      *arbitrum.Backend
-	FilterSystem *filters.FilterSystem
-	ArbInterface *ArbInterface
-	ExecEngine   *ExecutionEngine
-	Recorder     *BlockRecorder
-	Sequencer    *Sequencer // either nil or same as TxPublisher
-	TxPublisher  TransactionPublisher
+	ChainDB        ethdb.Database
+	Backend        *arbitrum.Backend
+	FilterSystem   *filters.FilterSystem
+	ArbInterface   *ArbInterface
+	ExecEngine     *ExecutionEngine
+	Recorder       *BlockRecorder
+	Sequencer      *Sequencer // either nil or same as TxPublisher
+	TxPublisher    TransactionPublisher
+	EventPublisher *EventPublisher    // nil if disabled
+	OwnerWatcher   *ChainOwnerWatcher // nil if disabled
 }
 
 func CreateExecutionNode(
@@ -34,11 +36,16 @@ func CreateExecutionNode(
 	recordingDbConfig *arbitrum.RecordingDatabaseConfig,
 	seqConfigFetcher SequencerConfigFetcher,
 	precheckConfigFetcher TxPreCheckerConfigFetcher,
+	arbosVersionCheckFetcher ArbOSVersionCheckConfigFetcher,
+	workersConfig *WorkersConfig,
+	eventPublisherConfig *EventPublisherConfig,
+	ownerWatcherConfig *ChainOwnerWatcherConfig,
 ) (*ExecutionNode, error) {
 	execEngine, err := NewExecutionEngine(l2BlockChain)
 	if err != nil {
 		return nil, err
 	}
+	execEngine.arbosVersionCheck = arbosVersionCheckFetcher
 	recorder := NewBlockRecorder(recordingDbConfig, execEngine, chainDB)
 	var txPublisher TransactionPublisher
 	var sequencer *Sequencer
@@ -47,7 +54,8 @@ func CreateExecutionNode(
 		if fwTarget != "" {
 			return nil, errors.New("sequencer and forwarding target both set")
 		}
-		sequencer, err = NewSequencer(execEngine, l1Reader, seqConfigFetcher)
+		workers := NewWorkerPool(workersConfig.SenderRecoveryWorkers())
+		sequencer, err = NewSequencer(execEngine, l1Reader, seqConfigFetcher, workers)
 		if err != nil {
 			return nil, err
 		}
@@ -62,7 +70,7 @@ func CreateExecutionNode(
 		}
 	}
 
-	txPublisher = NewTxPreChecker(txPublisher, l2BlockChain, precheckConfigFetcher)
+	txPublisher = NewTxPreChecker(txPublisher, l2BlockChain, precheckConfigFetcher, seqConfigFetcher)
 	arbInterface, err := NewArbInterface(execEngine, txPublisher)
 	if err != nil {
 		return nil, err
@@ -75,16 +83,32 @@ func CreateExecutionNode(
 	if err != nil {
 		return nil, err
 	}
+	var eventPublisher *EventPublisher
+	if eventPublisherConfig.Enable {
+		eventPublisher, err = NewEventPublisher(l2BlockChain, eventPublisherConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var ownerWatcher *ChainOwnerWatcher
+	if ownerWatcherConfig.Enabled() {
+		ownerWatcher, err = NewChainOwnerWatcher(l2BlockChain, ownerWatcherConfig, sequencer)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &ExecutionNode{
-		ChainDB:      chainDB,
-		Backend:      backend,
-		FilterSystem: filterSystem,
-		ArbInterface: arbInterface,
-		ExecEngine:   execEngine,
-		Recorder:     recorder,
-		Sequencer:    sequencer,
-		TxPublisher:  txPublisher,
+		ChainDB:        chainDB,
+		Backend:        backend,
+		FilterSystem:   filterSystem,
+		ArbInterface:   arbInterface,
+		ExecEngine:     execEngine,
+		Recorder:       recorder,
+		Sequencer:      sequencer,
+		TxPublisher:    txPublisher,
+		EventPublisher: eventPublisher,
+		OwnerWatcher:   ownerWatcher,
 	}, nil
 
 }
@@ -0,0 +1,142 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	ownerActionsCounter          = metrics.NewRegisteredCounter("arb/execution/owner/actions", nil)
+	untrustedOwnerActionsCounter = metrics.NewRegisteredCounter("arb/execution/owner/untrusted_actions", nil)
+)
+
+var arbOwnerAddress = common.HexToAddress("0x70")
+
+type ChainOwnerWatcherConfig struct {
+	TrustedOwners  []string `koanf:"trusted-owners"`
+	PauseSequencer bool     `koanf:"pause-sequencer"`
+}
+
+var DefaultChainOwnerWatcherConfig = ChainOwnerWatcherConfig{
+	TrustedOwners:  []string{},
+	PauseSequencer: false,
+}
+
+func ChainOwnerWatcherConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".trusted-owners", DefaultChainOwnerWatcherConfig.TrustedOwners, "chain owner addresses expected to change the chain's on-chain configuration, changes by any other owner are reported (empty disables the watcher)")
+	f.Bool(prefix+".pause-sequencer", DefaultChainOwnerWatcherConfig.PauseSequencer, "also pause the sequencer when an untrusted owner changes the chain's configuration, until it is restarted or reactivated")
+}
+
+func (c *ChainOwnerWatcherConfig) Enabled() bool {
+	return len(c.TrustedOwners) > 0
+}
+
+func (c *ChainOwnerWatcherConfig) Validate() error {
+	for _, owner := range c.TrustedOwners {
+		if !common.IsHexAddress(owner) {
+			return fmt.Errorf("invalid chain-owner-watcher.trusted-owners address %#v", owner)
+		}
+	}
+	return nil
+}
+
+// ChainOwnerWatcher reports chain configuration changes made through ArbOwner by owners other than the trusted ones.
+// ArbOS applies such changes regardless, this only alerts the operator.
+type ChainOwnerWatcher struct {
+	stopwaiter.StopWaiter
+
+	bc        *core.BlockChain
+	config    *ChainOwnerWatcherConfig
+	trusted   map[common.Address]struct{}
+	filterer  *precompilesgen.ArbOwnerFilterer
+	sequencer *Sequencer // nil if not sequencing
+}
+
+func NewChainOwnerWatcher(bc *core.BlockChain, config *ChainOwnerWatcherConfig, sequencer *Sequencer) (*ChainOwnerWatcher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	filterer, err := precompilesgen.NewArbOwnerFilterer(arbOwnerAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	trusted := make(map[common.Address]struct{})
+	for _, owner := range config.TrustedOwners {
+		trusted[common.HexToAddress(owner)] = struct{}{}
+	}
+	return &ChainOwnerWatcher{
+		bc:        bc,
+		config:    config,
+		trusted:   trusted,
+		filterer:  filterer,
+		sequencer: sequencer,
+	}, nil
+}
+
+func (w *ChainOwnerWatcher) Start(ctxIn context.Context) {
+	w.StopWaiter.Start(ctxIn, w)
+	chainEvents := make(chan core.ChainEvent, 128)
+	subscription := w.bc.SubscribeChainEvent(chainEvents)
+	w.LaunchThread(func(ctx context.Context) {
+		defer subscription.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-subscription.Err():
+				if err != nil {
+					log.Error("chain owner watcher chain subscription failed", "err", err)
+				}
+				return
+			case event := <-chainEvents:
+				w.checkLogs(event.Block, event.Logs)
+			}
+		}
+	})
+}
+
+func (w *ChainOwnerWatcher) checkLogs(block *types.Block, logs []*types.Log) {
+	for _, txLog := range logs {
+		if txLog.Address != arbOwnerAddress {
+			continue
+		}
+		ownerActs, err := w.filterer.ParseOwnerActs(*txLog)
+		if err != nil {
+			continue
+		}
+		ownerActionsCounter.Inc(1)
+		if _, ok := w.trusted[ownerActs.Owner]; ok {
+			log.Info("trusted chain owner changed chain configuration", "block", block.Number(), "tx", txLog.TxHash, "owner", ownerActs.Owner, "method", hexutil.Encode(ownerActs.Method[:]))
+			continue
+		}
+		untrustedOwnerActionsCounter.Inc(1)
+		log.Error(
+			"UNTRUSTED CHAIN OWNER CHANGED CHAIN CONFIGURATION",
+			"block", block.Number(),
+			"tx", txLog.TxHash,
+			"owner", ownerActs.Owner,
+			"method", hexutil.Encode(ownerActs.Method[:]),
+			"data", hexutil.Encode(ownerActs.Data),
+			"trusted", strings.Join(w.config.TrustedOwners, ","),
+		)
+		if w.config.PauseSequencer && w.sequencer != nil {
+			log.Error("pausing sequencer after untrusted chain owner action")
+			w.sequencer.Pause()
+		}
+	}
+}
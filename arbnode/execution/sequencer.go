@@ -46,10 +46,33 @@ var (
 	nonceCacheClearedCounter                = metrics.NewRegisteredCounter("arb/sequencer/noncecache/cleared", nil)
 	nonceFailureCacheSizeGauge              = metrics.NewRegisteredGauge("arb/sequencer/noncefailurecache/size", nil)
 	nonceFailureCacheOverflowCounter        = metrics.NewRegisteredGauge("arb/sequencer/noncefailurecache/overflow", nil)
+	nonceFailureQueuedCounter               = metrics.NewRegisteredCounter("arb/sequencer/noncefailurecache/queued", nil)
+	nonceFailureRevivedCounter              = metrics.NewRegisteredCounter("arb/sequencer/noncefailurecache/revived", nil)
+	nonceFailureDroppedCounter              = metrics.NewRegisteredCounter("arb/sequencer/noncefailurecache/dropped", nil)
+	nonceFailureGapRejectedCounter          = metrics.NewRegisteredCounter("arb/sequencer/noncefailurecache/gap_rejected", nil)
 	blockCreationTimer                      = metrics.NewRegisteredTimer("arb/sequencer/block/creation", nil)
 	successfulBlocksCounter                 = metrics.NewRegisteredCounter("arb/sequencer/block/successful", nil)
 	conditionalTxRejectedBySequencerCounter = metrics.NewRegisteredCounter("arb/sequencer/condtionaltx/rejected", nil)
 	conditionalTxAcceptedBySequencerCounter = metrics.NewRegisteredCounter("arb/sequencer/condtionaltx/accepted", nil)
+	senderWhitelistRejectedCounter          = metrics.NewRegisteredCounter("arb/sequencer/senderwhitelist/rejected", nil)
+	queuedTxExpiredCounter                  = metrics.NewRegisteredCounter("arb/sequencer/queue/expired", nil)
+	// queued transactions successfully forwarded, requeued and rejected on a role switch
+	roleSwitchForwardedCounter = metrics.NewRegisteredCounter("arb/sequencer/roleswitch/forwarded", nil)
+	roleSwitchRequeuedCounter  = metrics.NewRegisteredCounter("arb/sequencer/roleswitch/requeued", nil)
+	roleSwitchRejectedCounter  = metrics.NewRegisteredCounter("arb/sequencer/roleswitch/rejected", nil)
+	// held transactions successfully forwarded when evicted from the nonce failure cache
+	nonceFailureForwardedCounter = metrics.NewRegisteredCounter("arb/sequencer/noncefailurecache/forwarded", nil)
+)
+
+var ErrSenderNotWhitelisted = errors.New("transaction sender is not on the sequencer's sender whitelist")
+var ErrQueuedTxExpired = errors.New("transaction expired after waiting too long in the sequencer")
+var ErrSequencerRoleSwitched = fmt.Errorf("%w: switched to forwarding before the transaction was sequenced, resubmit it", ErrNoSequencer)
+
+const (
+	// transactions accepted before switching to forwarding are forwarded to the new sequencer
+	RoleSwitchTxsForward = "forward"
+	// transactions accepted before switching to forwarding are rejected with a retryable error
+	RoleSwitchTxsReject = "reject"
 )
 
 type SequencerConfig struct {
@@ -57,7 +80,7 @@ type SequencerConfig struct {
 	MaxBlockSpeed               time.Duration            `koanf:"max-block-speed" reload:"hot"`
 	MaxRevertGasReject          uint64                   `koanf:"max-revert-gas-reject" reload:"hot"`
 	MaxAcceptableTimestampDelta time.Duration            `koanf:"max-acceptable-timestamp-delta" reload:"hot"`
-	SenderWhitelist             string                   `koanf:"sender-whitelist"`
+	SenderWhitelist             string                   `koanf:"sender-whitelist" reload:"hot"`
 	Forwarder                   ForwarderConfig          `koanf:"forwarder"`
 	QueueSize                   int                      `koanf:"queue-size"`
 	QueueTimeout                time.Duration            `koanf:"queue-timeout" reload:"hot"`
@@ -65,6 +88,10 @@ type SequencerConfig struct {
 	MaxTxDataSize               int                      `koanf:"max-tx-data-size" reload:"hot"`
 	NonceFailureCacheSize       int                      `koanf:"nonce-failure-cache-size" reload:"hot"`
 	NonceFailureCacheExpiry     time.Duration            `koanf:"nonce-failure-cache-expiry" reload:"hot"`
+	NonceFailureMaxGap          uint64                   `koanf:"nonce-failure-max-gap" reload:"hot"`
+	MaxQueuedTxAge              time.Duration            `koanf:"max-queued-tx-age" reload:"hot"`
+	MaxResumeTimestampJump      time.Duration            `koanf:"max-resume-timestamp-jump" reload:"hot"`
+	RoleSwitchTxs               string                   `koanf:"role-switch-txs" reload:"hot"`
 	Dangerous                   DangerousSequencerConfig `koanf:"dangerous"`
 }
 
@@ -94,11 +121,28 @@ func (c *SequencerConfig) Validate() error {
 			return fmt.Errorf("sequencer sender whitelist entry \"%v\" is not a valid address", address)
 		}
 	}
+	if c.MaxQueuedTxAge < 0 {
+		return fmt.Errorf("invalid sequencer max-queued-tx-age %v, must not be negative", c.MaxQueuedTxAge)
+	}
+	if c.MaxResumeTimestampJump < 0 {
+		return fmt.Errorf("invalid sequencer max-resume-timestamp-jump %v, must not be negative", c.MaxResumeTimestampJump)
+	}
+	if c.MaxResumeTimestampJump > 0 && c.MaxResumeTimestampJump < time.Second {
+		return fmt.Errorf("invalid sequencer max-resume-timestamp-jump %v, must be at least a second as block timestamps are in seconds", c.MaxResumeTimestampJump)
+	}
+	if c.RoleSwitchTxs != RoleSwitchTxsForward && c.RoleSwitchTxs != RoleSwitchTxsReject {
+		return fmt.Errorf("invalid sequencer role-switch-txs %#v, expected %v or %v", c.RoleSwitchTxs, RoleSwitchTxsForward, RoleSwitchTxsReject)
+	}
 	return nil
 }
 
 type SequencerConfigFetcher func() *SequencerConfig
 
+// OversizedTxError is returned for transactions larger than max-tx-data-size
+func OversizedTxError(size int, maxSize int) error {
+	return fmt.Errorf("%w: transaction too large, limit %d bytes, got %d bytes", txpool.ErrOversizedData, maxSize, size)
+}
+
 var DefaultSequencerConfig = SequencerConfig{
 	Enable:                      false,
 	MaxBlockSpeed:               time.Millisecond * 100,
@@ -114,6 +158,10 @@ var DefaultSequencerConfig = SequencerConfig{
 	MaxTxDataSize:           95000,
 	NonceFailureCacheSize:   1024,
 	NonceFailureCacheExpiry: time.Second,
+	NonceFailureMaxGap:      0,
+	MaxQueuedTxAge:          0,
+	MaxResumeTimestampJump:  0,
+	RoleSwitchTxs:           RoleSwitchTxsForward,
 }
 
 var TestSequencerConfig = SequencerConfig{
@@ -130,6 +178,10 @@ var TestSequencerConfig = SequencerConfig{
 	MaxTxDataSize:               95000,
 	NonceFailureCacheSize:       1024,
 	NonceFailureCacheExpiry:     time.Second,
+	NonceFailureMaxGap:          0,
+	MaxQueuedTxAge:              0,
+	MaxResumeTimestampJump:      0,
+	RoleSwitchTxs:               RoleSwitchTxsForward,
 }
 
 func SequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -143,8 +195,12 @@ func SequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Duration(prefix+".queue-timeout", DefaultSequencerConfig.QueueTimeout, "maximum amount of time transaction can wait in queue")
 	f.Int(prefix+".nonce-cache-size", DefaultSequencerConfig.NonceCacheSize, "size of the tx sender nonce cache")
 	f.Int(prefix+".max-tx-data-size", DefaultSequencerConfig.MaxTxDataSize, "maximum transaction size the sequencer will accept")
-	f.Int(prefix+".nonce-failure-cache-size", DefaultSequencerConfig.NonceFailureCacheSize, "number of transactions with too high of a nonce to keep in memory while waiting for their predecessor")
-	f.Duration(prefix+".nonce-failure-cache-expiry", DefaultSequencerConfig.NonceFailureCacheExpiry, "maximum amount of time to wait for a predecessor before rejecting a tx with nonce too high")
+	f.Int(prefix+".nonce-failure-cache-size", DefaultSequencerConfig.NonceFailureCacheSize, "number of transactions with too high of a nonce to keep in memory while waiting for their predecessor (each held transaction, up to its maximum data size, and its pending RPC call stay in memory)")
+	f.Duration(prefix+".nonce-failure-cache-expiry", DefaultSequencerConfig.NonceFailureCacheExpiry, "maximum amount of time to wait for a predecessor before rejecting a tx with nonce too high (the RPC call submitting the tx blocks for up to this long)")
+	f.Uint64(prefix+".nonce-failure-max-gap", DefaultSequencerConfig.NonceFailureMaxGap, "maximum number of nonces a tx may be ahead of its sender's nonce to be held waiting for its predecessors, txs further ahead are rejected immediately (0 = no limit)")
+	f.String(prefix+".role-switch-txs", DefaultSequencerConfig.RoleSwitchTxs, "what to do with transactions accepted but not yet sequenced when switching to forwarding, e.g. on a sequencer coordinator handoff: \"forward\" (forward them to the new sequencer) or \"reject\" (return a retryable error so clients resubmit them)")
+	f.Duration(prefix+".max-queued-tx-age", DefaultSequencerConfig.MaxQueuedTxAge, "maximum amount of time a transaction can spend in the sequencer, including while held waiting for its nonce predecessors, before it's dropped with an error (0 = no limit besides queue-timeout and nonce-failure-cache-expiry)")
+	f.Duration(prefix+".max-resume-timestamp-jump", DefaultSequencerConfig.MaxResumeTimestampJump, "after the sequencer starts or is resumed, advance block timestamps by at most this much per block past the last block's timestamp until they catch up with the local clock, instead of a single jump over the pause (0 = jump immediately)")
 	DangerousSequencerConfigAddOptions(prefix+".dangerous", f)
 }
 
@@ -157,6 +213,23 @@ type txQueueItem struct {
 	firstAppearance time.Time
 }
 
+// expiredError returns an ErrQueuedTxExpired error if the item is older than maxAge (0 = no limit)
+func (i *txQueueItem) expiredError(maxAge time.Duration) error {
+	return i.expiredErrorAt(time.Now(), maxAge)
+}
+
+// expiredErrorAt is expiredError at the time now, an item exactly maxAge old hasn't expired yet
+func (i *txQueueItem) expiredErrorAt(now time.Time, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	age := now.Sub(i.firstAppearance)
+	if age > maxAge {
+		return fmt.Errorf("%w: tx %v waited %v, limit %v", ErrQueuedTxExpired, i.tx.Hash(), age.Round(time.Millisecond), maxAge)
+	}
+	return nil
+}
+
 func (i *txQueueItem) returnResult(err error) {
 	if i.returnedResult {
 		log.Error("attempting to return result to already finished queue item", "err", err)
@@ -258,9 +331,14 @@ type nonceFailure struct {
 	revived   bool
 }
 
+// nonceFailureCache holds transactions with too high of a nonce until their predecessor arrives.
+// Held transactions stay in memory along with their pending RPC calls, so the memory used is bounded
+// by nonce-failure-cache-size times the maximum transaction size.
 type nonceFailureCache struct {
 	*containers.LruCache[addressAndNonce, *nonceFailure]
 	getExpiry func() time.Duration
+	getMaxGap func() uint64
+	getMaxAge func() time.Duration
 }
 
 func (c nonceFailureCache) Contains(err NonceError) bool {
@@ -270,10 +348,19 @@ func (c nonceFailureCache) Contains(err NonceError) bool {
 
 func (c nonceFailureCache) Add(err NonceError, queueItem txQueueItem) {
 	expiry := queueItem.firstAppearance.Add(c.getExpiry())
+	if maxAge := c.getMaxAge(); maxAge > 0 && queueItem.firstAppearance.Add(maxAge).Before(expiry) {
+		expiry = queueItem.firstAppearance.Add(maxAge)
+	}
 	if c.Contains(err) || time.Now().After(expiry) {
 		queueItem.returnResult(err)
 		return
 	}
+	if maxGap := c.getMaxGap(); maxGap > 0 && err.txNonce-err.stateNonce > maxGap {
+		nonceFailureGapRejectedCounter.Inc(1)
+		queueItem.returnResult(err)
+		return
+	}
+	nonceFailureQueuedCounter.Inc(1)
 	key := addressAndNonce{err.sender, err.txNonce}
 	val := &nonceFailure{
 		queueItem: queueItem,
@@ -290,15 +377,19 @@ func (c nonceFailureCache) Add(err NonceError, queueItem txQueueItem) {
 type Sequencer struct {
 	stopwaiter.StopWaiter
 
-	execEngine      *ExecutionEngine
-	txQueue         chan txQueueItem
-	txRetryQueue    containers.Queue[txQueueItem]
-	l1Reader        *headerreader.HeaderReader
-	config          SequencerConfigFetcher
-	senderWhitelist map[common.Address]struct{}
-	nonceCache      *nonceCache
-	nonceFailures   *nonceFailureCache
-	onForwarderSet  chan struct{}
+	execEngine     *ExecutionEngine
+	txQueue        chan txQueueItem
+	txRetryQueue   containers.Queue[txQueueItem]
+	l1Reader       *headerreader.HeaderReader
+	config         SequencerConfigFetcher
+	nonceCache     *nonceCache
+	nonceFailures  *nonceFailureCache
+	onForwarderSet chan struct{}
+
+	// parsed from senderWhitelistConfig
+	senderWhitelistMutex  sync.Mutex
+	senderWhitelist       map[common.Address]struct{}
+	senderWhitelistConfig string
 
 	L1BlockAndTimeMutex sync.Mutex
 	l1BlockNumber       uint64
@@ -310,53 +401,70 @@ type Sequencer struct {
 	activeMutex sync.Mutex
 	pauseChan   chan struct{}
 	forwarder   *TxForwarder
+
+	workers *WorkerPool
+
+	// added to max-block-speed, e.g. while validation lags behind
+	extraBlockDelay atomic.Int64
+
+	// set when the sequencer starts or resumes, until block timestamps catch up with the local clock
+	resumingTimestamps atomic.Bool
+	// only accessed by the sequencing thread, whether the last block's timestamp was limited
+	cappedTimestamp bool
 }
 
-func NewSequencer(execEngine *ExecutionEngine, l1Reader *headerreader.HeaderReader, configFetcher SequencerConfigFetcher) (*Sequencer, error) {
+func NewSequencer(execEngine *ExecutionEngine, l1Reader *headerreader.HeaderReader, configFetcher SequencerConfigFetcher, workers *WorkerPool) (*Sequencer, error) {
 	config := configFetcher()
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
-	senderWhitelist := make(map[common.Address]struct{})
-	entries := strings.Split(config.SenderWhitelist, ",")
-	for _, address := range entries {
-		if len(address) == 0 {
-			continue
-		}
-		senderWhitelist[common.HexToAddress(address)] = struct{}{}
-	}
 	s := &Sequencer{
-		execEngine:      execEngine,
-		txQueue:         make(chan txQueueItem, config.QueueSize),
-		l1Reader:        l1Reader,
-		config:          configFetcher,
-		senderWhitelist: senderWhitelist,
-		nonceCache:      newNonceCache(config.NonceCacheSize),
-		l1BlockNumber:   0,
-		l1Timestamp:     0,
-		pauseChan:       nil,
-		onForwarderSet:  make(chan struct{}, 1),
+		execEngine:            execEngine,
+		txQueue:               make(chan txQueueItem, config.QueueSize),
+		l1Reader:              l1Reader,
+		config:                configFetcher,
+		senderWhitelist:       parseSenderWhitelist(config.SenderWhitelist),
+		senderWhitelistConfig: config.SenderWhitelist,
+		nonceCache:            newNonceCache(config.NonceCacheSize),
+		l1BlockNumber:         0,
+		l1Timestamp:           0,
+		pauseChan:             nil,
+		onForwarderSet:        make(chan struct{}, 1),
+		workers:               workers,
 	}
 	s.nonceFailures = &nonceFailureCache{
 		containers.NewLruCacheWithOnEvict(config.NonceCacheSize, s.onNonceFailureEvict),
 		func() time.Duration { return configFetcher().NonceFailureCacheExpiry },
+		func() uint64 { return configFetcher().NonceFailureMaxGap },
+		func() time.Duration { return configFetcher().MaxQueuedTxAge },
 	}
+	s.resumingTimestamps.Store(true)
 	execEngine.EnableReorgSequencing()
 	return s, nil
 }
 
 func (s *Sequencer) onNonceFailureEvict(_ addressAndNonce, failure *nonceFailure) {
 	if failure.revived {
+		nonceFailureRevivedCounter.Inc(1)
 		return
 	}
+	nonceFailureDroppedCounter.Inc(1)
 	queueItem := failure.queueItem
 	err := queueItem.ctx.Err()
 	if err != nil {
 		queueItem.returnResult(err)
 		return
 	}
+	if err := queueItem.expiredError(s.config().MaxQueuedTxAge); err != nil {
+		queuedTxExpiredCounter.Inc(1)
+		queueItem.returnResult(err)
+		return
+	}
 	_, forwarder := s.GetPauseAndForwarder()
-	if forwarder != nil {
+	if forwarder != nil && s.config().RoleSwitchTxs == RoleSwitchTxsReject {
+		roleSwitchRejectedCounter.Inc(1)
+		queueItem.returnResult(ErrSequencerRoleSwitched)
+	} else if forwarder != nil {
 		// We might not have gotten the predecessor tx because our forwarder did. Let's try there instead.
 		// We run this in a background goroutine because LRU eviction needs to be quick.
 		// We use an untracked thread for a few reasons:
@@ -366,6 +474,9 @@ func (s *Sequencer) onNonceFailureEvict(_ addressAndNonce, failure *nonceFailure
 		//   - The RPC handler is on a separate StopWaiter anyways -- we should respect its context.
 		s.LaunchUntrackedThread(func() {
 			err = forwarder.PublishTransaction(queueItem.ctx, queueItem.tx, queueItem.options)
+			if err == nil {
+				nonceFailureForwardedCounter.Inc(1)
+			}
 			queueItem.returnResult(err)
 		})
 	} else {
@@ -387,6 +498,20 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 	sequencerBacklogGauge.Inc(1)
 	defer sequencerBacklogGauge.Dec(1)
 
+	if s.config().SenderWhitelist != "" {
+		// rejected before taking up room in the queue or being forwarded, block production checks again
+		bc := s.execEngine.bc
+		header := bc.CurrentBlock()
+		signer := types.MakeSigner(bc.Config(), arbmath.BigAdd(header.Number, common.Big1), header.Time)
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			return err
+		}
+		if err := s.checkSenderWhitelist(sender); err != nil {
+			return err
+		}
+	}
+
 	_, forwarder := s.GetPauseAndForwarder()
 	if forwarder != nil {
 		err := forwarder.PublishTransaction(parentCtx, tx, options)
@@ -395,17 +520,6 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 		}
 	}
 
-	if len(s.senderWhitelist) > 0 {
-		signer := types.LatestSigner(s.execEngine.bc.Config())
-		sender, err := types.Sender(signer, tx)
-		if err != nil {
-			return err
-		}
-		_, authorized := s.senderWhitelist[sender]
-		if !authorized {
-			return errors.New("transaction sender is not on the whitelist")
-		}
-	}
 	if tx.Type() >= types.ArbitrumDepositTxType {
 		// Should be unreachable due to UnmarshalBinary not accepting Arbitrum internal txs
 		return types.ErrTxTypeNotSupported
@@ -449,7 +563,40 @@ func (s *Sequencer) PublishTransaction(parentCtx context.Context, tx *types.Tran
 	}
 }
 
+func parseSenderWhitelist(config string) map[common.Address]struct{} {
+	senderWhitelist := make(map[common.Address]struct{})
+	for _, address := range strings.Split(config, ",") {
+		if len(address) == 0 {
+			continue
+		}
+		senderWhitelist[common.HexToAddress(address)] = struct{}{}
+	}
+	return senderWhitelist
+}
+
+// checkSenderWhitelist rejects senders missing from a non-empty whitelist, picking up whitelist changes on config reloads
+func (s *Sequencer) checkSenderWhitelist(sender common.Address) error {
+	s.senderWhitelistMutex.Lock()
+	defer s.senderWhitelistMutex.Unlock()
+	if config := s.config().SenderWhitelist; config != s.senderWhitelistConfig {
+		s.senderWhitelist = parseSenderWhitelist(config)
+		s.senderWhitelistConfig = config
+		log.Info("sequencer sender whitelist updated", "senders", len(s.senderWhitelist))
+	}
+	if len(s.senderWhitelist) == 0 {
+		return nil
+	}
+	if _, authorized := s.senderWhitelist[sender]; !authorized {
+		senderWhitelistRejectedCounter.Inc(1)
+		return fmt.Errorf("%w: %v", ErrSenderNotWhitelisted, sender)
+	}
+	return nil
+}
+
 func (s *Sequencer) preTxFilter(_ *params.ChainConfig, header *types.Header, statedb *state.StateDB, _ *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, sender common.Address, l1Info *arbos.L1Info) error {
+	if err := s.checkSenderWhitelist(sender); err != nil {
+		return err
+	}
 	if s.nonceCache.Caching() {
 		stateNonce := s.nonceCache.Get(header, statedb, sender)
 		err := MakeNonceError(sender, tx.Nonce(), stateNonce)
@@ -545,6 +692,9 @@ func (s *Sequencer) ForwardTo(url string) error {
 func (s *Sequencer) Activate() {
 	s.activeMutex.Lock()
 	defer s.activeMutex.Unlock()
+	if s.forwarder != nil || s.pauseChan != nil {
+		s.resumingTimestamps.Store(true)
+	}
 	if s.forwarder != nil {
 		s.forwarder.Disable()
 		s.forwarder = nil
@@ -555,6 +705,41 @@ func (s *Sequencer) Activate() {
 	}
 }
 
+// resumeTimestamp returns the timestamp for the next block, limiting how far it jumps past the last block's
+// timestamp after the sequencer started or resumed, as configured by max-resume-timestamp-jump.
+// It never lags the local clock by more than max-acceptable-timestamp-delta.
+// Only the sequencing thread may call it.
+func (s *Sequencer) resumeTimestamp(now int64, lastHeader *types.Header, config *SequencerConfig) int64 {
+	if !s.resumingTimestamps.Load() {
+		return now
+	}
+	maxJump := int64(config.MaxResumeTimestampJump / time.Second)
+	if maxJump <= 0 || lastHeader == nil {
+		s.resumingTimestamps.Store(false)
+		s.cappedTimestamp = false
+		return now
+	}
+	lastTimestamp := int64(lastHeader.Time)
+	capped := lastTimestamp + maxJump
+	if minTimestamp := now - int64(config.MaxAcceptableTimestampDelta/time.Second); capped < minTimestamp {
+		capped = minTimestamp
+	}
+	if now <= capped {
+		if s.cappedTimestamp {
+			log.Info("sequencer block timestamps caught up with the local clock", "timestamp", now)
+			s.cappedTimestamp = false
+		}
+		s.resumingTimestamps.Store(false)
+		return now
+	}
+	if !s.cappedTimestamp {
+		// logged once, until the timestamps caught up
+		s.cappedTimestamp = true
+		log.Info("limiting block timestamp jumps after sequencer resumed", "lastTimestamp", lastTimestamp, "timestamp", capped, "localTimestamp", now, "behind", time.Duration(now-capped)*time.Second)
+	}
+	return capped
+}
+
 func (s *Sequencer) Pause() {
 	s.activeMutex.Lock()
 	defer s.activeMutex.Unlock()
@@ -595,14 +780,28 @@ func (s *Sequencer) handleInactive(ctx context.Context, queueItems []txQueueItem
 		case <-pause:
 		}
 	}
+	if s.config().RoleSwitchTxs == RoleSwitchTxsReject {
+		// the queued transactions were accepted before switching, clients can resubmit them to the new sequencer
+		for _, item := range queueItems {
+			item.returnResult(ErrSequencerRoleSwitched)
+		}
+		roleSwitchRejectedCounter.Inc(int64(len(queueItems)))
+		s.nonceFailures.Clear()
+		return true
+	}
 	publishResults := make(chan *txQueueItem, len(queueItems))
 	for _, item := range queueItems {
 		item := item
 		go func() {
 			res := forwarder.PublishTransaction(item.ctx, item.tx, item.options)
 			if errors.Is(res, ErrNoSequencer) {
+				// the new sequencer isn't ready yet, keep the transaction to try again
+				roleSwitchRequeuedCounter.Inc(1)
 				publishResults <- &item
 			} else {
+				if res == nil {
+					roleSwitchForwardedCounter.Inc(1)
+				}
 				publishResults <- nil
 				item.returnResult(res)
 			}
@@ -657,6 +856,11 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem) []txQueueItem {
 	}
 	nextHeaderNumber := arbmath.BigAdd(latestHeader.Number, common.Big1)
 	signer := types.MakeSigner(bc.Config(), nextHeaderNumber, latestHeader.Time)
+	txs := make([]*types.Transaction, 0, len(queueItems))
+	for _, queueItem := range queueItems {
+		txs = append(txs, queueItem.tx)
+	}
+	s.workers.RecoverSenders(signer, txs)
 	outputQueueItems := make([]txQueueItem, 0, len(queueItems))
 	var nextQueueItem *txQueueItem
 	var queueItemsIdx int
@@ -804,6 +1008,11 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 			queueItem.returnResult(err)
 			continue
 		}
+		if err := queueItem.expiredError(config.MaxQueuedTxAge); err != nil {
+			queuedTxExpiredCounter.Inc(1)
+			queueItem.returnResult(err)
+			continue
+		}
 		txBytes, err := queueItem.tx.MarshalBinary()
 		if err != nil {
 			queueItem.returnResult(err)
@@ -811,7 +1020,7 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		}
 		if len(txBytes) > config.MaxTxDataSize {
 			// This tx is too large
-			queueItem.returnResult(txpool.ErrOversizedData)
+			queueItem.returnResult(OversizedTxError(len(txBytes), config.MaxTxDataSize))
 			continue
 		}
 		if totalBatchSize+len(txBytes) > config.MaxTxDataSize {
@@ -839,7 +1048,7 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		return false
 	}
 
-	timestamp := time.Now().Unix()
+	timestamp := s.resumeTimestamp(time.Now().Unix(), s.execEngine.bc.CurrentBlock(), config)
 	s.L1BlockAndTimeMutex.Lock()
 	l1Block := s.l1BlockNumber
 	l1Timestamp := s.l1Timestamp
@@ -963,6 +1172,11 @@ func (s *Sequencer) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// SetExtraBlockDelay slows down block production by waiting an additional delay between blocks
+func (s *Sequencer) SetExtraBlockDelay(delay time.Duration) {
+	s.extraBlockDelay.Store(int64(delay))
+}
+
 func (s *Sequencer) Start(ctxIn context.Context) error {
 	s.StopWaiter.Start(ctxIn, s)
 	if s.l1Reader != nil {
@@ -991,7 +1205,7 @@ func (s *Sequencer) Start(ctxIn context.Context) error {
 	}
 
 	s.CallIteratively(func(ctx context.Context) time.Duration {
-		nextBlock := time.Now().Add(s.config().MaxBlockSpeed)
+		nextBlock := time.Now().Add(s.config().MaxBlockSpeed + time.Duration(s.extraBlockDelay.Load()))
 		madeBlock := s.createBlock(ctx)
 		if madeBlock {
 			// Note: this may return a negative duration, but timers are fine with that (they treat negative durations as 0).
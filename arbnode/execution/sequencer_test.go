@@ -0,0 +1,340 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/statetransfer"
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestMain(m *testing.M) {
+	// tests check metric values
+	os.Exit(testhelpers.RunWithMetrics(m))
+}
+
+func TestResumeTimestamp(t *testing.T) {
+	config := DefaultSequencerConfig
+	config.MaxResumeTimestampJump = 10 * time.Second
+	config.MaxAcceptableTimestampDelta = time.Hour
+	header := func(timestamp uint64) *types.Header {
+		return &types.Header{Time: timestamp}
+	}
+	s := &Sequencer{}
+
+	if got := s.resumeTimestamp(1100, header(1000), &config); got != 1100 {
+		t.Fatalf("timestamp limited without resuming: got %v, want 1100", got)
+	}
+
+	s.resumingTimestamps.Store(true)
+	if got := s.resumeTimestamp(1100, header(1000), &config); got != 1010 {
+		t.Fatalf("first resumed timestamp: got %v, want 1010", got)
+	}
+	if !s.cappedTimestamp || !s.resumingTimestamps.Load() {
+		t.Fatal("capping not tracked after limiting the timestamp")
+	}
+	if got := s.resumeTimestamp(1100, header(1010), &config); got != 1020 {
+		t.Fatalf("second resumed timestamp: got %v, want 1020", got)
+	}
+
+	// never lag the local clock by more than max-acceptable-timestamp-delta
+	lagConfig := config
+	lagConfig.MaxAcceptableTimestampDelta = time.Minute
+	if got := s.resumeTimestamp(2000, header(1000), &lagConfig); got != 1940 {
+		t.Fatalf("lagging timestamp: got %v, want 1940", got)
+	}
+
+	if got := s.resumeTimestamp(1100, header(1095), &config); got != 1100 {
+		t.Fatalf("caught up timestamp: got %v, want 1100", got)
+	}
+	if s.cappedTimestamp || s.resumingTimestamps.Load() {
+		t.Fatal("still limiting timestamps after catching up")
+	}
+	if got := s.resumeTimestamp(2000, header(1000), &config); got != 2000 {
+		t.Fatalf("timestamp limited after catching up: got %v, want 2000", got)
+	}
+
+	// disabled by a zero max-resume-timestamp-jump
+	config.MaxResumeTimestampJump = 0
+	s.resumingTimestamps.Store(true)
+	if got := s.resumeTimestamp(2000, header(1000), &config); got != 2000 {
+		t.Fatalf("timestamp limited while disabled: got %v, want 2000", got)
+	}
+	if s.resumingTimestamps.Load() {
+		t.Fatal("still resuming while disabled")
+	}
+}
+
+// roleSwitchTestTarget stands in for the new sequencer transactions are forwarded to
+type roleSwitchTestTarget struct {
+	received    atomic.Int64
+	rejectNonce uint64
+}
+
+var errRoleSwitchTestRejected = errors.New("rejected by the new sequencer")
+
+func (t *roleSwitchTestTarget) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	t.received.Add(1)
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if tx.Nonce() == t.rejectNonce {
+		return common.Hash{}, errRoleSwitchTestRejected
+	}
+	return tx.Hash(), nil
+}
+
+func TestRoleSwitchTxs(t *testing.T) {
+	for _, roleSwitchTxs := range []string{RoleSwitchTxsForward, RoleSwitchTxsReject} {
+		t.Run(roleSwitchTxs, func(t *testing.T) {
+			testRoleSwitchTxs(t, roleSwitchTxs)
+		})
+	}
+}
+
+func testRoleSwitchTxs(t *testing.T, roleSwitchTxs string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a queued transaction the new sequencer fails to take isn't counted as forwarded
+	target := &roleSwitchTestTarget{rejectNonce: 2}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("eth", target); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(rpcServer)
+	defer httpServer.Close()
+
+	config := TestSequencerConfig
+	config.RoleSwitchTxs = roleSwitchTxs
+	s := &Sequencer{config: func() *SequencerConfig { return &config }}
+	s.nonceFailures = &nonceFailureCache{
+		containers.NewLruCacheWithOnEvict(config.NonceFailureCacheSize, s.onNonceFailureEvict),
+		func() time.Duration { return config.NonceFailureCacheExpiry },
+		func() uint64 { return config.NonceFailureMaxGap },
+		func() time.Duration { return config.MaxQueuedTxAge },
+	}
+	// switched to forwarding, as by ForwardTo
+	s.forwarder = NewForwarder(httpServer.URL, &config.Forwarder)
+	if err := s.forwarder.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.forwarder.StopAndWait()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(412346))
+	var resultChans []chan error
+	newItem := func(nonce uint64) txQueueItem {
+		tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(params.GWei),
+			Gas:       params.TxGas,
+			To:        &sender,
+		})
+		resultChan := make(chan error, 1)
+		resultChans = append(resultChans, resultChan)
+		return txQueueItem{
+			tx:              tx,
+			resultChan:      resultChan,
+			ctx:             ctx,
+			firstAppearance: time.Now(),
+		}
+	}
+	var queueItems []txQueueItem
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		queueItems = append(queueItems, newItem(nonce))
+	}
+	// held until their predecessor arrives
+	for nonce := uint64(5); nonce < 7; nonce++ {
+		s.nonceFailures.Add(NonceError{sender: sender, txNonce: nonce, stateNonce: 3}, newItem(nonce))
+	}
+	if s.nonceFailures.Len() != 2 {
+		t.Fatal("unexpected number of held transactions", s.nonceFailures.Len())
+	}
+
+	forwardedBefore := roleSwitchForwardedCounter.Count()
+	heldForwardedBefore := nonceFailureForwardedCounter.Count()
+	requeuedBefore := roleSwitchRequeuedCounter.Count()
+	rejectedBefore := roleSwitchRejectedCounter.Count()
+
+	if !s.handleInactive(ctx, queueItems) {
+		t.Fatal("handleInactive didn't handle the transactions while forwarding")
+	}
+	if s.nonceFailures.Len() != 0 {
+		t.Fatal("held transactions left after switching to forwarding", s.nonceFailures.Len())
+	}
+	if s.txRetryQueue.Len() != 0 {
+		t.Fatal("transactions requeued although the new sequencer is ready", s.txRetryQueue.Len())
+	}
+
+	for i, resultChan := range resultChans {
+		var result error
+		select {
+		case res, ok := <-resultChan:
+			if !ok {
+				t.Fatal("result channel closed without a result for tx", i)
+			}
+			result = res
+		case <-time.After(5 * time.Second):
+			t.Fatal("no result for tx", i)
+		}
+		if _, ok := <-resultChan; ok {
+			t.Fatal("more than one result for tx", i)
+		}
+		if roleSwitchTxs == RoleSwitchTxsReject {
+			if !errors.Is(result, ErrSequencerRoleSwitched) {
+				t.Fatal("unexpected result for rejected tx", i, result)
+			}
+		} else if i == int(target.rejectNonce) {
+			if result == nil || !strings.Contains(result.Error(), errRoleSwitchTestRejected.Error()) {
+				t.Fatal("unexpected result for tx rejected by the new sequencer", i, result)
+			}
+		} else if result != nil {
+			t.Fatal("failed to forward tx", i, result)
+		}
+	}
+
+	txs := int64(len(resultChans))
+	wantForwarded, wantRejected := txs, int64(0)
+	if roleSwitchTxs == RoleSwitchTxsReject {
+		wantForwarded, wantRejected = 0, txs
+	}
+	if target.received.Load() != wantForwarded {
+		t.Fatal("unexpected number of transactions received by the new sequencer", target.received.Load(), "want", wantForwarded)
+	}
+	// queued transactions are counted as forwarded by the role switch, held ones by the nonce failure cache
+	wantHeldForwarded := int64(0)
+	if roleSwitchTxs != RoleSwitchTxsReject {
+		wantHeldForwarded = int64(len(resultChans) - len(queueItems))
+	}
+	wantRoleSwitchForwarded := wantForwarded - wantHeldForwarded
+	if roleSwitchTxs != RoleSwitchTxsReject {
+		wantRoleSwitchForwarded-- // the rejected one
+	}
+	if got := roleSwitchForwardedCounter.Count() - forwardedBefore; got != wantRoleSwitchForwarded {
+		t.Fatal("unexpected forwarded counter", got, "want", wantRoleSwitchForwarded)
+	}
+	if got := nonceFailureForwardedCounter.Count() - heldForwardedBefore; got != wantHeldForwarded {
+		t.Fatal("unexpected held forwarded counter", got, "want", wantHeldForwarded)
+	}
+	if got := roleSwitchRequeuedCounter.Count() - requeuedBefore; got != 0 {
+		t.Fatal("unexpected requeued counter", got, "want", 0)
+	}
+	if got := roleSwitchRejectedCounter.Count() - rejectedBefore; got != wantRejected {
+		t.Fatal("unexpected rejected counter", got, "want", wantRejected)
+	}
+}
+
+func TestSenderWhitelistBeforeForwarding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainConfig := params.ArbitrumDevTestChainConfig()
+	initReader := statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{})
+	bc, err := WriteOrTestBlockChain(rawdb.NewMemoryDatabase(), nil, initReader, chainConfig, arbostypes.TestInitMessage, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execEngine, err := NewExecutionEngine(bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &roleSwitchTestTarget{rejectNonce: 1}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("eth", target); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(rpcServer)
+	defer httpServer.Close()
+
+	allowedKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := TestSequencerConfig
+	config.SenderWhitelist = crypto.PubkeyToAddress(allowedKey.PublicKey).Hex()
+	s := &Sequencer{execEngine: execEngine, config: func() *SequencerConfig { return &config }}
+	s.forwarder = NewForwarder(httpServer.URL, &config.Forwarder)
+	if err := s.forwarder.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.forwarder.StopAndWait()
+
+	signer := types.LatestSignerForChainID(chainConfig.ChainID)
+	newTx := func(key *ecdsa.PrivateKey) *types.Transaction {
+		to := crypto.PubkeyToAddress(key.PublicKey)
+		return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(params.GWei),
+			Gas:       params.TxGas,
+			To:        &to,
+		})
+	}
+
+	// not forwarded to the active sequencer
+	err = s.PublishTransaction(ctx, newTx(otherKey), nil)
+	if !errors.Is(err, ErrSenderNotWhitelisted) {
+		t.Fatal("transaction of a sender missing from the whitelist not rejected, got", err)
+	}
+	if target.received.Load() != 0 {
+		t.Fatal("transaction of a sender missing from the whitelist was forwarded")
+	}
+	if err := s.PublishTransaction(ctx, newTx(allowedKey), nil); err != nil {
+		t.Fatal("failed to forward transaction of a whitelisted sender", err)
+	}
+	if target.received.Load() != 1 {
+		t.Fatal("transaction of a whitelisted sender wasn't forwarded")
+	}
+}
+
+func TestQueueItemExpiry(t *testing.T) {
+	start := time.Now()
+	item := &txQueueItem{tx: types.NewTx(&types.LegacyTx{}), firstAppearance: start}
+	maxAge := 10 * time.Second
+	if err := item.expiredErrorAt(start.Add(maxAge-time.Millisecond), maxAge); err != nil {
+		t.Fatal("tx younger than the max age expired:", err)
+	}
+	if err := item.expiredErrorAt(start.Add(maxAge), maxAge); err != nil {
+		t.Fatal("tx exactly the max age old expired:", err)
+	}
+	if err := item.expiredErrorAt(start.Add(maxAge+time.Millisecond), maxAge); !errors.Is(err, ErrQueuedTxExpired) {
+		t.Fatal("tx older than the max age not expired, got", err)
+	}
+	if err := item.expiredErrorAt(start.Add(time.Hour), 0); err != nil {
+		t.Fatal("tx expired without a max age:", err)
+	}
+}
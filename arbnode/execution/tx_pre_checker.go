@@ -5,11 +5,14 @@ package execution
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/arbitrum_types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -27,6 +30,8 @@ var (
 	conditionalTxAcceptedByTxPreCheckerCurrentStateCounter = metrics.NewRegisteredCounter("arb/txprechecker/condtionaltx/currentstate/accepted", nil)
 	conditionalTxRejectedByTxPreCheckerOldStateCounter     = metrics.NewRegisteredCounter("arb/txprechecker/condtionaltx/oldstate/rejected", nil)
 	conditionalTxAcceptedByTxPreCheckerOldStateCounter     = metrics.NewRegisteredCounter("arb/txprechecker/condtionaltx/oldstate/accepted", nil)
+	oversizedTxRejectedByTxPreCheckerCounter               = metrics.NewRegisteredCounter("arb/txprechecker/oversized/rejected", nil)
+	l1DataFeeTxRejectedByTxPreCheckerCounter               = metrics.NewRegisteredCounter("arb/txprechecker/l1datafee/rejected", nil)
 )
 
 const TxPreCheckerStrictnessNone uint = 0
@@ -38,6 +43,8 @@ type TxPreCheckerConfig struct {
 	Strictness             uint  `koanf:"strictness" reload:"hot"`
 	RequiredStateAge       int64 `koanf:"required-state-age" reload:"hot"`
 	RequiredStateMaxBlocks uint  `koanf:"required-state-max-blocks" reload:"hot"`
+	L1DataFeeErrors        bool  `koanf:"l1-data-fee-errors" reload:"hot"`
+	ReportL1DataFee        bool  `koanf:"report-l1-data-fee" reload:"hot"`
 }
 
 type TxPreCheckerConfigFetcher func() *TxPreCheckerConfig
@@ -46,6 +53,8 @@ var DefaultTxPreCheckerConfig = TxPreCheckerConfig{
 	Strictness:             TxPreCheckerStrictnessNone,
 	RequiredStateAge:       2,
 	RequiredStateMaxBlocks: 4,
+	L1DataFeeErrors:        true,
+	ReportL1DataFee:        false,
 }
 
 func TxPreCheckerConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -54,19 +63,23 @@ func TxPreCheckerConfigAddOptions(prefix string, f *flag.FlagSet) {
 		"30 = full validation which may reject txs that would succeed")
 	f.Int64(prefix+".required-state-age", DefaultTxPreCheckerConfig.RequiredStateAge, "how long ago should the storage conditions from eth_SendRawTransactionConditional be true, 0 = don't check old state")
 	f.Uint(prefix+".required-state-max-blocks", DefaultTxPreCheckerConfig.RequiredStateMaxBlocks, "maximum number of blocks to look back while looking for the <required-state-age> seconds old state, 0 = don't limit the search")
+	f.Bool(prefix+".l1-data-fee-errors", DefaultTxPreCheckerConfig.L1DataFeeErrors, "distinguish txs that can't pay for their L1 data fee from ordinary insufficient funds and intrinsic gas errors (requires strictness of at least 20)")
+	f.Bool(prefix+".report-l1-data-fee", DefaultTxPreCheckerConfig.ReportL1DataFee, "include the estimated L1 data fee in the message and data of L1 data fee errors")
 }
 
 type TxPreChecker struct {
 	TransactionPublisher
-	bc     *core.BlockChain
-	config TxPreCheckerConfigFetcher
+	bc        *core.BlockChain
+	config    TxPreCheckerConfigFetcher
+	seqConfig SequencerConfigFetcher
 }
 
-func NewTxPreChecker(publisher TransactionPublisher, bc *core.BlockChain, config TxPreCheckerConfigFetcher) *TxPreChecker {
+func NewTxPreChecker(publisher TransactionPublisher, bc *core.BlockChain, config TxPreCheckerConfigFetcher, seqConfig SequencerConfigFetcher) *TxPreChecker {
 	return &TxPreChecker{
 		TransactionPublisher: publisher,
 		bc:                   bc,
 		config:               config,
+		seqConfig:            seqConfig,
 	}
 }
 
@@ -109,6 +122,53 @@ func MakeNonceError(sender common.Address, txNonce uint64, stateNonce uint64) er
 	}
 }
 
+// L1DataFeeError is returned for txs that would've been accepted if not for the L1 data fee,
+// either because their gas limit or the sender's balance doesn't cover it.
+// It unwraps to core.ErrIntrinsicGas or core.ErrInsufficientFunds respectively.
+type L1DataFeeError struct {
+	err       error
+	sender    common.Address
+	txGas     uint64
+	intrinsic uint64
+	balance   *big.Int
+	cost      *big.Int
+	dataGas   uint64
+	dataFee   *big.Int // nil unless reported
+}
+
+// L1DataFeeErrorData is returned as the json rpc error data when the L1 data fee is reported
+type L1DataFeeErrorData struct {
+	L1DataFee *hexutil.Big   `json:"l1DataFee"`
+	L1DataGas hexutil.Uint64 `json:"l1DataGas"`
+}
+
+func (e L1DataFeeError) Error() string {
+	var msg string
+	if errors.Is(e.err, core.ErrInsufficientFunds) {
+		msg = fmt.Sprintf("%v: insufficient funds for L1 data fee: address %v have %v want %v, of which the L1 data fee is %v gas", e.err, e.sender, e.balance, e.cost, e.dataGas)
+	} else {
+		msg = fmt.Sprintf("%v: gas limit %v doesn't cover the L1 data fee: need %v gas for the L1 data fee in addition to %v intrinsic gas", e.err, e.txGas, e.dataGas, e.intrinsic)
+	}
+	if e.dataFee != nil {
+		msg += fmt.Sprintf(" (estimated L1 data fee %v wei)", e.dataFee)
+	}
+	return msg
+}
+
+func (e L1DataFeeError) Unwrap() error {
+	return e.err
+}
+
+func (e L1DataFeeError) ErrorData() interface{} {
+	if e.dataFee == nil {
+		return nil
+	}
+	return L1DataFeeErrorData{
+		L1DataFee: (*hexutil.Big)(e.dataFee),
+		L1DataGas: hexutil.Uint64(e.dataGas),
+	}
+}
+
 func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *types.Header, statedb *state.StateDB, arbos *arbosState.ArbosState, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, config *TxPreCheckerConfig) error {
 	if config.Strictness < TxPreCheckerStrictnessAlwaysCompatible {
 		return nil
@@ -180,23 +240,54 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 			conditionalTxAcceptedByTxPreCheckerOldStateCounter.Inc(1)
 		}
 	}
+	dataCost, _ := arbos.L1PricingState().GetPosterInfo(tx, l1pricing.BatchPosterAddress)
+	dataGas := arbmath.BigDiv(dataCost, header.BaseFee).Uint64()
+	l1DataFeeError := func(err error, balance, cost *big.Int) error {
+		l1DataFeeTxRejectedByTxPreCheckerCounter.Inc(1)
+		feeErr := L1DataFeeError{
+			err:       err,
+			sender:    sender,
+			txGas:     tx.Gas(),
+			intrinsic: intrinsic,
+			balance:   balance,
+			cost:      cost,
+			dataGas:   dataGas,
+		}
+		if config.ReportL1DataFee {
+			feeErr.dataFee = dataCost
+		}
+		return feeErr
+	}
 	balance := statedb.GetBalance(sender)
 	cost := tx.Cost()
 	if arbmath.BigLessThan(balance, cost) {
+		// the gas limit pays for the L1 data fee, so check whether the sender could afford the tx without it
+		if config.L1DataFeeErrors && dataGas > 0 && tx.Gas() >= intrinsic+dataGas {
+			costWithoutDataFee := arbmath.BigSub(cost, arbmath.BigMulByUint(tx.GasFeeCap(), dataGas))
+			if !arbmath.BigLessThan(balance, costWithoutDataFee) {
+				return l1DataFeeError(core.ErrInsufficientFunds, balance, cost)
+			}
+		}
 		return fmt.Errorf("%w: address %v have %v want %v", core.ErrInsufficientFunds, sender, balance, cost)
 	}
 	if config.Strictness >= TxPreCheckerStrictnessFullValidation && tx.Nonce() > stateNonce {
 		return MakeNonceError(sender, tx.Nonce(), stateNonce)
 	}
-	dataCost, _ := arbos.L1PricingState().GetPosterInfo(tx, l1pricing.BatchPosterAddress)
-	dataGas := arbmath.BigDiv(dataCost, header.BaseFee)
-	if tx.Gas() < intrinsic+dataGas.Uint64() {
+	if tx.Gas() < intrinsic+dataGas {
+		if config.L1DataFeeErrors {
+			return l1DataFeeError(core.ErrIntrinsicGas, balance, cost)
+		}
 		return core.ErrIntrinsicGas
 	}
 	return nil
 }
 
 func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions) error {
+	// the sequencer would reject it anyway, but only after queueing it
+	if maxSize := c.seqConfig().MaxTxDataSize; maxSize > 0 && tx.Size() > uint64(maxSize) {
+		oversizedTxRejectedByTxPreCheckerCounter.Inc(1)
+		return OversizedTxError(int(tx.Size()), maxSize)
+	}
 	block := c.bc.CurrentBlock()
 	statedb, err := c.bc.StateAt(block.Root)
 	if err != nil {
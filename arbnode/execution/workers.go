@@ -0,0 +1,98 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execution
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	senderRecoveryWorkersBusyGauge        = metrics.NewRegisteredGauge("arb/sequencer/senderrecovery/workers/busy", nil)
+	senderRecoveryWorkersUtilizationGauge = metrics.NewRegisteredGaugeFloat64("arb/sequencer/senderrecovery/workers/utilization", nil)
+)
+
+// WorkersConfig sets the execution worker counts. Only the sequencer's sender recovery runs on workers,
+// block production itself executes transactions one after another.
+type WorkersConfig struct {
+	SenderRecovery int `koanf:"sender-recovery"`
+}
+
+func WorkersConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".sender-recovery", DefaultWorkersConfig.SenderRecovery, "number of workers the sequencer uses to recover the senders of queued transactions in parallel before checking their nonces (0 = number of CPUs)")
+}
+
+var DefaultWorkersConfig = WorkersConfig{
+	SenderRecovery: 0,
+}
+
+func (c *WorkersConfig) Validate() error {
+	if c.SenderRecovery < 0 {
+		return fmt.Errorf("invalid sender recovery worker count %d, must not be negative", c.SenderRecovery)
+	}
+	if c.SenderRecovery > runtime.NumCPU() {
+		return fmt.Errorf("sender recovery worker count %d exceeds the number of CPUs %d", c.SenderRecovery, runtime.NumCPU())
+	}
+	return nil
+}
+
+func (c *WorkersConfig) SenderRecoveryWorkers() int {
+	if c.SenderRecovery == 0 {
+		return runtime.NumCPU()
+	}
+	return c.SenderRecovery
+}
+
+// WorkerPool bounds the number of goroutines the sequencer uses to recover transaction senders
+type WorkerPool struct {
+	size int
+	busy atomic.Int64
+}
+
+func NewWorkerPool(size int) *WorkerPool {
+	if size < 1 {
+		size = 1
+	}
+	return &WorkerPool{size: size}
+}
+
+func (p *WorkerPool) Size() int {
+	return p.size
+}
+
+func (p *WorkerPool) updateBusy(delta int64) {
+	busy := p.busy.Add(delta)
+	senderRecoveryWorkersBusyGauge.Update(busy)
+	senderRecoveryWorkersUtilizationGauge.Update(float64(busy) / float64(p.size))
+}
+
+// RecoverSenders recovers the senders of txs in parallel, so later calls to types.Sender hit the cache.
+// Recovery errors are ignored here and surface again when the sender is requested.
+func (p *WorkerPool) RecoverSenders(signer types.Signer, txs []*types.Transaction) {
+	workers := p.size
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		worker := worker
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.updateBusy(1)
+			defer p.updateBusy(-1)
+			for i := worker; i < len(txs); i += workers {
+				_, _ = types.Sender(signer, txs[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
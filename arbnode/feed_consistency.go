@@ -0,0 +1,140 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster"
+)
+
+var (
+	feedConsistencyCheckedCounter   = metrics.NewRegisteredCounter("arb/batchposter/feedcheck/checked", nil)
+	feedConsistencyUncheckedCounter = metrics.NewRegisteredCounter("arb/batchposter/feedcheck/unrecorded", nil)
+	feedConsistencyMismatchCounter  = metrics.NewRegisteredCounter("arb/batchposter/feedcheck/mismatch", nil)
+)
+
+// SetFeedConsistencyCheck makes the batch poster decode every batch before posting it, and compare
+// its messages to the ones sent out over the feed. A mismatch is sent to fatalErr and the batch isn't posted.
+func (b *BatchPoster) SetFeedConsistencyCheck(feed *broadcaster.Broadcaster, recordSize int, fatalErr chan<- error) {
+	feed.RecordMessages(recordSize)
+	b.feed = feed
+	b.fatalErr = fatalErr
+}
+
+// sameMessage compares messages ignoring the batch gas cost, which isn't part of the batch data
+func sameMessage(a, b arbostypes.MessageWithMetadata) (bool, error) {
+	encode := func(msg arbostypes.MessageWithMetadata) ([]byte, error) {
+		if msg.Message != nil && msg.Message.BatchGasCost != nil {
+			withoutGasCost := *msg.Message
+			withoutGasCost.BatchGasCost = nil
+			msg.Message = &withoutGasCost
+		}
+		return rlp.EncodeToBytes(msg)
+	}
+	encodedA, err := encode(a)
+	if err != nil {
+		return false, err
+	}
+	encodedB, err := encode(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(encodedA, encodedB), nil
+}
+
+// decodeBatchMessages extracts the messages from sequencerMsg the same way the inbox tracker will once it's posted
+func (b *BatchPoster) decodeBatchMessages(ctx context.Context, seqNum uint64, prevDelayed uint64, afterDelayed uint64, sequencerMsg []byte, count arbutil.MessageIndex) ([]arbostypes.MessageWithMetadata, error) {
+	// the time bounds are only used to clamp timestamps, which the batch poster keeps within the bounds already
+	var header []byte
+	for _, value := range []uint64{0, math.MaxUint64, 0, math.MaxUint64, afterDelayed} {
+		var valueBytes [8]byte
+		binary.BigEndian.PutUint64(valueBytes[:], value)
+		header = append(header, valueBytes[:]...)
+	}
+	batch := &SequencerInboxBatch{
+		SequenceNumber:    seqNum,
+		AfterDelayedCount: afterDelayed,
+		serialized:        append(header, sequencerMsg...),
+	}
+	backend := &multiplexerBackend{
+		batchSeqNum: seqNum,
+		batches:     []*SequencerInboxBatch{batch},
+
+		inbox:  b.inbox,
+		ctx:    ctx,
+		client: b.l1Reader.Client(),
+	}
+	multiplexer := arbstate.NewInboxMultiplexer(backend, prevDelayed, nil, arbstate.KeysetValidate)
+	messages := make([]arbostypes.MessageWithMetadata, 0, count)
+	for arbutil.MessageIndex(len(messages)) < count {
+		if len(backend.batches) == 0 {
+			return nil, fmt.Errorf("batch only contains %v of %v messages", len(messages), count)
+		}
+		msg, err := multiplexer.Pop(ctx)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *msg)
+	}
+	if len(backend.batches) != 0 {
+		return nil, fmt.Errorf("batch contains more than the expected %v messages", count)
+	}
+	return messages, nil
+}
+
+// checkFeedConsistency returns an error, after reporting it as fatal, if the batch doesn't match the feed output
+func (b *BatchPoster) checkFeedConsistency(ctx context.Context, position batchPosterPosition, sequencerMsg []byte) error {
+	if b.feed == nil {
+		return nil
+	}
+	afterDelayed := b.building.segments.delayedMsg
+	count := b.building.msgCount - position.MessageCount
+	messages, err := b.decodeBatchMessages(ctx, position.NextSeqNum, position.DelayedMessageCount, afterDelayed, sequencerMsg, count)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return b.feedInconsistent(fmt.Errorf("failed to decode batch %v before posting it: %w", position.NextSeqNum, err))
+	}
+	for i, msg := range messages {
+		pos := position.MessageCount + arbutil.MessageIndex(i)
+		feedMsg, ok := b.feed.RecordedMessage(pos)
+		if !ok {
+			// the message was broadcast too long ago, or before the node started
+			feedConsistencyUncheckedCounter.Inc(1)
+			continue
+		}
+		same, err := sameMessage(msg, feedMsg)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return b.feedInconsistent(fmt.Errorf("message %v in batch %v doesn't match the feed output", pos, position.NextSeqNum))
+		}
+		feedConsistencyCheckedCounter.Inc(1)
+	}
+	return nil
+}
+
+func (b *BatchPoster) feedInconsistent(err error) error {
+	log.Error("batch isn't consistent with the feed output, not posting it", "err", err)
+	feedConsistencyMismatchCounter.Inc(1)
+	select {
+	case b.fatalErr <- err:
+	default:
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/broadcaster"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+func TestRotateFeedSigner(t *testing.T) {
+	ctx := context.Background()
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	oldKey, err := crypto.GenerateKey()
+	Require(t, err)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, 5555, make(chan error, 10), signature.DataSignerFromPrivateKey(oldKey))
+	keyDir := t.TempDir()
+	config.SignerRotation = wsbroadcastserver.SignerRotationConfig{
+		KeyDir:  keyDir,
+		KeyEnvs: []string{"TEST_FEED_SIGNER_KEY", "TEST_FEED_SIGNER_PASSWORD"},
+	}
+	api := &FeedOutputAPI{broadcaster: b, config: func() *wsbroadcastserver.BroadcasterConfig { return &config }}
+
+	checkRotation := func(rotation *FeedSignerRotation, expected common.Address) {
+		t.Helper()
+		if rotation.Signer != expected {
+			Fail(t, "rotated to", rotation.Signer, "expected", expected)
+		}
+		if rotation.Persisted || rotation.Note == "" {
+			Fail(t, "rotation not reported as ephemeral", rotation)
+		}
+	}
+
+	envKey, err := crypto.GenerateKey()
+	Require(t, err)
+	if _, err := api.RotateFeedSignerFromEnv(ctx, "TEST_FEED_SIGNER_KEY"); err == nil {
+		Fail(t, "rotated to key from unset environment variable")
+	}
+	t.Setenv("TEST_FEED_SIGNER_KEY", common.Bytes2Hex(crypto.FromECDSA(envKey)))
+	rotation, err := api.RotateFeedSignerFromEnv(ctx, "TEST_FEED_SIGNER_KEY")
+	Require(t, err)
+	checkRotation(rotation, crypto.PubkeyToAddress(envKey.PublicKey))
+
+	keystoreKey, err := crypto.GenerateKey()
+	Require(t, err)
+	ks := keystore.NewKeyStore(keyDir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(keystoreKey, "passphrase")
+	Require(t, err)
+	t.Setenv("TEST_FEED_SIGNER_PASSWORD", "wrong")
+	if _, err := api.RotateFeedSignerFromKeystore(ctx, account.URL.Path, "TEST_FEED_SIGNER_PASSWORD"); err == nil {
+		Fail(t, "rotated to keystore key with a wrong password")
+	}
+	t.Setenv("TEST_FEED_SIGNER_PASSWORD", "passphrase")
+	rotation, err = api.RotateFeedSignerFromKeystore(ctx, account.URL.Path, "TEST_FEED_SIGNER_PASSWORD")
+	Require(t, err)
+	checkRotation(rotation, account.Address)
+
+	// keystore files are also found relative to the key directory
+	rotation, err = api.RotateFeedSignerFromKeystore(ctx, filepath.Base(account.URL.Path), "TEST_FEED_SIGNER_PASSWORD")
+	Require(t, err)
+	checkRotation(rotation, account.Address)
+
+	fileKey, err := crypto.GenerateKey()
+	Require(t, err)
+	Require(t, crypto.SaveECDSA(filepath.Join(keyDir, "feed-signer.key"), fileKey))
+	rotation, err = api.RotateFeedSignerFromFile(ctx, "feed-signer.key")
+	Require(t, err)
+	checkRotation(rotation, crypto.PubkeyToAddress(fileKey.PublicKey))
+}
+
+func TestRotateFeedSignerRefusesOtherSources(t *testing.T) {
+	ctx := context.Background()
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	oldKey, err := crypto.GenerateKey()
+	Require(t, err)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, 5555, make(chan error, 10), signature.DataSignerFromPrivateKey(oldKey))
+	api := &FeedOutputAPI{broadcaster: b, config: func() *wsbroadcastserver.BroadcasterConfig { return &config }}
+
+	otherKey, err := crypto.GenerateKey()
+	Require(t, err)
+	otherKeyHex := common.Bytes2Hex(crypto.FromECDSA(otherKey))
+	outsideDir := t.TempDir()
+	outsideFile := filepath.Join(outsideDir, "outside.key")
+	Require(t, crypto.SaveECDSA(outsideFile, otherKey))
+	t.Setenv("TEST_FEED_SIGNER_OTHER_KEY", otherKeyHex)
+
+	// nothing is allowed without configuring a key directory and environment variables
+	if _, err := api.RotateFeedSignerFromFile(ctx, outsideFile); err == nil {
+		Fail(t, "rotated to key file without a configured key directory")
+	}
+	if _, err := api.RotateFeedSignerFromEnv(ctx, "TEST_FEED_SIGNER_OTHER_KEY"); err == nil {
+		Fail(t, "rotated to key from environment variable without an allowlist")
+	}
+
+	keyDir := t.TempDir()
+	config.SignerRotation = wsbroadcastserver.SignerRotationConfig{
+		KeyDir:  keyDir,
+		KeyEnvs: []string{"TEST_FEED_SIGNER_KEY"},
+	}
+	t.Setenv("TEST_FEED_SIGNER_KEY", otherKeyHex)
+	Require(t, os.Symlink(outsideFile, filepath.Join(keyDir, "link.key")))
+	relativeOutside, err := filepath.Rel(keyDir, outsideFile)
+	Require(t, err)
+	for _, path := range []string{outsideFile, relativeOutside, filepath.Join(keyDir, "..", filepath.Base(outsideDir), "outside.key"), "link.key", ".", keyDir} {
+		if _, err := api.RotateFeedSignerFromFile(ctx, path); err == nil {
+			Fail(t, "rotated to key file outside the key directory", path)
+		}
+		if _, err := api.RotateFeedSignerFromKeystore(ctx, path, "TEST_FEED_SIGNER_KEY"); err == nil {
+			Fail(t, "rotated to keystore outside the key directory", path)
+		}
+	}
+	for _, env := range []string{"TEST_FEED_SIGNER_OTHER_KEY", "PATH", ""} {
+		if _, err := api.RotateFeedSignerFromEnv(ctx, env); err == nil {
+			Fail(t, "rotated to key from disallowed environment variable", env)
+		}
+	}
+	Require(t, os.WriteFile(filepath.Join(keyDir, "keystore.json"), []byte("{}"), 0600))
+	_, err = api.RotateFeedSignerFromKeystore(ctx, "keystore.json", "TEST_FEED_SIGNER_OTHER_KEY")
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		Fail(t, "keystore password read from disallowed environment variable", err)
+	}
+}
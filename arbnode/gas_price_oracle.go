@@ -0,0 +1,159 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+// GasPriceOracleChain is the part of the blockchain the gas price oracle reads recent fees from
+type GasPriceOracleChain interface {
+	CurrentBlock() *types.Header
+	GetHeaderByNumber(number uint64) *types.Header
+	GetBlock(hash common.Hash, number uint64) *types.Block
+}
+
+// blockFees are the fees paid in a block, which the gas price oracle takes its percentiles of
+type blockFees struct {
+	baseFee *big.Int
+	tips    []*big.Int
+}
+
+// GasPriceOracleAPI overrides eth_gasPrice and eth_maxPriorityFeePerGas of the eth namespace,
+// suggesting a percentile of the base fees and priority fees paid over the last blocks.
+// The suggested base fee is never below the current one, so the suggestion can always be included.
+type GasPriceOracleAPI struct {
+	chain      GasPriceOracleChain
+	blocks     uint64
+	percentile int
+
+	// the fees of recent blocks by block hash, so each block is only loaded once
+	feesMutex sync.Mutex
+	fees      *containers.LruCache[common.Hash, *blockFees]
+
+	mutex    sync.Mutex
+	lastHead common.Hash
+	baseFee  *big.Int
+	tip      *big.Int
+}
+
+func NewGasPriceOracleAPI(config *genericconf.GasPriceOracleConfig, chain GasPriceOracleChain) *GasPriceOracleAPI {
+	return &GasPriceOracleAPI{
+		chain:      chain,
+		blocks:     uint64(config.Blocks),
+		percentile: config.Percentile,
+		// room for the blocks replaced by a reorg
+		fees: containers.NewLruCache[common.Hash, *blockFees](config.Blocks * 2),
+	}
+}
+
+// percentileOf returns the given percentile of values, sorting them in place
+func percentileOf(values []*big.Int, percentile int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	return new(big.Int).Set(values[(len(values)-1)*percentile/100])
+}
+
+// blockFees returns the fees paid in the block, or nil if it isn't found or has no base fee
+func (a *GasPriceOracleAPI) blockFees(header *types.Header) *blockFees {
+	hash := header.Hash()
+	a.feesMutex.Lock()
+	fees, ok := a.fees.Get(hash)
+	a.feesMutex.Unlock()
+	if ok {
+		return fees
+	}
+	block := a.chain.GetBlock(hash, header.Number.Uint64())
+	if block == nil || block.BaseFee() == nil {
+		return nil
+	}
+	fees = &blockFees{baseFee: block.BaseFee()}
+	for _, tx := range block.Transactions() {
+		tip, err := tx.EffectiveGasTip(block.BaseFee())
+		if err != nil {
+			// e.g. internal transactions, which pay no fees
+			continue
+		}
+		fees.tips = append(fees.tips, tip)
+	}
+	a.feesMutex.Lock()
+	a.fees.Add(hash, fees)
+	a.feesMutex.Unlock()
+	return fees
+}
+
+// suggest returns the suggested base fee and tip, recomputed only when the head changes
+func (a *GasPriceOracleAPI) suggest() (*big.Int, *big.Int, error) {
+	head := a.chain.CurrentBlock()
+	if head == nil {
+		return nil, nil, errors.New("no current block")
+	}
+	a.mutex.Lock()
+	if a.baseFee != nil && a.lastHead == head.Hash() {
+		baseFee, tip := a.baseFee, a.tip
+		a.mutex.Unlock()
+		return baseFee, tip, nil
+	}
+	a.mutex.Unlock()
+
+	// computed without holding the lock, with the fees of the blocks seen before taken from the cache
+	headNumber := head.Number.Uint64()
+	var baseFees, tips []*big.Int
+	for i := uint64(0); i < a.blocks && i <= headNumber; i++ {
+		header := head
+		if i > 0 {
+			header = a.chain.GetHeaderByNumber(headNumber - i)
+			if header == nil {
+				continue
+			}
+		}
+		fees := a.blockFees(header)
+		if fees == nil {
+			continue
+		}
+		baseFees = append(baseFees, fees.baseFee)
+		tips = append(tips, fees.tips...)
+	}
+	baseFee := percentileOf(baseFees, a.percentile)
+	if head.BaseFee != nil && baseFee.Cmp(head.BaseFee) < 0 {
+		baseFee.Set(head.BaseFee)
+	}
+	tip := percentileOf(tips, a.percentile)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.lastHead = head.Hash()
+	a.baseFee = baseFee
+	a.tip = tip
+	return baseFee, tip, nil
+}
+
+func (a *GasPriceOracleAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	baseFee, tip, err := a.suggest()
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(new(big.Int).Add(baseFee, tip)), nil
+}
+
+func (a *GasPriceOracleAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	_, tip, err := a.suggest()
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(new(big.Int).Set(tip)), nil
+}
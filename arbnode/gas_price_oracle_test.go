@@ -0,0 +1,89 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+type testGasPriceChain struct {
+	blocks []*types.Block
+	loaded int
+}
+
+func (c *testGasPriceChain) CurrentBlock() *types.Header {
+	return c.blocks[len(c.blocks)-1].Header()
+}
+
+func (c *testGasPriceChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[number].Header()
+}
+
+func (c *testGasPriceChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if number >= uint64(len(c.blocks)) || c.blocks[number].Hash() != hash {
+		return nil
+	}
+	c.loaded++
+	return c.blocks[number]
+}
+
+func TestGasPriceOracle(t *testing.T) {
+	ctx := context.Background()
+	chain := &testGasPriceChain{}
+	for i, baseFee := range []int64{900, 100, 500, 300, 400, 200} {
+		chain.blocks = append(chain.blocks, types.NewBlockWithHeader(&types.Header{
+			Number:  big.NewInt(int64(i)),
+			BaseFee: big.NewInt(baseFee),
+		}))
+	}
+	config := genericconf.GasPriceOracleConfig{Enable: true, Blocks: 5, Percentile: 50}
+	Require(t, config.Validate())
+	oracle := NewGasPriceOracleAPI(&config, chain)
+
+	// the median of the base fees of the last 5 blocks, the 900 of the genesis block is out of range
+	price, err := oracle.GasPrice(ctx)
+	Require(t, err)
+	if price.ToInt().Int64() != 300 {
+		Fail(t, "unexpected gas price", price.ToInt())
+	}
+	tip, err := oracle.MaxPriorityFeePerGas(ctx)
+	Require(t, err)
+	if tip.ToInt().Sign() != 0 {
+		Fail(t, "unexpected priority fee without transactions", tip.ToInt())
+	}
+
+	if chain.loaded != 5 {
+		Fail(t, "unexpected number of blocks loaded", chain.loaded)
+	}
+
+	// never suggest less than the current base fee
+	chain.blocks = append(chain.blocks, types.NewBlockWithHeader(&types.Header{
+		Number:  big.NewInt(int64(len(chain.blocks))),
+		BaseFee: big.NewInt(1000),
+	}))
+	price, err = oracle.GasPrice(ctx)
+	Require(t, err)
+	if price.ToInt().Int64() != 1000 {
+		Fail(t, "gas price below the current base fee", price.ToInt())
+	}
+	// the fees of the blocks seen before are cached, only the new head is loaded
+	if chain.loaded != 6 {
+		Fail(t, "blocks loaded again for a new head, loaded", chain.loaded)
+	}
+
+	config.Percentile = 101
+	if config.Validate() == nil {
+		Fail(t, "invalid percentile accepted")
+	}
+}
@@ -6,6 +6,7 @@ package arbnode
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -240,3 +241,97 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestCheckFakeInitMessage(t *testing.T) {
+	_, inbox, _, _ := NewTransactionStreamerForTest(t, common.Address{})
+
+	err := inbox.CheckFakeInitMessage()
+	Require(t, err)
+
+	otherConfig := *inbox.chainConfig
+	otherConfig.ChainID = new(big.Int).Add(otherConfig.ChainID, common.Big1)
+	inbox.chainConfig = &otherConfig
+	if inbox.CheckFakeInitMessage() == nil {
+		Fail(t, "expected init message with a different chain id to be rejected")
+	}
+}
+
+// heartbeatMessage is an L2 heartbeat message, told apart from others by its request id
+func heartbeatMessage(id uint64) arbostypes.MessageWithMetadata {
+	var requestId common.Hash
+	binary.BigEndian.PutUint64(requestId.Bytes()[:8], id)
+	return arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:      arbostypes.L1MessageType_L2Message,
+				RequestId: &requestId,
+			},
+			L2msg: []byte{arbos.L2MessageKind_Heartbeat},
+		},
+		DelayedMessagesRead: 1,
+	}
+}
+
+func TestFeedInboxMismatch(t *testing.T) {
+	_, inbox, _, _ := NewTransactionStreamerForTest(t, common.Address{})
+	config := DefaultTransactionStreamerConfig
+	config.FeedInboxMismatch = FeedInboxMismatchFatal
+	Require(t, config.Validate())
+	inbox.config = func() *TransactionStreamerConfig { return &config }
+	fatalErrChan := make(chan error, 1)
+	inbox.fatalErrChan = fatalErrChan
+
+	// an unconfirmed message, as received from the feed
+	Require(t, inbox.AddMessages(1, false, []arbostypes.MessageWithMetadata{heartbeatMessage(1)}))
+	// the same message confirmed by the inbox is a duplicate
+	Require(t, inbox.AddMessages(1, true, []arbostypes.MessageWithMetadata{heartbeatMessage(1)}))
+	if len(fatalErrChan) != 0 {
+		Fail(t, "duplicate message reported as a mismatch", <-fatalErrChan)
+	}
+
+	Require(t, inbox.AddMessages(2, false, []arbostypes.MessageWithMetadata{heartbeatMessage(2)}))
+	err := inbox.AddMessages(2, true, []arbostypes.MessageWithMetadata{heartbeatMessage(3)})
+	if !errors.Is(err, ErrFeedInboxMismatch) {
+		Fail(t, "expected a feed inbox mismatch, got", err)
+	}
+	if fatalErr := <-fatalErrChan; !errors.Is(fatalErr, ErrFeedInboxMismatch) {
+		Fail(t, "unexpected fatal error", fatalErr)
+	}
+	stored, err := inbox.GetMessage(2)
+	Require(t, err)
+	if *stored.Message.Header.RequestId != *heartbeatMessage(2).Message.Header.RequestId {
+		Fail(t, "mismatching inbox message replaced the stored message")
+	}
+}
+
+func TestInboxReorgIsNotFeedMismatch(t *testing.T) {
+	_, inbox, _, _ := NewTransactionStreamerForTest(t, common.Address{})
+	config := DefaultTransactionStreamerConfig
+	config.FeedInboxMismatch = FeedInboxMismatchFatal
+	Require(t, config.Validate())
+	inbox.config = func() *TransactionStreamerConfig { return &config }
+	fatalErrChan := make(chan error, 1)
+	inbox.fatalErrChan = fatalErrChan
+
+	// a batch confirms messages 1 and 2
+	Require(t, inbox.AddBatchMessagesAndEndBatch(1, 1, []arbostypes.MessageWithMetadata{heartbeatMessage(1), heartbeatMessage(2)}, nil))
+	// a parent chain reorg replaces the batch with one holding different messages
+	Require(t, inbox.AddBatchMessagesAndEndBatch(1, 3, []arbostypes.MessageWithMetadata{heartbeatMessage(3), heartbeatMessage(4)}, nil))
+	if len(fatalErrChan) != 0 {
+		Fail(t, "parent chain reorg reported as a mismatch", <-fatalErrChan)
+	}
+	for pos, id := range map[arbutil.MessageIndex]uint64{1: 3, 2: 4} {
+		stored, err := inbox.GetMessage(pos)
+		Require(t, err)
+		if *stored.Message.Header.RequestId != *heartbeatMessage(id).Message.Header.RequestId {
+			Fail(t, "parent chain reorg didn't replace message", pos)
+		}
+	}
+
+	// replacing an unconfirmed message after the confirmed ones is still a mismatch
+	Require(t, inbox.AddMessages(3, false, []arbostypes.MessageWithMetadata{heartbeatMessage(5)}))
+	err := inbox.AddBatchMessagesAndEndBatch(3, 3, []arbostypes.MessageWithMetadata{heartbeatMessage(6)}, nil)
+	if !errors.Is(err, ErrFeedInboxMismatch) {
+		Fail(t, "expected a feed inbox mismatch, got", err)
+	}
+}
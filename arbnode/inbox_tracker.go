@@ -538,6 +538,19 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client arbutil.L
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
+	// messages confirmed before these batches, replacing them is a parent chain reorg
+	var prevConfirmedCount arbutil.MessageIndex
+	prevBatchCount, err := t.GetBatchCount()
+	if err != nil {
+		return err
+	}
+	if prevBatchCount > 0 {
+		prevConfirmedCount, err = t.GetBatchMessageCount(prevBatchCount - 1)
+		if err != nil {
+			return err
+		}
+	}
+
 	pos := batches[0].SequenceNumber
 	startPos := pos
 	var nextAcc common.Hash
@@ -554,7 +567,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client arbutil.L
 	}
 
 	dbBatch := t.db.NewBatch()
-	err := deleteStartingAt(t.db, dbBatch, delayedSequencedPrefix, uint64ToKey(prevbatchmeta.DelayedMessageCount+1))
+	err = deleteStartingAt(t.db, dbBatch, delayedSequencedPrefix, uint64ToKey(prevbatchmeta.DelayedMessageCount+1))
 	if err != nil {
 		return err
 	}
@@ -682,7 +695,7 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client arbutil.L
 	}
 
 	// This also writes the batch
-	err = t.txStreamer.AddMessagesAndEndBatch(prevbatchmeta.MessageCount, true, messages, dbBatch)
+	err = t.txStreamer.AddBatchMessagesAndEndBatch(prevbatchmeta.MessageCount, prevConfirmedCount, messages, dbBatch)
 	if err != nil {
 		return err
 	}
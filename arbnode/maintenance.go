@@ -5,26 +5,56 @@ package arbnode
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/nitro/arbnode/redislock"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	flag "github.com/spf13/pflag"
 )
 
+var (
+	maintenancePruneRunsCounter       = metrics.NewRegisteredCounter("arb/maintenance/prune/runs", nil)
+	maintenancePruneReclaimedGauge    = metrics.NewRegisteredGauge("arb/maintenance/prune/reclaimed_bytes", nil)
+	maintenancePruneDurationGauge     = metrics.NewRegisteredGauge("arb/maintenance/prune/duration_ms", nil)
+	maintenancePruneDiskUsageGauge    = metrics.NewRegisteredGauge("arb/maintenance/prune/disk_usage_bytes", nil)
+	maintenancePruneTotalReclaimedCnt = metrics.NewRegisteredCounter("arb/maintenance/prune/reclaimed_bytes_total", nil)
+	maintenancePrunedBlocksCounter    = metrics.NewRegisteredCounter("arb/maintenance/prune/blocks", nil)
+)
+
+// MaintenancePausable is a subsystem that is paused while the databases are pruned
+type MaintenancePausable interface {
+	PauseForMaintenance()
+	ResumeAfterMaintenance()
+}
+
 // Regularly runs db compaction if configured
 type MaintenanceRunner struct {
 	stopwaiter.StopWaiter
 
 	config          MaintenanceConfigFetcher
 	seqCoordinator  *SeqCoordinator
+	chainDb         ethdb.Database
+	arbDb           ethdb.Database
 	dbs             []ethdb.Database
+	dataDir         string
 	lastMaintenance time.Time
+	nextPrune       time.Time
+
+	// returns the lowest block that must be kept, e.g. because it isn't validated yet
+	pruneFloor func() (uint64, error)
+	pausables  []MaintenancePausable
 
 	// lock is used to ensures that at any given time, only single node is on
 	// maintenance mode.
@@ -32,8 +62,9 @@ type MaintenanceRunner struct {
 }
 
 type MaintenanceConfig struct {
-	TimeOfDay string              `koanf:"time-of-day" reload:"hot"`
-	Lock      redislock.SimpleCfg `koanf:"lock" reload:"hot"`
+	TimeOfDay string                 `koanf:"time-of-day" reload:"hot"`
+	Lock      redislock.SimpleCfg    `koanf:"lock" reload:"hot"`
+	Prune     MaintenancePruneConfig `koanf:"prune" reload:"hot"`
 
 	// Generated: the minutes since start of UTC day to compact at
 	minutesAfterMidnight int
@@ -66,32 +97,67 @@ func (c *MaintenanceConfig) Validate() error {
 	if !c.parseDbCompactionTime() {
 		return fmt.Errorf("expected sequencer coordinator db compaction time to be in 24-hour HH:MM format but got \"%v\"", c.TimeOfDay)
 	}
+	if c.Prune.Enable && c.Prune.Interval <= 0 {
+		return fmt.Errorf("maintenance prune interval must be positive but got %v", c.Prune.Interval)
+	}
+	if c.Prune.Enable && c.Prune.RetainBlocks == 0 {
+		return errors.New("maintenance prune retain-blocks must be positive")
+	}
 	return nil
 }
 
 func MaintenanceConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".time-of-day", DefaultMaintenanceConfig.TimeOfDay, "UTC 24-hour time of day to run maintenance (currently only db compaction) at (e.g. 15:00)")
 	redislock.AddConfigOptions(prefix+".lock", f)
+	MaintenancePruneConfigAddOptions(prefix+".prune", f)
+}
+
+// MaintenancePruneConfig schedules deleting the bodies and receipts of old blocks, and reclaiming their disk space,
+// while the node is running. State trie pruning still requires an offline --init.prune, as it can't be done while
+// the blockchain is live.
+type MaintenancePruneConfig struct {
+	Enable       bool          `koanf:"enable" reload:"hot"`
+	Interval     time.Duration `koanf:"interval" reload:"hot"`
+	RetainBlocks uint64        `koanf:"retain-blocks" reload:"hot"`
+}
+
+var DefaultMaintenancePruneConfig = MaintenancePruneConfig{
+	Enable:       false,
+	Interval:     24 * time.Hour,
+	RetainBlocks: 2_419_200, // 1 week at 4 blocks per second
+}
+
+func MaintenancePruneConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultMaintenancePruneConfig.Enable, "periodically delete the bodies and receipts of old blocks and reclaim their disk space while running (not allowed in archive mode); if time-of-day is set, only runs during the maintenance window. The state is NOT pruned, it still requires stopping the node and running it with --init.prune")
+	f.Duration(prefix+".interval", DefaultMaintenancePruneConfig.Interval, "minimum time between scheduled prunes")
+	f.Uint64(prefix+".retain-blocks", DefaultMaintenancePruneConfig.RetainBlocks, "number of recent blocks whose bodies and receipts are kept, older ones are deleted (blocks that aren't validated yet are always kept)")
 }
 
 var DefaultMaintenanceConfig = MaintenanceConfig{
 	TimeOfDay: "",
+	Prune:     DefaultMaintenancePruneConfig,
 
 	minutesAfterMidnight: 0,
 }
 
 type MaintenanceConfigFetcher func() *MaintenanceConfig
 
-func NewMaintenanceRunner(config MaintenanceConfigFetcher, seqCoordinator *SeqCoordinator, dbs []ethdb.Database) (*MaintenanceRunner, error) {
+// dataDir is measured to report reclaimed disk space, and may be empty for in-memory databases
+func NewMaintenanceRunner(config MaintenanceConfigFetcher, seqCoordinator *SeqCoordinator, chainDb ethdb.Database, arbDb ethdb.Database, dataDir string) (*MaintenanceRunner, error) {
 	cfg := config()
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
 	}
+	now := time.Now().UTC()
 	res := &MaintenanceRunner{
 		config:          config,
 		seqCoordinator:  seqCoordinator,
-		dbs:             dbs,
-		lastMaintenance: time.Now().UTC(),
+		chainDb:         chainDb,
+		arbDb:           arbDb,
+		dbs:             []ethdb.Database{chainDb, arbDb},
+		dataDir:         dataDir,
+		lastMaintenance: now,
+		nextPrune:       nextPruneTime(now, cfg),
 	}
 
 	if seqCoordinator != nil {
@@ -106,7 +172,20 @@ func NewMaintenanceRunner(config MaintenanceConfigFetcher, seqCoordinator *SeqCo
 	return res, nil
 }
 
+// SetPruneFloor sets a function returning the lowest block whose body and receipts must be kept
+func (mr *MaintenanceRunner) SetPruneFloor(floor func() (uint64, error)) {
+	mr.pruneFloor = floor
+}
+
+// AddPausable pauses the subsystem while the databases are pruned
+func (mr *MaintenanceRunner) AddPausable(pausable MaintenancePausable) {
+	mr.pausables = append(mr.pausables, pausable)
+}
+
 func (mr *MaintenanceRunner) Start(ctxIn context.Context) {
+	if mr.config().Prune.Enable {
+		log.Warn("maintenance.prune only deletes old blocks and receipts, the state isn't pruned and keeps growing; stop the node and run it with --init.prune to reclaim it")
+	}
 	mr.StopWaiter.Start(ctxIn, mr)
 	mr.CallIteratively(mr.maybeRunMaintenance)
 }
@@ -130,21 +209,56 @@ func wentPastTimeOfDay(before time.Time, after time.Time, timeOfDay int) bool {
 	return prevMinutes < dbCompactionMinutes && newMinutes >= dbCompactionMinutes
 }
 
-func (mr *MaintenanceRunner) maybeRunMaintenance(ctx context.Context) time.Duration {
-	config := mr.config()
+// nextPruneTime returns when to prune next after a prune due at last. With a maintenance window configured,
+// it's the start of the first window at least the prune interval after last, so prunes stay aligned to the
+// window however long the runs take.
+func nextPruneTime(last time.Time, config *MaintenanceConfig) time.Time {
+	earliest := last.Add(config.Prune.Interval)
 	if !config.enabled {
-		return time.Minute
+		return earliest
 	}
+	year, month, day := earliest.Date()
+	window := time.Date(year, month, day, 0, config.minutesAfterMidnight, 0, 0, time.UTC)
+	if window.Before(earliest) {
+		window = window.AddDate(0, 0, 1)
+	}
+	return window
+}
 
+// schedulePrune returns if a prune is due at now, advancing the schedule if so
+func (mr *MaintenanceRunner) schedulePrune(now time.Time, config *MaintenanceConfig) bool {
+	if !config.Prune.Enable || now.Before(mr.nextPrune) {
+		return false
+	}
+	due := mr.nextPrune
+	if config.enabled {
+		// count the interval from the window the prune was due in, not from when it happened to run
+		mr.nextPrune = nextPruneTime(due, config)
+	} else {
+		mr.nextPrune = nextPruneTime(now, config)
+	}
+	if mr.nextPrune.Before(now) {
+		// the node was down or busy for several intervals, don't catch up on all of them
+		mr.nextPrune = nextPruneTime(now.Add(-config.Prune.Interval), config)
+	}
+	return true
+}
+
+func (mr *MaintenanceRunner) maybeRunMaintenance(ctx context.Context) time.Duration {
+	config := mr.config()
 	now := time.Now().UTC()
 
-	if !wentPastTimeOfDay(mr.lastMaintenance, now, config.minutesAfterMidnight) {
+	var maintenanceDue bool
+	if config.enabled {
+		maintenanceDue = wentPastTimeOfDay(mr.lastMaintenance, now, config.minutesAfterMidnight)
+	}
+	pruneDue := mr.schedulePrune(now, config)
+	if !maintenanceDue && !pruneDue {
 		return time.Minute
 	}
 
 	if mr.seqCoordinator == nil {
-		mr.lastMaintenance = now
-		mr.runMaintenance()
+		mr.runScheduled(ctx, now, maintenanceDue, pruneDue)
 		return time.Minute
 	}
 
@@ -156,14 +270,27 @@ func (mr *MaintenanceRunner) maybeRunMaintenance(ctx context.Context) time.Durat
 	log.Info("Attempting avoiding lockout and handing off", "targetTime", config.TimeOfDay)
 	// Avoid lockout for the sequencer and try to handoff.
 	if mr.seqCoordinator.AvoidLockout(ctx) && mr.seqCoordinator.TryToHandoffChosenOne(ctx) {
-		mr.lastMaintenance = now
-		mr.runMaintenance()
+		mr.runScheduled(ctx, now, maintenanceDue, pruneDue)
+	} else if pruneDue {
+		// retry on the next iteration
+		mr.nextPrune = now
 	}
 	defer mr.seqCoordinator.SeekLockout(ctx) // needs called even if c.Zombify returns false
 
 	return time.Minute
 }
 
+func (mr *MaintenanceRunner) runScheduled(ctx context.Context, now time.Time, maintenanceDue, pruneDue bool) {
+	if maintenanceDue {
+		mr.lastMaintenance = now
+	}
+	if pruneDue {
+		mr.runPrune(ctx)
+	} else {
+		mr.runMaintenance()
+	}
+}
+
 func (mr *MaintenanceRunner) runMaintenance() {
 	log.Info("Compacting databases (this may take a while...)")
 	results := make(chan error, len(mr.dbs))
@@ -180,3 +307,151 @@ func (mr *MaintenanceRunner) runMaintenance() {
 	}
 	log.Info("Done compacting databases")
 }
+
+// runPrune deletes the bodies and receipts of old blocks, then compacts the databases to drop the deleted
+// entries (and those removed by the message pruner), and reports the reclaimed disk space.
+// The pausable subsystems are paused meanwhile.
+func (mr *MaintenanceRunner) runPrune(ctx context.Context) {
+	for _, pausable := range mr.pausables {
+		pausable.PauseForMaintenance()
+	}
+	defer func() {
+		for _, pausable := range mr.pausables {
+			pausable.ResumeAfterMaintenance()
+		}
+	}()
+	start := time.Now()
+	sizeBefore, measured := mr.diskUsage()
+	pruned, err := mr.pruneBlocks(ctx)
+	if err != nil {
+		log.Error("Failed to prune old blocks", "pruned", pruned, "err", err)
+	}
+	maintenancePrunedBlocksCounter.Inc(int64(pruned))
+	mr.runMaintenance()
+	maintenancePruneRunsCounter.Inc(1)
+	maintenancePruneDurationGauge.Update(time.Since(start).Milliseconds())
+	if !measured {
+		return
+	}
+	sizeAfter, measured := mr.diskUsage()
+	if !measured {
+		return
+	}
+	reclaimed := sizeBefore - sizeAfter
+	if reclaimed < 0 {
+		reclaimed = 0
+	}
+	maintenancePruneDiskUsageGauge.Update(sizeAfter)
+	maintenancePruneReclaimedGauge.Update(reclaimed)
+	maintenancePruneTotalReclaimedCnt.Inc(reclaimed)
+	log.Info("Done pruning databases", "reclaimedBytes", reclaimed, "diskUsageBytes", sizeAfter, "elapsed", time.Since(start))
+}
+
+// pruneBlocks deletes the bodies, receipts and transaction lookup entries of the canonical blocks older than
+// the retained ones, starting after the blocks pruned by previous runs, and returns how many blocks it pruned.
+// Blocks below the floor set by SetPruneFloor and the genesis block are always kept.
+func (mr *MaintenanceRunner) pruneBlocks(ctx context.Context) (uint64, error) {
+	config := mr.config()
+	headHash := rawdb.ReadHeadBlockHash(mr.chainDb)
+	headNumber := rawdb.ReadHeaderNumber(mr.chainDb, headHash)
+	if headNumber == nil || *headNumber < config.Prune.RetainBlocks {
+		return 0, nil
+	}
+	end := *headNumber - config.Prune.RetainBlocks
+	if mr.pruneFloor != nil {
+		floor, err := mr.pruneFloor()
+		if err != nil {
+			return 0, fmt.Errorf("error reading prune floor: %w", err)
+		}
+		if floor < end {
+			end = floor
+		}
+	}
+	start, err := mr.prunedBlocksTail()
+	if err != nil {
+		return 0, err
+	}
+	var pruned uint64
+	batch := mr.chainDb.NewBatch()
+	number := start
+	for ; number < end && ctx.Err() == nil; number++ {
+		hash := rawdb.ReadCanonicalHash(mr.chainDb, number)
+		if hash != (common.Hash{}) {
+			if body := rawdb.ReadBody(mr.chainDb, hash, number); body != nil {
+				txHashes := make([]common.Hash, 0, len(body.Transactions))
+				for _, tx := range body.Transactions {
+					txHashes = append(txHashes, tx.Hash())
+				}
+				rawdb.DeleteTxLookupEntries(batch, txHashes)
+			}
+			rawdb.DeleteBody(batch, hash, number)
+			rawdb.DeleteReceipts(batch, hash, number)
+			pruned++
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := mr.writePruneBatch(batch, number+1); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	if number > start {
+		if err := mr.writePruneBatch(batch, number); err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, ctx.Err()
+}
+
+// prunedBlocksTail returns the first block not pruned yet, which is after the genesis block on the first run
+func (mr *MaintenanceRunner) prunedBlocksTail() (uint64, error) {
+	has, err := mr.arbDb.Has(maintenancePrunedBlocksKey)
+	if err != nil {
+		return 0, err
+	}
+	if has {
+		data, err := mr.arbDb.Get(maintenancePrunedBlocksKey)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(data), nil
+	}
+	chainConfig := rawdb.ReadChainConfig(mr.chainDb, rawdb.ReadCanonicalHash(mr.chainDb, 0))
+	if chainConfig == nil {
+		return 0, errors.New("chain config not found")
+	}
+	return chainConfig.ArbitrumChainParams.GenesisBlockNum + 1, nil
+}
+
+func (mr *MaintenanceRunner) writePruneBatch(batch ethdb.Batch, tail uint64) error {
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return mr.arbDb.Put(maintenancePrunedBlocksKey, binary.BigEndian.AppendUint64(nil, tail))
+}
+
+func (mr *MaintenanceRunner) diskUsage() (int64, bool) {
+	if mr.dataDir == "" {
+		return 0, false
+	}
+	var size int64
+	err := filepath.WalkDir(mr.dataDir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		log.Warn("Failed to measure database disk usage", "dir", mr.dataDir, "err", err)
+		return 0, false
+	}
+	return size, true
+}
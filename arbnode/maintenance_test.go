@@ -4,8 +4,15 @@
 package arbnode
 
 import (
+	"context"
+	"math/big"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestWentPastTimeOfDay(t *testing.T) {
@@ -39,3 +46,94 @@ func TestWentPastTimeOfDay(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneSchedule(t *testing.T) {
+	config := MaintenanceConfig{TimeOfDay: "03:00", Prune: MaintenancePruneConfig{Enable: true, Interval: 24 * time.Hour, RetainBlocks: 1}}
+	Require(t, config.Validate())
+	day := func(d, hour, minute, second int) time.Time {
+		return time.Date(2000, 1, d, hour, minute, second, 0, time.UTC)
+	}
+	mr := &MaintenanceRunner{nextPrune: nextPruneTime(day(1, 1, 0, 0), &config)}
+	if !mr.nextPrune.Equal(day(2, 3, 0, 0)) {
+		Fail(t, "first prune not scheduled in the window after the interval", mr.nextPrune)
+	}
+	for _, tc := range []struct {
+		now  time.Time
+		want bool
+	}{
+		{now: day(2, 2, 59, 0)},
+		{now: day(2, 3, 0, 40), want: true},
+		{now: day(2, 3, 1, 40)},
+		// runs a bit earlier in the window than the day before
+		{now: day(3, 3, 0, 10), want: true},
+		{now: day(4, 3, 0, 30), want: true},
+		// after being down for days, the next prune is in the next window
+		{now: day(10, 12, 0, 0), want: true},
+		{now: day(11, 2, 0, 0)},
+		{now: day(11, 3, 0, 0), want: true},
+	} {
+		if got := mr.schedulePrune(tc.now, &config); got != tc.want {
+			Fail(t, "unexpected prune schedule at", tc.now, "got", got, "next prune", mr.nextPrune)
+		}
+	}
+
+	// without a window, prunes are the interval apart
+	config = MaintenanceConfig{Prune: MaintenancePruneConfig{Enable: true, Interval: time.Hour, RetainBlocks: 1}}
+	Require(t, config.Validate())
+	mr = &MaintenanceRunner{nextPrune: nextPruneTime(day(1, 0, 0, 0), &config)}
+	if mr.schedulePrune(day(1, 0, 59, 0), &config) || !mr.schedulePrune(day(1, 1, 0, 30), &config) {
+		Fail(t, "unexpected prune schedule without a window")
+	}
+	if !mr.nextPrune.Equal(day(1, 2, 0, 30)) {
+		Fail(t, "unexpected next prune without a window", mr.nextPrune)
+	}
+}
+
+func TestPruneBlocks(t *testing.T) {
+	ctx := context.Background()
+	chainDb := rawdb.NewMemoryDatabase()
+	arbDb := rawdb.NewMemoryDatabase()
+	var hashes []common.Hash
+	for number := uint64(0); number <= 10; number++ {
+		hash := common.BigToHash(new(big.Int).SetUint64(number + 1))
+		hashes = append(hashes, hash)
+		rawdb.WriteCanonicalHash(chainDb, hash, number)
+		rawdb.WriteHeaderNumber(chainDb, hash, number)
+		rawdb.WriteBody(chainDb, hash, number, &types.Body{})
+		rawdb.WriteReceipts(chainDb, hash, number, types.Receipts{})
+	}
+	rawdb.WriteChainConfig(chainDb, hashes[0], params.ArbitrumDevTestChainConfig())
+	rawdb.WriteHeadBlockHash(chainDb, hashes[10])
+
+	config := DefaultMaintenanceConfig
+	config.Prune.Enable = true
+	config.Prune.RetainBlocks = 4
+	mr, err := NewMaintenanceRunner(func() *MaintenanceConfig { return &config }, nil, chainDb, arbDb, "")
+	Require(t, err)
+	checkPruned := func(prunedUpTo uint64) {
+		t.Helper()
+		for number, hash := range hashes {
+			pruned := number > 0 && uint64(number) < prunedUpTo
+			if rawdb.HasBody(chainDb, hash, uint64(number)) == pruned || rawdb.HasReceipts(chainDb, hash, uint64(number)) == pruned {
+				Fail(t, "block", number, "expected pruned", pruned)
+			}
+		}
+	}
+
+	// blocks that aren't validated yet are kept
+	mr.SetPruneFloor(func() (uint64, error) { return 3, nil })
+	pruned, err := mr.pruneBlocks(ctx)
+	Require(t, err)
+	if pruned != 2 {
+		Fail(t, "unexpected pruned blocks", pruned)
+	}
+	checkPruned(3)
+
+	mr.SetPruneFloor(nil)
+	pruned, err = mr.pruneBlocks(ctx)
+	Require(t, err)
+	if pruned != 3 {
+		Fail(t, "unexpected pruned blocks", pruned)
+	}
+	checkPruned(6)
+}
@@ -0,0 +1,151 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type messageSource uint8
+
+const (
+	messageSourceFeed messageSource = iota
+	messageSourceInbox
+	messageSourceLocal
+	messageSourceCount
+)
+
+func (s messageSource) String() string {
+	switch s {
+	case messageSourceFeed:
+		return "feed"
+	case messageSourceInbox:
+		return "inbox"
+	case messageSourceLocal:
+		return "local"
+	default:
+		return "unknown"
+	}
+}
+
+type messageLatencyMetrics struct {
+	// from receiving the message until it's stored in the database
+	write metrics.Histogram
+	// from storing the message until it's executed, including execution itself
+	execute metrics.Histogram
+	// from receiving the message until it's executed
+	total metrics.Histogram
+}
+
+var messageLatencyMetricsBySource [messageSourceCount]messageLatencyMetrics
+
+func init() {
+	for source := messageSource(0); source < messageSourceCount; source++ {
+		prefix := "arb/txstreamer/latency/" + source.String()
+		messageLatencyMetricsBySource[source] = messageLatencyMetrics{
+			write:   metrics.NewRegisteredHistogram(prefix+"/write", nil, metrics.NewBoundedHistogramSample()),
+			execute: metrics.NewRegisteredHistogram(prefix+"/execute", nil, metrics.NewBoundedHistogramSample()),
+			total:   metrics.NewRegisteredHistogram(prefix+"/total", nil, metrics.NewBoundedHistogramSample()),
+		}
+	}
+}
+
+// maxTrackedMessages bounds the memory used while syncing, when messages are stored far ahead of execution
+const maxTrackedMessages = 1 << 16
+
+// messagesBetweenSweeps is how often stale messages are dropped, as doing so iterates all tracked messages
+const messagesBetweenSweeps = 256
+
+type messageTiming struct {
+	source     messageSource
+	receivedAt time.Time
+	writtenAt  time.Time // zero until stored
+}
+
+// messageLatencyTracker follows messages through the transaction streamer by position,
+// reporting per source latency metrics as they're stored and executed.
+// A message keeps the source it was first received from, e.g. a feed message later confirmed by the inbox counts as feed.
+type messageLatencyTracker struct {
+	mutex         sync.Mutex
+	timings       map[arbutil.MessageIndex]*messageTiming
+	executedCount uint64
+}
+
+func newMessageLatencyTracker() *messageLatencyTracker {
+	return &messageLatencyTracker{
+		timings: make(map[arbutil.MessageIndex]*messageTiming),
+	}
+}
+
+func (t *messageLatencyTracker) received(pos arbutil.MessageIndex, count int, source messageSource, receivedAt time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := 0; i < count && len(t.timings) < maxTrackedMessages; i++ {
+		msgPos := pos + arbutil.MessageIndex(i)
+		if _, ok := t.timings[msgPos]; !ok {
+			t.timings[msgPos] = &messageTiming{
+				source:     source,
+				receivedAt: receivedAt,
+			}
+		}
+	}
+}
+
+func (t *messageLatencyTracker) written(pos arbutil.MessageIndex, count int, writtenAt time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := 0; i < count; i++ {
+		msgPos := pos + arbutil.MessageIndex(i)
+		timing, ok := t.timings[msgPos]
+		if !ok || !timing.writtenAt.IsZero() {
+			continue
+		}
+		timing.writtenAt = writtenAt
+		messageLatencyMetricsBySource[timing.source].write.Update(writtenAt.Sub(timing.receivedAt).Nanoseconds())
+		if timing.source == messageSourceLocal {
+			// the sequencer executes its messages before storing them
+			delete(t.timings, msgPos)
+		}
+	}
+}
+
+func (t *messageLatencyTracker) executed(pos arbutil.MessageIndex, executedAt time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	timing, ok := t.timings[pos]
+	if ok {
+		delete(t.timings, pos)
+		if !timing.writtenAt.IsZero() {
+			latency := messageLatencyMetricsBySource[timing.source]
+			latency.execute.Update(executedAt.Sub(timing.writtenAt).Nanoseconds())
+			latency.total.Update(executedAt.Sub(timing.receivedAt).Nanoseconds())
+		}
+	}
+	t.executedCount++
+	if t.executedCount%messagesBetweenSweeps != 0 {
+		return
+	}
+	// drop messages received again after being executed, e.g. confirmations of feed messages
+	for msgPos := range t.timings {
+		if msgPos < pos {
+			delete(t.timings, msgPos)
+		}
+	}
+}
+
+// reorg forgets messages at or after count, which are replaced
+func (t *messageLatencyTracker) reorg(count arbutil.MessageIndex) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for msgPos := range t.timings {
+		if msgPos >= count {
+			delete(t.timings, msgPos)
+		}
+	}
+}
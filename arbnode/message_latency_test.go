@@ -0,0 +1,55 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+func TestMessageLatencyTracker(t *testing.T) {
+	tracker := newMessageLatencyTracker()
+	start := time.Now()
+
+	tracker.received(10, 2, messageSourceFeed, start)
+	// the inbox confirms the first feed message and adds another one
+	tracker.received(10, 3, messageSourceInbox, start.Add(time.Second))
+	expectedSources := []messageSource{messageSourceFeed, messageSourceFeed, messageSourceInbox}
+	for i, expected := range expectedSources {
+		timing := tracker.timings[arbutil.MessageIndex(10+i)]
+		if timing == nil || timing.source != expected {
+			t.Fatal("unexpected timing for message", 10+i, timing)
+		}
+	}
+	if !tracker.timings[10].receivedAt.Equal(start) {
+		t.Error("confirmation changed the feed message's receipt time")
+	}
+
+	writtenAt := start.Add(2 * time.Second)
+	tracker.written(10, 3, writtenAt)
+	if !tracker.timings[12].writtenAt.Equal(writtenAt) {
+		t.Error("write not tracked")
+	}
+	for pos := arbutil.MessageIndex(10); pos < 13; pos++ {
+		tracker.executed(pos, start.Add(3*time.Second))
+	}
+	if len(tracker.timings) != 0 {
+		t.Error("executed messages still tracked", len(tracker.timings))
+	}
+
+	// sequenced messages are already executed when written
+	tracker.received(13, 1, messageSourceLocal, start)
+	tracker.written(13, 1, start.Add(time.Second))
+	if len(tracker.timings) != 0 {
+		t.Error("sequenced message still tracked after write")
+	}
+
+	tracker.received(14, 2, messageSourceFeed, start)
+	tracker.reorg(15)
+	if len(tracker.timings) != 1 || tracker.timings[14] == nil {
+		t.Error("unexpected tracked messages after reorg", len(tracker.timings))
+	}
+}
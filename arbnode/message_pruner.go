@@ -65,6 +65,15 @@ func (m *MessagePruner) Start(ctxIn context.Context) {
 	m.StopWaiter.Start(ctxIn, m)
 }
 
+// PauseForMaintenance waits for a running prune to finish, and skips further prunes until resumed
+func (m *MessagePruner) PauseForMaintenance() {
+	m.pruningLock.Lock()
+}
+
+func (m *MessagePruner) ResumeAfterMaintenance() {
+	m.pruningLock.Unlock()
+}
+
 func (m *MessagePruner) UpdateLatestConfirmed(count arbutil.MessageIndex, globalState validator.GoGlobalState) {
 	locked := m.pruningLock.TryLock()
 	if !locked {
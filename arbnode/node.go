@@ -235,7 +235,8 @@ func GenerateRollupConfig(prod bool, wasmModuleRoot common.Hash, rollupOwner com
 	}
 }
 
-func DeployOnL1(ctx context.Context, parentChainReader *headerreader.HeaderReader, deployAuth *bind.TransactOpts, batchPoster common.Address, authorizeValidators uint64, config rollupgen.Config) (*chaininfo.RollupAddresses, error) {
+// DeployOnL1 deploys a new rollup, and waits for the given number of confirmations of its creation block before returning.
+func DeployOnL1(ctx context.Context, parentChainReader *headerreader.HeaderReader, deployAuth *bind.TransactOpts, batchPoster common.Address, authorizeValidators uint64, config rollupgen.Config, confirmations uint64) (*chaininfo.RollupAddresses, error) {
 	if config.WasmModuleRoot == (common.Hash{}) {
 		return nil, errors.New("no machine specified")
 	}
@@ -263,6 +264,21 @@ func DeployOnL1(ctx context.Context, parentChainReader *headerreader.HeaderReade
 	if err != nil {
 		return nil, fmt.Errorf("error executing create rollup tx: %w", err)
 	}
+	for confirmations > 0 {
+		err = WaitForDeploymentConfirmations(ctx, parentChainReader.Client(), receipt.BlockNumber.Uint64(), confirmations, time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for create rollup tx confirmations: %w", err)
+		}
+		confirmedReceipt, err := parentChainReader.Client().TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("error getting create rollup tx receipt after waiting for confirmations: %w", err)
+		}
+		if confirmedReceipt.BlockHash == receipt.BlockHash {
+			break
+		}
+		log.Warn("create rollup tx was reorged into another block, waiting for its confirmations again", "oldBlock", receipt.BlockNumber, "newBlock", confirmedReceipt.BlockNumber)
+		receipt = confirmedReceipt
+	}
 	info, err := rollupCreator.ParseRollupCreated(*receipt.Logs[len(receipt.Logs)-1])
 	if err != nil {
 		return nil, fmt.Errorf("error parsing rollup created log: %w", err)
@@ -280,30 +296,35 @@ func DeployOnL1(ctx context.Context, parentChainReader *headerreader.HeaderReade
 }
 
 type Config struct {
-	RPC                 arbitrum.Config                  `koanf:"rpc"`
-	Sequencer           execution.SequencerConfig        `koanf:"sequencer" reload:"hot"`
-	ParentChainReader   headerreader.Config              `koanf:"parent-chain-reader" reload:"hot"`
-	InboxReader         InboxReaderConfig                `koanf:"inbox-reader" reload:"hot"`
-	DelayedSequencer    DelayedSequencerConfig           `koanf:"delayed-sequencer" reload:"hot"`
-	BatchPoster         BatchPosterConfig                `koanf:"batch-poster" reload:"hot"`
-	MessagePruner       MessagePrunerConfig              `koanf:"message-pruner" reload:"hot"`
-	ForwardingTarget    string                           `koanf:"forwarding-target"`
-	Forwarder           execution.ForwarderConfig        `koanf:"forwarder"`
-	TxPreChecker        execution.TxPreCheckerConfig     `koanf:"tx-pre-checker" reload:"hot"`
-	BlockValidator      staker.BlockValidatorConfig      `koanf:"block-validator" reload:"hot"`
-	RecordingDatabase   arbitrum.RecordingDatabaseConfig `koanf:"recording-database"`
-	Feed                broadcastclient.FeedConfig       `koanf:"feed" reload:"hot"`
-	Staker              staker.L1ValidatorConfig         `koanf:"staker" reload:"hot"`
-	SeqCoordinator      SeqCoordinatorConfig             `koanf:"seq-coordinator"`
-	DataAvailability    das.DataAvailabilityConfig       `koanf:"data-availability"`
-	SyncMonitor         SyncMonitorConfig                `koanf:"sync-monitor"`
-	Dangerous           DangerousConfig                  `koanf:"dangerous"`
-	Caching             execution.CachingConfig          `koanf:"caching"`
-	Archive             bool                             `koanf:"archive"`
-	TxLookupLimit       uint64                           `koanf:"tx-lookup-limit"`
-	TransactionStreamer TransactionStreamerConfig        `koanf:"transaction-streamer" reload:"hot"`
-	Maintenance         MaintenanceConfig                `koanf:"maintenance" reload:"hot"`
-	ResourceMgmt        resourcemanager.Config           `koanf:"resource-mgmt" reload:"hot"`
+	RPC                 arbitrum.Config                   `koanf:"rpc"`
+	Sequencer           execution.SequencerConfig         `koanf:"sequencer" reload:"hot"`
+	ParentChainReader   headerreader.Config               `koanf:"parent-chain-reader" reload:"hot"`
+	InboxReader         InboxReaderConfig                 `koanf:"inbox-reader" reload:"hot"`
+	DelayedSequencer    DelayedSequencerConfig            `koanf:"delayed-sequencer" reload:"hot"`
+	BatchPoster         BatchPosterConfig                 `koanf:"batch-poster" reload:"hot"`
+	MessagePruner       MessagePrunerConfig               `koanf:"message-pruner" reload:"hot"`
+	ForwardingTarget    string                            `koanf:"forwarding-target"`
+	Forwarder           execution.ForwarderConfig         `koanf:"forwarder"`
+	TxPreChecker        execution.TxPreCheckerConfig      `koanf:"tx-pre-checker" reload:"hot"`
+	BlockValidator      staker.BlockValidatorConfig       `koanf:"block-validator" reload:"hot"`
+	RecordingDatabase   arbitrum.RecordingDatabaseConfig  `koanf:"recording-database"`
+	Feed                broadcastclient.FeedConfig        `koanf:"feed" reload:"hot"`
+	Staker              staker.L1ValidatorConfig          `koanf:"staker" reload:"hot"`
+	SeqCoordinator      SeqCoordinatorConfig              `koanf:"seq-coordinator"`
+	DataAvailability    das.DataAvailabilityConfig        `koanf:"data-availability"`
+	SyncMonitor         SyncMonitorConfig                 `koanf:"sync-monitor"`
+	Dangerous           DangerousConfig                   `koanf:"dangerous"`
+	Caching             execution.CachingConfig           `koanf:"caching"`
+	ExecutionWorkers    execution.WorkersConfig           `koanf:"execution-workers"`
+	EventPublisher      execution.EventPublisherConfig    `koanf:"event-publisher"`
+	ChainOwnerWatcher   execution.ChainOwnerWatcherConfig `koanf:"chain-owner-watcher"`
+	ArbOSVersionCheck   execution.ArbOSVersionCheckConfig `koanf:"arbos-version-check"`
+	StatusPage          StatusPageConfig                  `koanf:"status-page"`
+	Archive             bool                              `koanf:"archive"`
+	TxLookupLimit       uint64                            `koanf:"tx-lookup-limit"`
+	TransactionStreamer TransactionStreamerConfig         `koanf:"transaction-streamer" reload:"hot"`
+	Maintenance         MaintenanceConfig                 `koanf:"maintenance" reload:"hot"`
+	ResourceMgmt        resourcemanager.Config            `koanf:"resource-mgmt" reload:"hot"`
 }
 
 func (c *Config) Validate() error {
@@ -322,6 +343,12 @@ func (c *Config) Validate() error {
 	if err := c.Maintenance.Validate(); err != nil {
 		return err
 	}
+	if c.Maintenance.Prune.Enable && (c.Caching.Archive || c.Archive) {
+		return errors.New("maintenance pruning cannot be enabled in archive mode")
+	}
+	if err := c.ParentChainReader.Validate(); err != nil {
+		return err
+	}
 	if err := c.InboxReader.Validate(); err != nil {
 		return err
 	}
@@ -334,6 +361,30 @@ func (c *Config) Validate() error {
 	if err := c.Staker.Validate(); err != nil {
 		return err
 	}
+	if err := c.ExecutionWorkers.Validate(); err != nil {
+		return err
+	}
+	if err := c.ArbOSVersionCheck.Validate(); err != nil {
+		return err
+	}
+	if err := c.SyncMonitor.Validate(); err != nil {
+		return err
+	}
+	if err := c.EventPublisher.Validate(); err != nil {
+		return err
+	}
+	if err := c.ChainOwnerWatcher.Validate(); err != nil {
+		return err
+	}
+	if err := c.StatusPage.Validate(); err != nil {
+		return err
+	}
+	if err := c.ResourceMgmt.Validate(); err != nil {
+		return err
+	}
+	if err := c.TransactionStreamer.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -375,6 +426,11 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet, feedInputEnable bool, feed
 	SyncMonitorConfigAddOptions(prefix+".sync-monitor", f)
 	DangerousConfigAddOptions(prefix+".dangerous", f)
 	execution.CachingConfigAddOptions(prefix+".caching", f)
+	execution.WorkersConfigAddOptions(prefix+".execution-workers", f)
+	execution.EventPublisherConfigAddOptions(prefix+".event-publisher", f)
+	execution.ChainOwnerWatcherConfigAddOptions(prefix+".chain-owner-watcher", f)
+	execution.ArbOSVersionCheckConfigAddOptions(prefix+".arbos-version-check", f)
+	StatusPageConfigAddOptions(prefix+".status-page", f)
 	f.Uint64(prefix+".tx-lookup-limit", ConfigDefault.TxLookupLimit, "retain the ability to lookup transactions by hash for the past N blocks (0 = all blocks)")
 	TransactionStreamerConfigAddOptions(prefix+".transaction-streamer", f)
 	MaintenanceConfigAddOptions(prefix+".maintenance", f)
@@ -405,6 +461,11 @@ var ConfigDefault = Config{
 	Archive:             false,
 	TxLookupLimit:       126_230_400, // 1 year at 4 blocks per second
 	Caching:             execution.DefaultCachingConfig,
+	ExecutionWorkers:    execution.DefaultWorkersConfig,
+	EventPublisher:      execution.DefaultEventPublisherConfig,
+	ChainOwnerWatcher:   execution.DefaultChainOwnerWatcherConfig,
+	ArbOSVersionCheck:   execution.DefaultArbOSVersionCheckConfig,
+	StatusPage:          DefaultStatusPageConfig,
 	TransactionStreamer: DefaultTransactionStreamerConfig,
 	ResourceMgmt:        resourcemanager.DefaultConfig,
 }
@@ -488,6 +549,8 @@ type Node struct {
 	DASLifecycleManager     *das.LifecycleManager
 	ClassicOutboxRetriever  *ClassicOutboxRetriever
 	SyncMonitor             *SyncMonitor
+	SyncGuard               *SyncGuardAPI
+	StatusPage              *StatusPage
 	configFetcher           ConfigFetcher
 	ctx                     context.Context
 }
@@ -591,7 +654,7 @@ func createNodeImpl(
 	l2Config := l2BlockChain.Config()
 	l2ChainId := l2Config.ChainID.Uint64()
 
-	syncMonitor := NewSyncMonitor(&config.SyncMonitor)
+	syncMonitor := NewSyncMonitor(&config.SyncMonitor, fatalErrChan)
 	var classicOutbox *ClassicOutboxRetriever
 	classicMsgDb, err := stack.OpenDatabase("classic-msg", 0, 0, "", true)
 	if err != nil {
@@ -614,9 +677,10 @@ func createNodeImpl(
 
 	sequencerConfigFetcher := func() *execution.SequencerConfig { return &configFetcher.Get().Sequencer }
 	txprecheckConfigFetcher := func() *execution.TxPreCheckerConfig { return &configFetcher.Get().TxPreChecker }
+	arbosVersionCheckFetcher := func() *execution.ArbOSVersionCheckConfig { return &configFetcher.Get().ArbOSVersionCheck }
 	exec, err := execution.CreateExecutionNode(stack, chainDb, l2BlockChain, l1Reader, syncMonitor,
 		config.ForwardingTargetF(), &config.Forwarder, config.RPC, &config.RecordingDatabase,
-		sequencerConfigFetcher, txprecheckConfigFetcher)
+		sequencerConfigFetcher, txprecheckConfigFetcher, arbosVersionCheckFetcher, &config.ExecutionWorkers, &config.EventPublisher, &config.ChainOwnerWatcher)
 	if err != nil {
 		return nil, err
 	}
@@ -658,8 +722,7 @@ func createNodeImpl(
 	} else if config.Sequencer.Enable && !config.Sequencer.Dangerous.NoCoordinator {
 		return nil, errors.New("sequencer must be enabled with coordinator, unless dangerous.no-coordinator set")
 	}
-	dbs := []ethdb.Database{chainDb, arbDb}
-	maintenanceRunner, err := NewMaintenanceRunner(func() *MaintenanceConfig { return &configFetcher.Get().Maintenance }, coordinator, dbs)
+	maintenanceRunner, err := NewMaintenanceRunner(func() *MaintenanceConfig { return &configFetcher.Get().Maintenance }, coordinator, chainDb, arbDb, stack.InstanceDir())
 	if err != nil {
 		return nil, err
 	}
@@ -798,6 +861,11 @@ func createNodeImpl(
 		if err != nil {
 			return nil, err
 		}
+		if exec.Sequencer != nil {
+			blockValidator.SetBlockProductionThrottler(exec.Sequencer)
+		} else if config.BlockValidator.ValidationLagPolicy == staker.ValidationLagPolicyThrottle {
+			log.Warn("block-validator.validation-lag-policy throttle has no effect without a sequencer, validation lag will only be logged")
+		}
 	}
 
 	var stakerObj *staker.Staker
@@ -814,6 +882,11 @@ func createNodeImpl(
 		if err != nil {
 			return nil, err
 		}
+		if dp != nil {
+			if err := dp.CheckParentChainTxType(ctx); err != nil {
+				return nil, fmt.Errorf("staker data poster: %w", err)
+			}
+		}
 		getExtraGas := func() uint64 { return configFetcher.Get().Staker.ExtraGas }
 		var wallet staker.ValidatorWalletInterface
 		if config.Staker.UseSmartContractWallet || txOptsValidator == nil {
@@ -877,6 +950,33 @@ func createNodeImpl(
 		if err != nil {
 			return nil, err
 		}
+		if err := batchPoster.dataPoster.CheckParentChainTxType(ctx); err != nil {
+			return nil, fmt.Errorf("batch poster data poster: %w", err)
+		}
+		if config.BatchPoster.CheckFeedConsistency {
+			if broadcastServer != nil {
+				batchPoster.SetFeedConsistencyCheck(broadcastServer, config.BatchPoster.FeedConsistencyRecordSize, fatalErrChan)
+			} else {
+				log.Warn("batch poster feed consistency check enabled without the feed output, not checking batches")
+			}
+		}
+	}
+	if blockValidator != nil {
+		// the bodies of blocks that aren't validated yet are needed to validate them
+		maintenanceRunner.SetPruneFloor(func() (uint64, error) {
+			validated, err := blockValidator.ReadLastValidatedInfo()
+			if err != nil || validated == nil {
+				return 0, err
+			}
+			caughtUp, count, err := staker.GlobalStateToMsgCount(inboxTracker, txStreamer, validated.GlobalState)
+			if err != nil || !caughtUp {
+				return 0, err
+			}
+			return exec.ExecEngine.MessageIndexToBlockNumber(count), nil
+		})
+	}
+	if messagePruner != nil {
+		maintenanceRunner.AddPausable(messagePruner)
 	}
 	// always create DelayedSequencer, it won't do anything if it is disabled
 	delayedSequencer, err = NewDelayedSequencer(l1Reader, inboxReader, exec.ExecEngine, coordinator, func() *DelayedSequencerConfig { return &configFetcher.Get().DelayedSequencer })
@@ -934,6 +1034,9 @@ func CreateNode(
 	if err != nil {
 		return nil, err
 	}
+	if statusPageConfig := &configFetcher.Get().StatusPage; statusPageConfig.Enable {
+		currentNode.StatusPage = NewStatusPage(statusPageConfig, currentNode)
+	}
 	var apis []rpc.API
 	if currentNode.BlockValidator != nil {
 		apis = append(apis, rpc.API{
@@ -943,6 +1046,67 @@ func CreateNode(
 			Public:    false,
 		})
 	}
+	if currentNode.BatchPoster != nil || currentNode.BlockValidator != nil {
+		apis = append(apis, rpc.API{
+			Namespace:     "arbdebug",
+			Version:       "1.0",
+			Service:       NewStalenessAPI(currentNode.BatchPoster, currentNode.BlockValidator, configFetcher),
+			Public:        false,
+			Authenticated: true,
+		})
+	}
+	if currentNode.L1Reader != nil {
+		var sequencerInbox common.Address
+		if currentNode.DeployInfo != nil {
+			sequencerInbox = currentNode.DeployInfo.SequencerInbox
+		}
+		apis = append(apis, rpc.API{
+			Namespace:     "arbdebug",
+			Version:       "1.0",
+			Service:       NewParentChainTestAPI(currentNode.L1Reader, sequencerInbox),
+			Public:        false,
+			Authenticated: true,
+		})
+	}
+	if currentNode.InboxReader != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "arb",
+			Version:   "1.0",
+			Service:   &BatchInfoAPI{inboxReader: currentNode.InboxReader},
+			Public:    false,
+		})
+		apis = append(apis, rpc.API{
+			Namespace:     "arbdebug",
+			Version:       "1.0",
+			Service:       &BatchReplayAPI{inboxReader: currentNode.InboxReader},
+			Public:        false,
+			Authenticated: true,
+		})
+		apis = append(apis, rpc.API{
+			Namespace:     "arbdebug",
+			Version:       "1.0",
+			Service:       &DelayedMessageAPI{inboxReader: currentNode.InboxReader},
+			Public:        false,
+			Authenticated: true,
+		})
+	}
+	if currentNode.BroadcastServer != nil {
+		apis = append(apis, rpc.API{
+			Namespace:     "arbdebug",
+			Version:       "1.0",
+			Service:       NewFeedOutputAPI(currentNode.BroadcastServer, func() *wsbroadcastserver.BroadcasterConfig { return &configFetcher.Get().Feed.Output }),
+			Public:        false,
+			Authenticated: true,
+		})
+	}
+	if currentNode.BroadcastClients != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "arb",
+			Version:   "1.0",
+			Service:   &FeedInputAPI{clients: currentNode.BroadcastClients, streamer: currentNode.TxStreamer},
+			Public:    false,
+		})
+	}
 	if currentNode.StatelessBlockValidator != nil {
 		apis = append(apis, rpc.API{
 			Namespace: "arbvalidator",
@@ -986,15 +1150,58 @@ func CreateNode(
 		Service:   eth.NewDebugAPI(eth.NewArbEthereum(l2BlockChain, chainDb)),
 		Public:    false,
 	})
+	apis = append(apis, rpc.API{
+		Namespace:     "arbdebug",
+		Version:       "1.0",
+		Service:       resourcemanager.NewAPI(),
+		Public:        false,
+		Authenticated: true,
+	})
+	apis = append(apis, rpc.API{
+		Namespace:     "arbdebug",
+		Version:       "1.0",
+		Service:       &SubsystemsAPI{node: currentNode},
+		Public:        false,
+		Authenticated: true,
+	})
+	if config.SyncMonitor.RPCWhileSyncing != RPCWhileSyncingServe {
+		// registered after the execution backend's apis, so these methods override the eth ones
+		syncGuard, err := NewSyncGuardAPI(currentNode.SyncMonitor, config.SyncMonitor.RPCWhileSyncing, currentNode.Execution.Backend.APIs())
+		if err != nil {
+			return nil, err
+		}
+		currentNode.SyncGuard = syncGuard
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   syncGuard,
+			Public:    true,
+		})
+	}
 	stack.RegisterAPIs(apis)
 
 	return currentNode, nil
 }
 
+// EthAPIs returns the apis serving the eth namespace, in registration order.
+func (n *Node) EthAPIs() []rpc.API {
+	apis := n.Execution.Backend.APIs()
+	if n.SyncGuard != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   n.SyncGuard,
+			Public:    true,
+		})
+	}
+	return apis
+}
+
 func (n *Node) Start(ctx context.Context) error {
 	// config is the static config at start, not a dynamic config
 	config := n.configFetcher.Get()
 	n.SyncMonitor.Initialize(n.InboxReader, n.TxStreamer, n.SeqCoordinator)
+	n.SyncMonitor.Start(ctx)
 	n.Execution.ArbInterface.Initialize(n)
 	err := n.Stack.Start()
 	if err != nil {
@@ -1033,6 +1240,12 @@ func (n *Node) Start(ctx context.Context) error {
 		return fmt.Errorf("error starting transaction streamer: %w", err)
 	}
 	n.Execution.ExecEngine.Start(ctx)
+	if n.Execution.EventPublisher != nil {
+		n.Execution.EventPublisher.Start(ctx)
+	}
+	if n.Execution.OwnerWatcher != nil {
+		n.Execution.OwnerWatcher.Start(ctx)
+	}
 	if n.InboxReader != nil {
 		err = n.InboxReader.Start(ctx)
 		if err != nil {
@@ -1115,6 +1328,12 @@ func (n *Node) Start(ctx context.Context) error {
 			n.BroadcastClients.Start(ctx)
 		}()
 	}
+	if n.StatusPage != nil {
+		err = n.StatusPage.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("error starting status page: %w", err)
+		}
+	}
 	if n.configFetcher != nil {
 		n.configFetcher.Start(ctx)
 	}
@@ -1122,6 +1341,12 @@ func (n *Node) Start(ctx context.Context) error {
 }
 
 func (n *Node) StopAndWait() {
+	if n.StatusPage != nil && n.StatusPage.Started() {
+		n.StatusPage.StopAndWait()
+	}
+	if n.SyncMonitor.Started() {
+		n.SyncMonitor.StopAndWait()
+	}
 	if n.MaintenanceRunner != nil && n.MaintenanceRunner.Started() {
 		n.MaintenanceRunner.StopAndWait()
 	}
@@ -1171,6 +1396,12 @@ func (n *Node) StopAndWait() {
 	if n.TxStreamer.Started() {
 		n.TxStreamer.StopAndWait()
 	}
+	if n.Execution.EventPublisher != nil && n.Execution.EventPublisher.Started() {
+		n.Execution.EventPublisher.StopAndWait()
+	}
+	if n.Execution.OwnerWatcher != nil && n.Execution.OwnerWatcher.Started() {
+		n.Execution.OwnerWatcher.StopAndWait()
+	}
 	if n.Execution.ExecEngine.Started() {
 		n.Execution.ExecEngine.StopAndWait()
 	}
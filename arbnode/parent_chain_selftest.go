@@ -0,0 +1,125 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/nitro/util/headerreader"
+)
+
+// number of parent chain blocks covered by the sample log query
+const parentChainTestLogBlocks = 10
+
+type ParentChainCheckResult struct {
+	Name      string `json:"name"`
+	Success   bool   `json:"success"`
+	LatencyMs int64  `json:"latencyMs"`
+	Result    string `json:"result,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ParentChainTestReport struct {
+	Success bool                     `json:"success"`
+	Checks  []ParentChainCheckResult `json:"checks"`
+}
+
+type ParentChainTestAPI struct {
+	l1Reader       *headerreader.HeaderReader
+	sequencerInbox common.Address
+}
+
+func NewParentChainTestAPI(l1Reader *headerreader.HeaderReader, sequencerInbox common.Address) *ParentChainTestAPI {
+	return &ParentChainTestAPI{
+		l1Reader:       l1Reader,
+		sequencerInbox: sequencerInbox,
+	}
+}
+
+func (r *ParentChainTestReport) run(name string, check func() (string, error)) {
+	start := time.Now()
+	result, err := check()
+	checkResult := ParentChainCheckResult{
+		Name:      name,
+		Success:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Result:    result,
+	}
+	if err != nil {
+		checkResult.Error = err.Error()
+		r.Success = false
+	}
+	r.Checks = append(r.Checks, checkResult)
+}
+
+// TestParentChain exercises the parent chain connection directly, bypassing any cached headers
+func (a *ParentChainTestAPI) TestParentChain(ctx context.Context) (*ParentChainTestReport, error) {
+	client := a.l1Reader.Client()
+	report := &ParentChainTestReport{Success: true}
+
+	report.run("chainId", func() (string, error) {
+		chainIdReader, ok := client.(interface {
+			ChainID(ctx context.Context) (*big.Int, error)
+		})
+		if !ok {
+			return "", fmt.Errorf("parent chain client doesn't support reading the chain id")
+		}
+		chainId, err := chainIdReader.ChainID(ctx)
+		if err != nil {
+			return "", err
+		}
+		return chainId.String(), nil
+	})
+	var latestNumber *big.Int
+	report.run("latestHeader", func() (string, error) {
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		latestNumber = header.Number
+		return fmt.Sprintf("number %v hash %v age %v", header.Number, header.Hash(), time.Since(time.Unix(int64(header.Time), 0)).Truncate(time.Second)), nil
+	})
+	if a.l1Reader.UseFinalityData() {
+		for _, tag := range []struct {
+			name   string
+			number rpc.BlockNumber
+		}{
+			{"safeHeader", rpc.SafeBlockNumber},
+			{"finalizedHeader", rpc.FinalizedBlockNumber},
+		} {
+			tag := tag
+			report.run(tag.name, func() (string, error) {
+				header, err := client.HeaderByNumber(ctx, big.NewInt(tag.number.Int64()))
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("number %v hash %v", header.Number, header.Hash()), nil
+			})
+		}
+	}
+	if latestNumber != nil && a.sequencerInbox != (common.Address{}) {
+		report.run("logQuery", func() (string, error) {
+			from := new(big.Int).Sub(latestNumber, big.NewInt(parentChainTestLogBlocks))
+			if from.Sign() < 0 {
+				from.SetInt64(0)
+			}
+			logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: from,
+				ToBlock:   latestNumber,
+				Addresses: []common.Address{a.sequencerInbox},
+			})
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%v sequencer inbox logs in blocks %v-%v", len(logs), from, latestNumber), nil
+		})
+	}
+	return report, nil
+}
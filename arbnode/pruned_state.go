@@ -0,0 +1,101 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PrunedStateError replaces the missing state errors of historical requests,
+// telling the client which recent blocks this node still has the state of
+type PrunedStateError struct {
+	Block           uint64 `json:"block"`
+	OldestAvailable uint64 `json:"oldestAvailable"`
+	Latest          uint64 `json:"latest"`
+}
+
+func (e *PrunedStateError) Error() string {
+	return fmt.Sprintf("state pruned at block %v, oldest available %v (latest %v)", e.Block, e.OldestAvailable, e.Latest)
+}
+
+// ErrorCode is "resource not found", which isn't redacted by rpc.error-detail
+func (e *PrunedStateError) ErrorCode() int { return -32001 }
+
+func (e *PrunedStateError) ErrorData() interface{} { return e }
+
+// StateRangeFinder finds the range of recent blocks whose state is stored, for PrunedStateError
+type StateRangeFinder struct {
+	bc *core.BlockChain
+
+	mutex  sync.Mutex
+	head   uint64
+	oldest uint64
+}
+
+func NewStateRangeFinder(bc *core.BlockChain) *StateRangeFinder {
+	return &StateRangeFinder{bc: bc}
+}
+
+func (f *StateRangeFinder) hasState(number uint64) bool {
+	header := f.bc.GetHeaderByNumber(number)
+	return header != nil && f.bc.HasState(header.Root)
+}
+
+// the most blocks AvailableRange looks back from the head, well over the recent states a non-archive node keeps
+const maxStateRangeScan = 4096
+
+// AvailableRange returns the oldest block of the recent blocks with state, and the latest block.
+// Older blocks may still have state that was written to disk at an interval, those are left out.
+// As those are interspersed with pruned blocks, the range is found by walking back from the head.
+func (f *StateRangeFinder) AvailableRange() (uint64, uint64) {
+	head := f.bc.CurrentBlock().Number.Uint64()
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if head == f.head && f.head != 0 {
+		return f.oldest, f.head
+	}
+	genesis := f.bc.Config().ArbitrumChainParams.GenesisBlockNum
+	oldest := head
+	for oldest > genesis && head-oldest < maxStateRangeScan && f.hasState(oldest-1) {
+		oldest--
+	}
+	f.head = head
+	f.oldest = oldest
+	return f.oldest, f.head
+}
+
+// prunedStateError returns the error for a missing state error of a request for a historical block,
+// or nil if the block isn't known or is in the available range, so the original error is kept
+func (f *StateRangeFinder) prunedStateError(blockNrOrHash json.RawMessage) error {
+	var parsed rpc.BlockNumberOrHash
+	if err := json.Unmarshal(blockNrOrHash, &parsed); err != nil {
+		return nil
+	}
+	var block uint64
+	if hash, ok := parsed.Hash(); ok {
+		header := f.bc.GetHeaderByHash(hash)
+		if header == nil {
+			return nil
+		}
+		block = header.Number.Uint64()
+	} else if number, ok := parsed.Number(); ok && number >= 0 {
+		block = uint64(number)
+	} else {
+		return nil
+	}
+	oldest, latest := f.AvailableRange()
+	if block >= oldest {
+		return nil
+	}
+	return &PrunedStateError{
+		Block:           block,
+		OldestAvailable: oldest,
+		Latest:          latest,
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	receiptCacheHitCounter         = metrics.NewRegisteredCounter("arb/rpc/receiptcache/hit", nil)
+	receiptCacheMissCounter        = metrics.NewRegisteredCounter("arb/rpc/receiptcache/miss", nil)
+	receiptCacheInvalidatedCounter = metrics.NewRegisteredCounter("arb/rpc/receiptcache/invalidated", nil)
+)
+
+// CanonicalHashReader is the part of the blockchain the receipt cache needs to detect reorgs
+type CanonicalHashReader interface {
+	GetCanonicalHash(number uint64) common.Hash
+}
+
+type cachedReceipt struct {
+	receipt     json.RawMessage
+	blockNumber uint64
+	blockHash   common.Hash
+	cachedAt    time.Time
+}
+
+// ReceiptCacheAPI overrides eth_getTransactionReceipt of the eth namespace, serving receipts of
+// recently requested transactions from a bounded cache for up to ttl. A cached receipt is dropped
+// as soon as its block is no longer canonical, so reorged out receipts are never served.
+type ReceiptCacheAPI struct {
+	inner *rpc.Client
+	chain CanonicalHashReader
+	ttl   time.Duration
+
+	mutex sync.Mutex
+	cache *containers.LruCache[common.Hash, cachedReceipt]
+}
+
+// NewReceiptCacheAPI serves cache misses from the eth namespace apis of ethAPIs,
+// which should be the apis already registered for the eth namespace.
+func NewReceiptCacheAPI(size int, ttl time.Duration, chain CanonicalHashReader, ethAPIs []rpc.API) (*ReceiptCacheAPI, error) {
+	server := rpc.NewServer()
+	for _, api := range ethAPIs {
+		if api.Namespace != "eth" {
+			continue
+		}
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	return &ReceiptCacheAPI{
+		inner: rpc.DialInProc(server),
+		chain: chain,
+		ttl:   ttl,
+		cache: containers.NewLruCache[common.Hash, cachedReceipt](size),
+	}, nil
+}
+
+func (a *ReceiptCacheAPI) Close() {
+	a.inner.Close()
+}
+
+func (a *ReceiptCacheAPI) get(hash common.Hash) (json.RawMessage, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	entry, ok := a.cache.Get(hash)
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.cachedAt) > a.ttl {
+		a.cache.Remove(hash)
+		return nil, false
+	}
+	if a.chain.GetCanonicalHash(entry.blockNumber) != entry.blockHash {
+		receiptCacheInvalidatedCounter.Inc(1)
+		a.cache.Remove(hash)
+		return nil, false
+	}
+	return entry.receipt, true
+}
+
+func (a *ReceiptCacheAPI) add(hash common.Hash, receipt json.RawMessage) {
+	var block struct {
+		BlockHash   *common.Hash    `json:"blockHash"`
+		BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	}
+	// receipts of transactions that aren't included yet are null, and aren't cached
+	if err := json.Unmarshal(receipt, &block); err != nil || block.BlockHash == nil || block.BlockNumber == nil {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.cache.Add(hash, cachedReceipt{
+		receipt:     receipt,
+		blockNumber: uint64(*block.BlockNumber),
+		blockHash:   *block.BlockHash,
+		cachedAt:    time.Now(),
+	})
+}
+
+func (a *ReceiptCacheAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (json.RawMessage, error) {
+	if receipt, ok := a.get(hash); ok {
+		receiptCacheHitCounter.Inc(1)
+		return receipt, nil
+	}
+	receiptCacheMissCounter.Inc(1)
+	var receipt json.RawMessage
+	if err := a.inner.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	a.add(hash, receipt)
+	return receipt, nil
+}
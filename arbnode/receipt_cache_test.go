@@ -0,0 +1,75 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testReceiptService struct {
+	calls     int
+	blockHash common.Hash
+}
+
+func (s *testReceiptService) GetTransactionReceipt(hash common.Hash) map[string]interface{} {
+	s.calls++
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return map[string]interface{}{
+		"transactionHash": hash,
+		"blockHash":       s.blockHash,
+		"blockNumber":     hexutil.Uint64(5),
+	}
+}
+
+type testCanonicalChain map[uint64]common.Hash
+
+func (c testCanonicalChain) GetCanonicalHash(number uint64) common.Hash {
+	return c[number]
+}
+
+func TestReceiptCache(t *testing.T) {
+	ctx := context.Background()
+	service := &testReceiptService{blockHash: common.Hash{1}}
+	chain := testCanonicalChain{5: service.blockHash}
+	cache, err := NewReceiptCacheAPI(10, time.Hour, chain, []rpc.API{{Namespace: "eth", Service: service}})
+	Require(t, err)
+	defer cache.Close()
+
+	tx := common.Hash{0xaa}
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetTransactionReceipt(ctx, tx)
+		Require(t, err)
+	}
+	if service.calls != 1 {
+		Fail(t, "receipt not served from cache, lookups:", service.calls)
+	}
+
+	// receipts of pending transactions aren't cached
+	for i := 0; i < 2; i++ {
+		receipt, err := cache.GetTransactionReceipt(ctx, common.Hash{})
+		Require(t, err)
+		if string(receipt) != "null" {
+			Fail(t, "unexpected receipt for pending transaction", string(receipt))
+		}
+	}
+	if service.calls != 3 {
+		Fail(t, "null receipt was cached, lookups:", service.calls)
+	}
+
+	// a reorg invalidates the cached receipt
+	chain[5] = common.Hash{2}
+	_, err = cache.GetTransactionReceipt(ctx, tx)
+	Require(t, err)
+	if service.calls != 4 {
+		Fail(t, "reorged receipt served from cache, lookups:", service.calls)
+	}
+}
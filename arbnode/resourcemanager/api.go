@@ -0,0 +1,74 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package resourcemanager
+
+import (
+	"context"
+	"time"
+)
+
+type LimitsResult struct {
+	Enabled           bool       `json:"enabled"`
+	Checker           string     `json:"checker,omitempty"`
+	MemLimitPercent   int        `json:"memLimitPercent"`
+	SustainedDuration string     `json:"sustainedDuration"`
+	Action            string     `json:"action"`
+	ShedPercent       int        `json:"shedPercent"`
+	MemoryUsedBytes   int        `json:"memoryUsedBytes,omitempty"`
+	MemoryLimitBytes  int        `json:"memoryLimitBytes,omitempty"`
+	MemoryUsedPercent float64    `json:"memoryUsedPercent,omitempty"`
+	UsageError        string     `json:"usageError,omitempty"`
+	ExceededSince     *time.Time `json:"exceededSince,omitempty"`
+	LastCheck         *time.Time `json:"lastCheck,omitempty"`
+	LastCheckError    string     `json:"lastCheckError,omitempty"`
+}
+
+// API reports the limits enforced by the resource manager installed by Init and the current usage.
+type API struct{}
+
+func NewAPI() *API {
+	return &API{}
+}
+
+func (a *API) ResourceLimits(ctx context.Context) (*LimitsResult, error) {
+	l := activeLimiter.Load()
+	if l == nil {
+		return &LimitsResult{Enabled: false}, nil
+	}
+	conf := l.config()
+	result := &LimitsResult{
+		Enabled:           true,
+		Checker:           l.c.String(),
+		MemLimitPercent:   l.memLimitPercent,
+		SustainedDuration: conf.SustainedDuration.String(),
+		Action:            conf.Action,
+		ShedPercent:       conf.ShedPercent,
+	}
+	if reporter, ok := l.c.(memoryUsageReporter); ok {
+		used, limit, err := reporter.memoryUsage()
+		if err != nil {
+			result.UsageError = err.Error()
+		} else {
+			result.MemoryUsedBytes = used
+			result.MemoryLimitBytes = limit
+			if limit > 0 {
+				result.MemoryUsedPercent = float64(used) * 100 / float64(limit)
+			}
+		}
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.exceededSince.IsZero() {
+		exceededSince := l.exceededSince
+		result.ExceededSince = &exceededSince
+	}
+	if !l.lastCheck.IsZero() {
+		lastCheck := l.lastCheck
+		result.LastCheck = &lastCheck
+	}
+	if l.lastErr != nil {
+		result.LastCheckError = l.lastErr.Error()
+	}
+	return result, nil
+}
@@ -7,10 +7,13 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -23,6 +26,16 @@ var (
 	limitCheckDurationHistogram = metrics.NewRegisteredHistogram("arb/rpc/limitcheck/duration", nil, metrics.NewBoundedHistogramSample())
 	limitCheckSuccessCounter    = metrics.NewRegisteredCounter("arb/rpc/limitcheck/success", nil)
 	limitCheckFailureCounter    = metrics.NewRegisteredCounter("arb/rpc/limitcheck/failure", nil)
+	limitCheckRejectedCounter   = metrics.NewRegisteredCounter("arb/rpc/limitcheck/action/rejected", nil)
+	limitCheckShedCounter       = metrics.NewRegisteredCounter("arb/rpc/limitcheck/action/shed", nil)
+	limitCheckFatalCounter      = metrics.NewRegisteredCounter("arb/rpc/limitcheck/action/fatal", nil)
+	limitCheckExceededGauge     = metrics.NewRegisteredGauge("arb/rpc/limitcheck/exceeded_seconds", nil)
+)
+
+const (
+	ActionReject = "reject"
+	ActionShed   = "shed"
+	ActionFatal  = "fatal"
 )
 
 // Init adds the resource manager's httpServer to a custom hook in geth.
@@ -30,52 +43,147 @@ var (
 // prior to RPC request handling.
 //
 // Must be run before the go-ethereum stack is set up (ethereum/go-ethereum/node.New).
-func Init(conf *Config) {
-	if conf.MemLimitPercent > 0 {
+// If the configured action is "fatal", sustained exhaustion is reported on fatalErrChan.
+// The sustained duration, action and shed percent are read from config on every check,
+// so they can be changed on config reload.
+func Init(config ConfigFetcher, fatalErrChan chan<- error) {
+	if conf := config(); conf.MemLimitPercent > 0 {
+		l := newLimiter(newLimitChecker(conf), config, fatalErrChan)
+		activeLimiter.Store(l)
+		wrapHandler := node.WrapHTTPHandler
 		node.WrapHTTPHandler = func(srv http.Handler) (http.Handler, error) {
-			return newHttpServer(srv, newLimitChecker(conf)), nil
+			var err error
+			if wrapHandler != nil {
+				srv, err = wrapHandler(srv)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &httpServer{inner: srv, limiter: l}, nil
 		}
 	}
 }
 
+// activeLimiter is the limiter installed by Init, shared by all http servers
+var activeLimiter atomic.Pointer[limiter]
+
 // Config contains the configuration for resourcemanager functionality.
 // Currently only a memory limit is supported, other limits may be added
 // in the future.
 type Config struct {
-	MemLimitPercent int `koanf:"mem-limit-percent" reload:"hot"`
+	MemLimitPercent   int           `koanf:"mem-limit-percent" reload:"hot"`
+	SustainedDuration time.Duration `koanf:"sustained-duration" reload:"hot"`
+	Action            string        `koanf:"action" reload:"hot"`
+	ShedPercent       int           `koanf:"shed-percent" reload:"hot"`
 }
 
+type ConfigFetcher func() *Config
+
 // DefaultConfig has the defaul resourcemanager configuration,
 // all limits are disabled.
 var DefaultConfig = Config{
-	MemLimitPercent: 0,
+	MemLimitPercent:   0,
+	SustainedDuration: 0,
+	Action:            ActionReject,
+	ShedPercent:       50,
 }
 
 // ConfigAddOptions adds the configuration options for resourcemanager.
 func ConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Int(prefix+".mem-limit-percent", DefaultConfig.MemLimitPercent, "RPC calls are throttled if system memory utilization exceeds this percent value, zero (default) is disabled")
+	f.Duration(prefix+".sustained-duration", DefaultConfig.SustainedDuration, "how long a limit must be continuously exceeded before the action is taken (0 = immediately)")
+	f.String(prefix+".action", DefaultConfig.Action, "action taken while a limit is exceeded: \"reject\" all RPC calls (including transaction submission), \"shed\" a percentage of them, or \"fatal\" to shut down the node")
+	f.Int(prefix+".shed-percent", DefaultConfig.ShedPercent, "percentage of RPC calls rejected when the action is \"shed\"")
+}
+
+func (c *Config) Validate() error {
+	switch c.Action {
+	case ActionReject, ActionShed, ActionFatal:
+	default:
+		return fmt.Errorf("invalid resource-mgmt action %q, must be one of %q, %q or %q", c.Action, ActionReject, ActionShed, ActionFatal)
+	}
+	if c.MemLimitPercent < 0 || c.MemLimitPercent > 100 {
+		return fmt.Errorf("invalid resource-mgmt mem-limit-percent %d, must be between 0 and 100", c.MemLimitPercent)
+	}
+	if c.ShedPercent < 0 || c.ShedPercent > 100 {
+		return fmt.Errorf("invalid resource-mgmt shed-percent %d, must be between 0 and 100", c.ShedPercent)
+	}
+	if c.SustainedDuration < 0 {
+		return errors.New("resource-mgmt sustained-duration must not be negative")
+	}
+	return nil
+}
+
+// limiter tracks how long the limits of its limitChecker have been exceeded
+// and decides which action to take.
+type limiter struct {
+	c            limitChecker
+	config       ConfigFetcher
+	fatalErrChan chan<- error
+	// the limit c was created with, config reloads don't change it
+	memLimitPercent int
+
+	mutex         sync.Mutex
+	exceededSince time.Time
+	fatalSent     bool
+	lastCheck     time.Time
+	lastErr       error
+}
+
+func newLimiter(c limitChecker, config ConfigFetcher, fatalErrChan chan<- error) *limiter {
+	return &limiter{c: c, config: config, fatalErrChan: fatalErrChan, memLimitPercent: config().MemLimitPercent}
 }
 
 // httpServer implements http.Handler and wraps calls to inner with a resource
 // limit check.
 type httpServer struct {
 	inner http.Handler
-	c     limitChecker
+	*limiter
+}
+
+func newHttpServer(inner http.Handler, c limitChecker, config ConfigFetcher, fatalErrChan chan<- error) *httpServer {
+	return &httpServer{inner: inner, limiter: newLimiter(c, config, fatalErrChan)}
 }
 
-func newHttpServer(inner http.Handler, c limitChecker) *httpServer {
-	return &httpServer{inner: inner, c: c}
+// sustainedExceeded records whether a limit is currently exceeded, and returns
+// true once it has been exceeded for at least the configured duration.
+func (s *limiter) sustainedExceeded(exceeded bool, now time.Time) bool {
+	conf := s.config()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastCheck = now
+	s.lastErr = nil
+	if !exceeded {
+		if !s.exceededSince.IsZero() {
+			log.Info("Resource usage back under limit", "checker", s.c, "exceededFor", now.Sub(s.exceededSince))
+		}
+		s.exceededSince = time.Time{}
+		limitCheckExceededGauge.Update(0)
+		return false
+	}
+	if s.exceededSince.IsZero() {
+		s.exceededSince = now
+		log.Warn("Resource limit exceeded", "checker", s.c, "action", conf.Action, "actingAfter", conf.SustainedDuration)
+	}
+	exceededFor := now.Sub(s.exceededSince)
+	limitCheckExceededGauge.Update(int64(exceededFor.Seconds()))
+	return exceededFor >= conf.SustainedDuration
 }
 
 // ServeHTTP passes req to inner unless any configured system resource
-// limit is exceeded, in which case it returns a HTTP 429 error.
+// limit has been exceeded for the sustained duration, in which case it
+// takes the configured action.
 func (s *httpServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	exceeded, err := s.c.isLimitExceeded()
 	limitCheckDurationHistogram.Update(time.Since(start).Nanoseconds())
 	if err != nil {
 		log.Error("Error checking memory limit", "err", err, "checker", s.c)
-	} else if exceeded {
+		s.mutex.Lock()
+		s.lastCheck = start
+		s.lastErr = err
+		s.mutex.Unlock()
+	} else if s.sustainedExceeded(exceeded, start) && s.act() {
 		http.Error(w, "Too many requests", http.StatusTooManyRequests)
 		limitCheckFailureCounter.Inc(1)
 		return
@@ -85,11 +193,48 @@ func (s *httpServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	s.inner.ServeHTTP(w, req)
 }
 
+// act takes the configured action and returns whether the request should be rejected.
+func (s *limiter) act() bool {
+	conf := s.config()
+	switch conf.Action {
+	case ActionShed:
+		if rand.Intn(100) >= conf.ShedPercent {
+			return false
+		}
+		limitCheckShedCounter.Inc(1)
+		log.Debug("Shedding RPC call due to resource limit", "checker", s.c)
+	case ActionFatal:
+		s.mutex.Lock()
+		alreadySent := s.fatalSent
+		s.fatalSent = true
+		s.mutex.Unlock()
+		if !alreadySent {
+			limitCheckFatalCounter.Inc(1)
+			log.Error("Resource limit exceeded for sustained duration, shutting down", "checker", s.c, "duration", conf.SustainedDuration)
+			if s.fatalErrChan != nil {
+				// never block the request, another fatal error may already be shutting the node down
+				select {
+				case s.fatalErrChan <- fmt.Errorf("resource limit exceeded for %v (%v)", conf.SustainedDuration, s.c):
+				default:
+				}
+			}
+		}
+	default:
+		limitCheckRejectedCounter.Inc(1)
+	}
+	return true
+}
+
 type limitChecker interface {
 	isLimitExceeded() (bool, error)
 	String() string
 }
 
+// memoryUsageReporter is implemented by limitCheckers that can report the memory usage they check
+type memoryUsageReporter interface {
+	memoryUsage() (used, limit int, err error)
+}
+
 // newLimitChecker attempts to auto-discover the mechanism by which it
 // can check system limits. Currently Cgroups V1 is supported,
 // with Cgroups V2 likely to be implmemented next. If no supported
@@ -146,21 +291,30 @@ func isSupported(c limitChecker) bool {
 // which is reported as container_memory_working_set_bytes in prometheus:
 // https://mihai-albert.com/2022/02/13/out-of-memory-oom-in-kubernetes-part-3-memory-metrics-sources-and-tools-to-collect-them/
 func (c *cgroupsV1MemoryLimitChecker) isLimitExceeded() (bool, error) {
+	used, limit, err := c.memoryUsage()
+	if err != nil {
+		return false, err
+	}
+	return used >= ((limit * c.memoryLimitPercent) / 100), nil
+}
+
+// memoryUsage returns the working set memory and the memory limit of the cgroup in bytes.
+func (c *cgroupsV1MemoryLimitChecker) memoryUsage() (int, int, error) {
 	var limit, usage, inactive int
 	var err error
 	limit, err = readIntFromFile(c.limitFile)
 	if err != nil {
-		return false, err
+		return 0, 0, err
 	}
 	usage, err = readIntFromFile(c.usageFile)
 	if err != nil {
-		return false, err
+		return 0, 0, err
 	}
 	inactive, err = readInactive(c.statsFile)
 	if err != nil {
-		return false, err
+		return 0, 0, err
 	}
-	return usage-inactive >= ((limit * c.memoryLimitPercent) / 100), nil
+	return usage - inactive, limit, nil
 }
 
 func readIntFromFile(fileName string) (int, error) {
@@ -4,9 +4,13 @@
 package resourcemanager
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func updateFakeCgroupv1Files(c *cgroupsV1MemoryLimitChecker, limit, usage, inactive int) error {
@@ -76,3 +80,97 @@ func TestCgroupsv1MemoryLimit(t *testing.T) {
 	}
 
 }
+
+type fakeLimitChecker struct {
+	exceeded bool
+}
+
+func (c *fakeLimitChecker) isLimitExceeded() (bool, error) {
+	return c.exceeded, nil
+}
+
+func (c *fakeLimitChecker) String() string { return "fake" }
+
+func TestSustainedExceeded(t *testing.T) {
+	conf := DefaultConfig
+	conf.SustainedDuration = time.Minute
+	s := newHttpServer(nil, &fakeLimitChecker{}, func() *Config { return &conf }, nil)
+	start := time.Now()
+	if s.sustainedExceeded(true, start) {
+		t.Error("Expected limit not yet sustained")
+	}
+	if s.sustainedExceeded(true, start.Add(30*time.Second)) {
+		t.Error("Expected limit not yet sustained")
+	}
+	if !s.sustainedExceeded(true, start.Add(time.Minute)) {
+		t.Error("Expected limit sustained")
+	}
+	if s.sustainedExceeded(false, start.Add(2*time.Minute)) {
+		t.Error("Expected limit no longer exceeded")
+	}
+	if s.sustainedExceeded(true, start.Add(3*time.Minute)) {
+		t.Error("Expected sustained duration to restart")
+	}
+}
+
+func TestFatalAction(t *testing.T) {
+	conf := DefaultConfig
+	conf.MemLimitPercent = 90
+	conf.Action = ActionFatal
+	fatalErrChan := make(chan error, 10)
+	inner := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	s := newHttpServer(inner, &fakeLimitChecker{exceeded: true}, func() *Config { return &conf }, fatalErrChan)
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		if recorder.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, recorder.Code)
+		}
+	}
+	if len(fatalErrChan) != 1 {
+		t.Errorf("Expected exactly one fatal error, got %d", len(fatalErrChan))
+	}
+}
+
+func TestConfigReloaded(t *testing.T) {
+	conf := DefaultConfig
+	conf.MemLimitPercent = 90
+	conf.SustainedDuration = time.Hour
+	inner := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	s := newHttpServer(inner, &fakeLimitChecker{exceeded: true}, func() *Config { return &conf }, nil)
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		return recorder.Code
+	}
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("Expected status %d before the sustained duration, got %d", http.StatusOK, code)
+	}
+	// as on config reload
+	conf.SustainedDuration = 0
+	if code := serve(); code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d after the sustained duration was reloaded, got %d", http.StatusTooManyRequests, code)
+	}
+	conf.Action = ActionShed
+	conf.ShedPercent = 0
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("Expected status %d after the action was reloaded, got %d", http.StatusOK, code)
+	}
+}
+
+func TestResourceLimitsReportsRunningLimit(t *testing.T) {
+	conf := DefaultConfig
+	conf.MemLimitPercent = 90
+	l := newLimiter(&fakeLimitChecker{}, func() *Config { return &conf }, nil)
+	activeLimiter.Store(l)
+	defer activeLimiter.Store(nil)
+	// the checker keeps the limit it was created with
+	conf.MemLimitPercent = 50
+	result, err := NewAPI().ResourceLimits(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Enabled || result.MemLimitPercent != 90 {
+		t.Errorf("Expected the running limit 90 to be reported, got %d (enabled %v)", result.MemLimitPercent, result.Enabled)
+	}
+}
@@ -15,6 +15,8 @@ var (
 	delayedMessageCountKey []byte = []byte("_delayedMessageCount") // contains the current delayed message count
 	sequencerBatchCountKey []byte = []byte("_sequencerBatchCount") // contains the current sequencer message count
 	dbSchemaVersion        []byte = []byte("_schemaVersion")       // contains a uint64 representing the database schema version
+
+	maintenancePrunedBlocksKey []byte = []byte("_maintenancePrunedBlocks") // contains the first block whose body and receipts weren't pruned by the maintenance runner
 )
 
 const currentDbSchemaVersion uint64 = 1
@@ -0,0 +1,308 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+type StatusPageConfig struct {
+	Enable bool   `koanf:"enable"`
+	Addr   string `koanf:"addr"`
+	Port   uint64 `koanf:"port"`
+}
+
+var DefaultStatusPageConfig = StatusPageConfig{
+	Enable: false,
+	Addr:   "127.0.0.1",
+	Port:   8550, // next to the http (8547), ws (8548) and auth (8549) rpc ports
+}
+
+func StatusPageConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultStatusPageConfig.Enable, "serve a human readable status page of the node over HTTP, along with its data as json at /status and its health at /healthz, unhealthy while not synced or while batch posting or validation is stale")
+	f.String(prefix+".addr", DefaultStatusPageConfig.Addr, "status page server listening interface")
+	f.Uint64(prefix+".port", DefaultStatusPageConfig.Port, "status page server listening port")
+}
+
+func (c *StatusPageConfig) Validate() error {
+	if c.Enable && c.Port > 65535 {
+		return fmt.Errorf("invalid status-page.port %v", c.Port)
+	}
+	return nil
+}
+
+type SyncStatus struct {
+	Synced   bool                   `json:"synced"`
+	Progress map[string]interface{} `json:"progress,omitempty"`
+}
+
+type FeedInputStatus struct {
+	Connections int32 `json:"connections"`
+	Connected   int32 `json:"connected"`
+}
+
+// NodeStatus summarizes the node's state, from the same sources as the status rpcs
+type NodeStatus struct {
+	Time       time.Time                    `json:"time"`
+	Roles      []string                     `json:"roles"`
+	Subsystems map[string]string            `json:"subsystems"`
+	Sync       SyncStatus                   `json:"sync"`
+	Staleness  *StalenessResult             `json:"staleness,omitempty"`
+	FeedOutput *wsbroadcastserver.FeedStats `json:"feedOutput,omitempty"`
+	FeedInput  *FeedInputStatus             `json:"feedInput,omitempty"`
+	Errors     []string                     `json:"errors,omitempty"`
+}
+
+func (s *NodeStatus) SortedSubsystems() [][2]string {
+	var subsystems [][2]string
+	for name, state := range s.Subsystems {
+		subsystems = append(subsystems, [2]string{name, state})
+	}
+	sort.Slice(subsystems, func(i, j int) bool { return subsystems[i][0] < subsystems[j][0] })
+	return subsystems
+}
+
+func (s *NodeStatus) SortedProgress() [][2]string {
+	var progress [][2]string
+	for key, value := range s.Sync.Progress {
+		progress = append(progress, [2]string{key, fmt.Sprint(value)})
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i][0] < progress[j][0] })
+	return progress
+}
+
+// StatusPage serves the node's status over HTTP
+type StatusPage struct {
+	stopwaiter.StopWaiter
+	config    StatusPageConfig
+	node      *Node
+	staleness *StalenessAPI
+	listener  net.Listener
+}
+
+func NewStatusPage(config *StatusPageConfig, node *Node) *StatusPage {
+	return &StatusPage{
+		config: *config,
+		node:   node,
+	}
+}
+
+func (p *StatusPage) roles() []string {
+	n := p.node
+	var roles []string
+	if n.Execution.Sequencer != nil {
+		role := "sequencer"
+		if n.SeqCoordinator != nil {
+			if n.SeqCoordinator.CurrentlyChosen() {
+				role += " (chosen)"
+			} else {
+				role += " (standby)"
+			}
+		}
+		roles = append(roles, role)
+	} else if n.configFetcher.Get().ForwardingTargetF() != "" {
+		roles = append(roles, "forwarder")
+	}
+	if n.BatchPoster != nil {
+		roles = append(roles, "batch poster")
+	}
+	if n.BlockValidator != nil {
+		roles = append(roles, "block validator")
+	}
+	if n.Staker != nil {
+		roles = append(roles, "staker")
+	}
+	if n.BroadcastServer != nil {
+		roles = append(roles, "feed output")
+	}
+	if n.BroadcastClients != nil {
+		roles = append(roles, "feed input")
+	}
+	if len(roles) == 0 {
+		roles = append(roles, "full node")
+	}
+	return roles
+}
+
+func (p *StatusPage) Status(ctx context.Context) *NodeStatus {
+	n := p.node
+	status := &NodeStatus{
+		Time:       time.Now(),
+		Roles:      p.roles(),
+		Subsystems: n.SubsystemStates(),
+	}
+	status.Sync.Progress = n.SyncMonitor.SyncProgressMap()
+	status.Sync.Synced = len(status.Sync.Progress) == 0
+	staleness, err := p.staleness.Staleness(ctx)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to get staleness: %v", err))
+	} else if staleness.BatchPosting != nil || staleness.Validation != nil {
+		status.Staleness = staleness
+	}
+	if n.BroadcastServer != nil {
+		stats := n.BroadcastServer.Stats()
+		status.FeedOutput = &stats
+	}
+	if n.BroadcastClients != nil {
+		status.FeedInput = &FeedInputStatus{
+			Connections: n.BroadcastClients.Count(),
+			Connected:   n.BroadcastClients.Connected(),
+		}
+	}
+	return status
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="10">
+<title>Nitro node status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.ok { color: #080; } .bad { color: #c00; }
+</style>
+</head>
+<body>
+<h1>Nitro node status</h1>
+<p>As of {{.Time.Format "2006-01-02 15:04:05 MST"}}, refreshed every 10 seconds. Also available as <a href="/status">json</a>.</p>
+<h2>Roles</h2>
+<p>{{range $i, $role := .Roles}}{{if $i}}, {{end}}{{$role}}{{end}}</p>
+<h2>Subsystems</h2>
+<table>{{range .SortedSubsystems}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>{{end}}</table>
+<h2>Sync</h2>
+{{if .Sync.Synced}}<p class="ok">Synced</p>{{else}}<p class="bad">Syncing</p>
+<table>{{range .SortedProgress}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>{{end}}</table>{{end}}
+{{with .Staleness}}{{with .BatchPosting}}
+<h2>Batch posting</h2>
+<table>
+<tr><th>Last batch</th><td>{{with .LastPosted}}#{{.SequenceNumber}} in {{.TxHash}} at {{.Time.Format "2006-01-02 15:04:05 MST"}}{{else}}none since startup{{end}}</td></tr>
+<tr><th>Age</th><td>{{printf "%.0f" .AgeSeconds}}s</td></tr>
+<tr><th>Stale</th><td class="{{if .Stale}}bad{{else}}ok{{end}}">{{.Stale}}</td></tr>
+</table>{{end}}{{with .Validation}}
+<h2>Validation</h2>
+<table>
+<tr><th>Last validated</th><td>{{with .LastValidated}}block {{.GlobalState.BlockHash}} (batch {{.GlobalState.Batch}}, position {{.GlobalState.PosInBatch}}){{else}}none{{end}}</td></tr>
+<tr><th>Age</th><td>{{printf "%.0f" .AgeSeconds}}s</td></tr>
+<tr><th>Stale</th><td class="{{if .Stale}}bad{{else}}ok{{end}}">{{.Stale}}</td></tr>
+</table>{{end}}{{end}}
+{{with .FeedOutput}}
+<h2>Feed output</h2>
+<table>
+<tr><th>Subscribers</th><td>{{.Subscribers}}</td></tr>
+<tr><th>Messages per second</th><td>{{printf "%.2f" .MessagesPerSecond}}</td></tr>
+<tr><th>Bytes per second</th><td>{{printf "%.0f" .BytesPerSecond}}</td></tr>
+</table>{{end}}
+{{with .FeedInput}}
+<h2>Feed input</h2>
+<table>
+<tr><th>Connected feeds</th><td class="{{if .Connected}}ok{{else}}bad{{end}}">{{.Connected}} of {{.Connections}}</td></tr>
+</table>{{end}}
+{{with .Errors}}<h2>Errors</h2><ul>{{range .}}<li class="bad">{{.}}</li>{{end}}</ul>{{end}}
+</body>
+</html>
+`))
+
+func (p *StatusPage) serveHTML(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPageTemplate.Execute(w, p.Status(r.Context())); err != nil {
+		log.Warn("failed to render status page", "err", err)
+	}
+}
+
+func (p *StatusPage) serveJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(p.Status(r.Context())); err != nil {
+		log.Warn("failed to write status", "err", err)
+	}
+}
+
+// healthzStatus is the /healthz response: unhealthy while the node isn't synced,
+// or batch posting or validation is stale by their configured stale-threshold
+func healthzStatus(synced bool, staleness *StalenessResult, stalenessErr error) (int, string) {
+	if !synced {
+		return http.StatusServiceUnavailable, "not synced"
+	}
+	if stalenessErr != nil {
+		return http.StatusServiceUnavailable, fmt.Sprintf("failed to get staleness: %v", stalenessErr)
+	}
+	if staleness.BatchPosting != nil && staleness.BatchPosting.Stale {
+		return http.StatusServiceUnavailable, fmt.Sprintf("batch posting stale, no batch posted for %.0fs", staleness.BatchPosting.AgeSeconds)
+	}
+	if staleness.Validation != nil && staleness.Validation.Stale {
+		return http.StatusServiceUnavailable, fmt.Sprintf("validation stale, no block validated for %.0fs", staleness.Validation.AgeSeconds)
+	}
+	return http.StatusOK, "ok"
+}
+
+func (p *StatusPage) serveHealthz(w http.ResponseWriter, r *http.Request) {
+	staleness, err := p.staleness.Staleness(r.Context())
+	status, reason := healthzStatus(p.node.SyncMonitor.Synced(), staleness, err)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintln(w, reason)
+}
+
+// Start must be called after the node's components are started, as it reports the ones in use then
+func (p *StatusPage) Start(ctxIn context.Context) error {
+	n := p.node
+	p.staleness = NewStalenessAPI(n.BatchPoster, n.BlockValidator, n.configFetcher)
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", p.config.Addr, p.config.Port))
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", p.serveHTML)
+	mux.HandleFunc("/status", p.serveJSON)
+	mux.HandleFunc("/healthz", p.serveHealthz)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	p.StopWaiter.Start(ctxIn, p)
+	p.LaunchThread(func(ctx context.Context) {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warn("error shutting down status page server", "err", err)
+		}
+	})
+	p.LaunchThread(func(ctx context.Context) {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("error serving status page", "err", err)
+		}
+	})
+	log.Info("status page server started", "url", "http://"+listener.Addr().String())
+	return nil
+}
+
+func (p *StatusPage) Addr() net.Addr {
+	return p.listener.Addr()
+}
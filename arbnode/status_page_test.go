@@ -0,0 +1,54 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealthzStatus(t *testing.T) {
+	config := ConfigDefaultL1Test()
+	config.BatchPoster.StaleThreshold = time.Minute
+	config.BlockValidator.StaleThreshold = time.Minute
+	staleness := func(batchPostingAge time.Duration, validationAge time.Duration) *StalenessResult {
+		return &StalenessResult{
+			BatchPosting: &BatchPostingStaleness{
+				AgeSeconds: batchPostingAge.Seconds(),
+				Stale:      isStale(batchPostingAge, config.BatchPoster.StaleThreshold),
+			},
+			Validation: &ValidationStaleness{
+				AgeSeconds: validationAge.Seconds(),
+				Stale:      isStale(validationAge, config.BlockValidator.StaleThreshold),
+			},
+		}
+	}
+
+	if status, reason := healthzStatus(true, staleness(time.Second, time.Second), nil); status != http.StatusOK {
+		Fail(t, "synced node within the stale thresholds unhealthy", status, reason)
+	}
+	if status, _ := healthzStatus(true, &StalenessResult{}, nil); status != http.StatusOK {
+		Fail(t, "synced node without batch poster or validator unhealthy", status)
+	}
+	if status, _ := healthzStatus(false, staleness(time.Second, time.Second), nil); status != http.StatusServiceUnavailable {
+		Fail(t, "syncing node healthy", status)
+	}
+	if status, _ := healthzStatus(true, staleness(2*time.Minute, time.Second), nil); status != http.StatusServiceUnavailable {
+		Fail(t, "node with stale batch posting healthy", status)
+	}
+	if status, _ := healthzStatus(true, staleness(time.Second, 2*time.Minute), nil); status != http.StatusServiceUnavailable {
+		Fail(t, "node with stale validation healthy", status)
+	}
+	if status, _ := healthzStatus(true, nil, errors.New("no validated info")); status != http.StatusServiceUnavailable {
+		Fail(t, "node with unknown staleness healthy", status)
+	}
+
+	// a threshold of 0 disables the staleness check
+	config.BatchPoster.StaleThreshold = 0
+	if status, reason := healthzStatus(true, staleness(time.Hour, time.Second), nil); status != http.StatusOK {
+		Fail(t, "node with batch posting staleness disabled unhealthy", status, reason)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+const (
+	SubsystemRunning = "running"
+	// the sequencer is paused, e.g. while another sequencer is chosen or during maintenance
+	SubsystemPaused = "paused"
+	// the sequencer forwards transactions instead of sequencing them
+	SubsystemForwarding = "forwarding"
+	SubsystemStopped    = "stopped"
+	// not enabled in this node's configuration
+	SubsystemDisabled = "disabled"
+)
+
+type startStopper interface {
+	Started() bool
+	Stopped() bool
+}
+
+func stopWaiterState(s startStopper) string {
+	if s.Started() && !s.Stopped() {
+		return SubsystemRunning
+	}
+	return SubsystemStopped
+}
+
+// SubsystemStates returns the state of each of the node's subsystems
+func (n *Node) SubsystemStates() map[string]string {
+	states := map[string]string{
+		"sequencer":             SubsystemDisabled,
+		"delayed-sequencer":     SubsystemDisabled,
+		"sequencer-coordinator": SubsystemDisabled,
+		"batch-poster":          SubsystemDisabled,
+		"staker":                SubsystemDisabled,
+		"block-validator":       SubsystemDisabled,
+		"inbox-reader":          SubsystemDisabled,
+		"feed-input":            SubsystemDisabled,
+		"feed-output":           SubsystemDisabled,
+	}
+	if sequencer := n.Execution.Sequencer; sequencer != nil {
+		state := stopWaiterState(sequencer)
+		if state == SubsystemRunning {
+			pauseChan, forwarder := sequencer.GetPauseAndForwarder()
+			if forwarder != nil {
+				state = SubsystemForwarding
+			} else if pauseChan != nil {
+				state = SubsystemPaused
+			}
+		}
+		states["sequencer"] = state
+	}
+	if n.DelayedSequencer != nil {
+		states["delayed-sequencer"] = stopWaiterState(n.DelayedSequencer)
+	}
+	if n.SeqCoordinator != nil {
+		states["sequencer-coordinator"] = stopWaiterState(n.SeqCoordinator)
+	}
+	if n.BatchPoster != nil {
+		states["batch-poster"] = stopWaiterState(n.BatchPoster)
+	}
+	if n.Staker != nil {
+		states["staker"] = stopWaiterState(n.Staker)
+	}
+	if n.BlockValidator != nil {
+		states["block-validator"] = stopWaiterState(n.BlockValidator)
+	}
+	if n.InboxReader != nil {
+		states["inbox-reader"] = stopWaiterState(n.InboxReader)
+	}
+	if n.BroadcastClients != nil {
+		if n.BroadcastClients.Running() {
+			states["feed-input"] = SubsystemRunning
+		} else {
+			states["feed-input"] = SubsystemStopped
+		}
+	}
+	if n.BroadcastServer != nil {
+		if n.BroadcastServer.Started() {
+			states["feed-output"] = SubsystemRunning
+		} else {
+			states["feed-output"] = SubsystemStopped
+		}
+	}
+	return states
+}
+
+type SubsystemsAPI struct {
+	node *Node
+}
+
+// SubsystemStates returns which of the node's subsystems are running, paused, stopped or disabled
+func (a *SubsystemsAPI) SubsystemStates() map[string]string {
+	return a.node.SubsystemStates()
+}
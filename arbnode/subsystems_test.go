@@ -0,0 +1,37 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbnode/execution"
+)
+
+func TestSubsystemStates(t *testing.T) {
+	delayedSequencer := &DelayedSequencer{}
+	node := &Node{
+		Execution:        &execution.ExecutionNode{},
+		DelayedSequencer: delayedSequencer,
+	}
+	states := node.SubsystemStates()
+	if states["delayed-sequencer"] != SubsystemStopped {
+		Fail(t, "delayed sequencer not started but reported as", states["delayed-sequencer"])
+	}
+	if states["batch-poster"] != SubsystemDisabled || states["sequencer"] != SubsystemDisabled {
+		Fail(t, "unconfigured subsystems not reported as disabled", states)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	delayedSequencer.StopWaiter.Start(ctx, delayedSequencer)
+	if state := node.SubsystemStates()["delayed-sequencer"]; state != SubsystemRunning {
+		Fail(t, "started delayed sequencer reported as", state)
+	}
+	delayedSequencer.StopAndWait()
+	if state := node.SubsystemStates()["delayed-sequencer"]; state != SubsystemStopped {
+		Fail(t, "stopped delayed sequencer reported as", state)
+	}
+}
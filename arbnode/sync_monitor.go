@@ -3,42 +3,91 @@ package arbnode
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
 	flag "github.com/spf13/pflag"
 )
 
+var (
+	syncStalledGauge  = metrics.NewRegisteredGauge("arb/sync/stalled", nil)
+	syncStallsCounter = metrics.NewRegisteredCounter("arb/sync/stalls", nil)
+)
+
+var ErrSyncStalled = errors.New("sync stalled")
+
 type SyncMonitor struct {
-	config      *SyncMonitorConfig
-	inboxReader *InboxReader
-	txStreamer  *TransactionStreamer
-	coordinator *SeqCoordinator
-	initialized bool
+	stopwaiter.StopWaiter
+	config       *SyncMonitorConfig
+	inboxReader  *InboxReader
+	txStreamer   *TransactionStreamer
+	coordinator  *SeqCoordinator
+	initialized  bool
+	fatalErrChan chan<- error
+
+	// only accessed by the stall check thread
+	lastProgress     time.Time
+	lastMessageCount arbutil.MessageIndex
+	lastBuiltMessage arbutil.MessageIndex
+	stallReported    bool
 }
 
-func NewSyncMonitor(config *SyncMonitorConfig) *SyncMonitor {
+func NewSyncMonitor(config *SyncMonitorConfig, fatalErrChan chan<- error) *SyncMonitor {
 	return &SyncMonitor{
-		config: config,
+		config:       config,
+		fatalErrChan: fatalErrChan,
 	}
 }
 
 type SyncMonitorConfig struct {
-	BlockBuildLag               uint64 `koanf:"block-build-lag"`
-	BlockBuildSequencerInboxLag uint64 `koanf:"block-build-sequencer-inbox-lag"`
-	CoordinatorMsgLag           uint64 `koanf:"coordinator-msg-lag"`
+	BlockBuildLag               uint64        `koanf:"block-build-lag"`
+	BlockBuildSequencerInboxLag uint64        `koanf:"block-build-sequencer-inbox-lag"`
+	CoordinatorMsgLag           uint64        `koanf:"coordinator-msg-lag"`
+	RPCWhileSyncing             string        `koanf:"rpc-while-syncing"`
+	StallTimeout                time.Duration `koanf:"stall-timeout"`
+	StallFatal                  bool          `koanf:"stall-fatal"`
+}
+
+const (
+	RPCWhileSyncingServe  = "serve"
+	RPCWhileSyncingWarn   = "warn"
+	RPCWhileSyncingReject = "reject"
+)
+
+func (c *SyncMonitorConfig) Validate() error {
+	if c.StallTimeout < 0 {
+		return fmt.Errorf("invalid sync-monitor.stall-timeout %v", c.StallTimeout)
+	}
+	switch c.RPCWhileSyncing {
+	case RPCWhileSyncingServe, RPCWhileSyncingWarn, RPCWhileSyncingReject:
+		return nil
+	default:
+		return fmt.Errorf("invalid sync-monitor.rpc-while-syncing %q, expected one of %q, %q or %q", c.RPCWhileSyncing, RPCWhileSyncingServe, RPCWhileSyncingWarn, RPCWhileSyncingReject)
+	}
 }
 
 var DefaultSyncMonitorConfig = SyncMonitorConfig{
 	BlockBuildLag:               20,
 	BlockBuildSequencerInboxLag: 0,
 	CoordinatorMsgLag:           15,
+	RPCWhileSyncing:             RPCWhileSyncingServe,
+	StallTimeout:                0,
+	StallFatal:                  false,
 }
 
 func SyncMonitorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Uint64(prefix+".block-build-lag", DefaultSyncMonitorConfig.BlockBuildLag, "allowed lag between messages read and blocks built")
 	f.Uint64(prefix+".block-build-sequencer-inbox-lag", DefaultSyncMonitorConfig.BlockBuildSequencerInboxLag, "allowed lag between messages read from sequencer inbox and blocks built")
 	f.Uint64(prefix+".coordinator-msg-lag", DefaultSyncMonitorConfig.CoordinatorMsgLag, "allowed lag between local and remote messages")
+	f.String(prefix+".rpc-while-syncing", DefaultSyncMonitorConfig.RPCWhileSyncing, "how to handle state-dependent eth RPC calls while the node is syncing: \"serve\", \"warn\" (serve with a Warning header over http and log a warning) or \"reject\" (return a syncing error)")
+	f.Duration(prefix+".stall-timeout", DefaultSyncMonitorConfig.StallTimeout, "report the sync as stalled if the node is syncing but its message count and built blocks don't progress for this long (0 = disabled)")
+	f.Bool(prefix+".stall-fatal", DefaultSyncMonitorConfig.StallFatal, "shut the node down with an error when the sync stalls, so a supervisor can restart or reconfigure it")
 }
 
 func (s *SyncMonitor) Initialize(inboxReader *InboxReader, txStreamer *TransactionStreamer, coordinator *SeqCoordinator) {
@@ -169,3 +218,75 @@ func (s *SyncMonitor) FinalizedBlockNumber(ctx context.Context) (uint64, error)
 func (s *SyncMonitor) Synced() bool {
 	return len(s.SyncProgressMap()) == 0
 }
+
+func (s *SyncMonitor) stallCheckInterval() time.Duration {
+	interval := s.config.StallTimeout / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	return interval
+}
+
+func (s *SyncMonitor) checkStall(ctx context.Context) time.Duration {
+	interval := s.stallCheckInterval()
+	progress := s.SyncProgressMap()
+	messageCount, err := s.txStreamer.GetMessageCount()
+	if err != nil {
+		log.Warn("sync stall check failed to get message count", "err", err)
+		return interval
+	}
+	builtMessage, err := s.txStreamer.exec.HeadMessageNumber()
+	if err != nil {
+		log.Warn("sync stall check failed to get built message", "err", err)
+		return interval
+	}
+	s.updateStall(time.Now(), progress, messageCount, builtMessage)
+	return interval
+}
+
+// updateStall reports the sync as stalled once the node has been syncing for StallTimeout
+// without its message count or built messages increasing
+func (s *SyncMonitor) updateStall(now time.Time, progress map[string]interface{}, messageCount arbutil.MessageIndex, builtMessage arbutil.MessageIndex) {
+	synced := len(progress) == 0
+	if synced || s.lastProgress.IsZero() || messageCount != s.lastMessageCount || builtMessage != s.lastBuiltMessage {
+		if s.stallReported {
+			log.Info("sync progressing again after stall", "stalledFor", now.Sub(s.lastProgress), "msgCount", messageCount, "builtMessage", builtMessage)
+			syncStalledGauge.Update(0)
+			s.stallReported = false
+		}
+		s.lastProgress = now
+		s.lastMessageCount = messageCount
+		s.lastBuiltMessage = builtMessage
+		return
+	}
+	stalledFor := now.Sub(s.lastProgress)
+	if stalledFor < s.config.StallTimeout || s.stallReported {
+		return
+	}
+	s.stallReported = true
+	syncStalledGauge.Update(1)
+	syncStallsCounter.Inc(1)
+	logArgs := []interface{}{"stalledFor", stalledFor, "msgCount", messageCount, "builtMessage", builtMessage}
+	for key, value := range progress {
+		logArgs = append(logArgs, key, value)
+	}
+	log.Error("SYNC STALLED: node is syncing but has made no progress", logArgs...)
+	if s.config.StallFatal {
+		err := fmt.Errorf("%w: no progress for %v at message count %v", ErrSyncStalled, stalledFor, messageCount)
+		select {
+		case s.fatalErrChan <- err:
+		default:
+		}
+	}
+}
+
+// Start launches the sync stall detection, and must be called after Initialize
+func (s *SyncMonitor) Start(ctxIn context.Context) {
+	s.StopWaiter.Start(ctxIn, s)
+	if s.config.StallTimeout > 0 {
+		s.CallIteratively(s.checkStall)
+	}
+}
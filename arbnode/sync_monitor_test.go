@@ -0,0 +1,60 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncMonitorStall(t *testing.T) {
+	config := DefaultSyncMonitorConfig
+	if config.StallTimeout != 0 {
+		Fail(t, "sync stall detection enabled by default")
+	}
+	config.StallTimeout = time.Minute
+	config.StallFatal = true
+	fatalErrChan := make(chan error, 1)
+	s := NewSyncMonitor(&config, fatalErrChan)
+
+	syncing := map[string]interface{}{"msgCount": 10}
+	start := time.Now()
+	s.updateStall(start, syncing, 10, 5)
+	// progress in built blocks restarts the stall timeout
+	s.updateStall(start.Add(50*time.Second), syncing, 10, 6)
+	s.updateStall(start.Add(100*time.Second), syncing, 10, 6)
+	if s.stallReported {
+		Fail(t, "sync reported stalled before the stall timeout")
+	}
+
+	s.updateStall(start.Add(111*time.Second), syncing, 10, 6)
+	if !s.stallReported {
+		Fail(t, "sync without progress for the stall timeout not reported stalled")
+	}
+	select {
+	case err := <-fatalErrChan:
+		if !errors.Is(err, ErrSyncStalled) {
+			Fail(t, "unexpected fatal error", err)
+		}
+	default:
+		Fail(t, "stalled sync didn't push a fatal error")
+	}
+	// a stall is only reported once
+	s.updateStall(start.Add(200*time.Second), syncing, 10, 6)
+	if len(fatalErrChan) != 0 {
+		Fail(t, "stalled sync reported twice")
+	}
+
+	// progress ends the stall
+	s.updateStall(start.Add(210*time.Second), syncing, 11, 6)
+	if s.stallReported {
+		Fail(t, "sync still reported stalled after progressing")
+	}
+	// a synced node isn't stalled, even without progress
+	s.updateStall(start.Add(time.Hour), map[string]interface{}{}, 11, 6)
+	if s.stallReported || len(fatalErrChan) != 0 {
+		Fail(t, "synced node reported stalled")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+var (
+	syncGuardRejectedCounter = metrics.NewRegisteredCounter("arb/rpc/syncing/rejected", nil)
+	syncGuardWarnedCounter   = metrics.NewRegisteredCounter("arb/rpc/syncing/warned", nil)
+)
+
+const syncGuardCheckInterval = time.Second
+const syncGuardWarnInterval = time.Minute
+
+type syncingError struct{}
+
+func (syncingError) Error() string  { return "node is syncing, state may be stale" }
+func (syncingError) ErrorCode() int { return -32000 }
+
+// SyncGuardAPI overrides the state-dependent methods of the eth namespace.
+// eth_blockNumber isn't overridden, clients poll it to follow the sync progress.
+// Depending on node.sync-monitor.rpc-while-syncing it rejects them, or warns the client with a Warning header
+// over http and logs a warning, while the sync monitor reports the node as syncing, and otherwise forwards them
+// to the original implementation.
+type SyncGuardAPI struct {
+	syncMonitor *SyncMonitor
+	mode        string
+	inner       *rpc.Client
+
+	mutex     sync.Mutex
+	checkedAt time.Time
+	synced    bool
+	warnedAt  time.Time
+}
+
+// NewSyncGuardAPI serves the guarded calls from the eth namespace apis of ethAPIs,
+// which should be the apis registered by the execution backend.
+func NewSyncGuardAPI(syncMonitor *SyncMonitor, mode string, ethAPIs []rpc.API) (*SyncGuardAPI, error) {
+	server := rpc.NewServer()
+	for _, api := range ethAPIs {
+		if api.Namespace != "eth" {
+			continue
+		}
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	return &SyncGuardAPI{
+		syncMonitor: syncMonitor,
+		mode:        mode,
+		inner:       rpc.DialInProc(server),
+	}, nil
+}
+
+func (a *SyncGuardAPI) isSynced() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	// Synced may do a remote call to the coordinator, so don't repeat it per request
+	if time.Since(a.checkedAt) > syncGuardCheckInterval {
+		a.synced = a.syncMonitor.Synced()
+		a.checkedAt = time.Now()
+	}
+	return a.synced
+}
+
+func (a *SyncGuardAPI) check(ctx context.Context, method string) error {
+	if a.mode == RPCWhileSyncingServe || a.isSynced() {
+		return nil
+	}
+	if a.mode == RPCWhileSyncingReject {
+		syncGuardRejectedCounter.Inc(1)
+		return syncingError{}
+	}
+	syncGuardWarnedCounter.Inc(1)
+	// the client is warned with each response, the log is rate limited
+	genericconf.AddRpcWarning(ctx, syncingError{}.Error())
+	a.mutex.Lock()
+	shouldWarn := time.Since(a.warnedAt) > syncGuardWarnInterval
+	if shouldWarn {
+		a.warnedAt = time.Now()
+	}
+	a.mutex.Unlock()
+	if shouldWarn {
+		log.Warn("serving state-dependent RPC while syncing, results may be stale", "method", method)
+	}
+	return nil
+}
+
+// callRaw calls method with already encoded arguments, dropping unset optional trailing arguments
+func callRaw(ctx context.Context, client *rpc.Client, method string, args ...*json.RawMessage) (json.RawMessage, error) {
+	for len(args) > 0 && args[len(args)-1] == nil {
+		args = args[:len(args)-1]
+	}
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		params[i] = arg
+	}
+	var result json.RawMessage
+	err := client.CallContext(ctx, &result, method, params...)
+	return result, err
+}
+
+func (a *SyncGuardAPI) forward(ctx context.Context, method string, args ...*json.RawMessage) (json.RawMessage, error) {
+	if err := a.check(ctx, method); err != nil {
+		return nil, err
+	}
+	return callRaw(ctx, a.inner, method, args...)
+}
+
+func (a *SyncGuardAPI) GetBalance(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getBalance", &address, blockNrOrHash)
+}
+
+func (a *SyncGuardAPI) GetCode(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getCode", &address, blockNrOrHash)
+}
+
+func (a *SyncGuardAPI) GetStorageAt(ctx context.Context, address json.RawMessage, key json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getStorageAt", &address, &key, blockNrOrHash)
+}
+
+func (a *SyncGuardAPI) GetTransactionCount(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getTransactionCount", &address, blockNrOrHash)
+}
+
+func (a *SyncGuardAPI) Call(ctx context.Context, args json.RawMessage, blockNrOrHash *json.RawMessage, overrides *json.RawMessage, blockOverrides *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_call", &args, blockNrOrHash, overrides, blockOverrides)
+}
+
+func (a *SyncGuardAPI) EstimateGas(ctx context.Context, args json.RawMessage, blockNrOrHash *json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_estimateGas", &args, blockNrOrHash)
+}
+
+func (a *SyncGuardAPI) GetLogs(ctx context.Context, crit json.RawMessage) (json.RawMessage, error) {
+	return a.forward(ctx, "eth_getLogs", &crit)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+// syncGuardTestEth is the execution backend's eth api, answering every balance with 1
+type syncGuardTestEth struct{}
+
+func (syncGuardTestEth) GetBalance(ctx context.Context, address json.RawMessage, blockNrOrHash *json.RawMessage) (string, error) {
+	return "0x1", nil
+}
+
+type syncGuardTestResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// startSyncGuardTestServer serves the guarded eth api over http, as the node does, with a sync monitor that isn't synced
+func startSyncGuardTestServer(t *testing.T, mode string) *httptest.Server {
+	t.Helper()
+	config := DefaultSyncMonitorConfig
+	config.RPCWhileSyncing = mode
+	// never initialized, so always syncing
+	syncMonitor := NewSyncMonitor(&config, nil)
+	if syncMonitor.Synced() {
+		Fail(t, "uninitialized sync monitor reports the node as synced")
+	}
+	guard, err := NewSyncGuardAPI(syncMonitor, mode, []rpc.API{{Namespace: "eth", Service: syncGuardTestEth{}}})
+	Require(t, err)
+	server := rpc.NewServer()
+	Require(t, server.RegisterName("eth", guard))
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(genericconf.NewRpcHandler(server))
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func getBalanceOverHttp(t *testing.T, url string) (*syncGuardTestResponse, http.Header) {
+	t.Helper()
+	request := `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000001","latest"]}`
+	resp, err := http.Post(url, "application/json", strings.NewReader(request))
+	Require(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	Require(t, err)
+	var response syncGuardTestResponse
+	Require(t, json.Unmarshal(body, &response))
+	return &response, resp.Header
+}
+
+func TestSyncGuardWhileSyncing(t *testing.T) {
+	rejecting := startSyncGuardTestServer(t, RPCWhileSyncingReject)
+	response, header := getBalanceOverHttp(t, rejecting.URL)
+	if response.Error == nil || response.Error.Code != (syncingError{}).ErrorCode() || response.Error.Message != (syncingError{}).Error() {
+		Fail(t, "call while syncing not rejected with the syncing error", string(response.Result))
+	}
+	if header.Get(genericconf.RpcWarningHeader) != "" {
+		Fail(t, "rejected call warned about", header.Get(genericconf.RpcWarningHeader))
+	}
+
+	warning := startSyncGuardTestServer(t, RPCWhileSyncingWarn)
+	for i := 0; i < 2; i++ {
+		// every response is warned about, not only the logged ones
+		response, header = getBalanceOverHttp(t, warning.URL)
+		if response.Error != nil || string(response.Result) != `"0x1"` {
+			Fail(t, "call while syncing not served in warn mode", response.Error)
+		}
+		if !strings.Contains(header.Get(genericconf.RpcWarningHeader), (syncingError{}).Error()) {
+			Fail(t, "call while syncing served without a warning, got headers", header)
+		}
+	}
+
+	serving := startSyncGuardTestServer(t, RPCWhileSyncingServe)
+	response, header = getBalanceOverHttp(t, serving.URL)
+	if response.Error != nil || string(response.Result) != `"0x1"` {
+		Fail(t, "call while syncing not served in serve mode", response.Error)
+	}
+	if header.Get(genericconf.RpcWarningHeader) != "" {
+		Fail(t, "call served with a warning in serve mode", header.Get(genericconf.RpcWarningHeader))
+	}
+}
@@ -26,10 +26,12 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/offchainlabs/nitro/arbnode/execution"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcaster"
@@ -38,6 +40,23 @@ import (
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+var (
+	feedMessagesReceivedCounter   = metrics.NewRegisteredCounter("arb/txstreamer/messages/feed/received", nil)
+	feedMessagesDuplicateCounter  = metrics.NewRegisteredCounter("arb/txstreamer/messages/feed/duplicate", nil)
+	inboxMessagesReceivedCounter  = metrics.NewRegisteredCounter("arb/txstreamer/messages/inbox/received", nil)
+	inboxMessagesDuplicateCounter = metrics.NewRegisteredCounter("arb/txstreamer/messages/inbox/duplicate", nil)
+	feedInboxMismatchCounter      = metrics.NewRegisteredCounter("arb/txstreamer/messages/mismatch", nil)
+)
+
+var ErrFeedInboxMismatch = errors.New("inbox message differs from the message received earlier")
+
+const (
+	// the inbox is authoritative, messages received earlier are reorged out
+	FeedInboxMismatchReorg = "reorg"
+	// stop adding messages and report a fatal error
+	FeedInboxMismatchFatal = "fatal"
+)
+
 // TransactionStreamer produces blocks from a node's L1 messages, storing the results in the blockchain and recording their positions
 // The streamer is notified when there's new batches to process
 type TransactionStreamer struct {
@@ -46,6 +65,7 @@ type TransactionStreamer struct {
 	chainConfig      *params.ChainConfig
 	exec             *execution.ExecutionEngine
 	execLastMsgCount arbutil.MessageIndex
+	execOutOfDate    bool // only accessed by the execution loop
 	validator        *staker.BlockValidator
 
 	db           ethdb.Database
@@ -64,6 +84,7 @@ type TransactionStreamer struct {
 
 	coordinator     *SeqCoordinator
 	broadcastServer *broadcaster.Broadcaster
+	latencyTracker  *messageLatencyTracker
 	inboxReader     *InboxReader
 	delayedBridge   *DelayedBridge
 }
@@ -72,6 +93,14 @@ type TransactionStreamerConfig struct {
 	MaxBroadcasterQueueSize int           `koanf:"max-broadcaster-queue-size"`
 	MaxReorgResequenceDepth int64         `koanf:"max-reorg-resequence-depth" reload:"hot"`
 	ExecuteMessageLoopDelay time.Duration `koanf:"execute-message-loop-delay" reload:"hot"`
+	FeedInboxMismatch       string        `koanf:"feed-inbox-mismatch" reload:"hot"`
+}
+
+func (c *TransactionStreamerConfig) Validate() error {
+	if c.FeedInboxMismatch != FeedInboxMismatchReorg && c.FeedInboxMismatch != FeedInboxMismatchFatal {
+		return fmt.Errorf("invalid transaction-streamer.feed-inbox-mismatch %q, must be %q or %q", c.FeedInboxMismatch, FeedInboxMismatchReorg, FeedInboxMismatchFatal)
+	}
+	return nil
 }
 
 type TransactionStreamerConfigFetcher func() *TransactionStreamerConfig
@@ -80,18 +109,21 @@ var DefaultTransactionStreamerConfig = TransactionStreamerConfig{
 	MaxBroadcasterQueueSize: 1024,
 	MaxReorgResequenceDepth: 1024,
 	ExecuteMessageLoopDelay: time.Millisecond * 100,
+	FeedInboxMismatch:       FeedInboxMismatchReorg,
 }
 
 var TestTransactionStreamerConfig = TransactionStreamerConfig{
 	MaxBroadcasterQueueSize: 10_000,
 	MaxReorgResequenceDepth: 128 * 1024,
 	ExecuteMessageLoopDelay: time.Millisecond,
+	FeedInboxMismatch:       FeedInboxMismatchReorg,
 }
 
 func TransactionStreamerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".max-broadcaster-queue-size", DefaultTransactionStreamerConfig.MaxBroadcasterQueueSize, "maximum cache of pending broadcaster messages")
 	f.Int64(prefix+".max-reorg-resequence-depth", DefaultTransactionStreamerConfig.MaxReorgResequenceDepth, "maximum number of messages to attempt to resequence on reorg (0 = never resequence, -1 = always resequence)")
 	f.Duration(prefix+".execute-message-loop-delay", DefaultTransactionStreamerConfig.ExecuteMessageLoopDelay, "delay when polling calls to execute messages")
+	f.String(prefix+".feed-inbox-mismatch", DefaultTransactionStreamerConfig.FeedInboxMismatch, "what to do when the inbox disagrees with a message already received, e.g. from the feed (\"reorg\" to replace it with the inbox message, \"fatal\" to stop the node)")
 }
 
 func NewTransactionStreamer(
@@ -110,6 +142,7 @@ func NewTransactionStreamer(
 		broadcastServer:    broadcastServer,
 		fatalErrChan:       fatalErrChan,
 		config:             config,
+		latencyTracker:     newMessageLatencyTracker(),
 	}
 	streamer.exec.SetTransactionStreamer(streamer)
 	err := streamer.cleanupInconsistentState()
@@ -193,6 +226,27 @@ func (s *TransactionStreamer) ReorgToAndEndBatch(batch ethdb.Batch, count arbuti
 	return nil
 }
 
+// ReexecuteFrom rolls execution back to the given message count without removing any messages,
+// so that the following messages are executed again
+func (s *TransactionStreamer) ReexecuteFrom(count arbutil.MessageIndex) error {
+	if count == 0 {
+		return errors.New("cannot re-execute init message")
+	}
+	s.insertionMutex.Lock()
+	defer s.insertionMutex.Unlock()
+	s.reorgMutex.Lock()
+	defer s.reorgMutex.Unlock()
+	log.Warn("rolling back execution to re-execute messages", "count", count)
+	err := s.exec.Reorg(count, nil, nil)
+	if err != nil {
+		return err
+	}
+	if s.validator != nil {
+		return s.validator.Reorg(s.GetContext(), count)
+	}
+	return nil
+}
+
 func deleteStartingAt(db ethdb.Database, batch ethdb.Batch, prefix []byte, minKey []byte) error {
 	iter := db.NewIterator(prefix, minKey)
 	defer iter.Release()
@@ -347,6 +401,8 @@ func (s *TransactionStreamer) reorg(batch ethdb.Batch, count arbutil.MessageInde
 		}
 	}
 
+	s.latencyTracker.reorg(count)
+
 	err = deleteStartingAt(s.db, batch, messagePrefix, uint64ToKey(uint64(count)))
 	if err != nil {
 		return err
@@ -429,6 +485,7 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*broadcaster.B
 	if len(feedMessages) == 0 {
 		return nil
 	}
+	receivedAt := time.Now()
 	broadcastStartPos := feedMessages[0].SequenceNumber
 	var messages []arbostypes.MessageWithMetadata
 	broadcastAfterPos := broadcastStartPos
@@ -455,6 +512,8 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*broadcaster.B
 	if err != nil {
 		return err
 	}
+	feedMessagesReceivedCounter.Inc(int64(len(messages)))
+	feedMessagesDuplicateCounter.Inc(int64(dups))
 	messages = messages[dups:]
 	broadcastStartPos += arbutil.MessageIndex(dups)
 	if oldMsg != nil {
@@ -464,6 +523,7 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*broadcaster.B
 		// No new messages received
 		return nil
 	}
+	s.latencyTracker.received(broadcastStartPos, len(messages), messageSourceFeed, receivedAt)
 
 	if len(s.broadcasterQueuedMessages) == 0 || (feedReorg && !s.broadcasterQueuedMessagesActiveReorg) {
 		// Empty cache or feed different from database, save current feed messages until confirmed L1 messages catch up.
@@ -516,7 +576,7 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*broadcaster.B
 		}
 	}
 
-	err = s.addMessagesAndEndBatchImpl(broadcastStartPos, false, nil, nil)
+	err = s.addMessagesAndEndBatchImpl(broadcastStartPos, false, 0, nil, nil, receivedAt)
 	if err != nil {
 		return fmt.Errorf("error adding pending broadcaster messages: %w", err)
 	}
@@ -524,14 +584,13 @@ func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*broadcaster.B
 	return nil
 }
 
-// AddFakeInitMessage should only be used for testing or running a local dev node
-func (s *TransactionStreamer) AddFakeInitMessage() error {
+func (s *TransactionStreamer) fakeInitMessage() (*arbostypes.MessageWithMetadata, error) {
 	chainConfigJson, err := json.Marshal(s.chainConfig)
 	if err != nil {
-		return fmt.Errorf("failed to serialize chain config: %w", err)
+		return nil, fmt.Errorf("failed to serialize chain config: %w", err)
 	}
 	msg := append(append(math.U256Bytes(s.chainConfig.ChainID), 0), chainConfigJson...)
-	return s.AddMessages(0, false, []arbostypes.MessageWithMetadata{{
+	return &arbostypes.MessageWithMetadata{
 		Message: &arbostypes.L1IncomingMessage{
 			Header: &arbostypes.L1IncomingMessageHeader{
 				Kind:      arbostypes.L1MessageType_Initialize,
@@ -541,7 +600,42 @@ func (s *TransactionStreamer) AddFakeInitMessage() error {
 			L2msg: msg,
 		},
 		DelayedMessagesRead: 1,
-	}})
+	}, nil
+}
+
+// AddFakeInitMessage should only be used for testing or running a local dev node
+func (s *TransactionStreamer) AddFakeInitMessage() error {
+	msg, err := s.fakeInitMessage()
+	if err != nil {
+		return err
+	}
+	return s.AddMessages(0, false, []arbostypes.MessageWithMetadata{*msg})
+}
+
+// CheckFakeInitMessage verifies that the first message in the database matches
+// the one AddFakeInitMessage would create for the current chain config.
+func (s *TransactionStreamer) CheckFakeInitMessage() error {
+	expected, err := s.fakeInitMessage()
+	if err != nil {
+		return err
+	}
+	actual, err := s.GetMessage(0)
+	if err != nil {
+		return fmt.Errorf("failed to read first message: %w", err)
+	}
+	if actual.Message == nil || actual.Message.Header == nil {
+		return errors.New("first message is missing its header")
+	}
+	if actual.Message.Header.Kind != expected.Message.Header.Kind {
+		return fmt.Errorf("first message has kind %v but expected an init message", actual.Message.Header.Kind)
+	}
+	if actual.DelayedMessagesRead != expected.DelayedMessagesRead {
+		return fmt.Errorf("first message read %v delayed messages but expected %v", actual.DelayedMessagesRead, expected.DelayedMessagesRead)
+	}
+	if !bytes.Equal(actual.Message.L2msg, expected.Message.L2msg) {
+		return errors.New("first message has a different chain id or chain config than the current one")
+	}
+	return nil
 }
 
 // Used in redis tests
@@ -559,6 +653,17 @@ func endBatch(batch ethdb.Batch) error {
 }
 
 func (s *TransactionStreamer) AddMessagesAndEndBatch(pos arbutil.MessageIndex, messagesAreConfirmed bool, messages []arbostypes.MessageWithMetadata, batch ethdb.Batch) error {
+	return s.addMessagesAndEndBatch(pos, messagesAreConfirmed, pos, messages, batch)
+}
+
+// AddBatchMessagesAndEndBatch adds messages confirmed by sequencer batches, where the first prevConfirmedCount
+// messages were already confirmed by earlier batches, so replacing one of those is a parent chain reorg and not a feed mismatch
+func (s *TransactionStreamer) AddBatchMessagesAndEndBatch(pos arbutil.MessageIndex, prevConfirmedCount arbutil.MessageIndex, messages []arbostypes.MessageWithMetadata, batch ethdb.Batch) error {
+	return s.addMessagesAndEndBatch(pos, true, prevConfirmedCount, messages, batch)
+}
+
+func (s *TransactionStreamer) addMessagesAndEndBatch(pos arbutil.MessageIndex, messagesAreConfirmed bool, prevConfirmedCount arbutil.MessageIndex, messages []arbostypes.MessageWithMetadata, batch ethdb.Batch) error {
+	receivedAt := time.Now()
 	if messagesAreConfirmed {
 		s.reorgMutex.RLock()
 		dups, _, _, err := s.countDuplicateMessages(pos, messages, nil)
@@ -578,7 +683,7 @@ func (s *TransactionStreamer) AddMessagesAndEndBatch(pos arbutil.MessageIndex, m
 	s.insertionMutex.Lock()
 	defer s.insertionMutex.Unlock()
 
-	return s.addMessagesAndEndBatchImpl(pos, messagesAreConfirmed, messages, batch)
+	return s.addMessagesAndEndBatchImpl(pos, messagesAreConfirmed, prevConfirmedCount, messages, batch, receivedAt)
 }
 
 func (s *TransactionStreamer) getPrevPrevDelayedRead(pos arbutil.MessageIndex) (uint64, error) {
@@ -690,7 +795,7 @@ func (s *TransactionStreamer) logReorg(pos arbutil.MessageIndex, dbMsg *arbostyp
 
 }
 
-func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil.MessageIndex, messagesAreConfirmed bool, messages []arbostypes.MessageWithMetadata, batch ethdb.Batch) error {
+func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil.MessageIndex, messagesAreConfirmed bool, prevConfirmedCount arbutil.MessageIndex, messages []arbostypes.MessageWithMetadata, batch ethdb.Batch, receivedAt time.Time) error {
 	var confirmedReorg bool
 	var oldMsg *arbostypes.MessageWithMetadata
 	var lastDelayedRead uint64
@@ -706,6 +811,8 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 		if err != nil {
 			return err
 		}
+		inboxMessagesReceivedCounter.Inc(int64(len(messages)))
+		inboxMessagesDuplicateCounter.Inc(int64(duplicates))
 		if duplicates > 0 {
 			lastDelayedRead = messages[duplicates-1].DelayedMessagesRead
 			messages = messages[duplicates:]
@@ -779,6 +886,20 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 	}
 
 	if confirmedReorg {
+		// replacing a message confirmed by an earlier batch is a parent chain reorg,
+		// otherwise the replaced message was received from the feed or sequenced locally
+		if messageStartPos >= prevConfirmedCount {
+			feedInboxMismatchCounter.Inc(1)
+			log.Error("inbox disagrees with a message already received", "pos", messageStartPos, "action", s.config().FeedInboxMismatch)
+			if s.config().FeedInboxMismatch == FeedInboxMismatchFatal {
+				err := fmt.Errorf("%w at message %v", ErrFeedInboxMismatch, messageStartPos)
+				select {
+				case s.fatalErrChan <- err:
+				default:
+				}
+				return err
+			}
+		}
 		reorgBatch := s.db.NewBatch()
 		err := s.reorg(reorgBatch, messageStartPos, messages)
 		if err != nil {
@@ -793,6 +914,8 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 		return endBatch(batch)
 	}
 
+	// queued feed messages were already tracked when received
+	s.latencyTracker.received(messageStartPos, len(messages), messageSourceInbox, receivedAt)
 	err := s.writeMessages(messageStartPos, messages, batch)
 	if err != nil {
 		return err
@@ -822,6 +945,7 @@ func (s *TransactionStreamer) ExpectChosenSequencer() error {
 }
 
 func (s *TransactionStreamer) WriteMessageFromSequencer(pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata) error {
+	receivedAt := time.Now()
 	if err := s.ExpectChosenSequencer(); err != nil {
 		return err
 	}
@@ -844,6 +968,7 @@ func (s *TransactionStreamer) WriteMessageFromSequencer(pos arbutil.MessageIndex
 			return err
 		}
 	}
+	s.latencyTracker.received(pos, 1, messageSourceLocal, receivedAt)
 
 	if err := s.writeMessages(pos, []arbostypes.MessageWithMetadata{msgWithMeta}, nil); err != nil {
 		return err
@@ -908,6 +1033,7 @@ func (s *TransactionStreamer) writeMessages(pos arbutil.MessageIndex, messages [
 	if err != nil {
 		return err
 	}
+	s.latencyTracker.written(pos, len(messages), time.Now())
 
 	select {
 	case s.newMessageNotifier <- struct{}{}:
@@ -956,6 +1082,18 @@ func (s *TransactionStreamer) executeNextMsg(ctx context.Context, exec *executio
 		return false
 	}
 	err = s.exec.DigestMessage(pos, msg)
+	if errors.Is(err, arbosState.ErrFatalNodeOutOfDate) {
+		// ArbOS refuses to execute the upgrade, retrying can't succeed
+		if !s.execOutOfDate {
+			s.execOutOfDate = true
+			log.Error("the chain requires an unsupported ArbOS version, upgrade the node", "err", err, "pos", pos)
+			select {
+			case s.fatalErrChan <- fmt.Errorf("%w: unable to execute message %v: %v", execution.ErrUnsupportedArbOSVersion, pos, err):
+			default:
+			}
+		}
+		return false
+	}
 	if err != nil {
 		logger := log.Warn
 		if prevMessageCount < msgCount {
@@ -964,6 +1102,7 @@ func (s *TransactionStreamer) executeNextMsg(ctx context.Context, exec *executio
 		logger("feedOneMsg failed to send message to execEngine", "err", err, "pos", pos)
 		return false
 	}
+	s.latencyTracker.executed(pos, time.Now())
 	return pos+1 < msgCount
 }
 
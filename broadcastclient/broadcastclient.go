@@ -37,6 +37,8 @@ import (
 var (
 	sourcesConnectedGauge    = metrics.NewRegisteredGauge("arb/feed/sources/connected", nil)
 	sourcesDisconnectedGauge = metrics.NewRegisteredGauge("arb/feed/sources/disconnected", nil)
+	schemeRejectedCounter    = metrics.NewRegisteredCounter("arb/feed/signature/scheme_rejected", nil)
+	oversizedMessageCounter  = metrics.NewRegisteredCounter("arb/feed/input/oversized", nil)
 )
 
 type FeedConfig struct {
@@ -45,6 +47,20 @@ type FeedConfig struct {
 }
 
 func (fc *FeedConfig) Validate() error {
+	if !broadcaster.IsSigningScheme(fc.Output.SigningScheme) {
+		return fmt.Errorf("invalid feed output signing-scheme %q, expected one of %v", fc.Output.SigningScheme, broadcaster.SigningSchemes)
+	}
+	if fc.Input.MaxMessageSize < 0 {
+		return errors.New("feed input max-message-size must not be negative")
+	}
+	if len(fc.Input.AcceptedSigningSchemes) == 0 {
+		return errors.New("feed input accepted-signing-schemes must not be empty")
+	}
+	for _, scheme := range fc.Input.AcceptedSigningSchemes {
+		if !broadcaster.IsSigningScheme(scheme) {
+			return fmt.Errorf("invalid feed input accepted-signing-schemes entry %q, expected one of %v", scheme, broadcaster.SigningSchemes)
+		}
+	}
 	return fc.Output.Validate()
 }
 
@@ -70,7 +86,9 @@ type Config struct {
 	Timeout                 time.Duration            `koanf:"timeout" reload:"hot"`
 	URL                     []string                 `koanf:"url"`
 	Verify                  signature.VerifierConfig `koanf:"verify"`
+	AcceptedSigningSchemes  []string                 `koanf:"accepted-signing-schemes" reload:"hot"`
 	EnableCompression       bool                     `koanf:"enable-compression" reload:"hot"`
+	MaxMessageSize          int64                    `koanf:"max-message-size" reload:"hot"`
 }
 
 func (c *Config) Enable() bool {
@@ -87,7 +105,9 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Duration(prefix+".timeout", DefaultConfig.Timeout, "duration to wait before timing out connection to sequencer feed")
 	f.StringSlice(prefix+".url", DefaultConfig.URL, "URL of sequencer feed source")
 	signature.FeedVerifierConfigAddOptions(prefix+".verify", f)
+	f.StringSlice(prefix+".accepted-signing-schemes", DefaultConfig.AcceptedSigningSchemes, "feed message signing schemes to accept, messages signed with another scheme are rejected like invalid signatures")
 	f.Bool(prefix+".enable-compression", DefaultConfig.EnableCompression, "enable per message deflate compression support")
+	f.Int64(prefix+".max-message-size", DefaultConfig.MaxMessageSize, "maximum size in bytes of a (decompressed) message from the feed, larger messages aren't buffered and the connection is reset (0 = no limit). The catchup backlog sent on connect is a single message")
 }
 
 var DefaultConfig = Config{
@@ -96,9 +116,11 @@ var DefaultConfig = Config{
 	RequireChainId:          false,
 	RequireFeedVersion:      false,
 	Verify:                  signature.DefultFeedVerifierConfig,
+	AcceptedSigningSchemes:  []string{broadcaster.SigningSchemeECDSA, broadcaster.SigningSchemeECDSAFeedDomain},
 	URL:                     []string{""},
 	Timeout:                 20 * time.Second,
 	EnableCompression:       true,
+	MaxMessageSize:          1024 * 1024 * 1024,
 }
 
 var DefaultTestConfig = Config{
@@ -107,9 +129,11 @@ var DefaultTestConfig = Config{
 	RequireChainId:          false,
 	RequireFeedVersion:      false,
 	Verify:                  signature.DefultFeedVerifierConfig,
+	AcceptedSigningSchemes:  []string{broadcaster.SigningSchemeECDSA, broadcaster.SigningSchemeECDSAFeedDomain},
 	URL:                     []string{""},
 	Timeout:                 200 * time.Millisecond,
 	EnableCompression:       true,
+	MaxMessageSize:          1024 * 1024 * 1024,
 }
 
 type TransactionStreamerInterface interface {
@@ -132,6 +156,10 @@ type BroadcastClient struct {
 
 	retryCount int64
 
+	// Use atomic access. lastReceivedTime is the unix nano time, or 0 if nothing was received yet.
+	lastReceivedSeqNum uint64
+	lastReceivedTime   int64
+
 	retrying                        bool
 	shuttingDown                    bool
 	confirmedSequenceNumberListener chan arbutil.MessageIndex
@@ -144,6 +172,7 @@ var ErrIncorrectFeedServerVersion = errors.New("incorrect feed server version")
 var ErrIncorrectChainId = errors.New("incorrect chain id")
 var ErrMissingChainId = errors.New("missing chain id")
 var ErrMissingFeedServerVersion = errors.New("missing feed server version")
+var ErrSigningSchemeNotAccepted = fmt.Errorf("%w: signing scheme not accepted", signature.ErrSignatureNotVerified)
 
 func NewBroadcastClient(
 	config ConfigFetcher,
@@ -342,12 +371,15 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 			var op ws.OpCode
 			var err error
 			config := bc.config()
-			msg, op, err = wsbroadcastserver.ReadData(ctx, bc.conn, earlyFrameData, config.Timeout, ws.StateClientSide, config.EnableCompression, flateReader)
+			msg, op, err = wsbroadcastserver.ReadData(ctx, bc.conn, earlyFrameData, config.Timeout, ws.StateClientSide, config.EnableCompression, flateReader, config.MaxMessageSize)
 			if err != nil {
 				if bc.isShuttingDown() {
 					return
 				}
-				if strings.Contains(err.Error(), "i/o timeout") {
+				if errors.Is(err, wsbroadcastserver.ErrMessageTooLarge) {
+					oversizedMessageCounter.Inc(1)
+					log.Error("Feed source sent a message over the size limit, reconnecting", "url", bc.websocketUrl, "maxMessageSize", config.MaxMessageSize)
+				} else if strings.Contains(err.Error(), "i/o timeout") {
 					log.Error("Server connection timed out without receiving data", "url", bc.websocketUrl, "err", err)
 				} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 					log.Warn("readData returned EOF", "url", bc.websocketUrl, "opcode", int(op), "err", err)
@@ -413,6 +445,8 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 							}
 
 							bc.nextSeqNum = message.SequenceNumber + 1
+							atomic.StoreUint64(&bc.lastReceivedSeqNum, uint64(message.SequenceNumber))
+							atomic.StoreInt64(&bc.lastReceivedTime, time.Now().UnixNano())
 						}
 						if err := bc.txStreamer.AddBroadcastMessages(res.Messages); err != nil {
 							log.Error("Error adding message from Sequencer Feed", "err", err)
@@ -427,6 +461,16 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 	})
 }
 
+// LastReceived returns the sequence number and receive time of the last valid message from the feed,
+// or false if none was received yet
+func (bc *BroadcastClient) LastReceived() (arbutil.MessageIndex, time.Time, bool) {
+	receivedTime := atomic.LoadInt64(&bc.lastReceivedTime)
+	if receivedTime == 0 {
+		return 0, time.Time{}, false
+	}
+	return arbutil.MessageIndex(atomic.LoadUint64(&bc.lastReceivedSeqNum)), time.Unix(0, receivedTime), true
+}
+
 func (bc *BroadcastClient) GetRetryCount() int64 {
 	return atomic.LoadInt64(&bc.retryCount)
 }
@@ -484,7 +528,20 @@ func (bc *BroadcastClient) isValidSignature(ctx context.Context, message *broadc
 		// Verifier disabled
 		return nil
 	}
-	hash, err := message.Hash(bc.chainId)
+	if len(message.Signature) > 0 {
+		accepted := false
+		for _, scheme := range bc.config().AcceptedSigningSchemes {
+			if message.SigningScheme() == scheme {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			schemeRejectedCounter.Inc(1)
+			return fmt.Errorf("%w: message signed with scheme %q, accepted schemes are %v (see accepted-signing-schemes)", ErrSigningSchemeNotAccepted, message.SigningScheme(), bc.config().AcceptedSigningSchemes)
+		}
+	}
+	hash, err := message.SigningHash(bc.chainId)
 	if err != nil {
 		return fmt.Errorf("error getting message hash for sequence number %v: %w", message.SequenceNumber, err)
 	}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"strconv"
 	"sync"
 	"testing"
@@ -29,6 +30,11 @@ import (
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
 
+func TestMain(m *testing.M) {
+	// tests check metric values
+	os.Exit(testhelpers.RunWithMetrics(m))
+}
+
 func TestReceiveMessagesWithoutCompression(t *testing.T) {
 	t.Parallel()
 	testReceiveMessages(t, false, false, false, false)
@@ -177,6 +183,126 @@ func TestInvalidSignature(t *testing.T) {
 	}
 }
 
+func TestSigningSchemeNotAccepted(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	settings := wsbroadcastserver.DefaultTestBroadcasterConfig
+	settings.SigningScheme = broadcaster.SigningSchemeECDSAFeedDomain
+
+	chainId := uint64(9742)
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	fatalErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &settings }, chainId, fatalErrChan, dataSigner)
+
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	config := DefaultTestConfig
+	config.AcceptedSigningSchemes = []string{broadcaster.SigningSchemeECDSA}
+
+	ts := NewDummyTransactionStreamer(chainId, &sequencerAddr)
+	broadcastClient, err := newTestBroadcastClient(
+		config,
+		b.ListenerAddr(),
+		chainId,
+		0,
+		ts,
+		nil,
+		fatalErrChan,
+		&sequencerAddr,
+	)
+	Require(t, err)
+	broadcastClient.Start(ctx)
+
+	go func() {
+		Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, 0))
+	}()
+
+	timer := time.NewTimer(2 * time.Second)
+	defer timer.Stop()
+	select {
+	case err := <-fatalErrChan:
+		if !errors.Is(err, ErrSigningSchemeNotAccepted) {
+			t.Errorf("unexpected error occurred: %v", err)
+		}
+	case <-timer.C:
+		t.Error("message with a signing scheme that isn't accepted wasn't rejected")
+	}
+}
+
+func TestOversizedMessageRejected(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	settings := wsbroadcastserver.DefaultTestBroadcasterConfig
+	chainId := uint64(9742)
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	fatalErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &settings }, chainId, fatalErrChan, dataSigner)
+
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	config := DefaultTestConfig
+	config.MaxMessageSize = 100
+
+	ts := NewDummyTransactionStreamer(chainId, &sequencerAddr)
+	broadcastClient, err := newTestBroadcastClient(
+		config,
+		b.ListenerAddr(),
+		chainId,
+		0,
+		ts,
+		nil,
+		fatalErrChan,
+		&sequencerAddr,
+	)
+	Require(t, err)
+	oversized := oversizedMessageCounter.Count()
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	go func() {
+		Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, 0))
+	}()
+
+	timer := time.NewTimer(10 * time.Second)
+	defer timer.Stop()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for oversizedMessageCounter.Count() == oversized {
+		select {
+		case msg := <-ts.messageReceiver:
+			t.Fatal("message over the size limit was received", msg.SequenceNumber)
+		case err := <-fatalErrChan:
+			t.Fatal("unexpected error", err)
+		case <-timer.C:
+			t.Fatal("message over the size limit wasn't rejected")
+		case <-ticker.C:
+		}
+	}
+	select {
+	case msg := <-ts.messageReceiver:
+		t.Fatal("message over the size limit was received", msg.SequenceNumber)
+	default:
+	}
+}
+
 type dummyTransactionStreamer struct {
 	messageReceiver chan broadcaster.BroadcastFeedMessage
 	chainId         uint64
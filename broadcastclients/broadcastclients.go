@@ -6,6 +6,7 @@ package broadcastclients
 import (
 	"context"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
@@ -71,6 +72,51 @@ func (bcs *BroadcastClients) adjustCount(delta int32) {
 	}
 }
 
+// Count returns the number of configured feeds
+func (bcs *BroadcastClients) Count() int32 {
+	return int32(len(bcs.clients))
+}
+
+// Connected returns the number of feeds currently connected
+func (bcs *BroadcastClients) Connected() int32 {
+	return atomic.LoadInt32(&bcs.connected)
+}
+
+// LastReceived returns the highest sequence number received from any feed and when the latest message was received,
+// or false if nothing was received yet
+func (bcs *BroadcastClients) LastReceived() (arbutil.MessageIndex, time.Time, bool) {
+	var seqNum arbutil.MessageIndex
+	var receivedTime time.Time
+	received := false
+	for _, client := range bcs.clients {
+		if client == nil {
+			continue
+		}
+		clientSeqNum, clientTime, ok := client.LastReceived()
+		if !ok {
+			continue
+		}
+		if !received || clientSeqNum > seqNum {
+			seqNum = clientSeqNum
+		}
+		if clientTime.After(receivedTime) {
+			receivedTime = clientTime
+		}
+		received = true
+	}
+	return seqNum, receivedTime, received
+}
+
+// Running returns true if any feed client is started and not yet stopped
+func (bcs *BroadcastClients) Running() bool {
+	for _, client := range bcs.clients {
+		if client != nil && client.Started() && !client.Stopped() {
+			return true
+		}
+	}
+	return false
+}
+
 func (bcs *BroadcastClients) Start(ctx context.Context) {
 	for _, client := range bcs.clients {
 		client.Start(ctx)
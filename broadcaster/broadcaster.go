@@ -5,24 +5,37 @@ package broadcaster
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"sync"
 
 	"github.com/gobwas/ws"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
 
 type Broadcaster struct {
+	config        wsbroadcastserver.BroadcasterConfigFetcher
 	server        *wsbroadcastserver.WSBroadcastServer
 	catchupBuffer *SequenceNumberCatchupBuffer
 	chainId       uint64
+
+	signerMutex   sync.RWMutex
 	dataSigner    signature.DataSignerFunc
+	signerAddress *common.Address // nil if unknown
+
+	// the most recently broadcast messages, only kept if enabled with RecordMessages
+	recordMutex sync.Mutex
+	record      *containers.LruCache[arbutil.MessageIndex, arbostypes.MessageWithMetadata]
 }
 
 // BroadcastMessage is the base message type for messages to send over the network.
@@ -47,15 +60,61 @@ type BroadcastMessage struct {
 }
 
 type BroadcastFeedMessage struct {
-	SequenceNumber arbutil.MessageIndex           `json:"sequenceNumber"`
-	Message        arbostypes.MessageWithMetadata `json:"message"`
-	Signature      []byte                         `json:"signature"`
+	SequenceNumber  arbutil.MessageIndex           `json:"sequenceNumber"`
+	Message         arbostypes.MessageWithMetadata `json:"message"`
+	Signature       []byte                         `json:"signature"`
+	SignatureScheme string                         `json:"signatureScheme,omitempty"`
+}
+
+const (
+	// signs the message hash, the only scheme known to older nodes, which leave the signature scheme empty
+	SigningSchemeECDSA = "ecdsa"
+	// signs the message hash prefixed with a feed specific domain,
+	// so the signature can't be mistaken for one over other data signed by the same key
+	SigningSchemeECDSAFeedDomain = "ecdsa-feed-domain"
+)
+
+var SigningSchemes = []string{SigningSchemeECDSA, SigningSchemeECDSAFeedDomain}
+
+var feedSigningDomain = []byte("arbitrum feed message")
+
+func IsSigningScheme(scheme string) bool {
+	for _, known := range SigningSchemes {
+		if scheme == known {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *BroadcastFeedMessage) Hash(chainId uint64) (common.Hash, error) {
 	return m.Message.Hash(m.SequenceNumber, chainId)
 }
 
+// SigningScheme returns the scheme the message is signed with, an empty scheme is SigningSchemeECDSA
+func (m *BroadcastFeedMessage) SigningScheme() string {
+	if m.SignatureScheme == "" {
+		return SigningSchemeECDSA
+	}
+	return m.SignatureScheme
+}
+
+// SigningHash returns the hash the message's signature is over, according to its signing scheme
+func (m *BroadcastFeedMessage) SigningHash(chainId uint64) (common.Hash, error) {
+	hash, err := m.Hash(chainId)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	switch m.SigningScheme() {
+	case SigningSchemeECDSA:
+		return hash, nil
+	case SigningSchemeECDSAFeedDomain:
+		return crypto.Keccak256Hash(feedSigningDomain, hash.Bytes()), nil
+	default:
+		return common.Hash{}, fmt.Errorf("unknown feed signing scheme %q", m.SignatureScheme)
+	}
+}
+
 type ConfirmedSequenceNumberMessage struct {
 	SequenceNumber arbutil.MessageIndex `json:"sequenceNumber"`
 }
@@ -63,6 +122,7 @@ type ConfirmedSequenceNumberMessage struct {
 func NewBroadcaster(config wsbroadcastserver.BroadcasterConfigFetcher, chainId uint64, feedErrChan chan error, dataSigner signature.DataSignerFunc) *Broadcaster {
 	catchupBuffer := NewSequenceNumberCatchupBuffer(func() bool { return config().LimitCatchup })
 	return &Broadcaster{
+		config:        config,
 		server:        wsbroadcastserver.NewWSBroadcastServer(config, catchupBuffer, chainId, feedErrChan),
 		catchupBuffer: catchupBuffer,
 		chainId:       chainId,
@@ -70,24 +130,64 @@ func NewBroadcaster(config wsbroadcastserver.BroadcasterConfigFetcher, chainId u
 	}
 }
 
+// RotateDataSigner switches to signing newly broadcast messages with dataSigner, which must sign as address.
+// Connected clients aren't affected, but must accept signatures by the new address.
+func (b *Broadcaster) RotateDataSigner(dataSigner signature.DataSignerFunc, address common.Address) error {
+	if dataSigner == nil {
+		return errors.New("no feed signer given")
+	}
+	testHash := crypto.Keccak256([]byte("feed signer rotation"), address.Bytes())
+	sig, err := dataSigner(testHash)
+	if err != nil {
+		return fmt.Errorf("new feed signer failed to sign: %w", err)
+	}
+	pubkey, err := crypto.SigToPub(testHash, sig)
+	if err != nil {
+		return fmt.Errorf("new feed signer produced an invalid signature: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != address {
+		return fmt.Errorf("new feed signer signs as %v, expected %v", signer, address)
+	}
+
+	b.signerMutex.Lock()
+	defer b.signerMutex.Unlock()
+	if b.dataSigner == nil {
+		return errors.New("feed output is not signed")
+	}
+	previous := b.signerAddress
+	b.dataSigner = dataSigner
+	b.signerAddress = &address
+	if previous != nil {
+		log.Info("rotated feed signer", "previous", *previous, "signer", address)
+	} else {
+		log.Info("rotated feed signer", "signer", address)
+	}
+	return nil
+}
+
 func (b *Broadcaster) NewBroadcastFeedMessage(message arbostypes.MessageWithMetadata, sequenceNumber arbutil.MessageIndex) (*BroadcastFeedMessage, error) {
-	var messageSignature []byte
-	if b.dataSigner != nil {
-		hash, err := message.Hash(sequenceNumber, b.chainId)
+	feedMessage := &BroadcastFeedMessage{
+		SequenceNumber: sequenceNumber,
+		Message:        message,
+	}
+	b.signerMutex.RLock()
+	dataSigner := b.dataSigner
+	b.signerMutex.RUnlock()
+	if dataSigner != nil {
+		// the default scheme is left out, so older clients keep working
+		if scheme := b.config().SigningScheme; scheme != SigningSchemeECDSA {
+			feedMessage.SignatureScheme = scheme
+		}
+		hash, err := feedMessage.SigningHash(b.chainId)
 		if err != nil {
 			return nil, err
 		}
-		messageSignature, err = b.dataSigner(hash.Bytes())
+		feedMessage.Signature, err = dataSigner(hash.Bytes())
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	return &BroadcastFeedMessage{
-		SequenceNumber: sequenceNumber,
-		Message:        message,
-		Signature:      messageSignature,
-	}, nil
+	return feedMessage, nil
 }
 
 func (b *Broadcaster) BroadcastSingle(msg arbostypes.MessageWithMetadata, seq arbutil.MessageIndex) error {
@@ -113,7 +213,31 @@ func (b *Broadcaster) BroadcastSingleFeedMessage(bfm *BroadcastFeedMessage) {
 	b.BroadcastFeedMessages(broadcastFeedMessages)
 }
 
+// RecordMessages keeps the last size broadcast messages, so they can later be compared to what's posted to the parent chain.
+func (b *Broadcaster) RecordMessages(size int) {
+	b.recordMutex.Lock()
+	defer b.recordMutex.Unlock()
+	b.record = containers.NewLruCache[arbutil.MessageIndex, arbostypes.MessageWithMetadata](size)
+}
+
+// RecordedMessage returns the message broadcast with the given sequence number, if it's still recorded.
+func (b *Broadcaster) RecordedMessage(seq arbutil.MessageIndex) (arbostypes.MessageWithMetadata, bool) {
+	b.recordMutex.Lock()
+	defer b.recordMutex.Unlock()
+	if b.record == nil {
+		return arbostypes.MessageWithMetadata{}, false
+	}
+	return b.record.Get(seq)
+}
+
 func (b *Broadcaster) BroadcastFeedMessages(messages []*BroadcastFeedMessage) {
+	b.recordMutex.Lock()
+	if b.record != nil {
+		for _, message := range messages {
+			b.record.Add(message.SequenceNumber, message.Message)
+		}
+	}
+	b.recordMutex.Unlock()
 
 	bm := BroadcastMessage{
 		Version:  1,
@@ -134,6 +258,10 @@ func (b *Broadcaster) ClientCount() int32 {
 	return b.server.ClientCount()
 }
 
+func (b *Broadcaster) Stats() wsbroadcastserver.FeedStats {
+	return b.server.Stats()
+}
+
 func (b *Broadcaster) ListenerAddr() net.Addr {
 	return b.server.ListenerAddr()
 }
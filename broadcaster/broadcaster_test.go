@@ -9,7 +9,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
+
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/testhelpers"
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
@@ -102,6 +105,46 @@ func TestBroadcasterMessagesRemovedOnConfirmation(t *testing.T) {
 		"clear all messages after confirmed 1 beyond latest"))
 }
 
+type statsPredicate struct {
+	b        *Broadcaster
+	expected uint64
+	was      uint64
+}
+
+func (p *statsPredicate) Test() bool {
+	p.was = p.b.Stats().TotalMessages
+	return p.was == p.expected
+}
+
+func (p *statsPredicate) Error() string {
+	return fmt.Sprintf("Expected %d broadcast messages, was %d", p.expected, p.was)
+}
+
+func TestBroadcasterStats(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+
+	feedErrChan := make(chan error, 10)
+	b := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, 5555, feedErrChan, nil)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	Require(t, b.BroadcastSingle(arbostypes.EmptyTestMessageWithMetadata, 1))
+	b.Confirm(1)
+	waitUntilUpdated(t, &statsPredicate{b: b, expected: 2})
+
+	stats := b.Stats()
+	if stats.Subscribers != 0 {
+		Fail(t, "unexpected subscribers", stats.Subscribers)
+	}
+	if stats.TotalBytes != 0 {
+		Fail(t, "bytes counted without any subscribers", stats.TotalBytes)
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
@@ -111,3 +154,36 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestBroadcasterRotateDataSigner(t *testing.T) {
+	chainId := uint64(5555)
+	config := wsbroadcastserver.DefaultTestBroadcasterConfig
+	feedErrChan := make(chan error, 10)
+
+	oldKey, err := crypto.GenerateKey()
+	Require(t, err)
+	newKey, err := crypto.GenerateKey()
+	Require(t, err)
+	newAddress := crypto.PubkeyToAddress(newKey.PublicKey)
+
+	unsigned := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, nil)
+	if unsigned.RotateDataSigner(signature.DataSignerFromPrivateKey(newKey), newAddress) == nil {
+		Fail(t, "rotated signer of unsigned feed")
+	}
+
+	b := NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config }, chainId, feedErrChan, signature.DataSignerFromPrivateKey(oldKey))
+	if b.RotateDataSigner(signature.DataSignerFromPrivateKey(oldKey), newAddress) == nil {
+		Fail(t, "rotated to signer not matching the given address")
+	}
+	Require(t, b.RotateDataSigner(signature.DataSignerFromPrivateKey(newKey), newAddress))
+
+	msg, err := b.NewBroadcastFeedMessage(arbostypes.EmptyTestMessageWithMetadata, 1)
+	Require(t, err)
+	hash, err := msg.Hash(chainId)
+	Require(t, err)
+	pubkey, err := crypto.SigToPub(hash.Bytes(), msg.Signature)
+	Require(t, err)
+	if crypto.PubkeyToAddress(*pubkey) != newAddress {
+		Fail(t, "message not signed by the new signer")
+	}
+}
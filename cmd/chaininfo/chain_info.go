@@ -6,7 +6,9 @@ package chaininfo
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 
@@ -17,6 +19,8 @@ import (
 //go:embed arbitrum_chain_info.json
 var DefaultChainInfo []byte
 
+var ErrChainInfoTooLarge = errors.New("chain info too large")
+
 type ChainInfo struct {
 	ChainName             string `json:"chain-name"`
 	ParentChainId         uint64 `json:"parent-chain-id"`
@@ -28,10 +32,18 @@ type ChainInfo struct {
 	HasGenesisState bool                `json:"has-genesis-state"`
 	ChainConfig     *params.ChainConfig `json:"chain-config"`
 	RollupAddresses *RollupAddresses    `json:"rollup"`
+	// If set, enables the gas price oracle by default with these parameters, see rpc.gas-price-oracle
+	GasPriceOracle *GasPriceOracleInfo `json:"gas-price-oracle"`
+}
+
+type GasPriceOracleInfo struct {
+	Blocks     int  `json:"blocks"`
+	Percentile *int `json:"percentile"`
 }
 
-func GetChainConfig(chainId *big.Int, chainName string, genesisBlockNum uint64, l2ChainInfoFiles []string, l2ChainInfoJson string) (*params.ChainConfig, error) {
-	chainInfo, err := ProcessChainInfo(chainId.Uint64(), chainName, l2ChainInfoFiles, l2ChainInfoJson)
+// GetChainConfig returns the chain config from the chain info, read within the limits of ProcessChainInfoWithLimits
+func GetChainConfig(chainId *big.Int, chainName string, genesisBlockNum uint64, l2ChainInfoFiles []string, l2ChainInfoJson string, maxSources int, maxBytes int64) (*params.ChainConfig, error) {
+	chainInfo, err := ProcessChainInfoWithLimits(chainId.Uint64(), chainName, l2ChainInfoFiles, l2ChainInfoJson, maxSources, maxBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +57,22 @@ func GetChainConfig(chainId *big.Int, chainName string, genesisBlockNum uint64,
 	return nil, fmt.Errorf("missing chain config for L2 chain name %v", chainName)
 }
 
-func GetRollupAddressesConfig(chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string) (RollupAddresses, error) {
-	chainInfo, err := ProcessChainInfo(chainId, chainName, l2ChainInfoFiles, l2ChainInfoJson)
+// GetGenesisBlockNum returns the L2 genesis block number set in the chain info, which is non-zero for chains migrated from classic,
+// read within the limits of ProcessChainInfoWithLimits
+func GetGenesisBlockNum(chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string, maxSources int, maxBytes int64) (uint64, error) {
+	chainInfo, err := ProcessChainInfoWithLimits(chainId, chainName, l2ChainInfoFiles, l2ChainInfoJson, maxSources, maxBytes)
+	if err != nil {
+		return 0, err
+	}
+	if chainInfo.ChainConfig == nil {
+		return 0, nil
+	}
+	return chainInfo.ChainConfig.ArbitrumChainParams.GenesisBlockNum, nil
+}
+
+// GetRollupAddressesConfig returns the rollup addresses from the chain info, read within the limits of ProcessChainInfoWithLimits
+func GetRollupAddressesConfig(chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string, maxSources int, maxBytes int64) (RollupAddresses, error) {
+	chainInfo, err := ProcessChainInfoWithLimits(chainId, chainName, l2ChainInfoFiles, l2ChainInfoJson, maxSources, maxBytes)
 	if err != nil {
 		return RollupAddresses{}, err
 	}
@@ -60,6 +86,24 @@ func GetRollupAddressesConfig(chainId uint64, chainName string, l2ChainInfoFiles
 }
 
 func ProcessChainInfo(chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string) (*ChainInfo, error) {
+	return ProcessChainInfoWithLimits(chainId, chainName, l2ChainInfoFiles, l2ChainInfoJson, 0, 0)
+}
+
+// ProcessChainInfoWithLimits is ProcessChainInfo failing if more than maxSources chain info sources are given,
+// or if the chain info read from them is larger than maxBytes in total.
+// The embedded default chain info doesn't count towards the limits, and a limit of 0 disables it.
+func ProcessChainInfoWithLimits(chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string, maxSources int, maxBytes int64) (*ChainInfo, error) {
+	sources := len(l2ChainInfoFiles)
+	if l2ChainInfoJson != "" {
+		sources++
+	}
+	if maxSources > 0 && sources > maxSources {
+		return nil, fmt.Errorf("too many chain info sources: %v given, at most %v allowed (see --chain.info-max-sources)", sources, maxSources)
+	}
+	remainingBytes := maxBytes - int64(len(l2ChainInfoJson))
+	if maxBytes > 0 && remainingBytes < 0 {
+		return nil, fmt.Errorf("%w: info-json has %v bytes, at most %v allowed (see --chain.info-max-bytes)", ErrChainInfoTooLarge, len(l2ChainInfoJson), maxBytes)
+	}
 	if l2ChainInfoJson != "" {
 		chainInfo, err := findChainInfo(chainId, chainName, []byte(l2ChainInfoJson))
 		if err != nil || chainInfo != nil {
@@ -67,7 +111,14 @@ func ProcessChainInfo(chainId uint64, chainName string, l2ChainInfoFiles []strin
 		}
 	}
 	for _, l2ChainInfoFile := range l2ChainInfoFiles {
-		chainsInfoBytes, err := os.ReadFile(l2ChainInfoFile)
+		var chainsInfoBytes []byte
+		var err error
+		if maxBytes > 0 {
+			chainsInfoBytes, err = readFileWithLimit(l2ChainInfoFile, remainingBytes)
+			remainingBytes -= int64(len(chainsInfoBytes))
+		} else {
+			chainsInfoBytes, err = os.ReadFile(l2ChainInfoFile)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to read file %s err %w", l2ChainInfoFile, err)
 		}
@@ -87,6 +138,23 @@ func ProcessChainInfo(chainId uint64, chainName string, l2ChainInfoFiles []strin
 	return nil, fmt.Errorf("unsupported chain name %v", chainName)
 }
 
+// readFileWithLimit reads the file, failing without reading further once it's larger than limit bytes
+func readFileWithLimit(name string, limit int64) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: the chain info exceeds the remaining %v bytes (see --chain.info-max-bytes)", ErrChainInfoTooLarge, limit)
+	}
+	return data, nil
+}
+
 func findChainInfo(chainId uint64, chainName string, chainsInfoBytes []byte) (*ChainInfo, error) {
 	var chainsInfo []ChainInfo
 	err := json.Unmarshal(chainsInfoBytes, &chainsInfo)
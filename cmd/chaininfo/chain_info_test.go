@@ -0,0 +1,73 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package chaininfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestProcessChainInfoWithLimits(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		file := filepath.Join(dir, name)
+		// 100 bytes of chain info without the requested chain
+		testhelpers.RequireImpl(t, os.WriteFile(file, []byte("["+strings.Repeat(" ", 98)+"]"), 0600))
+		files = append(files, file)
+	}
+	process := func(maxSources int, maxBytes int64) error {
+		_, err := ProcessChainInfoWithLimits(0, "arb1", files, "[]", maxSources, maxBytes)
+		return err
+	}
+
+	testhelpers.RequireImpl(t, process(4, 302))
+	testhelpers.RequireImpl(t, process(0, 0))
+	if process(3, 0) == nil {
+		testhelpers.FailImpl(t, "too many chain info sources accepted")
+	}
+	if err := process(0, 301); !errors.Is(err, ErrChainInfoTooLarge) {
+		testhelpers.FailImpl(t, "too large chain info accepted", err)
+	}
+	if err := process(0, 1); !errors.Is(err, ErrChainInfoTooLarge) {
+		testhelpers.FailImpl(t, "too large info-json accepted", err)
+	}
+	if _, err := ProcessChainInfoWithLimits(0, "arb1", []string{filepath.Join(dir, "missing.json")}, "", 0, 0); err == nil {
+		testhelpers.FailImpl(t, "missing chain info file accepted")
+	}
+
+	// the chain info getters read within the same limits
+	if _, err := GetGenesisBlockNum(0, "arb1", files, "[]", 0, 301); !errors.Is(err, ErrChainInfoTooLarge) {
+		testhelpers.FailImpl(t, "too large chain info accepted reading the genesis block number", err)
+	}
+	if _, err := GetRollupAddressesConfig(0, "arb1", files, "[]", 3, 0); err == nil {
+		testhelpers.FailImpl(t, "too many chain info sources accepted reading the rollup addresses")
+	}
+	if _, err := GetChainConfig(common.Big0, "arb1", 0, files, "[]", 0, 301); !errors.Is(err, ErrChainInfoTooLarge) {
+		testhelpers.FailImpl(t, "too large chain info accepted reading the chain config", err)
+	}
+	_, err := GetChainConfig(common.Big0, "arb1", 0, files, "[]", 4, 302)
+	testhelpers.RequireImpl(t, err)
+}
+
+func TestReadFileWithLimit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "info.json")
+	testhelpers.RequireImpl(t, os.WriteFile(file, make([]byte, 100), 0600))
+
+	data, err := readFileWithLimit(file, 100)
+	testhelpers.RequireImpl(t, err)
+	if len(data) != 100 {
+		testhelpers.FailImpl(t, "unexpected data length", len(data))
+	}
+	if _, err := readFileWithLimit(file, 99); !errors.Is(err, ErrChainInfoTooLarge) {
+		testhelpers.FailImpl(t, "file larger than the limit accepted", err)
+	}
+}
@@ -4,6 +4,7 @@
 package conf
 
 import (
+	"errors"
 	"time"
 
 	"github.com/offchainlabs/nitro/cmd/genericconf"
@@ -15,6 +16,20 @@ type L1Config struct {
 	ID         uint64                   `koanf:"id"`
 	Connection rpcclient.ClientConfig   `koanf:"connection" reload:"hot"`
 	Wallet     genericconf.WalletConfig `koanf:"wallet"`
+	Dangerous  L1DangerousConfig        `koanf:"dangerous"`
+	ReadOnly   bool                     `koanf:"read-only"`
+}
+
+type L1DangerousConfig struct {
+	AllowChainIdMismatch bool `koanf:"allow-chainid-mismatch"`
+}
+
+var DefaultL1DangerousConfig = L1DangerousConfig{
+	AllowChainIdMismatch: false,
+}
+
+func L1DangerousConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".allow-chainid-mismatch", DefaultL1DangerousConfig.AllowChainIdMismatch, "DANGEROUS! only warn instead of exiting if the parent chain id doesn't match parent-chain.id")
 }
 
 var L1ConnectionConfigDefault = rpcclient.ClientConfig{
@@ -22,12 +37,15 @@ var L1ConnectionConfigDefault = rpcclient.ClientConfig{
 	Retries:        2,
 	Timeout:        time.Minute,
 	ConnectionWait: time.Minute,
+	Throttle:       rpcclient.DefaultThrottleConfig,
 }
 
 var L1ConfigDefault = L1Config{
 	ID:         0,
 	Connection: L1ConnectionConfigDefault,
 	Wallet:     DefaultL1WalletConfig,
+	Dangerous:  DefaultL1DangerousConfig,
+	ReadOnly:   false,
 }
 
 var DefaultL1WalletConfig = genericconf.WalletConfig{
@@ -36,12 +54,15 @@ var DefaultL1WalletConfig = genericconf.WalletConfig{
 	PrivateKey:    genericconf.WalletConfigDefault.PrivateKey,
 	Account:       genericconf.WalletConfigDefault.Account,
 	OnlyCreateKey: genericconf.WalletConfigDefault.OnlyCreateKey,
+	BackupDir:     genericconf.WalletConfigDefault.BackupDir,
 }
 
 func L1ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Uint64(prefix+".id", L1ConfigDefault.ID, "if set other than 0, will be used to validate database and L1 connection")
 	rpcclient.RPCClientAddOptions(prefix+".connection", f, &L1ConfigDefault.Connection)
 	genericconf.WalletConfigAddOptions(prefix+".wallet", f, L1ConfigDefault.Wallet.Pathname)
+	L1DangerousConfigAddOptions(prefix+".dangerous", f)
+	f.Bool(prefix+".read-only", L1ConfigDefault.ReadOnly, "only read from the parent chain: refuse to start components that send parent chain transactions, to open a parent chain wallet, and make anything but known read calls over the connection")
 }
 
 func (c *L1Config) ResolveDirectoryNames(chain string) {
@@ -60,6 +81,8 @@ type L2Config struct {
 	DevWallet            genericconf.WalletConfig `koanf:"dev-wallet"`
 	InfoIpfsUrl          string                   `koanf:"info-ipfs-url"`
 	InfoIpfsDownloadPath string                   `koanf:"info-ipfs-download-path"`
+	InfoMaxSources       int                      `koanf:"info-max-sources"`
+	InfoMaxBytes         int64                    `koanf:"info-max-bytes"`
 }
 
 var L2ConfigDefault = L2Config{
@@ -70,19 +93,32 @@ var L2ConfigDefault = L2Config{
 	DevWallet:            genericconf.WalletConfigDefault,
 	InfoIpfsUrl:          "",
 	InfoIpfsDownloadPath: "/tmp/",
+	InfoMaxSources:       16,
+	InfoMaxBytes:         16 * 1024 * 1024,
 }
 
 func L2ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Uint64(prefix+".id", L2ConfigDefault.ID, "L2 chain ID (determines Arbitrum network)")
 	f.String(prefix+".name", L2ConfigDefault.Name, "L2 chain name (determines Arbitrum network)")
 	f.StringSlice(prefix+".info-files", L2ConfigDefault.InfoFiles, "L2 chain info json files")
-	f.String(prefix+".info-json", L2ConfigDefault.InfoJson, "L2 chain info in json string format")
+	f.String(prefix+".info-json", L2ConfigDefault.InfoJson, "L2 chain info in json string format (\"-\" to read it from stdin)")
 
 	// Dev wallet does not exist unless specified
 	genericconf.WalletConfigAddOptions(prefix+".dev-wallet", f, "")
 	f.String(prefix+".info-ipfs-url", L2ConfigDefault.InfoIpfsUrl, "url to download chain info file")
 	f.String(prefix+".info-ipfs-download-path", L2ConfigDefault.InfoIpfsDownloadPath, "path to save temp downloaded file")
+	f.Int(prefix+".info-max-sources", L2ConfigDefault.InfoMaxSources, "maximum number of chain info sources (info-json, info-files and the ipfs file) to load (0 = no limit)")
+	f.Int64(prefix+".info-max-bytes", L2ConfigDefault.InfoMaxBytes, "maximum total size in bytes of the chain info loaded from all sources (0 = no limit)")
+}
 
+func (c *L2Config) Validate() error {
+	if c.InfoMaxSources < 0 {
+		return errors.New("chain.info-max-sources must not be negative")
+	}
+	if c.InfoMaxBytes < 0 {
+		return errors.New("chain.info-max-bytes must not be negative")
+	}
+	return nil
 }
 
 func (c *L2Config) ResolveDirectoryNames(chain string) {
@@ -18,6 +18,7 @@ type PersistentConfig struct {
 	LogDir       string `koanf:"log-dir"`
 	Handles      int    `koanf:"handles"`
 	Ancient      string `koanf:"ancient"`
+	InMemory     bool   `koanf:"in-memory"`
 }
 
 var PersistentConfigDefault = PersistentConfig{
@@ -26,6 +27,7 @@ var PersistentConfigDefault = PersistentConfig{
 	LogDir:       "",
 	Handles:      512,
 	Ancient:      "",
+	InMemory:     false,
 }
 
 func PersistentConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -34,6 +36,7 @@ func PersistentConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".log-dir", PersistentConfigDefault.LogDir, "directory to store log file")
 	f.Int(prefix+".handles", PersistentConfigDefault.Handles, "number of file descriptor handles to use for the database")
 	f.String(prefix+".ancient", PersistentConfigDefault.Ancient, "directory of ancient where the chain freezer can be opened")
+	f.Bool(prefix+".in-memory", PersistentConfigDefault.InMemory, "keep all databases in memory instead of on disk; all chain data is lost on exit")
 }
 
 func (c *PersistentConfig) ResolveDirectoryNames() error {
@@ -39,6 +39,7 @@ type DAServerConfig struct {
 	RESTAddr           string                              `koanf:"rest-addr"`
 	RESTPort           uint64                              `koanf:"rest-port"`
 	RESTServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"rest-server-timeouts"`
+	RESTServerHeaders  das.RestfulServerHeadersConfig      `koanf:"rest-server-headers"`
 
 	DataAvailability das.DataAvailabilityConfig `koanf:"data-availability"`
 
@@ -60,6 +61,7 @@ var DefaultDAServerConfig = DAServerConfig{
 	RESTAddr:           "localhost",
 	RESTPort:           9877,
 	RESTServerTimeouts: genericconf.HTTPServerTimeoutConfigDefault,
+	RESTServerHeaders:  das.DefaultRestfulServerHeadersConfig,
 	DataAvailability:   das.DefaultDataAvailabilityConfig,
 	Conf:               genericconf.ConfConfigDefault,
 	Metrics:            false,
@@ -91,6 +93,7 @@ func parseDAServer(args []string) (*DAServerConfig, error) {
 	f.String("rest-addr", DefaultDAServerConfig.RESTAddr, "REST server listening interface")
 	f.Uint64("rest-port", DefaultDAServerConfig.RESTPort, "REST server listening port")
 	genericconf.HTTPServerTimeoutConfigAddOptions("rest-server-timeouts", f)
+	das.RestfulServerHeadersConfigAddOptions("rest-server-headers", f)
 
 	f.Bool("metrics", DefaultDAServerConfig.Metrics, "enable metrics")
 	genericconf.MetricsServerAddOptions("metrics-server", f)
@@ -112,7 +115,7 @@ func parseDAServer(args []string) (*DAServerConfig, error) {
 		return nil, err
 	}
 	if serverConfig.Conf.Dump {
-		err = confighelpers.DumpConfig(k, map[string]interface{}{
+		err = confighelpers.DumpConfig(k, f, map[string]interface{}{
 			"data-availability.key.priv-key": "",
 		})
 		if err != nil {
@@ -147,7 +150,7 @@ func (c *L1ReaderCloser) String() string {
 // Checks metrics and PProf flag, runs them if enabled.
 // Note: they are separate so one can enable/disable them as they wish, the only
 // requirement is that they can't run on the same address and port.
-func startMetrics(cfg *DAServerConfig) error {
+func startMetrics(ctx context.Context, cfg *DAServerConfig) error {
 	mAddr := fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port)
 	pAddr := fmt.Sprintf("%v:%v", cfg.PprofCfg.Addr, cfg.PprofCfg.Port)
 	if cfg.Metrics && !metrics.Enabled {
@@ -163,6 +166,9 @@ func startMetrics(cfg *DAServerConfig) error {
 	if cfg.PProf {
 		genericconf.StartPprof(pAddr)
 	}
+	if err := genericconf.StartPeriodicProfiles(ctx, &cfg.PprofCfg.PeriodicProfiles); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -182,16 +188,16 @@ func startup() error {
 	glogger.Verbosity(log.Lvl(serverConfig.LogLevel))
 	log.Root().SetHandler(glogger)
 
-	if err := startMetrics(serverConfig); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := startMetrics(ctx, serverConfig); err != nil {
 		return err
 	}
 
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	var l1Reader *headerreader.HeaderReader
 	if serverConfig.DataAvailability.ParentChainNodeURL != "" && serverConfig.DataAvailability.ParentChainNodeURL != "none" {
 		l1Client, err := das.GetL1Client(ctx, serverConfig.DataAvailability.ParentChainConnectionAttempts, serverConfig.DataAvailability.ParentChainNodeURL)
@@ -245,7 +251,7 @@ func startup() error {
 	if serverConfig.EnableREST {
 		log.Info("Starting REST server", "addr", serverConfig.RESTAddr, "port", serverConfig.RESTPort, "revision", vcsRevision, "vcs.time", vcsTime)
 
-		restServer, err = das.NewRestfulDasServer(serverConfig.RESTAddr, serverConfig.RESTPort, serverConfig.RESTServerTimeouts, daReader, daHealthChecker)
+		restServer, err = das.NewRestfulDasServer(serverConfig.RESTAddr, serverConfig.RESTPort, serverConfig.RESTServerTimeouts, serverConfig.RESTServerHeaders, daReader, daHealthChecker)
 		if err != nil {
 			return err
 		}
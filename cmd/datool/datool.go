@@ -16,7 +16,6 @@ import (
 	"strings"
 	"time"
 
-	koanfjson "github.com/knadh/koanf/parsers/json"
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -333,9 +332,16 @@ func parseDumpKeyset(args []string) (*DumpKeysetConfig, error) {
 	}
 
 	if config.Conf.Dump {
-		c, err := k.Marshal(koanfjson.Parser())
+		if config.Conf.DumpRedactedOnly {
+			// the keyset config has no secrets
+			k, err = confighelpers.RedactedOnlyConfig(k, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		c, err := confighelpers.FormatConfig(k, f, config.Conf.DumpFormat, config.Conf.DumpOmitDefaults)
 		if err != nil {
-			return nil, fmt.Errorf("unable to marshal config file to JSON: %w", err)
+			return nil, err
 		}
 
 		fmt.Println(string(c))
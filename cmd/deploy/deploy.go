@@ -53,6 +53,7 @@ func main() {
 	authorizevalidators := flag.Uint64("authorizevalidators", 0, "Number of validators to preemptively authorize")
 	txTimeout := flag.Duration("txtimeout", 10*time.Minute, "Timeout when waiting for a transaction to be included in a block")
 	prod := flag.Bool("prod", false, "Whether to configure the rollup for production or testing")
+	confirmations := flag.Uint64("confirmations", 0, "Number of L1 confirmations of the rollup creation block to wait for before writing the deployment")
 	flag.Parse()
 	l1ChainId := new(big.Int).SetUint64(*l1ChainIdUint)
 
@@ -144,6 +145,7 @@ func main() {
 		sequencerAddress,
 		*authorizevalidators,
 		arbnode.GenerateRollupConfig(*prod, moduleRoot, ownerAddress, &chainConfig, chainConfigJson, loserEscrowAddress),
+		*confirmations,
 	)
 	if err != nil {
 		flag.Usage()
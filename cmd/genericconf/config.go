@@ -5,6 +5,7 @@ package genericconf
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -13,30 +14,42 @@ import (
 )
 
 type ConfConfig struct {
-	Dump           bool          `koanf:"dump"`
-	EnvPrefix      string        `koanf:"env-prefix"`
-	File           []string      `koanf:"file"`
-	S3             S3Config      `koanf:"s3"`
-	String         string        `koanf:"string"`
-	ReloadInterval time.Duration `koanf:"reload-interval" reload:"hot"`
+	Dump             bool          `koanf:"dump"`
+	DumpFormat       string        `koanf:"dump-format"`
+	DumpOmitDefaults bool          `koanf:"dump-omit-defaults"`
+	DumpRedactedOnly bool          `koanf:"dump-redacted-only"`
+	EnvPrefix        string        `koanf:"env-prefix"`
+	File             []string      `koanf:"file"`
+	S3               S3Config      `koanf:"s3"`
+	String           string        `koanf:"string"`
+	ReloadInterval   time.Duration `koanf:"reload-interval" reload:"hot"`
+	Strict           bool          `koanf:"strict"`
 }
 
 func ConfConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".dump", ConfConfigDefault.Dump, "print out currently active configuration file")
+	f.String(prefix+".dump-format", ConfConfigDefault.DumpFormat, "format of the configuration dump: \"json\", \"json-pretty\" or \"flat\" (one sorted key=value per line, for diffing)")
+	f.Bool(prefix+".dump-omit-defaults", ConfConfigDefault.DumpOmitDefaults, "only include values differing from their defaults in the configuration dump")
+	f.Bool(prefix+".dump-redacted-only", ConfConfigDefault.DumpRedactedOnly, "only include the redacted secrets in the configuration dump, showing \"REDACTED\" for those that are set")
 	f.String(prefix+".env-prefix", ConfConfigDefault.EnvPrefix, "environment variables with given prefix will be loaded as configuration values")
-	f.StringSlice(prefix+".file", ConfConfigDefault.File, "name of configuration file")
+	f.StringSlice(prefix+".file", ConfConfigDefault.File, "name of configuration file, may be repeated to layer files with later files overriding earlier ones (conf.string, command line options and environment variables override all files)")
 	S3ConfigAddOptions(prefix+".s3", f)
 	f.String(prefix+".string", ConfConfigDefault.String, "configuration as JSON string")
 	f.Duration(prefix+".reload-interval", ConfConfigDefault.ReloadInterval, "how often to reload configuration (0=disable periodic reloading)")
+	f.Bool(prefix+".strict", ConfConfigDefault.Strict, "fail to start if the configuration contains any unknown keys, listing all of them")
 }
 
 var ConfConfigDefault = ConfConfig{
-	Dump:           false,
-	EnvPrefix:      "",
-	File:           nil,
-	S3:             DefaultS3Config,
-	String:         "",
-	ReloadInterval: 0,
+	Dump:             false,
+	DumpFormat:       "json",
+	DumpOmitDefaults: false,
+	DumpRedactedOnly: false,
+	EnvPrefix:        "",
+	File:             nil,
+	S3:               DefaultS3Config,
+	String:           "",
+	ReloadInterval:   0,
+	Strict:           false,
 }
 
 type S3Config struct {
@@ -106,17 +119,94 @@ func FileLoggingConfigAddOptions(prefix string, f *flag.FlagSet) {
 }
 
 type RpcConfig struct {
-	MaxBatchResponseSize int `koanf:"max-batch-response-size"`
+	MaxBatchResponseSize int                  `koanf:"max-batch-response-size"`
+	ErrorDetail          string               `koanf:"error-detail"`
+	ArchiveFallbackUrl   string               `koanf:"archive-fallback-url"`
+	PrunedStateDetails   bool                 `koanf:"pruned-state-details"`
+	MaxInFlightRequests  int                  `koanf:"max-in-flight-requests" reload:"hot"`
+	ReceiptCacheSize     int                  `koanf:"receipt-cache-size"`
+	ReceiptCacheTTL      time.Duration        `koanf:"receipt-cache-ttl"`
+	GasPriceOracle       GasPriceOracleConfig `koanf:"gas-price-oracle"`
 }
 
 var DefaultRpcConfig = RpcConfig{
 	MaxBatchResponseSize: 10_000_000, // 10MB
+	ErrorDetail:          RpcErrorDetailFull,
+	ArchiveFallbackUrl:   "",
+	PrunedStateDetails:   false,
+	MaxInFlightRequests:  0,
+	ReceiptCacheSize:     0,
+	ReceiptCacheTTL:      5 * time.Second,
+	GasPriceOracle:       DefaultGasPriceOracleConfig,
 }
 
+// Apply must be run once at startup, before the go-ethereum stack is set up (ethereum/go-ethereum/node.New).
+// Only max-in-flight-requests can be changed later, with SetMaxInFlightRequests.
 func (c *RpcConfig) Apply() {
 	rpc.MaxBatchResponseSize = c.MaxBatchResponseSize
+	SetMaxInFlightRequests(c.MaxInFlightRequests)
+	rpcErrorsRedacted.Store(c.ErrorDetail == RpcErrorDetailGeneric)
+	wrapRpcHandler()
+}
+
+func (c *RpcConfig) Validate() error {
+	if c.ErrorDetail != RpcErrorDetailFull && c.ErrorDetail != RpcErrorDetailGeneric {
+		return fmt.Errorf("invalid rpc.error-detail %q, must be %q or %q", c.ErrorDetail, RpcErrorDetailFull, RpcErrorDetailGeneric)
+	}
+	if c.MaxInFlightRequests < 0 {
+		return fmt.Errorf("invalid rpc.max-in-flight-requests %v, must not be negative", c.MaxInFlightRequests)
+	}
+	if c.ReceiptCacheSize < 0 {
+		return fmt.Errorf("invalid rpc.receipt-cache-size %v, must not be negative", c.ReceiptCacheSize)
+	}
+	if c.ReceiptCacheSize > 0 && c.ReceiptCacheTTL <= 0 {
+		return fmt.Errorf("invalid rpc.receipt-cache-ttl %v, must be positive", c.ReceiptCacheTTL)
+	}
+	return c.GasPriceOracle.Validate()
 }
 
 func RpcConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".max-batch-response-size", DefaultRpcConfig.MaxBatchResponseSize, "the maximum response size for a JSON-RPC request measured in bytes (-1 means no limit)")
+	f.String(prefix+".error-detail", DefaultRpcConfig.ErrorDetail, "detail of JSON-RPC errors returned over http and websocket: \"full\" returns the original errors, \"generic\" replaces internal errors with a generic message and an id that is logged along with the original error")
+	f.Int(prefix+".max-in-flight-requests", DefaultRpcConfig.MaxInFlightRequests, "the maximum number of JSON-RPC calls over http and websocket processed at once, further calls are rejected with a busy error until one finishes (0 = no limit)")
+	f.String(prefix+".archive-fallback-url", DefaultRpcConfig.ArchiveFallbackUrl, "url of an archive node that historical eth_call, eth_getBalance, eth_getCode, eth_getStorageAt and eth_getTransactionCount requests are forwarded to if the state isn't available locally")
+	f.Int(prefix+".receipt-cache-size", DefaultRpcConfig.ReceiptCacheSize, "number of transaction receipts to cache for repeated eth_getTransactionReceipt requests (0 = disabled)")
+	f.Duration(prefix+".receipt-cache-ttl", DefaultRpcConfig.ReceiptCacheTTL, "how long a transaction receipt is served from the receipt cache")
+	f.Bool(prefix+".pruned-state-details", DefaultRpcConfig.PrunedStateDetails, "on a non-archive node, fail those historical requests whose state was pruned (and the archive fallback failed, if set) with an error telling the oldest block with state, instead of the generic missing state error")
+	GasPriceOracleConfigAddOptions(prefix+".gas-price-oracle", f)
+}
+
+// GasPriceOracleConfig sets how eth_gasPrice and eth_maxPriorityFeePerGas are computed,
+// chain info can set different defaults per chain
+type GasPriceOracleConfig struct {
+	Enable     bool `koanf:"enable"`
+	Blocks     int  `koanf:"blocks"`
+	Percentile int  `koanf:"percentile"`
+}
+
+const GasPriceOracleMaxBlocks = 1024
+
+var DefaultGasPriceOracleConfig = GasPriceOracleConfig{
+	Enable:     false,
+	Blocks:     20,
+	Percentile: 60,
+}
+
+func GasPriceOracleConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultGasPriceOracleConfig.Enable, "compute eth_gasPrice and eth_maxPriorityFeePerGas from the fees of recent blocks, instead of the default gas price oracle")
+	f.Int(prefix+".blocks", DefaultGasPriceOracleConfig.Blocks, "number of recent blocks the gas price oracle looks at")
+	f.Int(prefix+".percentile", DefaultGasPriceOracleConfig.Percentile, "percentile of the recent base fees and priority fees suggested by the gas price oracle")
+}
+
+func (c *GasPriceOracleConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Blocks <= 0 || c.Blocks > GasPriceOracleMaxBlocks {
+		return fmt.Errorf("invalid rpc.gas-price-oracle.blocks %v, must be between 1 and %v", c.Blocks, GasPriceOracleMaxBlocks)
+	}
+	if c.Percentile < 0 || c.Percentile > 100 {
+		return fmt.Errorf("invalid rpc.gas-price-oracle.percentile %v, must be between 0 and 100", c.Percentile)
+	}
+	return nil
 }
@@ -0,0 +1,86 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	rpcInFlightGauge           = metrics.NewRegisteredGauge("arb/rpc/inflight", nil)
+	rpcInFlightRejectedCounter = metrics.NewRegisteredCounter("arb/rpc/inflight/rejected", nil)
+)
+
+// the JSON-RPC "limit exceeded" error, passed through even if errors are redacted
+const (
+	rpcBusyErrorCode    = -32005
+	rpcBusyErrorMessage = "node is busy: too many requests in flight, try again later"
+)
+
+// the busy error for http requests, sent with http status 429
+const rpcBusyResponse = `{"jsonrpc":"2.0","id":null,"error":{"code":-32005,"message":"` + rpcBusyErrorMessage + `"}}` + "\n"
+
+// inFlightLimiter rejects JSON-RPC calls over a limit of concurrent calls, shared by all http and websocket servers
+type inFlightLimiter struct {
+	limit    atomic.Int64 // 0 means no limit
+	inFlight atomic.Int64
+}
+
+var rpcInFlightLimiter inFlightLimiter
+
+// SetMaxInFlightRequests changes the limit of concurrent JSON-RPC calls, e.g. on config reload
+func SetMaxInFlightRequests(limit int) {
+	rpcInFlightLimiter.limit.Store(int64(limit))
+}
+
+// tryAcquire returns false if the limit of in-flight requests is reached
+func (l *inFlightLimiter) tryAcquire() bool {
+	inFlight := l.inFlight.Add(1)
+	if limit := l.limit.Load(); limit > 0 && inFlight > limit {
+		l.inFlight.Add(-1)
+		return false
+	}
+	rpcInFlightGauge.Update(inFlight)
+	return true
+}
+
+func (l *inFlightLimiter) release() {
+	l.releaseN(1)
+}
+
+func (l *inFlightLimiter) releaseN(count int64) {
+	rpcInFlightGauge.Update(l.inFlight.Add(-count))
+}
+
+type rpcBusyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcBusyMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcBusyError    `json:"error"`
+}
+
+// rpcBusyResponses answers the calls of a rejected websocket message with busy errors
+func rpcBusyResponses(msgs []rpcMessageHeader, batch bool) ([]byte, error) {
+	var responses []rpcBusyMessage
+	for _, msg := range msgs {
+		if msg.isCall() {
+			responses = append(responses, rpcBusyMessage{
+				Version: "2.0",
+				ID:      msg.ID,
+				Error:   rpcBusyError{Code: rpcBusyErrorCode, Message: rpcBusyErrorMessage},
+			})
+		}
+	}
+	if batch {
+		return json.Marshal(responses)
+	}
+	return json.Marshal(responses[0])
+}
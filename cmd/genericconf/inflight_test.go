@@ -0,0 +1,179 @@
+package genericconf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	inner := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+	limiter := &inFlightLimiter{}
+	limiter.limit.Store(1)
+	server := httptest.NewServer(&rpcHandler{inner: inner, limiter: limiter})
+	defer server.Close()
+
+	post := func() (*http.Response, error) {
+		return http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`))
+	}
+	firstDone := make(chan error, 1)
+	go func() {
+		resp, err := post()
+		if err == nil {
+			err = resp.Body.Close()
+		}
+		firstDone <- err
+	}()
+	<-started
+
+	// the busy error is passed through even when errors are redacted
+	rpcErrorsRedacted.Store(true)
+	resp, err := post()
+	rpcErrorsRedacted.Store(false)
+	testhelpers.RequireImpl(t, err)
+	body, err := io.ReadAll(resp.Body)
+	testhelpers.RequireImpl(t, err)
+	testhelpers.RequireImpl(t, resp.Body.Close())
+	if resp.StatusCode != http.StatusTooManyRequests {
+		testhelpers.FailImpl(t, "request over the limit not rejected, status", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), rpcBusyErrorMessage) {
+		testhelpers.FailImpl(t, "busy error not passed through, got", string(body))
+	}
+
+	// raising the limit, as on config reload, lets requests through again
+	limiter.limit.Store(2)
+	secondDone := make(chan error, 1)
+	go func() {
+		resp, err := post()
+		if err == nil {
+			err = resp.Body.Close()
+		}
+		secondDone <- err
+	}()
+	<-started
+	close(release)
+	testhelpers.RequireImpl(t, <-firstDone)
+	testhelpers.RequireImpl(t, <-secondDone)
+	if limiter.inFlight.Load() != 0 {
+		testhelpers.FailImpl(t, "requests still counted as in flight", limiter.inFlight.Load())
+	}
+}
+
+type waitService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *waitService) Wait() {
+	s.started <- struct{}{}
+	<-s.release
+}
+
+func (s *waitService) Ping() string {
+	return "pong"
+}
+
+func TestInFlightLimiterWebsocket(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	service := &waitService{started: make(chan struct{}), release: make(chan struct{})}
+	rpcServer := rpc.NewServer()
+	testhelpers.RequireImpl(t, rpcServer.RegisterName("test", service))
+	defer rpcServer.Stop()
+	limiter := &inFlightLimiter{}
+	limiter.limit.Store(1)
+	server := httptest.NewServer(&rpcHandler{inner: rpcServer.WebsocketHandler([]string{"*"}), limiter: limiter})
+	defer server.Close()
+	rpcErrorsRedacted.Store(true)
+	defer rpcErrorsRedacted.Store(false)
+
+	client, err := rpc.DialWebsocket(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), "")
+	testhelpers.RequireImpl(t, err)
+	defer client.Close()
+
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- client.CallContext(ctx, nil, "test_wait")
+	}()
+	<-service.started
+
+	// rejected with the busy error, which isn't redacted
+	err = client.CallContext(ctx, nil, "test_wait")
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != rpcBusyErrorCode || rpcErr.Error() != rpcBusyErrorMessage {
+		testhelpers.FailImpl(t, "websocket call over the limit not rejected with the busy error, got", err)
+	}
+
+	// the rejected call didn't take a slot, and the answered one releases its slot
+	close(service.release)
+	testhelpers.RequireImpl(t, <-firstDone)
+	if limiter.inFlight.Load() != 0 {
+		testhelpers.FailImpl(t, "websocket calls still counted as in flight", limiter.inFlight.Load())
+	}
+}
+
+func TestInFlightLimiterWebsocketBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	service := &waitService{started: make(chan struct{}), release: make(chan struct{})}
+	rpcServer := rpc.NewServer()
+	testhelpers.RequireImpl(t, rpcServer.RegisterName("test", service))
+	defer rpcServer.Stop()
+	limiter := &inFlightLimiter{}
+	limiter.limit.Store(2)
+	server := httptest.NewServer(&rpcHandler{inner: rpcServer.WebsocketHandler([]string{"*"}), limiter: limiter})
+	defer server.Close()
+
+	client, err := rpc.DialWebsocket(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), "")
+	testhelpers.RequireImpl(t, err)
+	defer client.Close()
+
+	// a batch with more calls than the limit holds a single slot, as over http
+	batch := make([]rpc.BatchElem, 5)
+	results := make([]string, len(batch))
+	for i := range batch {
+		batch[i] = rpc.BatchElem{Method: "test_ping", Result: &results[i]}
+	}
+	testhelpers.RequireImpl(t, client.BatchCallContext(ctx, batch))
+	for i, elem := range batch {
+		if elem.Error != nil || results[i] != "pong" {
+			testhelpers.FailImpl(t, "call", i, "of a batch over the limit rejected on an idle node", elem.Error)
+		}
+	}
+	if limiter.inFlight.Load() != 0 {
+		testhelpers.FailImpl(t, "answered batch still counted as in flight", limiter.inFlight.Load())
+	}
+
+	// with the other slot taken, the batch is still let through
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- client.CallContext(ctx, nil, "test_wait")
+	}()
+	<-service.started
+	testhelpers.RequireImpl(t, client.BatchCallContext(ctx, batch))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			testhelpers.FailImpl(t, "call", i, "of a batch rejected with a free slot", elem.Error)
+		}
+	}
+	close(service.release)
+	testhelpers.RequireImpl(t, <-firstDone)
+	if limiter.inFlight.Load() != 0 {
+		testhelpers.FailImpl(t, "websocket messages still counted as in flight", limiter.inFlight.Load())
+	}
+}
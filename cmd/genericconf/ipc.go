@@ -0,0 +1,194 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	ipcActiveConnectionsGauge     = metrics.NewRegisteredGauge("arb/ipc/connections/active", nil)
+	ipcRejectedConnectionsCounter = metrics.NewRegisteredCounter("arb/ipc/connections/rejected", nil)
+	ipcOversizedRequestsCounter   = metrics.NewRegisteredCounter("arb/ipc/requests/oversized", nil)
+)
+
+var errIPCRequestTooLarge = errors.New("ipc request too large")
+
+// LimitedIPC serves the IPC endpoint on behalf of the node, enforcing the connection and
+// request size limits of IPCConfig. The node itself listens on a socket in a private
+// directory, and every accepted connection is forwarded to it.
+type LimitedIPC struct {
+	stopwaiter.StopWaiter
+	config      IPCConfig
+	path        string // the endpoint's location, resolved as the node would
+	backendDir  string
+	backendPath string
+	listener    net.Listener
+	connections atomic.Int64
+}
+
+// NewLimitedIPC moves the node's IPC endpoint to a private socket if config has limits,
+// and returns nil otherwise. It must be called after config.Apply and before the node is created.
+func NewLimitedIPC(config *IPCConfig, stackConf *node.Config) (*LimitedIPC, error) {
+	if !config.Limited() {
+		return nil, nil
+	}
+	// a bare file name is placed in the data directory
+	path := stackConf.IPCEndpoint()
+	backendDir, err := os.MkdirTemp("", "nitro-ipc-")
+	if err != nil {
+		return nil, err
+	}
+	backendPath := filepath.Join(backendDir, "node.ipc")
+	stackConf.IPCPath = backendPath
+	return &LimitedIPC{
+		config:      *config,
+		path:        path,
+		backendDir:  backendDir,
+		backendPath: backendPath,
+	}, nil
+}
+
+func (l *LimitedIPC) Start(ctx context.Context) error {
+	// remove a stale socket left over by an unclean shutdown, like the node would
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	listener, err := net.Listen("unix", l.path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(l.path, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+	l.listener = listener
+	l.StopWaiter.Start(ctx, l)
+	l.LaunchThread(func(ctx context.Context) {
+		<-ctx.Done()
+		listener.Close()
+	})
+	l.LaunchThread(l.acceptLoop)
+	log.Info("IPC endpoint opened", "url", l.path, "maxConnections", l.config.MaxConnections, "maxRequestSize", l.config.MaxRequestSize)
+	return nil
+}
+
+func (l *LimitedIPC) StopAndWait() {
+	l.StopWaiter.StopAndWait()
+	if err := os.RemoveAll(l.backendDir); err != nil {
+		log.Warn("failed to remove private IPC directory", "dir", l.backendDir, "err", err)
+	}
+}
+
+func (l *LimitedIPC) acceptLoop(ctx context.Context) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("IPC accept failed", "err", err)
+			}
+			return
+		}
+		active := l.connections.Add(1)
+		if l.config.MaxConnections > 0 && active > int64(l.config.MaxConnections) {
+			l.connections.Add(-1)
+			ipcRejectedConnectionsCounter.Inc(1)
+			log.Warn("rejecting IPC connection, too many connections", "maxConnections", l.config.MaxConnections)
+			conn.Close()
+			continue
+		}
+		ipcActiveConnectionsGauge.Update(active)
+		l.LaunchUntrackedThread(func() {
+			defer func() {
+				ipcActiveConnectionsGauge.Update(l.connections.Add(-1))
+			}()
+			l.serve(ctx, conn)
+		})
+	}
+}
+
+func (l *LimitedIPC) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	var dialer net.Dialer
+	backend, err := dialer.DialContext(ctx, "unix", l.backendPath)
+	if err != nil {
+		log.Warn("failed to connect IPC connection to node", "err", err)
+		return
+	}
+	defer backend.Close()
+	go func() {
+		_, _ = io.Copy(conn, backend)
+		conn.Close()
+	}()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	if l.config.MaxRequestSize == 0 {
+		_, _ = io.Copy(backend, conn)
+		return
+	}
+	err = forwardLimitedRequests(backend, conn, int64(l.config.MaxRequestSize))
+	if errors.Is(err, errIPCRequestTooLarge) {
+		ipcOversizedRequestsCounter.Inc(1)
+		log.Warn("closing IPC connection after oversized request", "maxRequestSize", l.config.MaxRequestSize)
+	}
+}
+
+// requestLimitReader fails once more than remaining bytes are read
+type requestLimitReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *requestLimitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, errIPCRequestTooLarge
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// forwardLimitedRequests copies the JSON requests read from src to dst one by one,
+// failing with errIPCRequestTooLarge on a request larger than maxSize
+func forwardLimitedRequests(dst io.Writer, src io.Reader, maxSize int64) error {
+	limited := &requestLimitReader{reader: src}
+	decoder := json.NewDecoder(limited)
+	for {
+		// data the decoder already buffered was counted against the previous request
+		limited.remaining = maxSize
+		var request json.RawMessage
+		if err := decoder.Decode(&request); err != nil {
+			return err
+		}
+		if int64(len(request)) > maxSize {
+			return errIPCRequestTooLarge
+		}
+		if _, err := dst.Write(request); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package genericconf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestLimitedIPCPath(t *testing.T) {
+	dataDir := t.TempDir()
+	config := IPCConfig{Path: "nitro.ipc", MaxConnections: 1}
+	stackConf := node.DefaultConfig
+	stackConf.DataDir = dataDir
+	config.Apply(&stackConf)
+	limitedIPC, err := NewLimitedIPC(&config, &stackConf)
+	testhelpers.RequireImpl(t, err)
+	if limitedIPC == nil {
+		testhelpers.FailImpl(t, "no limited IPC endpoint despite limits")
+	}
+	if stackConf.IPCPath != limitedIPC.backendPath {
+		testhelpers.FailImpl(t, "node not moved to the private socket", stackConf.IPCPath)
+	}
+
+	// a bare file name is placed in the data directory, as the node would place it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	testhelpers.RequireImpl(t, limitedIPC.Start(ctx))
+	info, err := os.Stat(filepath.Join(dataDir, "nitro.ipc"))
+	testhelpers.RequireImpl(t, err)
+	if info.Mode()&os.ModeSocket == 0 {
+		testhelpers.FailImpl(t, "IPC endpoint isn't a socket", info.Mode())
+	}
+	limitedIPC.StopAndWait()
+	if _, err := os.Stat(limitedIPC.backendDir); !os.IsNotExist(err) {
+		testhelpers.FailImpl(t, "private IPC directory not removed", err)
+	}
+}
+
+func TestForwardLimitedRequests(t *testing.T) {
+	small := `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`
+	large := `{"jsonrpc":"2.0","id":2,"method":"eth_call","params":["` + strings.Repeat("ff", 100) + `"]}`
+
+	var forwarded bytes.Buffer
+	err := forwardLimitedRequests(&forwarded, strings.NewReader(small+"\n"+small), 100)
+	if !errors.Is(err, io.EOF) {
+		testhelpers.FailImpl(t, "unexpected error", err)
+	}
+	if forwarded.String() != small+small {
+		testhelpers.FailImpl(t, "unexpected forwarded requests", forwarded.String())
+	}
+
+	forwarded.Reset()
+	err = forwardLimitedRequests(&forwarded, strings.NewReader(small+large+small), 100)
+	if !errors.Is(err, errIPCRequestTooLarge) {
+		testhelpers.FailImpl(t, "oversized request not rejected", err)
+	}
+	if forwarded.String() != small {
+		testhelpers.FailImpl(t, "unexpected forwarded requests", forwarded.String())
+	}
+}
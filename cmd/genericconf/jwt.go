@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -30,8 +31,16 @@ func TryCreatingJWTSecret(filename string) error {
 			log.Error("failed to close file", "err", err)
 		}
 	}()
+	if err := writeJWTSecret(f); err != nil {
+		return err
+	}
+	log.Info("created jwt file", "filename", filename)
+	return nil
+}
+
+func writeJWTSecret(f *os.File) error {
 	secret := common.Hash{}
-	_, err = rand.Read(secret[:])
+	_, err := rand.Read(secret[:])
 	if err != nil {
 		return fmt.Errorf("couldn't generate secret: %w", err)
 	}
@@ -39,6 +48,52 @@ func TryCreatingJWTSecret(filename string) error {
 	if err != nil {
 		return fmt.Errorf("couldn't writeto file: %w", err)
 	}
-	log.Info("created jwt file", "filename", filename)
 	return nil
 }
+
+var ErrInvalidJWTSecret = errors.New("invalid jwt secret")
+
+// CheckJWTSecret returns an error if the file doesn't hold a JWT secret the auth RPC accepts,
+// i.e. 32 bytes hex encoded with an optional 0x prefix
+func CheckJWTSecret(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("couldn't read jwt secret file: %w", err)
+	}
+	secret := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	if len(secret) != 2*common.HashLength {
+		return fmt.Errorf("%w in %v: expected %v hex characters, found %v", ErrInvalidJWTSecret, filename, 2*common.HashLength, len(secret))
+	}
+	for _, c := range secret {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return fmt.Errorf("%w in %v: %q isn't a hex character", ErrInvalidJWTSecret, filename, c)
+		}
+	}
+	return nil
+}
+
+// PrepareJWTSecret makes sure the file holds a valid JWT secret. If it doesn't, the secret is replaced by a new one
+// if regenerateOnInvalid is set, or else an error is returned telling how to fix it.
+func PrepareJWTSecret(filename string, regenerateOnInvalid bool) error {
+	err := CheckJWTSecret(filename)
+	if err == nil || !errors.Is(err, ErrInvalidJWTSecret) {
+		return err
+	}
+	if !regenerateOnInvalid {
+		return fmt.Errorf("%w, fix or delete the file, or set --auth.regenerate-jwt-on-invalid to replace it with a new secret", err)
+	}
+	log.Warn("replacing invalid jwt secret with a new one, clients of the auth RPC need the new secret", "filename", filename, "err", err)
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("couldn't create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := writeJWTSecret(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
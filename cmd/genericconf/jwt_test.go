@@ -0,0 +1,28 @@
+package genericconf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestPrepareJWTSecret(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "jwtsecret")
+	testhelpers.RequireImpl(t, TryCreatingJWTSecret(filename))
+	testhelpers.RequireImpl(t, CheckJWTSecret(filename))
+	testhelpers.RequireImpl(t, PrepareJWTSecret(filename, false))
+
+	testhelpers.RequireImpl(t, os.WriteFile(filename, []byte("0x1234\n"), 0600))
+	if err := PrepareJWTSecret(filename, false); !errors.Is(err, ErrInvalidJWTSecret) {
+		testhelpers.FailImpl(t, "short jwt secret accepted, err", err)
+	}
+	testhelpers.RequireImpl(t, PrepareJWTSecret(filename, true))
+	testhelpers.RequireImpl(t, CheckJWTSecret(filename))
+
+	if err := PrepareJWTSecret(filepath.Join(t.TempDir(), "missing"), true); err == nil || errors.Is(err, ErrInvalidJWTSecret) {
+		testhelpers.FailImpl(t, "unexpected error for missing jwt secret file", err)
+	}
+}
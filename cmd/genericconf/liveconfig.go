@@ -2,16 +2,48 @@ package genericconf
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/offchainlabs/nitro/util/colors"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
+var (
+	configReloadAttemptsCounter   = metrics.NewRegisteredCounter("arb/config/reload/attempts", nil)
+	configReloadParseErrorCounter = metrics.NewRegisteredCounter("arb/config/reload/parse_errors", nil)
+	configReloadRejectedCounter   = metrics.NewRegisteredCounter("arb/config/reload/rejected", nil)
+	configReloadHookErrorCounter  = metrics.NewRegisteredCounter("arb/config/reload/hook_errors", nil)
+	configReloadSuccessCounter    = metrics.NewRegisteredCounter("arb/config/reload/success", nil)
+	configReloadLastSuccessGauge  = metrics.NewRegisteredGauge("arb/config/reload/last_success_timestamp", nil)
+)
+
+// IllegalChangeError is returned by CanReload when a field that can't be hot reloaded changed
+type IllegalChangeError struct {
+	Path string
+}
+
+func (e *IllegalChangeError) Error() string {
+	return fmt.Sprintf("illegal change to %v%v%v", colors.Red, e.Path, colors.Clear)
+}
+
+func countRejectedReload(err error) {
+	configReloadRejectedCounter.Inc(1)
+	var illegalChange *IllegalChangeError
+	if errors.As(err, &illegalChange) {
+		field := strings.ToLower(strings.ReplaceAll(illegalChange.Path, ".", "/"))
+		metrics.GetOrRegisterCounter("arb/config/reload/rejected/"+field, nil).Inc(1)
+	}
+}
+
 type ConfigConstrain[T any] interface {
 	CanReload(T) error
 	GetReloadInterval() time.Duration
@@ -46,13 +78,17 @@ func (c *LiveConfig[T]) Set(config T) error {
 	defer c.mutex.Unlock()
 
 	if err := c.config.CanReload(config); err != nil {
+		countRejectedReload(err)
 		return err
 	}
 	if err := c.onReloadHook(c.config, config); err != nil {
 		// TODO(magic) panic? return err? only log the error?
 		log.Error("Failed to execute onReloadHook", "err", err)
+		configReloadHookErrorCounter.Inc(1)
 	}
 	c.config = config
+	configReloadSuccessCounter.Inc(1)
+	configReloadLastSuccessGauge.Update(time.Now().Unix())
 	return nil
 }
 
@@ -84,8 +120,10 @@ func (c *LiveConfig[T]) Start(ctxIn context.Context) {
 				case <-timer.C:
 				}
 			}
+			configReloadAttemptsCounter.Inc(1)
 			nodeConfig, err := c.parse(ctx, c.args)
 			if err != nil {
+				configReloadParseErrorCounter.Inc(1)
 				log.Error("error parsing live config", "error", err.Error())
 				continue
 			}
@@ -5,13 +5,35 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var globalFileHandlerFactory = fileHandlerFactory{}
 
+var (
+	logErrorsCounter   = metrics.NewRegisteredCounter("arb/log/errors", nil)
+	logWarningsCounter = metrics.NewRegisteredCounter("arb/log/warnings", nil)
+)
+
+// counted separately from the metrics, which are no-ops unless metrics are enabled
+var loggedErrors, loggedWarnings atomic.Int64
+
+// LoggedErrorsCount returns how many records of level error or above were logged since startup
+func LoggedErrorsCount() int64 {
+	return loggedErrors.Load()
+}
+
+// LoggedWarningsCount returns how many warnings were logged since startup
+func LoggedWarningsCount() int64 {
+	return loggedWarnings.Load()
+}
+
 type fileHandlerFactory struct {
 	writer  *lumberjack.Logger
 	records chan *log.Record
@@ -75,8 +97,56 @@ func (l *fileHandlerFactory) close() error {
 	return nil
 }
 
+// ResolveNodeName returns the configured node name, defaulting to the hostname
+func ResolveNodeName(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return hostname
+}
+
+var invalidMetricNameChars = regexp.MustCompile("[^a-z0-9_]")
+
+// RegisterNodeNameMetric exports the node name as an info metric, since exported metrics don't support labels
+func RegisterNodeNameMetric(nodeName string) {
+	if nodeName == "" {
+		return
+	}
+	name := invalidMetricNameChars.ReplaceAllString(strings.ToLower(nodeName), "_")
+	metrics.NewRegisteredGauge("arb/node/name/"+name, nil).Update(1)
+}
+
+// withNodeName adds the node name to the context of every log record
+func withNodeName(nodeName string, handler log.Handler) log.Handler {
+	if nodeName == "" {
+		return handler
+	}
+	return log.FuncHandler(func(r *log.Record) error {
+		r.Ctx = append([]interface{}{"node", nodeName}, r.Ctx...)
+		return handler.Log(r)
+	})
+}
+
+// withLevelCounters counts the error and warning records passed to handler
+func withLevelCounters(handler log.Handler) log.Handler {
+	return log.FuncHandler(func(r *log.Record) error {
+		if r.Lvl <= log.LvlError {
+			loggedErrors.Add(1)
+			logErrorsCounter.Inc(1)
+		} else if r.Lvl == log.LvlWarn {
+			loggedWarnings.Add(1)
+			logWarningsCounter.Inc(1)
+		}
+		return handler.Log(r)
+	})
+}
+
 // initLog is not threadsafe
-func InitLog(logType string, logLevel log.Lvl, fileLoggingConfig *FileLoggingConfig, pathResolver func(string) string) error {
+func InitLog(logType string, logLevel log.Lvl, fileLoggingConfig *FileLoggingConfig, pathResolver func(string) string, nodeName string) error {
 	logFormat, err := ParseLogType(logType)
 	if err != nil {
 		flag.Usage()
@@ -88,16 +158,16 @@ func InitLog(logType string, logLevel log.Lvl, fileLoggingConfig *FileLoggingCon
 		return fmt.Errorf("failed to close file writer: %w", err)
 	}
 	if fileLoggingConfig.Enable {
-		glogger = log.NewGlogHandler(
+		glogger = log.NewGlogHandler(withLevelCounters(withRecentErrors(withNodeName(nodeName,
 			log.MultiHandler(
 				log.StreamHandler(os.Stderr, logFormat),
 				// on overflow records are dropped silently as MultiHandler ignores errors
 				globalFileHandlerFactory.newHandler(logFormat, fileLoggingConfig, pathResolver(fileLoggingConfig.File)),
-			))
+			)))))
 	} else {
-		glogger = log.NewGlogHandler(log.StreamHandler(os.Stderr, logFormat))
+		glogger = log.NewGlogHandler(withLevelCounters(withRecentErrors(withNodeName(nodeName, log.StreamHandler(os.Stderr, logFormat)))))
 	}
-	glogger.Verbosity(logLevel)
+	globalLogLevel.setHandler(glogger, logLevel)
 	log.Root().SetHandler(glogger)
 	return nil
 }
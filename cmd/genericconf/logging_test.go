@@ -0,0 +1,50 @@
+package genericconf
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestLoggedLevelCounts(t *testing.T) {
+	testhelpers.RequireImpl(t, InitLog("plaintext", log.LvlInfo, &FileLoggingConfig{}, func(path string) string { return path }, ""))
+	errorsBefore := LoggedErrorsCount()
+	warningsBefore := LoggedWarningsCount()
+	log.Info("not counted")
+	log.Warn("counted warning")
+	log.Error("counted error")
+	log.Error("another counted error")
+	if errors := LoggedErrorsCount() - errorsBefore; errors != 2 {
+		testhelpers.FailImpl(t, "unexpected logged errors count", errors)
+	}
+	if warnings := LoggedWarningsCount() - warningsBefore; warnings != 1 {
+		testhelpers.FailImpl(t, "unexpected logged warnings count", warnings)
+	}
+}
+
+func TestRecentErrors(t *testing.T) {
+	testhelpers.RequireImpl(t, InitLog("plaintext", log.LvlInfo, &FileLoggingConfig{}, func(path string) string { return path }, ""))
+	log.Warn("not kept")
+	log.Error("first error", "attempt", 1)
+	log.Error("second error", "attempt", 2)
+	api := &RecentErrorsAPI{}
+	count := 2
+	events := api.RecentErrors(context.Background(), &count)
+	if len(events) != 2 || events[0].Message != "second error" || events[1].Message != "first error" {
+		testhelpers.FailImpl(t, "unexpected recent errors", events)
+	}
+	if events[0].Context["attempt"] != "2" || events[0].Subsystem != "genericconf" {
+		testhelpers.FailImpl(t, "unexpected recent error details", events[0])
+	}
+
+	ring := newLogEventRing(3)
+	for i := 0; i < 5; i++ {
+		ring.add(LogEvent{Message: fmt.Sprint(i)})
+	}
+	if latest := ring.latest(0); len(latest) != 3 || latest[0].Message != "4" || latest[2].Message != "2" {
+		testhelpers.FailImpl(t, "unexpected events after wrapping around", latest)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// MaxTemporaryLogLevelDuration bounds how long a log level set over RPC lasts
+const MaxTemporaryLogLevelDuration = 24 * time.Hour
+
+// logLevelOverride keeps the log level of the handler set up by InitLog,
+// which can temporarily be changed before it reverts to the configured level
+type logLevelOverride struct {
+	mutex      sync.Mutex
+	glogger    *log.GlogHandler
+	configured log.Lvl
+	// while a temporary level is set
+	level   log.Lvl
+	vmodule string
+	until   time.Time
+	revert  *time.Timer
+}
+
+var globalLogLevel logLevelOverride
+
+func (o *logLevelOverride) active() bool {
+	return o.revert != nil
+}
+
+// setHandler applies the configured level, or the temporary level if one is set, to a new handler
+func (o *logLevelOverride) setHandler(glogger *log.GlogHandler, configured log.Lvl) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.glogger = glogger
+	o.configured = configured
+	o.apply()
+}
+
+func (o *logLevelOverride) apply() {
+	if o.glogger == nil {
+		return
+	}
+	if o.active() {
+		o.glogger.Verbosity(o.level)
+		if err := o.glogger.Vmodule(o.vmodule); err != nil {
+			log.Warn("failed to set temporary log vmodule", "vmodule", o.vmodule, "err", err)
+		}
+	} else {
+		o.glogger.Verbosity(o.configured)
+		_ = o.glogger.Vmodule("")
+	}
+}
+
+func (o *logLevelOverride) set(level log.Lvl, vmodule string, duration time.Duration) (time.Time, error) {
+	if duration <= 0 || duration > MaxTemporaryLogLevelDuration {
+		return time.Time{}, fmt.Errorf("log level duration %v must be positive and at most %v", duration, MaxTemporaryLogLevelDuration)
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.glogger == nil {
+		return time.Time{}, errors.New("logging isn't initialized")
+	}
+	// validate vmodule before changing anything
+	if err := log.NewGlogHandler(log.DiscardHandler()).Vmodule(vmodule); err != nil {
+		return time.Time{}, fmt.Errorf("invalid vmodule %q: %w", vmodule, err)
+	}
+	if o.revert != nil {
+		o.revert.Stop()
+	}
+	o.level = level
+	o.vmodule = vmodule
+	o.until = time.Now().Add(duration)
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		o.mutex.Lock()
+		defer o.mutex.Unlock()
+		// a later call may have replaced this timer
+		if o.revert == timer {
+			o.reset()
+			log.Info("temporary log level expired, reverted to the configured level", "level", o.configured)
+		}
+	})
+	o.revert = timer
+	o.apply()
+	return o.until, nil
+}
+
+func (o *logLevelOverride) reset() {
+	if o.revert != nil {
+		o.revert.Stop()
+		o.revert = nil
+	}
+	o.vmodule = ""
+	o.until = time.Time{}
+	o.apply()
+}
+
+// ParseLogLevel accepts a level name such as "debug", or its number as in the log-level option
+func ParseLogLevel(level string) (log.Lvl, error) {
+	if number, err := strconv.Atoi(level); err == nil {
+		if number < int(log.LvlCrit) || number > int(log.LvlTrace) {
+			return 0, fmt.Errorf("log level %v out of range", number)
+		}
+		return log.Lvl(number), nil
+	}
+	return log.LvlFromString(level)
+}
+
+type LogLevelResult struct {
+	Level      string     `json:"level"`
+	Vmodule    string     `json:"vmodule,omitempty"`
+	Configured string     `json:"configured"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+func (o *logLevelOverride) result() *LogLevelResult {
+	result := &LogLevelResult{
+		Level:      o.configured.String(),
+		Configured: o.configured.String(),
+	}
+	if o.active() {
+		result.Level = o.level.String()
+		result.Vmodule = o.vmodule
+		until := o.until
+		result.Until = &until
+	}
+	return result
+}
+
+// LogLevelAPI lets operators raise the log level for a while, it reverts to the configured level on its own
+type LogLevelAPI struct{}
+
+// SetLogLevel sets the global log level, and optionally per module levels in the vmodule format
+// (e.g. "arbnode/*=5,staker=4"), for the given duration (e.g. "5m")
+func (a *LogLevelAPI) SetLogLevel(ctx context.Context, level string, duration string, vmodule *string) (*LogLevelResult, error) {
+	lvl, err := ParseLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	parsedDuration, err := time.ParseDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q: %w", duration, err)
+	}
+	modules := ""
+	if vmodule != nil {
+		modules = *vmodule
+	}
+	until, err := globalLogLevel.set(lvl, modules, parsedDuration)
+	if err != nil {
+		return nil, err
+	}
+	log.Warn("temporarily changed log level", "level", lvl, "vmodule", modules, "until", until)
+	return a.GetLogLevel(ctx), nil
+}
+
+// ResetLogLevel reverts to the configured log level before the temporary level expires
+func (a *LogLevelAPI) ResetLogLevel(ctx context.Context) *LogLevelResult {
+	globalLogLevel.mutex.Lock()
+	globalLogLevel.reset()
+	globalLogLevel.mutex.Unlock()
+	log.Info("reset log level to the configured level")
+	return a.GetLogLevel(ctx)
+}
+
+func (a *LogLevelAPI) GetLogLevel(ctx context.Context) *LogLevelResult {
+	globalLogLevel.mutex.Lock()
+	defer globalLogLevel.mutex.Unlock()
+	return globalLogLevel.result()
+}
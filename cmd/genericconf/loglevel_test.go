@@ -0,0 +1,42 @@
+package genericconf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestTemporaryLogLevel(t *testing.T) {
+	testhelpers.RequireImpl(t, InitLog("plaintext", log.LvlInfo, &FileLoggingConfig{}, func(path string) string { return path }, ""))
+	api := &LogLevelAPI{}
+	ctx := context.Background()
+
+	if _, err := api.SetLogLevel(ctx, "debug", "48h", nil); err == nil {
+		testhelpers.FailImpl(t, "duration over the maximum accepted")
+	}
+	vmodule := "arbnode/*=5"
+	result, err := api.SetLogLevel(ctx, "debug", "100ms", &vmodule)
+	testhelpers.RequireImpl(t, err)
+	if result.Level != log.LvlDebug.String() || result.Vmodule != vmodule || result.Configured != log.LvlInfo.String() || result.Until == nil {
+		testhelpers.FailImpl(t, "unexpected temporary log level", result)
+	}
+	// reloading the config keeps the temporary level
+	testhelpers.RequireImpl(t, InitLog("plaintext", log.LvlWarn, &FileLoggingConfig{}, func(path string) string { return path }, ""))
+	if result := api.GetLogLevel(ctx); result.Level != log.LvlDebug.String() || result.Configured != log.LvlWarn.String() {
+		testhelpers.FailImpl(t, "temporary log level lost on reload", result)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if result := api.GetLogLevel(ctx); result.Level != log.LvlWarn.String() || result.Until != nil {
+		testhelpers.FailImpl(t, "temporary log level didn't revert", result)
+	}
+
+	_, err = api.SetLogLevel(ctx, "5", "1h", nil)
+	testhelpers.RequireImpl(t, err)
+	if result := api.ResetLogLevel(ctx); result.Level != log.LvlWarn.String() {
+		testhelpers.FailImpl(t, "log level not reset", result)
+	}
+}
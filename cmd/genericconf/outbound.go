@@ -0,0 +1,132 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+)
+
+type OutboundHTTPConfig struct {
+	Proxy              string `koanf:"proxy"`
+	CABundle           string `koanf:"ca-bundle"`
+	InsecureSkipVerify bool   `koanf:"insecure-skip-verify"`
+	IpfsGateway        string `koanf:"ipfs-gateway"`
+}
+
+var OutboundHTTPConfigDefault = OutboundHTTPConfig{
+	Proxy:              "",
+	CABundle:           "",
+	InsecureSkipVerify: false,
+	IpfsGateway:        "",
+}
+
+func OutboundHTTPConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".proxy", OutboundHTTPConfigDefault.Proxy, "proxy url for all outbound http and websocket connections (parent chain rpc, init and chain info downloads); empty uses the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables")
+	f.String(prefix+".ca-bundle", OutboundHTTPConfigDefault.CABundle, "path to a PEM file of additional certificate authorities trusted by outbound connections")
+	f.Bool(prefix+".insecure-skip-verify", OutboundHTTPConfigDefault.InsecureSkipVerify, "DANGEROUS! skip TLS certificate verification of outbound connections, only for development")
+	f.String(prefix+".ipfs-gateway", OutboundHTTPConfigDefault.IpfsGateway, "url of an IPFS http gateway (e.g. https://ipfs.io) to fetch IPFS init and chain info files from over http, through the outbound proxy, instead of joining the IPFS network")
+}
+
+func (c *OutboundHTTPConfig) Validate() error {
+	if c.Proxy != "" {
+		proxyUrl, err := url.Parse(c.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid outbound-http.proxy: %w", err)
+		}
+		if proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+			return fmt.Errorf("invalid outbound-http.proxy %#v, expected scheme://host[:port]", c.Proxy)
+		}
+	}
+	if c.IpfsGateway != "" {
+		gatewayUrl, err := url.Parse(c.IpfsGateway)
+		if err != nil {
+			return fmt.Errorf("invalid outbound-http.ipfs-gateway: %w", err)
+		}
+		if (gatewayUrl.Scheme != "http" && gatewayUrl.Scheme != "https") || gatewayUrl.Host == "" {
+			return fmt.Errorf("invalid outbound-http.ipfs-gateway %#v, expected http[s]://host[:port]", c.IpfsGateway)
+		}
+	}
+	if c.CABundle != "" {
+		if _, err := os.Stat(c.CABundle); err != nil {
+			return fmt.Errorf("invalid outbound-http.ca-bundle: %w", err)
+		}
+	}
+	return nil
+}
+
+// Transport returns an http.Transport using the configured proxy and TLS settings
+func (c *OutboundHTTPConfig) Transport() (*http.Transport, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("default http transport was replaced by an unknown implementation")
+	}
+	transport := base.Clone()
+	if c.Proxy != "" {
+		proxyUrl, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	if c.CABundle != "" || c.InsecureSkipVerify {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		if c.CABundle != "" {
+			pem, err := os.ReadFile(c.CABundle)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read outbound-http.ca-bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in outbound-http.ca-bundle %v", c.CABundle)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		// #nosec G402
+		tlsConfig.InsecureSkipVerify = c.InsecureSkipVerify
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// Client returns an http client using the configured proxy and TLS settings,
+// for downloads which may happen before Apply
+func (c *OutboundHTTPConfig) Client() (*http.Client, error) {
+	transport, err := c.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// Apply installs the configured transport as http.DefaultTransport, which every
+// outbound client (rpc clients, init and chain info downloads) is built on
+func (c *OutboundHTTPConfig) Apply() error {
+	if c.Proxy == "" && c.CABundle == "" && !c.InsecureSkipVerify {
+		return nil
+	}
+	transport, err := c.Transport()
+	if err != nil {
+		return err
+	}
+	if c.InsecureSkipVerify {
+		log.Warn("TLS certificate verification of outbound connections is disabled")
+	}
+	http.DefaultTransport = transport
+	return nil
+}
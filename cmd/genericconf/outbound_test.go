@@ -0,0 +1,44 @@
+package genericconf
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestOutboundHTTPConfig(t *testing.T) {
+	config := OutboundHTTPConfig{Proxy: "not a url"}
+	if config.Validate() == nil {
+		testhelpers.FailImpl(t, "invalid proxy accepted")
+	}
+
+	config = OutboundHTTPConfig{Proxy: "http://proxy.internal:3128"}
+	testhelpers.RequireImpl(t, config.Validate())
+	transport, err := config.Transport()
+	testhelpers.RequireImpl(t, err)
+	req, err := http.NewRequest(http.MethodGet, "https://parent-chain.example", nil)
+	testhelpers.RequireImpl(t, err)
+	proxyUrl, err := transport.Proxy(req)
+	testhelpers.RequireImpl(t, err)
+	if proxyUrl == nil || proxyUrl.Host != "proxy.internal:3128" {
+		testhelpers.FailImpl(t, "unexpected proxy", proxyUrl)
+	}
+
+	config = OutboundHTTPConfig{IpfsGateway: "ipfs.io"}
+	if config.Validate() == nil {
+		testhelpers.FailImpl(t, "ipfs gateway without scheme accepted")
+	}
+	config = OutboundHTTPConfig{IpfsGateway: "https://ipfs.io"}
+	testhelpers.RequireImpl(t, config.Validate())
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	testhelpers.RequireImpl(t, os.WriteFile(bundle, []byte("no certificates here"), 0600))
+	config = OutboundHTTPConfig{CABundle: bundle}
+	testhelpers.RequireImpl(t, config.Validate())
+	if _, err := config.Transport(); err == nil {
+		testhelpers.FailImpl(t, "ca bundle without certificates accepted")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+)
+
+type PerformanceConfig struct {
+	GoMaxProcs  int   `koanf:"gomaxprocs"`
+	CPUAffinity []int `koanf:"cpu-affinity"`
+}
+
+var PerformanceConfigDefault = PerformanceConfig{
+	GoMaxProcs:  0,
+	CPUAffinity: []int{},
+}
+
+func PerformanceConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".gomaxprocs", PerformanceConfigDefault.GoMaxProcs, "maximum number of CPUs executing go code simultaneously (0 to use the GOMAXPROCS environment variable or the number of available CPUs)")
+	f.IntSlice(prefix+".cpu-affinity", PerformanceConfigDefault.CPUAffinity, "only run on these CPU cores, e.g. 0,1,2,3 (linux only, empty to not restrict)")
+}
+
+func (c *PerformanceConfig) Validate() error {
+	if c.GoMaxProcs < 0 {
+		return fmt.Errorf("invalid performance.gomaxprocs %v, must not be negative", c.GoMaxProcs)
+	}
+	numCPU := runtime.NumCPU()
+	if c.GoMaxProcs > numCPU {
+		return fmt.Errorf("invalid performance.gomaxprocs %v, only %v CPUs are available", c.GoMaxProcs, numCPU)
+	}
+	if len(c.CPUAffinity) == 0 {
+		return nil
+	}
+	// the cores the process may run on, which needn't be numbered from 0 when it's already restricted
+	allowed, err := allowedCPUs()
+	if err != nil {
+		return fmt.Errorf("invalid performance.cpu-affinity: %w", err)
+	}
+	allowedSet := make(map[int]bool)
+	for _, cpu := range allowed {
+		allowedSet[cpu] = true
+	}
+	seen := make(map[int]bool)
+	for _, cpu := range c.CPUAffinity {
+		if !allowedSet[cpu] {
+			return fmt.Errorf("invalid performance.cpu-affinity core %v, cores %v are available", cpu, allowed)
+		}
+		if seen[cpu] {
+			return fmt.Errorf("performance.cpu-affinity core %v listed twice", cpu)
+		}
+		seen[cpu] = true
+	}
+	if c.GoMaxProcs > 0 && c.GoMaxProcs > len(c.CPUAffinity) {
+		return fmt.Errorf("performance.gomaxprocs %v is more than the %v cores in performance.cpu-affinity", c.GoMaxProcs, len(c.CPUAffinity))
+	}
+	return nil
+}
+
+// Apply sets the process' CPU affinity and GOMAXPROCS, and should be called early at startup
+func (c *PerformanceConfig) Apply() error {
+	if len(c.CPUAffinity) > 0 {
+		if err := setCPUAffinity(c.CPUAffinity); err != nil {
+			return fmt.Errorf("failed to set cpu affinity: %w", err)
+		}
+		log.Info("restricted process to CPU cores", "cores", c.CPUAffinity)
+	}
+	maxProcs := c.GoMaxProcs
+	if maxProcs == 0 && len(c.CPUAffinity) > 0 {
+		// the go runtime only reads the affinity when it starts
+		maxProcs = len(c.CPUAffinity)
+	}
+	if maxProcs > 0 {
+		previous := runtime.GOMAXPROCS(maxProcs)
+		log.Info("set GOMAXPROCS", "value", maxProcs, "previous", previous)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+//go:build !linux
+
+package genericconf
+
+import "errors"
+
+var errCPUAffinityUnsupported = errors.New("cpu affinity is only supported on linux")
+
+func allowedCPUs() ([]int, error) {
+	return nil, errCPUAffinityUnsupported
+}
+
+func setCPUAffinity(cpus []int) error {
+	return errCPUAffinityUnsupported
+}
@@ -0,0 +1,49 @@
+package genericconf
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestPerformanceConfig(t *testing.T) {
+	numCPU := runtime.NumCPU()
+	valid := []PerformanceConfig{
+		PerformanceConfigDefault,
+		{GoMaxProcs: numCPU},
+	}
+	allowed, err := allowedCPUs()
+	if err == nil {
+		valid = append(valid, PerformanceConfig{GoMaxProcs: 1, CPUAffinity: allowed[:1]}, PerformanceConfig{CPUAffinity: allowed})
+	}
+	for _, config := range valid {
+		testhelpers.RequireImpl(t, config.Validate())
+	}
+	invalid := []PerformanceConfig{
+		{GoMaxProcs: -1},
+		{GoMaxProcs: numCPU + 1},
+		{CPUAffinity: []int{-1}},
+		{CPUAffinity: []int{1 << 20}},
+	}
+	if err == nil {
+		invalid = append(invalid,
+			PerformanceConfig{CPUAffinity: []int{allowed[len(allowed)-1] + 1}},
+			PerformanceConfig{CPUAffinity: []int{allowed[0], allowed[0]}},
+			PerformanceConfig{GoMaxProcs: 2, CPUAffinity: allowed[:1]},
+		)
+	}
+	for _, config := range invalid {
+		if config.Validate() == nil {
+			testhelpers.FailImpl(t, "invalid performance config accepted", config)
+		}
+	}
+
+	previous := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(previous)
+	config := PerformanceConfig{GoMaxProcs: 1}
+	testhelpers.RequireImpl(t, config.Apply())
+	if runtime.GOMAXPROCS(0) != 1 {
+		testhelpers.FailImpl(t, "GOMAXPROCS not applied", runtime.GOMAXPROCS(0))
+	}
+}
@@ -1,8 +1,16 @@
 package genericconf
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
 
 	// Blank import pprof registers its HTTP handlers.
 	_ "net/http/pprof" // #nosec G108
@@ -23,3 +31,97 @@ func StartPprof(address string) {
 		}
 	}()
 }
+
+// periodicProfiles are the runtime/pprof profiles written to disk, in the order they're captured
+var periodicProfiles = []string{"heap", "goroutine"}
+
+const periodicProfileTimeFormat = "20060102-150405"
+
+// StartPeriodicProfiles periodically writes heap and goroutine profiles to the configured directory,
+// keeping the most recent ones, so that they're available after a crash or hang. It stops when ctx is done.
+func StartPeriodicProfiles(ctx context.Context, config *PeriodicProfilesConfig) error {
+	if !config.Enable {
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	log.Info("Writing periodic profiles", "dir", config.Dir, "interval", config.Interval, "retention", config.Retention)
+	go func() {
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			if err := writePeriodicProfiles(config.Dir, config.Retention, time.Now()); err != nil {
+				log.Warn("Failed to write periodic profiles", "dir", config.Dir, "err", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func writePeriodicProfiles(dir string, retention int, now time.Time) error {
+	timestamp := now.UTC().Format(periodicProfileTimeFormat)
+	for _, name := range periodicProfiles {
+		profile := pprof.Lookup(name)
+		if profile == nil {
+			return fmt.Errorf("unknown profile %v", name)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.pprof", name, timestamp))
+		// write to a temporary file first, so a crash while writing doesn't leave a truncated profile
+		tmpPath := path + ".tmp"
+		file, err := os.Create(tmpPath)
+		if err != nil {
+			return err
+		}
+		err = profile.WriteTo(file, 0)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmpPath, path)
+		}
+		if err != nil {
+			_ = os.Remove(tmpPath)
+			return err
+		}
+		if err := prunePeriodicProfiles(dir, name, retention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prunePeriodicProfiles removes all but the retention most recent profiles with the given name
+func prunePeriodicProfiles(dir string, name string, retention int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var profiles []string
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(fileName, name+"-") && strings.HasSuffix(fileName, ".pprof") {
+			profiles = append(profiles, fileName)
+		}
+	}
+	if len(profiles) <= retention {
+		return nil
+	}
+	// the timestamp format sorts chronologically
+	sort.Strings(profiles)
+	var errs []error
+	for _, fileName := range profiles[:len(profiles)-retention] {
+		if err := os.Remove(filepath.Join(dir, fileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package genericconf
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeriodicProfilesRetention(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := writePeriodicProfiles(dir, 2, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			t.Error("temporary profile left behind", name)
+		}
+		counts[strings.SplitN(name, "-", 2)[0]]++
+	}
+	for _, profile := range periodicProfiles {
+		if counts[profile] != 2 {
+			t.Error("unexpected number of", profile, "profiles kept", counts[profile])
+		}
+	}
+	newest := start.Add(3 * time.Minute).UTC().Format(periodicProfileTimeFormat)
+	if _, err := os.Stat(dir + "/heap-" + newest + ".pprof"); err != nil {
+		t.Error("newest profile removed", err)
+	}
+}
+
+// profileModTimes returns when each profile in dir was last written
+func profileModTimes(t *testing.T, dir string) map[string]time.Time {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTimes := make(map[string]time.Time)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		modTimes[entry.Name()] = info.ModTime()
+	}
+	return modTimes
+}
+
+func TestPeriodicProfilesStop(t *testing.T) {
+	dir := t.TempDir()
+	config := PeriodicProfilesConfigDefault
+	config.Enable = true
+	config.Dir = dir
+	config.Interval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := StartPeriodicProfiles(ctx, &config); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; len(profileModTimes(t, dir)) == 0; i++ {
+		if i == 100 {
+			t.Fatal("no profiles written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	// let a write in progress finish
+	time.Sleep(100 * time.Millisecond)
+	stopped := profileModTimes(t, dir)
+	time.Sleep(100 * time.Millisecond)
+	after := profileModTimes(t, dir)
+	if len(after) != len(stopped) {
+		t.Fatal("profiles written after the context was done", after)
+	}
+	for name, modTime := range stopped {
+		if !after[name].Equal(modTime) {
+			t.Fatal("profile", name, "written after the context was done")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RecentErrorsBufferSize is how many of the latest error records are kept for the RecentErrors RPC
+const RecentErrorsBufferSize = 256
+
+type LogEvent struct {
+	Time      time.Time         `json:"time"`
+	Level     string            `json:"level"`
+	Subsystem string            `json:"subsystem"`
+	Location  string            `json:"location"`
+	Message   string            `json:"message"`
+	Context   map[string]string `json:"context,omitempty"`
+}
+
+// logEventRing keeps the latest error records, overwriting the oldest once full
+type logEventRing struct {
+	mutex  sync.Mutex
+	events []LogEvent
+	next   int
+	full   bool
+}
+
+var recentErrors = newLogEventRing(RecentErrorsBufferSize)
+
+func newLogEventRing(size int) *logEventRing {
+	return &logEventRing{events: make([]LogEvent, size)}
+}
+
+func (b *logEventRing) add(event LogEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.events[b.next] = event
+	b.next++
+	if b.next == len(b.events) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+// latest returns up to count events, newest first
+func (b *logEventRing) latest(count int) []LogEvent {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	stored := b.next
+	if b.full {
+		stored = len(b.events)
+	}
+	if count <= 0 || count > stored {
+		count = stored
+	}
+	events := make([]LogEvent, 0, count)
+	for i := 1; i <= count; i++ {
+		events = append(events, b.events[(b.next-i+len(b.events))%len(b.events)])
+	}
+	return events
+}
+
+func logEventFromRecord(r *log.Record) LogEvent {
+	event := LogEvent{
+		Time:      r.Time,
+		Level:     r.Lvl.String(),
+		Subsystem: fmt.Sprintf("%k", r.Call),
+		Location:  fmt.Sprintf("%v", r.Call),
+		Message:   r.Msg,
+	}
+	if len(r.Ctx) > 0 {
+		event.Context = make(map[string]string, len(r.Ctx)/2)
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			event.Context[fmt.Sprint(r.Ctx[i])] = fmt.Sprint(r.Ctx[i+1])
+		}
+	}
+	return event
+}
+
+// withRecentErrors keeps the error records passed to handler for the RecentErrors RPC
+func withRecentErrors(handler log.Handler) log.Handler {
+	return log.FuncHandler(func(r *log.Record) error {
+		if r.Lvl <= log.LvlError {
+			recentErrors.add(logEventFromRecord(r))
+		}
+		return handler.Log(r)
+	})
+}
+
+type RecentErrorsAPI struct{}
+
+// RecentErrors returns the latest error and critical log records, newest first,
+// limited to count if given and at most RecentErrorsBufferSize
+func (a *RecentErrorsAPI) RecentErrors(ctx context.Context, count *int) []LogEvent {
+	limit := 0
+	if count != nil {
+		limit = *count
+	}
+	return recentErrors.latest(limit)
+}
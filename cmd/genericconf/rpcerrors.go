@@ -0,0 +1,174 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var rpcErrorsRedactedCounter = metrics.NewRegisteredCounter("arb/rpc/errors/redacted", nil)
+
+// rpcErrorsRedacted is set once at startup by RpcConfig.Apply if rpc.error-detail is generic
+var rpcErrorsRedacted atomic.Bool
+
+const (
+	RpcErrorDetailFull    = "full"
+	RpcErrorDetailGeneric = "generic"
+)
+
+// Errors with these codes describe the request itself, or carry revert data the caller needs for
+// code 3, so they are passed through even when error details are hidden.
+var rpcPassthroughErrorCodes = map[int]bool{
+	-32700: true, // parse error
+	-32600: true, // invalid request
+	-32601: true, // method not found
+	-32602: true, // invalid params
+	-32001: true, // resource not found, e.g. state pruned
+	-32005: true, // limit exceeded, the node is busy
+	3:      true, // execution reverted
+}
+
+// geth uses -32000 both for transaction validation errors and for internal failures such as
+// "missing trie node", so only errors starting with one of these known user-facing messages,
+// or with the message of an error registered with PassThroughRpcErrors, are passed through.
+// Everything else is redacted.
+var rpcPassthroughServerErrors = []string{
+	"nonce too low",
+	"nonce too high",
+	"nonce has max value",
+	"insufficient funds for gas * price + value",
+	"insufficient funds for transfer",
+	"intrinsic gas too low",
+	"gas required exceeds allowance",
+	"exceeds block gas limit",
+	"already known",
+	"replacement transaction underpriced",
+	"transaction underpriced",
+	"txpool is full",
+	"invalid sender",
+	"negative value",
+	"oversized data",
+	"transaction type not supported",
+	"tip above fee cap",
+	"max priority fee per gas higher than max fee per gas",
+	"max fee per gas less than block base fee",
+	"only replay-protected (EIP-155) transactions allowed over RPC",
+	"execution reverted",
+}
+
+var rpcPassthroughErrorsMutex sync.RWMutex
+var rpcPassthroughErrors []error
+
+// PassThroughRpcErrors registers user-facing errors that are passed through even when error details are hidden.
+// An error is recognized by its message starting with the registered error's, as is the case when it's wrapped with %w.
+func PassThroughRpcErrors(errs ...error) {
+	rpcPassthroughErrorsMutex.Lock()
+	defer rpcPassthroughErrorsMutex.Unlock()
+	rpcPassthroughErrors = append(rpcPassthroughErrors, errs...)
+}
+
+func rpcErrorPassesThrough(rpcErr *rpcErrorObject) bool {
+	if rpcPassthroughErrorCodes[rpcErr.Code] {
+		return true
+	}
+	if rpcErr.Code != -32000 {
+		return false
+	}
+	for _, message := range rpcPassthroughServerErrors {
+		if strings.HasPrefix(rpcErr.Message, message) {
+			return true
+		}
+	}
+	rpcPassthroughErrorsMutex.RLock()
+	defer rpcPassthroughErrorsMutex.RUnlock()
+	for _, err := range rpcPassthroughErrors {
+		if strings.HasPrefix(rpcErr.Message, err.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+type rpcErrorObject struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func newRpcErrorId() string {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// redactRpcErrors rewrites the errors in a JSON-RPC response or batch of responses, sent over http or websocket.
+// Anything that doesn't parse as such is returned unchanged.
+func redactRpcErrors(body []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return body, false
+	}
+	var responses []map[string]json.RawMessage
+	batch := trimmed[0] == '['
+	if batch {
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return body, false
+		}
+	} else {
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			return body, false
+		}
+		responses = append(responses, response)
+	}
+	changed := false
+	for _, response := range responses {
+		rawErr, ok := response["error"]
+		if !ok {
+			continue
+		}
+		var rpcErr rpcErrorObject
+		if err := json.Unmarshal(rawErr, &rpcErr); err != nil || rpcErrorPassesThrough(&rpcErr) {
+			continue
+		}
+		errId := newRpcErrorId()
+		log.Warn("redacted RPC error", "errorId", errId, "code", rpcErr.Code, "message", rpcErr.Message, "data", string(rpcErr.Data))
+		rpcErrorsRedactedCounter.Inc(1)
+		redacted, err := json.Marshal(rpcErrorObject{
+			Code:    rpcErr.Code,
+			Message: fmt.Sprintf("internal error (error id %s)", errId),
+		})
+		if err != nil {
+			continue
+		}
+		response["error"] = redacted
+		changed = true
+	}
+	if !changed {
+		return body, false
+	}
+	var result []byte
+	var err error
+	if batch {
+		result, err = json.Marshal(responses)
+	} else {
+		result, err = json.Marshal(responses[0])
+	}
+	if err != nil {
+		return body, false
+	}
+	return append(result, '\n'), true
+}
@@ -0,0 +1,107 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRedactRpcErrors(t *testing.T) {
+	body := []byte(`[{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"missing trie node 1f2e3d (path )"}},` +
+		`{"jsonrpc":"2.0","id":2,"error":{"code":3,"message":"execution reverted","data":"0x08c379a0"}},` +
+		`{"jsonrpc":"2.0","id":3,"result":"0x1"},` +
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32000,"message":"nonce too low: address 0x0000000000000000000000000000000000000001, tx: 1 state: 2"}}]`)
+	redacted, changed := redactRpcErrors(body)
+	if !changed {
+		t.Fatal("expected response to be redacted")
+	}
+	if strings.Contains(string(redacted), "trie") {
+		t.Error("internal error message wasn't redacted:", string(redacted))
+	}
+	var responses []map[string]json.RawMessage
+	if err := json.Unmarshal(redacted, &responses); err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 4 {
+		t.Fatal("unexpected number of responses", len(responses))
+	}
+	var first rpcErrorObject
+	if err := json.Unmarshal(responses[0]["error"], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Code != -32000 || !regexp.MustCompile(`^internal error \(error id [0-9a-f]{16}\)$`).MatchString(first.Message) {
+		t.Error("unexpected redacted error", first)
+	}
+	if string(responses[1]["error"]) != `{"code":3,"message":"execution reverted","data":"0x08c379a0"}` {
+		t.Error("revert error wasn't passed through:", string(responses[1]["error"]))
+	}
+	if string(responses[2]["result"]) != `"0x1"` {
+		t.Error("result was modified:", string(responses[2]["result"]))
+	}
+	if string(responses[3]["error"]) != `{"code":-32000,"message":"nonce too low: address 0x0000000000000000000000000000000000000001, tx: 1 state: 2"}` {
+		t.Error("user error wasn't passed through:", string(responses[3]["error"]))
+	}
+
+	prunedState := []byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32001,"message":"state pruned at block 10, oldest available 900 (latest 1000)"}}`)
+	if _, changed := redactRpcErrors(prunedState); changed {
+		t.Error("pruned state error was redacted")
+	}
+
+	unchanged := []byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+	if _, changed := redactRpcErrors(unchanged); changed {
+		t.Error("response without errors was modified")
+	}
+}
+
+var errTestUserFacing = errors.New("test user facing error")
+
+type errorService struct{}
+
+func (s *errorService) Internal() error {
+	return errors.New("missing trie node 1f2e3d (path )")
+}
+
+func (s *errorService) UserFacing() error {
+	return fmt.Errorf("%w: with details", errTestUserFacing)
+}
+
+func TestRedactRpcErrorsWebsocket(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	rpcErrorsRedacted.Store(true)
+	defer rpcErrorsRedacted.Store(false)
+	PassThroughRpcErrors(errTestUserFacing)
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("test", &errorService{}); err != nil {
+		t.Fatal(err)
+	}
+	defer rpcServer.Stop()
+	server := httptest.NewServer(&rpcHandler{inner: rpcServer.WebsocketHandler([]string{"*"}), limiter: &inFlightLimiter{}})
+	defer server.Close()
+	client, err := rpc.DialWebsocket(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.CallContext(ctx, nil, "test_internal")
+	if err == nil || strings.Contains(err.Error(), "trie") || !strings.HasPrefix(err.Error(), "internal error (error id ") {
+		t.Error("internal error wasn't redacted over websocket:", err)
+	}
+	err = client.CallContext(ctx, nil, "test_userFacing")
+	if err == nil || err.Error() != "test user facing error: with details" {
+		t.Error("registered user facing error wasn't passed through:", err)
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// rpcHandler passes every JSON-RPC message the node's http and websocket servers receive and send through
+// the same checks: calls over rpc.max-in-flight-requests are rejected with a busy error, and the error messages
+// of responses are redacted if rpc.error-detail is generic. Warnings added by calls over http are sent as headers.
+type rpcHandler struct {
+	inner   http.Handler
+	limiter *inFlightLimiter
+}
+
+var installRpcHandler sync.Once
+
+// wrapRpcHandler adds the rpcHandler to the handlers geth wraps its http and websocket servers with.
+// Must be run before the go-ethereum stack is set up (ethereum/go-ethereum/node.New), later calls have no effect.
+func wrapRpcHandler() {
+	installRpcHandler.Do(func() {
+		wrapHandler := node.WrapHTTPHandler
+		node.WrapHTTPHandler = func(srv http.Handler) (http.Handler, error) {
+			var err error
+			if wrapHandler != nil {
+				srv, err = wrapHandler(srv)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return NewRpcHandler(srv), nil
+		}
+	})
+}
+
+// NewRpcHandler wraps a JSON-RPC http and websocket handler with the rpcHandler, as RpcConfig.Apply does for the node's servers
+func NewRpcHandler(inner http.Handler) http.Handler {
+	return &rpcHandler{inner: inner, limiter: &rpcInFlightLimiter}
+}
+
+func isWebsocketUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
+func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if isWebsocketUpgrade(req) {
+		// websocket connections are long lived, so instead of the connection each message with calls over it holds a request slot.
+		// They're only proxied while needed, so a limit set on config reload applies to new connections.
+		if rpcErrorsRedacted.Load() || h.limiter.limit.Load() > 0 {
+			h.serveWebsocket(w, req)
+		} else {
+			h.inner.ServeHTTP(w, req)
+		}
+		return
+	}
+	if req.Method != http.MethodPost {
+		h.inner.ServeHTTP(w, req)
+		return
+	}
+	req, warnings := withRpcWarnings(req)
+	w = &warningResponseWriter{ResponseWriter: w, warnings: warnings}
+	// a batch holds a single slot, as the rpc server handles the calls of a batch one after another
+	if !h.limiter.tryAcquire() {
+		rpcInFlightRejectedCounter.Inc(1)
+		log.Debug("Rejecting RPC request, too many requests in flight", "limit", h.limiter.limit.Load())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(rpcBusyResponse))
+		return
+	}
+	defer h.limiter.release()
+	if !rpcErrorsRedacted.Load() {
+		h.inner.ServeHTTP(w, req)
+		return
+	}
+	buffered := &bufferedResponseWriter{header: w.Header()}
+	h.inner.ServeHTTP(buffered, req)
+	body := buffered.body.Bytes()
+	if redacted, changed := redactRpcErrors(body); changed {
+		body = redacted
+		w.Header().Del("Content-Length")
+	}
+	if buffered.status != 0 {
+		w.WriteHeader(buffered.status)
+	}
+	_, _ = w.Write(body)
+}
+
+// serveWebsocket proxies a websocket connection to the inner handler over an in-memory connection,
+// so the JSON-RPC messages sent over it pass through the same checks as those sent over http.
+func (h *rpcHandler) serveWebsocket(w http.ResponseWriter, req *http.Request) {
+	clientSide, serverSide := net.Pipe()
+	listener := newSingleConnListener(serverSide)
+	go func() {
+		// returns once the listener is closed, the handler keeps serving the connection
+		_ = (&http.Server{Handler: h.inner}).Serve(listener)
+	}()
+	defer listener.Close()
+
+	header := http.Header{}
+	for key, values := range req.Header {
+		if !wsHandshakeHeaders[key] {
+			header[key] = values
+		}
+	}
+	if header.Get("X-Forwarded-For") == "" {
+		if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+			header.Set("X-Forwarded-For", host)
+		}
+	}
+	dialer := websocket.Dialer{
+		NetDialContext: func(context.Context, string, string) (net.Conn, error) {
+			return clientSide, nil
+		},
+	}
+	backend, resp, err := dialer.DialContext(req.Context(), "ws://"+req.Host+req.URL.RequestURI(), header)
+	if err != nil {
+		clientSide.Close()
+		if resp != nil {
+			// e.g. the origin or the jwt were rejected
+			for key, values := range resp.Header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(resp.StatusCode)
+			_, _ = io.Copy(w, resp.Body)
+			return
+		}
+		log.Warn("failed to proxy websocket connection", "err", err)
+		http.Error(w, "websocket handshake failed", http.StatusInternalServerError)
+		return
+	}
+	client, err := wsProxyUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// the upgrader already replied with an error
+		backend.Close()
+		return
+	}
+	client.SetReadLimit(wsProxyReadLimit)
+	conn := &rpcWebsocket{client: client, backend: backend, limiter: h.limiter}
+	conn.serve()
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// the largest message accepted from websocket clients, as go-ethereum's own limit
+const wsProxyReadLimit = 15 * 1024 * 1024
+
+var wsProxyUpgrader = websocket.Upgrader{
+	// the origin was already checked by the handshake with the inner handler
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// the headers of the client's handshake which the websocket dialer sets itself
+var wsHandshakeHeaders = map[string]bool{
+	"Upgrade":                  true,
+	"Connection":               true,
+	"Sec-Websocket-Key":        true,
+	"Sec-Websocket-Version":    true,
+	"Sec-Websocket-Extensions": true,
+	"Sec-Websocket-Protocol":   true,
+}
+
+// rpcWebsocket passes the messages of a proxied websocket connection on, tracking the messages with calls in flight
+// and redacting the errors of responses
+type rpcWebsocket struct {
+	client  *websocket.Conn
+	backend *websocket.Conn
+	limiter *inFlightLimiter
+
+	clientWriteMutex sync.Mutex   // responses and busy errors are written by different goroutines
+	inFlight         atomic.Int64 // the messages with calls passed on which weren't answered yet
+}
+
+func (c *rpcWebsocket) serve() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.forwardResponses()
+		c.close()
+	}()
+	c.forwardRequests()
+	c.close()
+	<-done
+	c.limiter.releaseN(c.inFlight.Swap(0))
+}
+
+// close closes both connections, ending the forwarding in both directions
+func (c *rpcWebsocket) close() {
+	_ = c.client.Close()
+	_ = c.backend.Close()
+}
+
+func (c *rpcWebsocket) writeClient(messageType int, data []byte) error {
+	c.clientWriteMutex.Lock()
+	defer c.clientWriteMutex.Unlock()
+	return c.client.WriteMessage(messageType, data)
+}
+
+func (c *rpcWebsocket) forwardRequests() {
+	for {
+		messageType, data, err := c.client.ReadMessage()
+		if err != nil {
+			return
+		}
+		msgs, batch := parseRpcMessages(data)
+		hasCalls := false
+		for _, msg := range msgs {
+			if msg.isCall() {
+				hasCalls = true
+			}
+		}
+		if !hasCalls {
+			if err := c.backend.WriteMessage(messageType, data); err != nil {
+				return
+			}
+			continue
+		}
+		// like an http request, a message holds a single slot however many calls its batch has
+		if !c.limiter.tryAcquire() {
+			rpcInFlightRejectedCounter.Inc(1)
+			log.Debug("Rejecting websocket RPC request, too many requests in flight", "limit", c.limiter.limit.Load(), "calls", len(msgs))
+			response, err := rpcBusyResponses(msgs, batch)
+			if err != nil {
+				log.Warn("failed to encode busy response", "err", err)
+				return
+			}
+			if err := c.writeClient(websocket.TextMessage, response); err != nil {
+				return
+			}
+			continue
+		}
+		c.inFlight.Add(1)
+		if err := c.backend.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func (c *rpcWebsocket) forwardResponses() {
+	for {
+		messageType, data, err := c.backend.ReadMessage()
+		if err != nil {
+			return
+		}
+		msgs, _ := parseRpcMessages(data)
+		for _, msg := range msgs {
+			if msg.isResponse() {
+				// the server answers a message, batch or not, with a single message
+				c.release()
+				break
+			}
+		}
+		if rpcErrorsRedacted.Load() {
+			if redacted, changed := redactRpcErrors(data); changed {
+				data = redacted
+			}
+		}
+		if err := c.writeClient(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+// release releases the slot of an answered message, but never more than this connection acquired,
+// e.g. when a response's id wasn't recognized
+func (c *rpcWebsocket) release() {
+	for {
+		inFlight := c.inFlight.Load()
+		if inFlight == 0 {
+			return
+		}
+		if c.inFlight.CompareAndSwap(inFlight, inFlight-1) {
+			c.limiter.release()
+			return
+		}
+	}
+}
+
+// rpcMessageHeader holds the fields telling JSON-RPC calls, notifications and responses apart
+type rpcMessageHeader struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+}
+
+func (m *rpcMessageHeader) hasID() bool {
+	return len(m.ID) > 0 && !bytes.Equal(m.ID, []byte("null"))
+}
+
+// isCall is true for calls expecting a response, unlike notifications
+func (m *rpcMessageHeader) isCall() bool {
+	return m.Method != "" && m.hasID()
+}
+
+func (m *rpcMessageHeader) isResponse() bool {
+	return m.Method == "" && m.hasID()
+}
+
+// parseRpcMessages decodes the headers of a JSON-RPC message or batch, none if it isn't valid JSON
+func parseRpcMessages(data []byte) ([]rpcMessageHeader, bool) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var msgs []rpcMessageHeader
+		if json.Unmarshal(data, &msgs) != nil {
+			return nil, true
+		}
+		return msgs, true
+	}
+	var msg rpcMessageHeader
+	if json.Unmarshal(data, &msg) != nil {
+		return nil, false
+	}
+	return []rpcMessageHeader{msg}, false
+}
+
+// singleConnListener accepts a single connection, then blocks until it's closed
+type singleConnListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+	addr      net.Addr
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	l := &singleConnListener{
+		conns:  make(chan net.Conn, 1),
+		closed: make(chan struct{}),
+		addr:   conn.LocalAddr(),
+	}
+	l.conns <- conn
+	return l
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *singleConnListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.addr
+}
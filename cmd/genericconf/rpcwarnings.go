@@ -0,0 +1,74 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// RpcWarningHeader is the http response header telling the client about warnings of its JSON-RPC calls,
+// e.g. that the node is syncing. Each warning is a "199 - <quoted text>" value, as in RFC 7234.
+const RpcWarningHeader = "Warning"
+
+type rpcWarningsKey struct{}
+
+// rpcWarnings collects the warnings of the calls of an http request
+type rpcWarnings struct {
+	mutex    sync.Mutex
+	warnings []string
+}
+
+// AddRpcWarning adds a warning to the http response of the JSON-RPC call with ctx.
+// It has no effect for calls over websocket, which have no response headers, or without the rpc handler.
+func AddRpcWarning(ctx context.Context, warning string) {
+	warnings, ok := ctx.Value(rpcWarningsKey{}).(*rpcWarnings)
+	if !ok {
+		return
+	}
+	warnings.mutex.Lock()
+	defer warnings.mutex.Unlock()
+	for _, existing := range warnings.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	warnings.warnings = append(warnings.warnings, warning)
+}
+
+func withRpcWarnings(req *http.Request) (*http.Request, *rpcWarnings) {
+	warnings := &rpcWarnings{}
+	return req.WithContext(context.WithValue(req.Context(), rpcWarningsKey{}, warnings)), warnings
+}
+
+// warningResponseWriter sets the warning headers once the response is written, after the calls are handled
+type warningResponseWriter struct {
+	http.ResponseWriter
+	warnings *rpcWarnings
+	written  bool
+}
+
+func (w *warningResponseWriter) setHeaders() {
+	if w.written {
+		return
+	}
+	w.written = true
+	w.warnings.mutex.Lock()
+	defer w.warnings.mutex.Unlock()
+	for _, warning := range w.warnings.warnings {
+		w.Header().Add(RpcWarningHeader, "199 - "+strconv.Quote(warning))
+	}
+}
+
+func (w *warningResponseWriter) WriteHeader(status int) {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *warningResponseWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
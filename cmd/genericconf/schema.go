@@ -0,0 +1,113 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// ConfigField describes a config struct field visited by WalkConfig
+type ConfigField struct {
+	Field reflect.StructField
+	Path  string // go field path, e.g. config.Node.Sequencer
+	Key   string // koanf key, e.g. node.sequencer
+	// whether the field and all of its parents are tagged reload:"hot"
+	Hot bool
+}
+
+// WalkConfig visits every exported field of the given config structs, which must all have the same type,
+// passing the field's value in each of them. Nested structs are only descended into if visit returns true.
+func WalkConfig(values []reflect.Value, visit func(field ConfigField, values []reflect.Value) bool) {
+	walkConfig(values, "config", "", true, visit)
+}
+
+func walkConfig(values []reflect.Value, path string, key string, hot bool, visit func(ConfigField, []reflect.Value) bool) {
+	if len(values) == 0 || values[0].Kind() != reflect.Struct {
+		return
+	}
+	nodeTy := values[0].Type()
+	for i := 0; i < nodeTy.NumField(); i++ {
+		fieldTy := nodeTy.Field(i)
+		if !fieldTy.IsExported() {
+			continue
+		}
+		name := strings.Split(fieldTy.Tag.Get("koanf"), ",")[0]
+		if name == "" {
+			name = fieldTy.Name
+		}
+		field := ConfigField{
+			Field: fieldTy,
+			Path:  path + "." + fieldTy.Name,
+			Key:   name,
+			Hot:   hot && fieldTy.Tag.Get("reload") == "hot",
+		}
+		if key != "" {
+			field.Key = key + "." + name
+		}
+		fieldValues := make([]reflect.Value, 0, len(values))
+		for _, value := range values {
+			fieldValues = append(fieldValues, value.Field(i))
+		}
+		if visit(field, fieldValues) {
+			walkConfig(fieldValues, field.Path, field.Key, field.Hot, visit)
+		}
+	}
+}
+
+type ConfigSchemaField struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default"`
+	Reload      bool        `json:"reload"`
+	Description string      `json:"description,omitempty"`
+}
+
+// ConfigSchema lists every config key of the defaults struct, with descriptions taken from
+// the matching flags in f if there are any
+func ConfigSchema(defaults interface{}, f *flag.FlagSet) []ConfigSchemaField {
+	var schema []ConfigSchemaField
+	WalkConfig([]reflect.Value{reflect.Indirect(reflect.ValueOf(defaults))}, func(field ConfigField, values []reflect.Value) bool {
+		if field.Field.Type.Kind() == reflect.Struct {
+			return true
+		}
+		entry := ConfigSchemaField{
+			Key:     field.Key,
+			Type:    field.Field.Type.String(),
+			Default: values[0].Interface(),
+			Reload:  field.Hot,
+		}
+		if duration, ok := entry.Default.(time.Duration); ok {
+			entry.Default = duration.String()
+		}
+		if f != nil {
+			if fl := f.Lookup(field.Key); fl != nil {
+				entry.Description = fl.Usage
+			}
+		}
+		schema = append(schema, entry)
+		return false
+	})
+	return schema
+}
+
+// ReloadableKeys lists the koanf key of every leaf config field, split by whether it can be changed on config reload
+func ReloadableKeys(config interface{}) (hot []string, cold []string) {
+	hot, cold = []string{}, []string{}
+	WalkConfig([]reflect.Value{reflect.Indirect(reflect.ValueOf(config))}, func(field ConfigField, values []reflect.Value) bool {
+		if values[0].Kind() == reflect.Struct {
+			return true
+		}
+		if field.Hot {
+			hot = append(hot, field.Key)
+		} else {
+			cold = append(cold, field.Key)
+		}
+		return false
+	})
+	return hot, cold
+}
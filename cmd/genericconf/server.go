@@ -4,6 +4,8 @@
 package genericconf
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	flag "github.com/spf13/pflag"
@@ -114,29 +116,42 @@ func WSConfigAddOptions(prefix string, f *flag.FlagSet) {
 }
 
 type IPCConfig struct {
-	Path string `koanf:"path"`
+	Path           string `koanf:"path"`
+	MaxConnections uint   `koanf:"max-connections"`
+	MaxRequestSize uint   `koanf:"max-request-size"`
 }
 
 var IPCConfigDefault = IPCConfig{
-	Path: "",
+	Path:           "",
+	MaxConnections: 0,
+	MaxRequestSize: 0,
 }
 
 func (c *IPCConfig) Apply(stackConf *node.Config) {
 	stackConf.IPCPath = c.Path
 }
 
+// Limited returns true if the IPC endpoint needs to be served by a LimitedIPC
+func (c *IPCConfig) Limited() bool {
+	return c.Path != "" && (c.MaxConnections > 0 || c.MaxRequestSize > 0)
+}
+
 func IPCConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".path", IPCConfigDefault.Path, "Requested location to place the IPC endpoint. An empty path disables IPC.")
+	f.Uint(prefix+".max-connections", IPCConfigDefault.MaxConnections, "maximum number of concurrent IPC connections, further connections are closed immediately (0 = unlimited)")
+	f.Uint(prefix+".max-request-size", IPCConfigDefault.MaxRequestSize, "maximum size in bytes of a single IPC request, connections sending larger requests are closed (0 = unlimited)")
 }
 
 type GraphQLConfig struct {
 	Enable     bool     `koanf:"enable"`
+	Optional   bool     `koanf:"optional"`
 	CORSDomain []string `koanf:"corsdomain"`
 	VHosts     []string `koanf:"vhosts"`
 }
 
 var GraphQLConfigDefault = GraphQLConfig{
 	Enable:     false,
+	Optional:   false,
 	CORSDomain: node.DefaultConfig.GraphQLCors,
 	VHosts:     node.DefaultConfig.GraphQLVirtualHosts,
 }
@@ -148,16 +163,18 @@ func (c GraphQLConfig) Apply(stackConf *node.Config) {
 
 func GraphQLConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", GraphQLConfigDefault.Enable, "Enable graphql endpoint on the rpc endpoint")
+	f.Bool(prefix+".optional", GraphQLConfigDefault.Optional, "if the graphql endpoint fails to register, log a warning and continue without it instead of exiting")
 	f.StringSlice(prefix+".corsdomain", GraphQLConfigDefault.CORSDomain, "Comma separated list of domains from which to accept cross origin requests (browser enforced)")
 	f.StringSlice(prefix+".vhosts", GraphQLConfigDefault.VHosts, "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard")
 }
 
 type AuthRPCConfig struct {
-	Addr      string   `koanf:"addr"`
-	Port      int      `koanf:"port"`
-	API       []string `koanf:"api"`
-	Origins   []string `koanf:"origins"`
-	JwtSecret string   `koanf:"jwtsecret"`
+	Addr                   string   `koanf:"addr"`
+	Port                   int      `koanf:"port"`
+	API                    []string `koanf:"api"`
+	Origins                []string `koanf:"origins"`
+	JwtSecret              string   `koanf:"jwtsecret"`
+	RegenerateJwtOnInvalid bool     `koanf:"regenerate-jwt-on-invalid"`
 }
 
 func (a AuthRPCConfig) Apply(stackConf *node.Config) {
@@ -170,11 +187,12 @@ func (a AuthRPCConfig) Apply(stackConf *node.Config) {
 }
 
 var AuthRPCConfigDefault = AuthRPCConfig{
-	Addr:      "127.0.0.1",
-	Port:      8549,
-	API:       []string{"validation"},
-	Origins:   []string{"localhost"},
-	JwtSecret: "",
+	Addr:                   "127.0.0.1",
+	Port:                   8549,
+	API:                    []string{"validation"},
+	Origins:                []string{"localhost"},
+	JwtSecret:              "",
+	RegenerateJwtOnInvalid: false,
 }
 
 func AuthRPCConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -183,6 +201,7 @@ func AuthRPCConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".port", AuthRPCConfigDefault.Port, "AUTH-RPC server listening port")
 	f.StringSlice(prefix+".origins", AuthRPCConfigDefault.Origins, "Origins from which to accept AUTH requests")
 	f.StringSlice(prefix+".api", AuthRPCConfigDefault.API, "APIs offered over the AUTH-RPC interface")
+	f.Bool(prefix+".regenerate-jwt-on-invalid", AuthRPCConfigDefault.RegenerateJwtOnInvalid, "replace an invalid JWT secret file with a new secret instead of failing to start")
 }
 
 type MetricsServerConfig struct {
@@ -198,13 +217,45 @@ var MetricsServerConfigDefault = MetricsServerConfig{
 }
 
 type PProf struct {
-	Addr string `koanf:"addr"`
-	Port int    `koanf:"port"`
+	Addr             string                 `koanf:"addr"`
+	Port             int                    `koanf:"port"`
+	PeriodicProfiles PeriodicProfilesConfig `koanf:"periodic-profiles"`
 }
 
 var PProfDefault = PProf{
-	Addr: "127.0.0.1",
-	Port: 6071,
+	Addr:             "127.0.0.1",
+	Port:             6071,
+	PeriodicProfiles: PeriodicProfilesConfigDefault,
+}
+
+type PeriodicProfilesConfig struct {
+	Enable    bool          `koanf:"enable"`
+	Dir       string        `koanf:"dir"`
+	Interval  time.Duration `koanf:"interval"`
+	Retention int           `koanf:"retention"`
+}
+
+var PeriodicProfilesConfigDefault = PeriodicProfilesConfig{
+	Enable:    false,
+	Dir:       "",
+	Interval:  10 * time.Minute,
+	Retention: 12,
+}
+
+func (c *PeriodicProfilesConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Dir == "" {
+		return errors.New("pprof-cfg.periodic-profiles.dir must be set when periodic profiles are enabled")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("invalid pprof-cfg.periodic-profiles.interval %v, must be positive", c.Interval)
+	}
+	if c.Retention < 1 {
+		return fmt.Errorf("invalid pprof-cfg.periodic-profiles.retention %v, must keep at least one profile", c.Retention)
+	}
+	return nil
 }
 
 func MetricsServerAddOptions(prefix string, f *flag.FlagSet) {
@@ -216,4 +267,12 @@ func MetricsServerAddOptions(prefix string, f *flag.FlagSet) {
 func PProfAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".addr", PProfDefault.Addr, "pprof server address")
 	f.Int(prefix+".port", PProfDefault.Port, "pprof server port")
+	PeriodicProfilesAddOptions(prefix+".periodic-profiles", f)
+}
+
+func PeriodicProfilesAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", PeriodicProfilesConfigDefault.Enable, "periodically write heap and goroutine profiles to disk, for debugging crashes and hangs after the fact")
+	f.String(prefix+".dir", PeriodicProfilesConfigDefault.Dir, "directory to write the periodic profiles to")
+	f.Duration(prefix+".interval", PeriodicProfilesConfigDefault.Interval, "how often to write the profiles")
+	f.Int(prefix+".retention", PeriodicProfilesConfigDefault.Retention, "number of most recent profiles of each kind to keep")
 }
@@ -0,0 +1,66 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	flag "github.com/spf13/pflag"
+)
+
+type ClientVersionConfig struct {
+	Hide     bool   `koanf:"hide"`
+	Override string `koanf:"override"`
+}
+
+var ClientVersionConfigDefault = ClientVersionConfig{
+	Hide:     false,
+	Override: "",
+}
+
+func ClientVersionConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".hide", ClientVersionConfigDefault.Hide, "only report the client name, without its version, platform or go version, in web3_clientVersion")
+	f.String(prefix+".override", ClientVersionConfigDefault.Override, "report this instead of the client name and version in web3_clientVersion (empty to report the actual version)")
+}
+
+func (c *ClientVersionConfig) Validate() error {
+	if c.Hide && c.Override != "" {
+		return errors.New("client-version.hide and client-version.override can't both be set")
+	}
+	return nil
+}
+
+// ClientVersion returns what web3_clientVersion should report for the named client, or false to report the stack's default
+func (c *ClientVersionConfig) ClientVersion(name string) (string, bool) {
+	if c.Override != "" {
+		return c.Override, true
+	}
+	if c.Hide {
+		return name, true
+	}
+	return "", false
+}
+
+type ClientVersionAPI struct {
+	version string
+}
+
+func (a *ClientVersionAPI) ClientVersion() string {
+	return a.version
+}
+
+// RegisterClientVersion overrides the stack's web3_clientVersion if configured to, so must be called after node.New
+func (c *ClientVersionConfig) RegisterClientVersion(stack *node.Node, name string) {
+	version, ok := c.ClientVersion(name)
+	if !ok {
+		return
+	}
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "web3",
+		Service:   &ClientVersionAPI{version},
+		Public:    true,
+	}})
+}
@@ -0,0 +1,48 @@
+package genericconf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func clientVersion(t *testing.T, config *ClientVersionConfig) string {
+	t.Helper()
+	stackConf := node.DefaultConfig
+	stackConf.DataDir = ""
+	stackConf.HTTPHost = ""
+	stackConf.WSHost = ""
+	stackConf.AuthPort = 0
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stackConf.Version = "v1.2.3-test"
+	stack, err := node.New(&stackConf)
+	testhelpers.RequireImpl(t, err)
+	defer stack.Close()
+	config.RegisterClientVersion(stack, "nitro")
+	testhelpers.RequireImpl(t, stack.Start())
+	client, err := stack.Attach()
+	testhelpers.RequireImpl(t, err)
+	var version string
+	testhelpers.RequireImpl(t, client.Call(&version, "web3_clientVersion"))
+	return version
+}
+
+func TestClientVersion(t *testing.T) {
+	if version := clientVersion(t, &ClientVersionConfig{}); !strings.Contains(version, "v1.2.3-test") {
+		testhelpers.FailImpl(t, "default client version doesn't include the version", version)
+	}
+	if version := clientVersion(t, &ClientVersionConfig{Hide: true}); version != "nitro" {
+		testhelpers.FailImpl(t, "hidden client version", version)
+	}
+	if version := clientVersion(t, &ClientVersionConfig{Override: "custom"}); version != "custom" {
+		testhelpers.FailImpl(t, "overridden client version", version)
+	}
+	invalid := ClientVersionConfig{Hide: true, Override: "custom"}
+	if invalid.Validate() == nil {
+		testhelpers.FailImpl(t, "hide and override both accepted")
+	}
+}
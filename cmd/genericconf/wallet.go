@@ -4,20 +4,27 @@
 package genericconf
 
 import (
+	"fmt"
+	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	flag "github.com/spf13/pflag"
 )
 
 const PASSWORD_NOT_SET = "PASSWORD_NOT_SET"
 
+// PrivateKeyEnvPrefix marks a private key to be read from the environment variable named after it, e.g. "env:NITRO_L1_KEY"
+const PrivateKeyEnvPrefix = "env:"
+
 type WalletConfig struct {
 	Pathname      string `koanf:"pathname"`
 	Password      string `koanf:"password"`
 	PrivateKey    string `koanf:"private-key"`
 	Account       string `koanf:"account"`
 	OnlyCreateKey bool   `koanf:"only-create-key"`
+	BackupDir     string `koanf:"backup-dir"`
 }
 
 func (w *WalletConfig) Pwd() *string {
@@ -27,20 +34,39 @@ func (w *WalletConfig) Pwd() *string {
 	return &w.Password
 }
 
+// ResolvePrivateKey returns the hex private key, reading it from the environment if it's set as "env:NAME".
+// The returned key must never be logged.
+func (w *WalletConfig) ResolvePrivateKey() (string, error) {
+	if !strings.HasPrefix(w.PrivateKey, PrivateKeyEnvPrefix) {
+		return w.PrivateKey, nil
+	}
+	name := strings.TrimPrefix(w.PrivateKey, PrivateKeyEnvPrefix)
+	if name == "" {
+		return "", fmt.Errorf("missing environment variable name in private key %q", w.PrivateKey)
+	}
+	key := strings.TrimSpace(os.Getenv(name))
+	if key == "" {
+		return "", fmt.Errorf("private key environment variable %s is not set or empty", name)
+	}
+	return key, nil
+}
+
 var WalletConfigDefault = WalletConfig{
 	Pathname:      "",
 	Password:      PASSWORD_NOT_SET,
 	PrivateKey:    "",
 	Account:       "",
 	OnlyCreateKey: false,
+	BackupDir:     "",
 }
 
 func WalletConfigAddOptions(prefix string, f *flag.FlagSet, defaultPathname string) {
 	f.String(prefix+".pathname", defaultPathname, "pathname for wallet")
 	f.String(prefix+".password", WalletConfigDefault.Password, "wallet passphrase")
-	f.String(prefix+".private-key", WalletConfigDefault.PrivateKey, "private key for wallet")
+	f.String(prefix+".private-key", WalletConfigDefault.PrivateKey, "private key for wallet, or "+PrivateKeyEnvPrefix+"NAME to read it from the environment variable NAME")
 	f.String(prefix+".account", WalletConfigDefault.Account, "account to use (default is first account in keystore)")
 	f.Bool(prefix+".only-create-key", WalletConfigDefault.OnlyCreateKey, "if true, creates new key then exits")
+	f.String(prefix+".backup-dir", WalletConfigDefault.BackupDir, "directory to write an additional copy of the encrypted key file to when creating a new key")
 }
 
 func (w *WalletConfig) ResolveDirectoryNames(chain string) {
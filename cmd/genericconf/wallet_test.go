@@ -0,0 +1,35 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genericconf
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestResolvePrivateKey(t *testing.T) {
+	const key = "b6b15c8cb491557369f3c7d2c287b053eb229daa9c22138887752191c9520659"
+	config := WalletConfig{PrivateKey: key}
+	resolved, err := config.ResolvePrivateKey()
+	testhelpers.RequireImpl(t, err)
+	if resolved != key {
+		testhelpers.FailImpl(t, "private key not used as is")
+	}
+
+	t.Setenv("TEST_WALLET_KEY", key+"\n")
+	config.PrivateKey = PrivateKeyEnvPrefix + "TEST_WALLET_KEY"
+	resolved, err = config.ResolvePrivateKey()
+	testhelpers.RequireImpl(t, err)
+	if resolved != key {
+		testhelpers.FailImpl(t, "private key not read from the environment")
+	}
+
+	for _, privateKey := range []string{PrivateKeyEnvPrefix + "TEST_WALLET_KEY_MISSING", PrivateKeyEnvPrefix} {
+		config.PrivateKey = privateKey
+		if _, err := config.ResolvePrivateKey(); err == nil {
+			testhelpers.FailImpl(t, "missing environment variable accepted", privateKey)
+		}
+	}
+}
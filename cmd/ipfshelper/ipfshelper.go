@@ -2,9 +2,11 @@ package ipfshelper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -201,6 +203,71 @@ func (h *IpfsHelper) DownloadFile(ctx context.Context, cidString string, destina
 	return outputFilePath, nil
 }
 
+var ErrFileTooLarge = errors.New("ipfs file too large")
+
+// DownloadFileFromGateway fetches the file from an IPFS http gateway with the given client instead of the IPFS network,
+// for nodes which can only make outbound connections through an http proxy. Files over maxBytes are refused (0 = no limit).
+func DownloadFileFromGateway(ctx context.Context, client *http.Client, gatewayUrl string, cidString string, destinationDir string, maxBytes int64) (string, error) {
+	cidString = normalizeCidString(cidString)
+	if !strings.HasPrefix(cidString, "/") {
+		cidString = "/ipfs/" + cidString
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(gatewayUrl, "/")+cidString, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch from ipfs gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ipfs gateway returned status %v for %v", resp.Status, cidString)
+	}
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return "", fmt.Errorf("%w: %v bytes, at most %v allowed", ErrFileTooLarge, resp.ContentLength, maxBytes)
+	}
+	var body io.Reader = resp.Body
+	if maxBytes > 0 {
+		// the gateway may not tell the size up front
+		body = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	log.Info("Writing file...")
+	outputFilePath := filepath.Join(destinationDir, filepath.Base(cidString))
+	_ = os.Remove(outputFilePath)
+	file, err := os.Create(outputFilePath)
+	if err != nil {
+		return "", err
+	}
+	written, err := io.Copy(file, body)
+	closeErr := file.Close()
+	if err == nil && maxBytes > 0 && written > maxBytes {
+		err = fmt.Errorf("%w: more than %v bytes", ErrFileTooLarge, maxBytes)
+	}
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(outputFilePath)
+		return "", err
+	}
+	log.Info("Download done.")
+	return outputFilePath, nil
+}
+
+// CumulativeSize returns the size of the object with all its children, an upper bound of the size of the downloaded file
+func (h *IpfsHelper) CumulativeSize(ctx context.Context, cidString string) (int64, error) {
+	resolvedPath, err := h.api.ResolvePath(ctx, path.New(normalizeCidString(cidString)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	stat, err := h.api.Object().Stat(ctx, resolvedPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return int64(stat.CumulativeSize), nil
+}
+
 func (h *IpfsHelper) AddFile(ctx context.Context, filePath string, includeHidden bool) (path.Resolved, error) {
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
@@ -3,7 +3,10 @@ package ipfshelper
 import (
 	"bytes"
 	"context"
+	"errors"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -79,6 +82,42 @@ func TestIpfsHelper(t *testing.T) {
 	testhelpers.RequireImpl(t, err)
 }
 
+func TestDownloadFileFromGateway(t *testing.T) {
+	ctx := context.Background()
+	testCid := "QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ"
+	testData := make([]byte, 1024)
+	_, err := rand.Read(testData)
+	testhelpers.RequireImpl(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/ipfs/"+testCid {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(testData)
+	}))
+	defer server.Close()
+
+	for _, cid := range []string{testCid, "ipfs://" + testCid, "/ipfs/" + testCid} {
+		downloadedFile, err := DownloadFileFromGateway(ctx, server.Client(), server.URL+"/", cid, t.TempDir(), 0)
+		testhelpers.RequireImpl(t, err)
+		if !fileDataEqual(t, downloadedFile, testData) {
+			testhelpers.FailImpl(t, "Downloaded file does not contain expected data, cid:", cid)
+		}
+	}
+	downloadDir := t.TempDir()
+	_, err = DownloadFileFromGateway(ctx, server.Client(), server.URL, testCid, downloadDir, int64(len(testData)-1))
+	if !errors.Is(err, ErrFileTooLarge) {
+		testhelpers.FailImpl(t, "Download of too large file did not fail as expected, got:", err)
+	}
+	if _, err := os.Stat(filepath.Join(downloadDir, testCid)); !os.IsNotExist(err) {
+		testhelpers.FailImpl(t, "Partially downloaded file was not removed")
+	}
+	_, err = DownloadFileFromGateway(ctx, server.Client(), server.URL, "/ipfs/QmUnknown", t.TempDir(), 0)
+	if err == nil {
+		testhelpers.FailImpl(t, "Download of missing file did not fail")
+	}
+}
+
 func TestNormalizeCidString(t *testing.T) {
 	for _, test := range []struct {
 		input    string
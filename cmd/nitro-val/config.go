@@ -12,7 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/cmd/conf"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
-	"github.com/offchainlabs/nitro/util/colors"
 	"github.com/offchainlabs/nitro/validator/valnode"
 	flag "github.com/spf13/pflag"
 )
@@ -22,6 +21,7 @@ type ValidationNodeConfig struct {
 	Validation    valnode.Config                  `koanf:"validation" reload:"hot"`
 	LogLevel      int                             `koanf:"log-level" reload:"hot"`
 	LogType       string                          `koanf:"log-type" reload:"hot"`
+	NodeName      string                          `koanf:"node-name"`
 	FileLogging   genericconf.FileLoggingConfig   `koanf:"file-logging" reload:"hot"`
 	Persistent    conf.PersistentConfig           `koanf:"persistent"`
 	HTTP          genericconf.HTTPConfig          `koanf:"http"`
@@ -62,6 +62,7 @@ var ValidationNodeConfigDefault = ValidationNodeConfig{
 	Conf:          genericconf.ConfConfigDefault,
 	LogLevel:      int(log.LvlInfo),
 	LogType:       "plaintext",
+	NodeName:      "",
 	Persistent:    conf.PersistentConfigDefault,
 	HTTP:          HTTPConfigDefault,
 	WS:            WSConfigDefault,
@@ -79,6 +80,7 @@ func ValidationNodeConfigAddOptions(f *flag.FlagSet) {
 	valnode.ValidationConfigAddOptions("validation", f)
 	f.Int("log-level", ValidationNodeConfigDefault.LogLevel, "log level")
 	f.String("log-type", ValidationNodeConfigDefault.LogType, "log type (plaintext or json)")
+	f.String("node-name", ValidationNodeConfigDefault.NodeName, "name identifying this node in logs and metrics (defaults to the hostname)")
 	genericconf.FileLoggingConfigAddOptions("file-logging", f)
 	conf.PersistentConfigAddOptions("persistent", f)
 	genericconf.HTTPConfigAddOptions("http", f)
@@ -108,34 +110,16 @@ func (c *ValidationNodeConfig) ShallowClone() *ValidationNodeConfig {
 }
 
 func (c *ValidationNodeConfig) CanReload(new *ValidationNodeConfig) error {
-	var check func(node, other reflect.Value, path string)
 	var err error
-
-	check = func(node, value reflect.Value, path string) {
-		if node.Kind() != reflect.Struct {
-			return
-		}
-
-		for i := 0; i < node.NumField(); i++ {
-			fieldTy := node.Type().Field(i)
-			if !fieldTy.IsExported() {
-				continue
-			}
-			hot := fieldTy.Tag.Get("reload") == "hot"
-			dot := path + "." + fieldTy.Name
-
-			first := node.Field(i).Interface()
-			other := value.Field(i).Interface()
-
-			if !hot && !reflect.DeepEqual(first, other) {
-				err = fmt.Errorf("illegal change to %v%v%v", colors.Red, dot, colors.Clear)
-			} else {
-				check(node.Field(i), value.Field(i), dot)
-			}
+	configs := []reflect.Value{reflect.ValueOf(c).Elem(), reflect.ValueOf(new).Elem()}
+	genericconf.WalkConfig(configs, func(field genericconf.ConfigField, values []reflect.Value) bool {
+		hot := field.Field.Tag.Get("reload") == "hot"
+		if !hot && !reflect.DeepEqual(values[0].Interface(), values[1].Interface()) {
+			err = &genericconf.IllegalChangeError{Path: field.Path}
+			return false
 		}
-	}
-
-	check(reflect.ValueOf(c).Elem(), reflect.ValueOf(new).Elem(), "config")
+		return true
+	})
 	return err
 }
 
@@ -145,7 +129,7 @@ func (c *ValidationNodeConfig) GetReloadInterval() time.Duration {
 
 func (c *ValidationNodeConfig) Validate() error {
 	// TODO
-	return nil
+	return c.Validation.Validate()
 }
 
 var DefaultValidationNodeStackConfig = node.Config{
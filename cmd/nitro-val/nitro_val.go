@@ -35,7 +35,7 @@ func main() {
 // Checks metrics and PProf flag, runs them if enabled.
 // Note: they are separate so one can enable/disable them as they wish, the only
 // requirement is that they can't run on the same address and port.
-func startMetrics(cfg *ValidationNodeConfig) error {
+func startMetrics(ctx context.Context, cfg *ValidationNodeConfig) error {
 	mAddr := fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port)
 	pAddr := fmt.Sprintf("%v:%v", cfg.PprofCfg.Addr, cfg.PprofCfg.Port)
 	if cfg.Metrics && !metrics.Enabled {
@@ -45,12 +45,16 @@ func startMetrics(cfg *ValidationNodeConfig) error {
 		return fmt.Errorf("metrics and pprof cannot be enabled on the same address:port: %s", mAddr)
 	}
 	if cfg.Metrics {
+		genericconf.RegisterNodeNameMetric(genericconf.ResolveNodeName(cfg.NodeName))
 		go metrics.CollectProcessMetrics(cfg.MetricsServer.UpdateInterval)
 		exp.Setup(fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port))
 	}
 	if cfg.PProf {
 		genericconf.StartPprof(pAddr)
 	}
+	if err := genericconf.StartPeriodicProfiles(ctx, &cfg.PprofCfg.PeriodicProfiles); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -70,6 +74,12 @@ func mainImpl() int {
 	nodeConfig.WS.Apply(&stackConf)
 	nodeConfig.Auth.Apply(&stackConf)
 	nodeConfig.IPC.Apply(&stackConf)
+	limitedIPC, err := genericconf.NewLimitedIPC(&nodeConfig.IPC, &stackConf)
+	if err != nil {
+		// logging isn't set up yet
+		fmt.Fprintf(os.Stderr, "Error setting up IPC endpoint: %v\n", err)
+		return 1
+	}
 	stackConf.P2P.ListenAddr = ""
 	stackConf.P2P.NoDial = true
 	stackConf.P2P.NoDiscovery = true
@@ -91,7 +101,8 @@ func mainImpl() int {
 		}
 	}
 
-	err = genericconf.InitLog(nodeConfig.LogType, log.Lvl(nodeConfig.LogLevel), &nodeConfig.FileLogging, pathResolver(nodeConfig.Persistent.LogDir))
+	nodeName := genericconf.ResolveNodeName(nodeConfig.NodeName)
+	err = genericconf.InitLog(nodeConfig.LogType, log.Lvl(nodeConfig.LogLevel), &nodeConfig.FileLogging, pathResolver(nodeConfig.Persistent.LogDir), nodeName)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
 		return 1
@@ -104,13 +115,19 @@ func mainImpl() int {
 		}
 		stackConf.JWTSecret = filename
 	}
+	if stackConf.JWTSecret != "" && stackConf.AuthAddr != "" {
+		if err := genericconf.PrepareJWTSecret(stackConf.JWTSecret, nodeConfig.Auth.RegenerateJwtOnInvalid); err != nil {
+			log.Error("Failed to prepare jwt secret file", "err", err)
+			return 1
+		}
+	}
 
 	log.Info("Running Arbitrum nitro validation node", "revision", vcsRevision, "vcs.time", vcsTime)
 
 	liveNodeConfig := genericconf.NewLiveConfig[*ValidationNodeConfig](args, nodeConfig, ParseNode)
 	liveNodeConfig.SetOnReloadHook(func(oldCfg *ValidationNodeConfig, newCfg *ValidationNodeConfig) error {
 
-		return genericconf.InitLog(newCfg.LogType, log.Lvl(newCfg.LogLevel), &newCfg.FileLogging, pathResolver(nodeConfig.Persistent.LogDir))
+		return genericconf.InitLog(newCfg.LogType, log.Lvl(newCfg.LogLevel), &newCfg.FileLogging, pathResolver(nodeConfig.Persistent.LogDir), nodeName)
 	})
 
 	valnode.EnsureValidationExposedViaAuthRPC(&stackConf)
@@ -121,7 +138,7 @@ func mainImpl() int {
 		log.Crit("failed to initialize geth stack", "err", err)
 	}
 
-	if err := startMetrics(nodeConfig); err != nil {
+	if err := startMetrics(ctx, nodeConfig); err != nil {
 		log.Error("Starting metrics: %v", err)
 		return 1
 	}
@@ -148,6 +165,13 @@ func mainImpl() int {
 		fatalErrChan <- fmt.Errorf("error starting stack: %w", err)
 	}
 	defer stack.Close()
+	if limitedIPC != nil {
+		err = limitedIPC.Start(ctx)
+		if err != nil {
+			fatalErrChan <- fmt.Errorf("error starting IPC endpoint: %w", err)
+		}
+		defer limitedIPC.StopAndWait()
+	}
 
 	liveNodeConfig.Start(ctx)
 	defer liveNodeConfig.StopAndWait()
@@ -193,7 +217,7 @@ func ParseNode(ctx context.Context, args []string) (*ValidationNodeConfig, error
 
 	// Don't print wallet passwords
 	if nodeConfig.Conf.Dump {
-		err = confighelpers.DumpConfig(k, map[string]interface{}{
+		err = confighelpers.DumpConfig(k, f, map[string]interface{}{
 			"l1.wallet.password":        "",
 			"l1.wallet.private-key":     "",
 			"l2.dev-wallet.password":    "",
@@ -0,0 +1,23 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+type ReloadableConfigResult struct {
+	Hot  []string `json:"hot"`
+	Cold []string `json:"cold"`
+}
+
+// ConfigAPI tells operators which config keys can be changed on config reload and which need a restart
+type ConfigAPI struct {
+	config func() *NodeConfig
+}
+
+func (a *ConfigAPI) ReloadableConfig() *ReloadableConfigResult {
+	hot, cold := genericconf.ReloadableKeys(a.config())
+	return &ReloadableConfigResult{Hot: hot, Cold: cold}
+}
@@ -14,6 +14,8 @@ import (
 	"testing"
 	"time"
 
+	flag "github.com/spf13/pflag"
+
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/util/colors"
 	"github.com/offchainlabs/nitro/util/testhelpers"
@@ -37,12 +39,108 @@ func TestValidatorConfig(t *testing.T) {
 	Require(t, err)
 }
 
+func TestParentChainReadOnlyConfig(t *testing.T) {
+	base := "--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --http.addr 0.0.0.0 --ws.addr 0.0.0.0 --node.forwarding-target null --parent-chain.read-only"
+	_, _, _, err := ParseNode(context.Background(), strings.Split(base+" --node.staker.enable --node.staker.strategy Watchtower", " "))
+	Require(t, err)
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --node.staker.enable --node.staker.strategy MakeNodes", " "))
+	if err == nil {
+		Fail(t, "read-only parent chain accepted with an active staker")
+	}
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --node.batch-poster.enable", " "))
+	if err == nil {
+		Fail(t, "read-only parent chain accepted with the batch poster")
+	}
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --parent-chain.wallet.only-create-key", " "))
+	if err == nil {
+		Fail(t, "read-only parent chain accepted when creating a parent chain wallet key")
+	}
+}
+
+func TestReadOnlyArchiveReload(t *testing.T) {
+	args := strings.Split("--persistent.chain /tmp/data --parent-chain.id 5 --chain.id 421613 --read-only-archive --node.block-validator.enable --node.feed.input.url ws://localhost:9642", " ")
+	config, _, _, err := ParseNode(context.Background(), args)
+	Require(t, err)
+	applyReadOnlyArchive(config)
+
+	// the overrides aren't hot reloadable, so a reloaded config needs them too
+	reloaded, _, _, err := ParseNode(context.Background(), args)
+	Require(t, err)
+	if config.CanReload(reloaded) == nil {
+		Fail(t, "reload without the read-only archive overrides accepted")
+	}
+	applyReadOnlyArchive(reloaded)
+	Require(t, config.CanReload(reloaded))
+}
+
+func TestInMemoryConfig(t *testing.T) {
+	base := "--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --http.addr 0.0.0.0 --ws.addr 0.0.0.0 --node.forwarding-target null --persistent.in-memory"
+	_, _, _, err := ParseNode(context.Background(), strings.Split(base, " "))
+	Require(t, err)
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --node.caching.archive", " "))
+	if err == nil {
+		Fail(t, "in-memory databases accepted in archive mode")
+	}
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --init.url https://example.com/snapshot.tar", " "))
+	if err == nil {
+		Fail(t, "in-memory databases accepted with a database snapshot")
+	}
+	_, _, _, err = ParseNode(context.Background(), strings.Split(base+" --init.prune full", " "))
+	if err == nil {
+		Fail(t, "in-memory databases accepted with init.prune")
+	}
+	readOnlyArchive := "--persistent.chain /tmp/data --parent-chain.id 5 --chain.id 421613 --read-only-archive --persistent.in-memory"
+	_, _, _, err = ParseNode(context.Background(), strings.Split(readOnlyArchive, " "))
+	if err == nil {
+		Fail(t, "in-memory databases accepted for a read-only archive")
+	}
+}
+
 func TestAggregatorConfig(t *testing.T) {
 	args := strings.Split("--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --parent-chain.wallet.pathname /l1keystore --parent-chain.wallet.password passphrase --http.addr 0.0.0.0 --ws.addr 0.0.0.0 --node.sequencer.enable --node.feed.output.enable --node.feed.output.port 9642 --node.data-availability.enable --node.data-availability.rpc-aggregator.backends {[\"url\":\"http://localhost:8547\",\"pubkey\":\"abc==\",\"signerMask\":0x1]}", " ")
 	_, _, _, err := ParseNode(context.Background(), args)
 	Require(t, err)
 }
 
+func TestStrictConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	jsonConfig := "{\"node\":{\"sequncer\":{\"enable\":true}}}"
+	Require(t, WriteToConfigFile(configFile, jsonConfig))
+
+	args := strings.Split("--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --node.forwarding-target null --conf.strict", " ")
+	args = append(args, []string{"--conf.file", configFile}...)
+	_, _, _, err := ParseNode(context.Background(), args)
+	if err == nil || !strings.Contains(err.Error(), "node.sequncer.enable") {
+		Fail(t, "strict config parsing should report unknown key, got:", err)
+	}
+}
+
+func TestLayeredConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	baseFile := filepath.Join(dir, "base.json")
+	overlayFile := filepath.Join(dir, "overlay.json")
+	Require(t, WriteToConfigFile(baseFile, "{\"node\":{\"sequencer\":{\"max-block-speed\":\"1s\",\"max-tx-data-size\":1000}}}"))
+	Require(t, WriteToConfigFile(overlayFile, "{\"node\":{\"sequencer\":{\"max-block-speed\":\"2s\"}}}"))
+
+	args := strings.Split("--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --node.sequencer.enable --node.feed.output.enable --node.feed.output.port 9642", " ")
+	args = append(args, []string{"--conf.file", baseFile, "--conf.file", overlayFile}...)
+	config, _, _, err := ParseNode(context.Background(), args)
+	Require(t, err)
+	if config.Node.Sequencer.MaxBlockSpeed != 2*time.Second {
+		Fail(t, "later config file should override earlier one, got max-block-speed", config.Node.Sequencer.MaxBlockSpeed)
+	}
+	if config.Node.Sequencer.MaxTxDataSize != 1000 {
+		Fail(t, "earlier config file values should be kept, got max-tx-data-size", config.Node.Sequencer.MaxTxDataSize)
+	}
+
+	args = append(args, "--node.sequencer.max-block-speed", "3s")
+	config, _, _, err = ParseNode(context.Background(), args)
+	Require(t, err)
+	if config.Node.Sequencer.MaxBlockSpeed != 3*time.Second {
+		Fail(t, "command line should override config files, got max-block-speed", config.Node.Sequencer.MaxBlockSpeed)
+	}
+}
+
 func TestReloads(t *testing.T) {
 	var check func(node reflect.Value, cold bool, path string)
 	check = func(node reflect.Value, cold bool, path string) {
@@ -243,3 +341,62 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestConfigSchema(t *testing.T) {
+	f := flag.NewFlagSet("", flag.ContinueOnError)
+	NodeConfigAddOptions(f)
+	schema := make(map[string]genericconf.ConfigSchemaField)
+	for _, field := range genericconf.ConfigSchema(NodeConfigDefault, f) {
+		schema[field.Key] = field
+	}
+
+	speed, ok := schema["node.sequencer.max-block-speed"]
+	if !ok || !speed.Reload || speed.Type != "time.Duration" || speed.Default != NodeConfigDefault.Node.Sequencer.MaxBlockSpeed.String() || speed.Description == "" {
+		Fail(t, "unexpected schema for node.sequencer.max-block-speed", speed)
+	}
+	metrics, ok := schema["metrics"]
+	if !ok || metrics.Reload || metrics.Default != false {
+		Fail(t, "unexpected schema for metrics", metrics)
+	}
+	// every flag has a config key, so tooling can rely on the schema being complete
+	f.VisitAll(func(fl *flag.Flag) {
+		if _, ok := schema[fl.Name]; !ok {
+			Fail(t, "flag missing from config schema", fl.Name)
+		}
+	})
+}
+
+func TestReloadableConfig(t *testing.T) {
+	config := NodeConfigDefault
+	result := (&ConfigAPI{config: func() *NodeConfig { return &config }}).ReloadableConfig()
+	contains := func(keys []string, key string) bool {
+		for _, k := range keys {
+			if k == key {
+				return true
+			}
+		}
+		return false
+	}
+	if !contains(result.Hot, "node.sequencer.max-block-speed") || contains(result.Cold, "node.sequencer.max-block-speed") {
+		Fail(t, "node.sequencer.max-block-speed should be hot", result)
+	}
+	if !contains(result.Cold, "metrics") || contains(result.Hot, "metrics") {
+		Fail(t, "metrics should be cold", result)
+	}
+}
+
+func TestStrictDeprecations(t *testing.T) {
+	args := strings.Split("--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --node.archive", " ")
+	config, _, _, err := ParseNode(context.Background(), args)
+	Require(t, err)
+	Require(t, config.MigrateDeprecatedOptions())
+	if !config.Node.Caching.Archive {
+		Fail(t, "--node.archive wasn't migrated to --node.caching.archive")
+	}
+
+	config, _, _, err = ParseNode(context.Background(), append(args, "--strict-deprecations"))
+	Require(t, err)
+	if config.MigrateDeprecatedOptions() == nil {
+		Fail(t, "deprecated option accepted with --strict-deprecations")
+	}
+}
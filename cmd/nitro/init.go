@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net/http"
 	"os"
 	"regexp"
 	"runtime"
@@ -39,6 +41,7 @@ import (
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/ipfshelper"
 	"github.com/offchainlabs/nitro/staker"
 	"github.com/offchainlabs/nitro/statetransfer"
@@ -46,40 +49,50 @@ import (
 )
 
 type InitConfig struct {
-	Force           bool          `koanf:"force"`
-	Url             string        `koanf:"url"`
-	DownloadPath    string        `koanf:"download-path"`
-	DownloadPoll    time.Duration `koanf:"download-poll"`
-	DevInit         bool          `koanf:"dev-init"`
-	DevInitAddress  string        `koanf:"dev-init-address"`
-	DevInitBlockNum uint64        `koanf:"dev-init-blocknum"`
-	Empty           bool          `koanf:"empty"`
-	AccountsPerSync uint          `koanf:"accounts-per-sync"`
-	ImportFile      string        `koanf:"import-file"`
-	ThenQuit        bool          `koanf:"then-quit"`
-	Prune           string        `koanf:"prune"`
-	PruneBloomSize  uint64        `koanf:"prune-bloom-size"`
-	ResetToMessage  int64         `koanf:"reset-to-message"`
+	Force                   bool          `koanf:"force"`
+	Url                     string        `koanf:"url"`
+	DownloadPath            string        `koanf:"download-path"`
+	DownloadPoll            time.Duration `koanf:"download-poll"`
+	DevInit                 bool          `koanf:"dev-init"`
+	DevInitAddress          string        `koanf:"dev-init-address"`
+	DevInitBlockNum         uint64        `koanf:"dev-init-blocknum"`
+	Empty                   bool          `koanf:"empty"`
+	AccountsPerSync         uint          `koanf:"accounts-per-sync"`
+	ImportFile              string        `koanf:"import-file"`
+	ThenQuit                bool          `koanf:"then-quit"`
+	Prune                   string        `koanf:"prune"`
+	PruneBloomSize          uint64        `koanf:"prune-bloom-size"`
+	ResetToMessage          int64         `koanf:"reset-to-message"`
+	ResetBelowValidated     bool          `koanf:"reset-below-validated"`
+	VerifyDb                string        `koanf:"verify-db"`
+	VerifyDbDepth           uint64        `koanf:"verify-db-depth"`
+	SkipMigrations          bool          `koanf:"skip-migrations"`
+	DeploymentConfirmations uint64        `koanf:"deployment-confirmations"`
 }
 
 var InitConfigDefault = InitConfig{
-	Force:           false,
-	Url:             "",
-	DownloadPath:    "/tmp/",
-	DownloadPoll:    time.Minute,
-	DevInit:         false,
-	DevInitAddress:  "",
-	DevInitBlockNum: 0,
-	ImportFile:      "",
-	AccountsPerSync: 100000,
-	ThenQuit:        false,
-	Prune:           "",
-	PruneBloomSize:  2048,
-	ResetToMessage:  -1,
+	Force:                   false,
+	Url:                     "",
+	DownloadPath:            "/tmp/",
+	DownloadPoll:            time.Minute,
+	DevInit:                 false,
+	DevInitAddress:          "",
+	DevInitBlockNum:         0,
+	ImportFile:              "",
+	AccountsPerSync:         100000,
+	ThenQuit:                false,
+	Prune:                   "",
+	PruneBloomSize:          2048,
+	ResetToMessage:          -1,
+	ResetBelowValidated:     false,
+	VerifyDb:                "",
+	VerifyDbDepth:           128,
+	SkipMigrations:          false,
+	DeploymentConfirmations: 0,
 }
 
 func InitConfigAddOptions(prefix string, f *pflag.FlagSet) {
-	f.Bool(prefix+".force", InitConfigDefault.Force, "if true: in case database exists init code will be reexecuted and genesis block compared to database")
+	f.Bool(prefix+".force", InitConfigDefault.Force, "if true: in case database exists init code will be reexecuted and genesis block compared to database, and dev-init compatibility checks are skipped")
 	f.String(prefix+".url", InitConfigDefault.Url, "url to download initializtion data - will poll if download fails")
 	f.String(prefix+".download-path", InitConfigDefault.DownloadPath, "path to save temp downloaded file")
 	f.Duration(prefix+".download-poll", InitConfigDefault.DownloadPoll, "how long to wait between polling attempts")
@@ -88,20 +101,106 @@ func InitConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Uint64(prefix+".dev-init-blocknum", InitConfigDefault.DevInitBlockNum, "Number of preinit blocks. Must exist in ancient database.")
 	f.Bool(prefix+".empty", InitConfigDefault.Empty, "init with empty state")
 	f.Bool(prefix+".then-quit", InitConfigDefault.ThenQuit, "quit after init is done")
-	f.String(prefix+".import-file", InitConfigDefault.ImportFile, "path for json data to import")
+	f.String(prefix+".import-file", InitConfigDefault.ImportFile, "path for json data to import (\"-\" to read it from stdin)")
 	f.Uint(prefix+".accounts-per-sync", InitConfigDefault.AccountsPerSync, "during init - sync database every X accounts. Lower value for low-memory systems. 0 disables.")
 	f.String(prefix+".prune", InitConfigDefault.Prune, "pruning for a given use: \"full\" for full nodes serving RPC requests, or \"validator\" for validators")
 	f.Uint64(prefix+".prune-bloom-size", InitConfigDefault.PruneBloomSize, "the amount of memory in megabytes to use for the pruning bloom filter (higher values prune better)")
 	f.Int64(prefix+".reset-to-message", InitConfigDefault.ResetToMessage, "forces a reset to an old message height. Also set max-reorg-resequence-depth=0 to force re-reading messages")
+	f.Bool(prefix+".reset-below-validated", InitConfigDefault.ResetBelowValidated, "DANGEROUS! allow reset-to-message to reset below the messages already validated by the block validator or staked on the parent chain")
+	f.String(prefix+".verify-db", InitConfigDefault.VerifyDb, "check the consistency of an existing chain database on startup: \"warn\" to log problems, \"abort\" to also refuse to start, or empty to skip the check")
+	f.Uint64(prefix+".verify-db-depth", InitConfigDefault.VerifyDbDepth, "number of blocks below the head checked by verify-db")
+	f.Bool(prefix+".skip-migrations", InitConfigDefault.SkipMigrations, "DANGEROUS! don't apply pending database migrations on startup, only for emergencies")
+	f.Uint64(prefix+".deployment-confirmations", InitConfigDefault.DeploymentConfirmations, "number of parent chain confirmations of the rollup deployment block to wait for before reading the chain's init message from it, protecting a new node from shallow parent chain reorgs")
 }
 
-func downloadInit(ctx context.Context, initConfig *InitConfig) (string, error) {
+func (c *InitConfig) Validate() error {
+	if c.VerifyDb != "" && c.VerifyDb != VerifyDbWarn && c.VerifyDb != VerifyDbAbort {
+		return fmt.Errorf("invalid init.verify-db %q, must be empty, %q or %q", c.VerifyDb, VerifyDbWarn, VerifyDbAbort)
+	}
+	return nil
+}
+
+// resetFloorValidator is the part of the block validator resetFloor reads the validated messages from
+type resetFloorValidator interface {
+	LastValidatedMessageCount() (arbutil.MessageIndex, bool, error)
+}
+
+// resetFloorStaker is the part of the staker resetFloor reads the staked messages from
+type resetFloorStaker interface {
+	LatestStakedMessageCount(ctx context.Context) (arbutil.MessageIndex, error)
+}
+
+// resetFloor returns the lowest message count reset-to-message can reset to without dropping messages
+// that were already validated by the block validator or are part of our latest staked assertion.
+// Either of blockValidator and nodeStaker may be nil.
+func resetFloor(ctx context.Context, blockValidator resetFloorValidator, nodeStaker resetFloorStaker) (arbutil.MessageIndex, string, error) {
+	var floor arbutil.MessageIndex
+	var reason string
+	if blockValidator != nil {
+		count, found, err := blockValidator.LastValidatedMessageCount()
+		if err != nil {
+			return 0, "", fmt.Errorf("error finding last validated message: %w", err)
+		}
+		if found && count > floor {
+			floor, reason = count, "validated by the block validator"
+		}
+	}
+	if nodeStaker != nil {
+		count, err := nodeStaker.LatestStakedMessageCount(ctx)
+		if err != nil {
+			return 0, "", fmt.Errorf("error finding latest staked message: %w", err)
+		}
+		if count > floor {
+			floor, reason = count, "staked on the parent chain"
+		}
+	}
+	return floor, reason, nil
+}
+
+// checkResetToMessage refuses to reset below the messages already validated or staked, unless reset-below-validated is set
+func checkResetToMessage(ctx context.Context, initConfig *InitConfig, blockValidator resetFloorValidator, nodeStaker resetFloorStaker, count arbutil.MessageIndex) error {
+	if initConfig.ResetBelowValidated {
+		return nil
+	}
+	floor, reason, err := resetFloor(ctx, blockValidator, nodeStaker)
+	if err != nil {
+		return fmt.Errorf("%w (set --init.reset-below-validated to reset anyway)", err)
+	}
+	if count < floor {
+		return fmt.Errorf("refusing to reset to message %v: messages up to %v were already %v, set --init.reset-below-validated to reset anyway", count, floor, reason)
+	}
+	return nil
+}
+
+// checkNodeResetToMessage is checkResetToMessage with the node's block validator and staker, if it runs them
+func checkNodeResetToMessage(ctx context.Context, initConfig *InitConfig, node *arbnode.Node, count arbutil.MessageIndex) error {
+	var blockValidator resetFloorValidator
+	if node.BlockValidator != nil {
+		blockValidator = node.BlockValidator
+	}
+	var nodeStaker resetFloorStaker
+	if node.Staker != nil {
+		nodeStaker = node.Staker
+	}
+	return checkResetToMessage(ctx, initConfig, blockValidator, nodeStaker, count)
+}
+
+func downloadInit(ctx context.Context, initConfig *InitConfig, outbound *genericconf.OutboundHTTPConfig) (string, error) {
 	if initConfig.Url == "" {
 		return "", nil
 	}
 	if strings.HasPrefix(initConfig.Url, "file:") {
 		return initConfig.Url[5:], nil
 	}
+	if ipfshelper.CanBeIpfsPath(initConfig.Url) && outbound.IpfsGateway != "" {
+		log.Info("Downloading initial database via IPFS gateway", "url", initConfig.Url, "gateway", outbound.IpfsGateway)
+		// http.DefaultTransport has the outbound-http proxy and TLS settings
+		initFile, err := ipfshelper.DownloadFileFromGateway(ctx, &http.Client{Transport: http.DefaultTransport}, outbound.IpfsGateway, initConfig.Url, initConfig.DownloadPath, 0)
+		if err != nil {
+			return "", fmt.Errorf("Failed to download file from IPFS gateway: %w", err)
+		}
+		return initFile, nil
+	}
 	if ipfshelper.CanBeIpfsPath(initConfig.Url) {
 		ipfsNode, err := ipfshelper.CreateIpfsHelper(ctx, initConfig.DownloadPath, false, []string{}, ipfshelper.DefaultIpfsProfiles)
 		if err != nil {
@@ -122,6 +221,8 @@ func downloadInit(ctx context.Context, initConfig *InitConfig) (string, error) {
 		return initFile, nil
 	}
 	grabclient := grab.NewClient()
+	// use the outbound-http proxy and TLS settings
+	grabclient.HTTPClient = &http.Client{Transport: http.DefaultTransport}
 	log.Info("Downloading initial database", "url", initConfig.Url)
 	fmt.Println()
 	printTicker := time.NewTicker(time.Second)
@@ -214,6 +315,27 @@ func validateBlockChain(blockChain *core.BlockChain, chainConfig *params.ChainCo
 	return nil
 }
 
+// validateDevInit checks that an existing dev database was created with the currently requested dev-init parameters
+func validateDevInit(blockChain *core.BlockChain, initConfig *InitConfig) error {
+	genesisNum := blockChain.Config().ArbitrumChainParams.GenesisBlockNum
+	if genesisNum != initConfig.DevInitBlockNum {
+		return fmt.Errorf("database was initialized with dev-init-blocknum %v but %v was requested", genesisNum, initConfig.DevInitBlockNum)
+	}
+	genesis := blockChain.GetHeaderByNumber(genesisNum)
+	if genesis == nil {
+		return fmt.Errorf("database missing genesis block %v", genesisNum)
+	}
+	statedb, err := blockChain.StateAt(genesis.Root)
+	if err != nil {
+		return fmt.Errorf("failed to open genesis state: %w", err)
+	}
+	devAddr := common.HexToAddress(initConfig.DevInitAddress)
+	if statedb.GetBalance(devAddr).Sign() == 0 {
+		return fmt.Errorf("dev account %v was not funded at genesis, database was initialized with a different dev-init-address", devAddr)
+	}
+	return nil
+}
+
 type importantRoots struct {
 	chainDb ethdb.Database
 	roots   []common.Hash
@@ -446,6 +568,25 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 				if err != nil {
 					return chainDb, nil, err
 				}
+				// the chain info from ipfs isn't downloaded again for an existing database
+				configuredGenesisBlockNr, err := chaininfo.GetGenesisBlockNum(config.Chain.ID, config.Chain.Name, config.Chain.InfoFiles, config.Chain.InfoJson, config.Chain.InfoMaxSources, config.Chain.InfoMaxBytes)
+				if err != nil {
+					log.Warn("couldn't read genesis block number from chain info to check the database against", "err", err)
+					configuredGenesisBlockNr = 0
+				}
+				if err := checkStoredGenesis(chainDb, chainConfig, configuredGenesisBlockNr); err != nil {
+					return chainDb, nil, err
+				}
+				err = runDbMigrations(ctx, chainDb, dbMigrations, config.Init.SkipMigrations)
+				if err != nil {
+					return chainDb, nil, err
+				}
+				if config.Init.VerifyDb != "" {
+					problems := verifyChainDb(chainDb, config.Init.VerifyDbDepth)
+					if len(problems) > 0 && config.Init.VerifyDb == VerifyDbAbort {
+						return chainDb, nil, fmt.Errorf("database verification found %d problems, first: %v", len(problems), problems[0])
+					}
+				}
 				err = pruneChainDb(ctx, chainDb, stack, config, cacheConfig, l1Client, rollupAddrs)
 				if err != nil {
 					return chainDb, nil, fmt.Errorf("error pruning: %w", err)
@@ -464,7 +605,11 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		}
 	}
 
-	initFile, err := downloadInit(ctx, &config.Init)
+	if config.ReadOnlyArchive {
+		return nil, nil, errors.New("--read-only-archive requires an existing chain database in the data directory")
+	}
+
+	initFile, err := downloadInit(ctx, &config.Init, &config.OutboundHTTP)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -492,7 +637,16 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		return chainDb, nil, err
 	}
 
-	if config.Init.ImportFile != "" {
+	if config.Init.ImportFile == stdinArg {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return chainDb, nil, fmt.Errorf("error reading import data from stdin: %w", err)
+		}
+		initDataReader, err = statetransfer.NewJsonInitDataReaderFromBytes(data, ".")
+		if err != nil {
+			return chainDb, nil, fmt.Errorf("error reading import data from stdin: %w", err)
+		}
+	} else if config.Init.ImportFile != "" {
 		initDataReader, err = statetransfer.NewJsonInitDataReader(config.Init.ImportFile)
 		if err != nil {
 			return chainDb, nil, fmt.Errorf("error reading import file: %w", err)
@@ -533,6 +687,10 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		if chainConfig == nil {
 			return chainDb, nil, errors.New("no --init.* mode supplied and chain data not in expected directory")
 		}
+		err = runDbMigrations(ctx, chainDb, dbMigrations, config.Init.SkipMigrations)
+		if err != nil {
+			return chainDb, nil, err
+		}
 		l2BlockChain, err = execution.GetBlockChain(chainDb, cacheConfig, chainConfig, config.Node.TxLookupLimit)
 		if err != nil {
 			return chainDb, nil, err
@@ -553,13 +711,27 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		}
 		combinedL2ChainInfoFiles := config.Chain.InfoFiles
 		if config.Chain.InfoIpfsUrl != "" {
-			l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, config.Chain.InfoIpfsUrl, config.Chain.InfoIpfsDownloadPath)
+			l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, config.Chain.InfoIpfsUrl, config.Chain.InfoIpfsDownloadPath, config.Chain.InfoMaxBytes, &config.OutboundHTTP)
 			if err != nil {
 				log.Error("error getting l2 chain info file from ipfs", "err", err)
 			}
 			combinedL2ChainInfoFiles = append(combinedL2ChainInfoFiles, l2ChainInfoIpfsFile)
 		}
-		chainConfig, err = chaininfo.GetChainConfig(new(big.Int).SetUint64(config.Chain.ID), config.Chain.Name, genesisBlockNr, combinedL2ChainInfoFiles, config.Chain.InfoJson)
+		configuredGenesisBlockNr, err := chaininfo.GetGenesisBlockNum(config.Chain.ID, config.Chain.Name, combinedL2ChainInfoFiles, config.Chain.InfoJson, config.Chain.InfoMaxSources, config.Chain.InfoMaxBytes)
+		if err != nil {
+			return chainDb, nil, err
+		}
+		if config.Init.Empty && configuredGenesisBlockNr > 0 {
+			// a migrated chain starts after its pre-init blocks, which must already be in the database
+			genesisBlockNr = configuredGenesisBlockNr
+			initDataReader = statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{
+				NextBlockNumber: genesisBlockNr,
+			})
+		}
+		if err := checkGenesisBlockNum(genesisBlockNr, configuredGenesisBlockNr); err != nil {
+			return chainDb, nil, err
+		}
+		chainConfig, err = chaininfo.GetChainConfig(new(big.Int).SetUint64(config.Chain.ID), config.Chain.Name, genesisBlockNr, combinedL2ChainInfoFiles, config.Chain.InfoJson, config.Chain.InfoMaxSources, config.Chain.InfoMaxBytes)
 		if err != nil {
 			return chainDb, nil, err
 		}
@@ -580,11 +752,24 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 			log.Warn("Re-creating genesis though it seems to exist in database", "blockNr", genesisBlockNr)
 		}
 		log.Info("Initializing", "ancients", ancients, "genesisBlockNr", genesisBlockNr)
+		// a database that already has chain data might predate migrations, a new one needs none of them
+		if execution.TryReadStoredChainConfig(chainDb) != nil {
+			err = runDbMigrations(ctx, chainDb, dbMigrations, config.Init.SkipMigrations)
+		} else {
+			err = markDbMigrationsApplied(chainDb, dbMigrations)
+		}
+		if err != nil {
+			return chainDb, nil, err
+		}
 		if config.Init.ThenQuit {
 			cacheConfig.SnapshotWait = true
 		}
 		var parsedInitMessage *arbostypes.ParsedInitMessage
 		if config.Node.ParentChainReader.Enable {
+			err = arbnode.WaitForDeploymentConfirmations(ctx, l1Client, rollupAddrs.DeployedAt, config.Init.DeploymentConfirmations, config.Node.ParentChainReader.PollInterval)
+			if err != nil {
+				return chainDb, nil, fmt.Errorf("failed waiting for rollup deployment block confirmations: %w", err)
+			}
 			delayedBridge, err := arbnode.NewDelayedBridge(l1Client, rollupAddrs.Bridge, rollupAddrs.DeployedAt)
 			if err != nil {
 				return chainDb, nil, fmt.Errorf("failed creating delayed bridge while attempting to get serialized chain config from init message: %w", err)
@@ -612,6 +797,10 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 				return chainDb, nil, fmt.Errorf("expected L2 chain ID %v but read L2 chain ID %v from init message in L1 inbox", chainId, parsedInitMessage.ChainId)
 			}
 			if parsedInitMessage.ChainConfig != nil {
+				initGenesisBlockNr := parsedInitMessage.ChainConfig.ArbitrumChainParams.GenesisBlockNum
+				if initGenesisBlockNr != genesisBlockNr {
+					return chainDb, nil, fmt.Errorf("genesis block number %v doesn't match genesis block number %v from init message in L1 inbox", genesisBlockNr, initGenesisBlockNr)
+				}
 				if err := parsedInitMessage.ChainConfig.CheckCompatible(chainConfig, chainConfig.ArbitrumChainParams.GenesisBlockNum, 0); err != nil {
 					return chainDb, nil, fmt.Errorf("incompatible chain config read from init message in L1 inbox: %w", err)
 				}
@@ -652,9 +841,34 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		return chainDb, l2BlockChain, err
 	}
 
+	genesisBlockNr := chainConfig.ArbitrumChainParams.GenesisBlockNum
+	log.Info("L2 chain ready", "chainId", chainConfig.ChainID, "genesisBlockNum", genesisBlockNr, "genesisHash", rawdb.ReadCanonicalHash(chainDb, genesisBlockNr), "head", l2BlockChain.CurrentBlock().Number)
+
 	return chainDb, l2BlockChain, nil
 }
 
+// checkGenesisBlockNum checks the init data starts at the genesis block number from the chain info, if it sets one
+func checkGenesisBlockNum(initDataGenesisBlockNr uint64, configuredGenesisBlockNr uint64) error {
+	if configuredGenesisBlockNr == 0 || initDataGenesisBlockNr == configuredGenesisBlockNr {
+		return nil
+	}
+	return fmt.Errorf("chain info sets genesis block number %v, but the init data starts at block %v", configuredGenesisBlockNr, initDataGenesisBlockNr)
+}
+
+// checkStoredGenesis checks an existing database was initialized at the genesis block number from the chain info, if it sets one,
+// and still has its genesis block
+func checkStoredGenesis(chainDb ethdb.Reader, storedChainConfig *params.ChainConfig, configuredGenesisBlockNr uint64) error {
+	genesisBlockNr := storedChainConfig.ArbitrumChainParams.GenesisBlockNum
+	if configuredGenesisBlockNr != 0 && genesisBlockNr != configuredGenesisBlockNr {
+		return fmt.Errorf("chain info sets genesis block number %v, but the database was initialized with genesis block %v", configuredGenesisBlockNr, genesisBlockNr)
+	}
+	genesisHash := rawdb.ReadCanonicalHash(chainDb, genesisBlockNr)
+	if genesisHash == (common.Hash{}) || rawdb.ReadHeader(chainDb, genesisHash, genesisBlockNr) == nil {
+		return fmt.Errorf("database missing genesis block %v", genesisBlockNr)
+	}
+	return nil
+}
+
 func testTxIndexUpdated(chainDb ethdb.Database, lastBlock uint64) bool {
 	var transactions types.Transactions
 	blockHash := rawdb.ReadCanonicalHash(chainDb, lastBlock)
@@ -0,0 +1,102 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type testResetFloorValidator struct {
+	count arbutil.MessageIndex
+	found bool
+	err   error
+}
+
+func (v *testResetFloorValidator) LastValidatedMessageCount() (arbutil.MessageIndex, bool, error) {
+	return v.count, v.found, v.err
+}
+
+type testResetFloorStaker struct {
+	count arbutil.MessageIndex
+	err   error
+}
+
+func (s *testResetFloorStaker) LatestStakedMessageCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return s.count, s.err
+}
+
+func TestCheckResetToMessage(t *testing.T) {
+	ctx := context.Background()
+	config := InitConfigDefault
+	blockValidator := &testResetFloorValidator{count: 100, found: true}
+	nodeStaker := &testResetFloorStaker{count: 80}
+
+	floor, _, err := resetFloor(ctx, blockValidator, nodeStaker)
+	Require(t, err)
+	if floor != 100 {
+		Fail(t, "unexpected reset floor", floor)
+	}
+	Require(t, checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 100))
+	if checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 99) == nil {
+		Fail(t, "reset below the validated messages accepted")
+	}
+
+	// the staked messages hold as well, also without a block validator
+	nodeStaker.count = 120
+	if checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 110) == nil {
+		Fail(t, "reset below the staked messages accepted")
+	}
+	if checkResetToMessage(ctx, &config, nil, nodeStaker, 110) == nil {
+		Fail(t, "reset below the staked messages accepted without a block validator")
+	}
+	// a validated state that isn't in our chain yet doesn't hold
+	blockValidator.found = false
+	Require(t, checkResetToMessage(ctx, &config, blockValidator, nil, 50))
+
+	// the check fails closed
+	nodeStaker.err = errors.New("parent chain unavailable")
+	if checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 500) == nil {
+		Fail(t, "reset accepted without knowing the staked messages")
+	}
+
+	config.ResetBelowValidated = true
+	Require(t, checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 0))
+	blockValidator.found = true
+	nodeStaker.err = nil
+	Require(t, checkResetToMessage(ctx, &config, blockValidator, nodeStaker, 0))
+}
+
+func TestCheckStoredGenesis(t *testing.T) {
+	chainDb := rawdb.NewMemoryDatabase()
+	storedChainConfig := params.ArbitrumDevTestChainConfig()
+	storedChainConfig.ArbitrumChainParams.GenesisBlockNum = 100
+
+	if checkStoredGenesis(chainDb, storedChainConfig, 0) == nil {
+		Fail(t, "database without its genesis block accepted")
+	}
+	genesis := &types.Header{Number: big.NewInt(100), Difficulty: common.Big1}
+	rawdb.WriteHeader(chainDb, genesis)
+	rawdb.WriteCanonicalHash(chainDb, genesis.Hash(), 100)
+
+	Require(t, checkStoredGenesis(chainDb, storedChainConfig, 0))
+	Require(t, checkStoredGenesis(chainDb, storedChainConfig, 100))
+	// a database initialized at another genesis block than the chain info sets
+	if checkStoredGenesis(chainDb, storedChainConfig, 200) == nil {
+		Fail(t, "database with mismatched genesis block number accepted")
+	}
+	storedChainConfig.ArbitrumChainParams.GenesisBlockNum = 0
+	if checkStoredGenesis(chainDb, storedChainConfig, 100) == nil {
+		Fail(t, "database initialized at block 0 accepted for a migrated chain")
+	}
+}
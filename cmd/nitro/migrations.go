@@ -0,0 +1,171 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	dbMigrationVersionKey    = []byte("_nitroMigrationVersion")
+	dbMigrationCheckpointKey = []byte("_nitroMigrationCheckpoint")
+)
+
+const dbMigrationLogInterval = 8 * time.Second
+
+// dbMigration is a one-time change to the chain database, applied on startup to databases
+// created by older versions.
+type dbMigration struct {
+	version     uint64
+	description string
+	// migrate may be interrupted at any point, in which case it is run again on the next startup.
+	// Long migrations should save checkpoints with progress.Save and resume from progress.Checkpoint.
+	migrate func(ctx context.Context, db ethdb.Database, progress *dbMigrationProgress) error
+}
+
+// dbMigrations must be sorted by version, and versions must never be reused
+var dbMigrations = []dbMigration{}
+
+func latestDbMigrationVersion(migrations []dbMigration) uint64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+type dbMigrationProgress struct {
+	db         ethdb.KeyValueStore
+	version    uint64
+	checkpoint []byte
+	start      time.Time
+	lastLog    time.Time
+}
+
+// Checkpoint returns the checkpoint last saved by an interrupted run of the migration, or nil
+func (p *dbMigrationProgress) Checkpoint() []byte {
+	return p.checkpoint
+}
+
+// Save stores a checkpoint to resume the migration from, and periodically logs the progress
+func (p *dbMigrationProgress) Save(checkpoint []byte, done uint64, total uint64) error {
+	value := binary.BigEndian.AppendUint64(nil, p.version)
+	value = append(value, checkpoint...)
+	if err := p.db.Put(dbMigrationCheckpointKey, value); err != nil {
+		return err
+	}
+	p.checkpoint = checkpoint
+	if time.Since(p.lastLog) >= dbMigrationLogInterval {
+		p.lastLog = time.Now()
+		logArgs := []interface{}{"version", p.version, "done", done, "elapsed", time.Since(p.start)}
+		if total > 0 {
+			logArgs = append(logArgs, "total", total, "percent", fmt.Sprintf("%.2f", float64(done)*100/float64(total)))
+		}
+		log.Info("database migration in progress", logArgs...)
+	}
+	return nil
+}
+
+func readDbMigrationVersion(db ethdb.KeyValueReader) (uint64, error) {
+	has, err := db.Has(dbMigrationVersionKey)
+	if err != nil || !has {
+		return 0, err
+	}
+	data, err := db.Get(dbMigrationVersionKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid database migration version %x", data)
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func readDbMigrationCheckpoint(db ethdb.KeyValueReader, version uint64) ([]byte, error) {
+	has, err := db.Has(dbMigrationCheckpointKey)
+	if err != nil || !has {
+		return nil, err
+	}
+	data, err := db.Get(dbMigrationCheckpointKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 {
+		return nil, errors.New("invalid database migration checkpoint")
+	}
+	if binary.BigEndian.Uint64(data[:8]) != version {
+		// left over by another migration, which completed since
+		return nil, nil
+	}
+	return data[8:], nil
+}
+
+// markDbMigrationsApplied records that the database needs none of the given migrations,
+// which is the case for newly initialized databases
+func markDbMigrationsApplied(db ethdb.KeyValueWriter, migrations []dbMigration) error {
+	return db.Put(dbMigrationVersionKey, binary.BigEndian.AppendUint64(nil, latestDbMigrationVersion(migrations)))
+}
+
+// runDbMigrations applies the migrations the database hasn't applied yet, in order.
+// Each applied migration is recorded in the database, so an interrupted run continues
+// with the interrupted migration on the next startup.
+func runDbMigrations(ctx context.Context, db ethdb.Database, migrations []dbMigration, skip bool) error {
+	applied, err := readDbMigrationVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read database migration version: %w", err)
+	}
+	var pending []dbMigration
+	for _, migration := range migrations {
+		if migration.version > applied {
+			pending = append(pending, migration)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if skip {
+		log.Warn("skipping pending database migrations, the node may not work correctly", "applied", applied, "pending", len(pending), "latest", latestDbMigrationVersion(migrations))
+		return nil
+	}
+	log.Info("running database migrations", "applied", applied, "pending", len(pending))
+	for _, migration := range pending {
+		checkpoint, err := readDbMigrationCheckpoint(db, migration.version)
+		if err != nil {
+			return err
+		}
+		if checkpoint != nil {
+			log.Info("resuming interrupted database migration", "version", migration.version, "description", migration.description)
+		} else {
+			log.Info("starting database migration", "version", migration.version, "description", migration.description)
+		}
+		progress := &dbMigrationProgress{
+			db:         db,
+			version:    migration.version,
+			checkpoint: checkpoint,
+			start:      time.Now(),
+			lastLog:    time.Now(),
+		}
+		if err := migration.migrate(ctx, db, progress); err != nil {
+			return fmt.Errorf("database migration %v (%v) failed: %w", migration.version, migration.description, err)
+		}
+		batch := db.NewBatch()
+		if err := batch.Put(dbMigrationVersionKey, binary.BigEndian.AppendUint64(nil, migration.version)); err != nil {
+			return err
+		}
+		if err := batch.Delete(dbMigrationCheckpointKey); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		log.Info("database migration done", "version", migration.version, "elapsed", time.Since(progress.start))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestDbMigrationsResume(t *testing.T) {
+	ctx := context.Background()
+	db := rawdb.NewMemoryDatabase()
+	errInterrupted := errors.New("interrupted")
+
+	var runs []uint64
+	interrupt := true
+	migrations := []dbMigration{
+		{
+			version:     1,
+			description: "first",
+			migrate: func(ctx context.Context, db ethdb.Database, progress *dbMigrationProgress) error {
+				runs = append(runs, 1)
+				return nil
+			},
+		},
+		{
+			version:     2,
+			description: "second",
+			migrate: func(ctx context.Context, db ethdb.Database, progress *dbMigrationProgress) error {
+				runs = append(runs, 2)
+				start := byte(0)
+				if checkpoint := progress.Checkpoint(); checkpoint != nil {
+					start = checkpoint[0]
+				}
+				for i := start; i < 10; i++ {
+					if interrupt && i == 5 {
+						return errInterrupted
+					}
+					if err := progress.Save([]byte{i + 1}, uint64(i+1), 10); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	err := runDbMigrations(ctx, db, migrations, false)
+	if !errors.Is(err, errInterrupted) {
+		testhelpers.FailImpl(t, "expected interrupted migration, got", err)
+	}
+	version, err := readDbMigrationVersion(db)
+	testhelpers.RequireImpl(t, err)
+	if version != 1 {
+		testhelpers.FailImpl(t, "unexpected migration version", version)
+	}
+	checkpoint, err := readDbMigrationCheckpoint(db, 2)
+	testhelpers.RequireImpl(t, err)
+	if len(checkpoint) != 1 || checkpoint[0] != 5 {
+		testhelpers.FailImpl(t, "unexpected checkpoint", checkpoint)
+	}
+
+	// skipping leaves the pending migration in place
+	testhelpers.RequireImpl(t, runDbMigrations(ctx, db, migrations, true))
+	version, err = readDbMigrationVersion(db)
+	testhelpers.RequireImpl(t, err)
+	if version != 1 {
+		testhelpers.FailImpl(t, "skipped migration was applied, version", version)
+	}
+
+	interrupt = false
+	testhelpers.RequireImpl(t, runDbMigrations(ctx, db, migrations, false))
+	version, err = readDbMigrationVersion(db)
+	testhelpers.RequireImpl(t, err)
+	if version != 2 {
+		testhelpers.FailImpl(t, "unexpected migration version", version)
+	}
+	checkpoint, err = readDbMigrationCheckpoint(db, 2)
+	testhelpers.RequireImpl(t, err)
+	if checkpoint != nil {
+		testhelpers.FailImpl(t, "checkpoint not removed", checkpoint)
+	}
+	if len(runs) != 3 || runs[0] != 1 || runs[1] != 2 || runs[2] != 2 {
+		testhelpers.FailImpl(t, "unexpected migration runs", runs)
+	}
+
+	// a new database needs no migrations
+	newDb := rawdb.NewMemoryDatabase()
+	testhelpers.RequireImpl(t, markDbMigrationsApplied(newDb, migrations))
+	runs = nil
+	testhelpers.RequireImpl(t, runDbMigrations(ctx, newDb, migrations, false))
+	if len(runs) != 0 {
+		testhelpers.FailImpl(t, "migrations ran on new database", runs)
+	}
+}
@@ -6,6 +6,7 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +16,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -28,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	_ "github.com/ethereum/go-ethereum/eth/tracers/js"
@@ -38,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbnode/execution"
@@ -51,7 +56,6 @@ import (
 	_ "github.com/offchainlabs/nitro/nodeInterface"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/staker"
-	"github.com/offchainlabs/nitro/util/colors"
 	"github.com/offchainlabs/nitro/util/headerreader"
 	"github.com/offchainlabs/nitro/util/rpcclient"
 	"github.com/offchainlabs/nitro/util/signature"
@@ -68,14 +72,17 @@ func addUnlockWallet(accountManager *accounts.Manager, walletConf *genericconf.W
 	var devPrivKey *ecdsa.PrivateKey
 	var err error
 	if walletConf.PrivateKey != "" {
-		devPrivKey, err = crypto.HexToECDSA(walletConf.PrivateKey)
+		hexKey, err := walletConf.ResolvePrivateKey()
 		if err != nil {
 			return common.Address{}, err
 		}
+		devPrivKey, err = crypto.HexToECDSA(hexKey)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid private key: %w", err)
+		}
 
 		devAddr = crypto.PubkeyToAddress(devPrivKey.PublicKey)
 
-		log.Info("Dev node funded private key", "priv", walletConf.PrivateKey)
 		log.Info("Funded public address", "addr", devAddr)
 	}
 
@@ -123,13 +130,16 @@ func closeDb(db io.Closer, name string) {
 }
 
 func main() {
-	os.Exit(mainImpl())
+	var shutdown shutdownRecorder
+	exitCode := mainImpl(&shutdown)
+	shutdown.report(exitCode)
+	os.Exit(exitCode)
 }
 
 // Checks metrics and PProf flag, runs them if enabled.
 // Note: they are separate so one can enable/disable them as they wish, the only
 // requirement is that they can't run on the same address and port.
-func startMetrics(cfg *NodeConfig) error {
+func startMetrics(ctx context.Context, cfg *NodeConfig) error {
 	mAddr := fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port)
 	pAddr := fmt.Sprintf("%v:%v", cfg.PprofCfg.Addr, cfg.PprofCfg.Port)
 	if cfg.Metrics && !metrics.Enabled {
@@ -139,17 +149,21 @@ func startMetrics(cfg *NodeConfig) error {
 		return fmt.Errorf("metrics and pprof cannot be enabled on the same address:port: %s", mAddr)
 	}
 	if cfg.Metrics {
+		genericconf.RegisterNodeNameMetric(genericconf.ResolveNodeName(cfg.NodeName))
 		go metrics.CollectProcessMetrics(cfg.MetricsServer.UpdateInterval)
 		exp.Setup(fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port))
 	}
 	if cfg.PProf {
 		genericconf.StartPprof(pAddr)
 	}
+	if err := genericconf.StartPeriodicProfiles(ctx, &cfg.PprofCfg.PeriodicProfiles); err != nil {
+		return err
+	}
 	return nil
 }
 
 // Returns the exit code
-func mainImpl() int {
+func mainImpl(shutdown *shutdownRecorder) int {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
 
@@ -158,13 +172,24 @@ func mainImpl() int {
 	if err != nil {
 		confighelpers.PrintErrorAndExit(err, printSampleUsage)
 	}
+	shutdown.setConfig(&nodeConfig.ShutdownRecord)
 	stackConf := node.DefaultConfig
 	stackConf.DataDir = nodeConfig.Persistent.Chain
 	stackConf.DBEngine = "leveldb"
+	if nodeConfig.Persistent.InMemory {
+		// the stack opens memory databases when it has no data directory
+		stackConf.DataDir = ""
+	}
 	nodeConfig.HTTP.Apply(&stackConf)
 	nodeConfig.WS.Apply(&stackConf)
 	nodeConfig.Auth.Apply(&stackConf)
 	nodeConfig.IPC.Apply(&stackConf)
+	limitedIPC, err := genericconf.NewLimitedIPC(&nodeConfig.IPC, &stackConf)
+	if err != nil {
+		// logging isn't set up yet
+		fmt.Fprintf(os.Stderr, "Error setting up IPC endpoint: %v\n", err)
+		return shutdown.startupFailed(err)
+	}
 	nodeConfig.GraphQL.Apply(&stackConf)
 	if nodeConfig.WS.ExposeAll {
 		stackConf.WSModules = append(stackConf.WSModules, "personal")
@@ -194,22 +219,52 @@ func mainImpl() int {
 		filename := pathResolver(nodeConfig.Persistent.GlobalConfig)("jwtsecret")
 		if err := genericconf.TryCreatingJWTSecret(filename); err != nil {
 			log.Error("Failed to prepare jwt secret file", "err", err)
-			return 1
+			return shutdown.startupFailed(err)
 		}
 		stackConf.JWTSecret = filename
 	}
-	err = genericconf.InitLog(nodeConfig.LogType, log.Lvl(nodeConfig.LogLevel), &nodeConfig.FileLogging, pathResolver(nodeConfig.Persistent.LogDir))
+	nodeName := genericconf.ResolveNodeName(nodeConfig.NodeName)
+	err = genericconf.InitLog(nodeConfig.LogType, log.Lvl(nodeConfig.LogLevel), &nodeConfig.FileLogging, pathResolver(nodeConfig.Persistent.LogDir), nodeName)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
-		return 1
+		return shutdown.startupFailed(err)
+	}
+	// after the logger is set up, so a failure is reported
+	if err := nodeConfig.Performance.Apply(); err != nil {
+		log.Error("failed to apply performance config", "err", err)
+		return shutdown.startupFailed(err)
+	}
+	if err := nodeConfig.OutboundHTTP.Apply(); err != nil {
+		log.Error("failed to apply outbound http config", "err", err)
+		return shutdown.startupFailed(err)
+	}
+	// after the logger is set up, so the warning about a regenerated secret or the error about an invalid one is shown
+	if stackConf.JWTSecret != "" && stackConf.AuthAddr != "" {
+		if err := genericconf.PrepareJWTSecret(stackConf.JWTSecret, nodeConfig.Auth.RegenerateJwtOnInvalid); err != nil {
+			log.Error("Failed to prepare jwt secret file", "err", err)
+			return shutdown.startupFailed(err)
+		}
+	}
+	if err := nodeConfig.MigrateDeprecatedOptions(); err != nil {
+		log.Error("refusing to start with deprecated options", "err", err)
+		return shutdown.startupFailed(err)
 	}
-	if nodeConfig.Node.Archive {
-		log.Warn("--node.archive has been deprecated. Please use --node.caching.archive instead.")
-		nodeConfig.Node.Caching.Archive = true
+	if nodeConfig.Persistent.InMemory {
+		log.Warn("Running with in-memory databases, all chain data will be lost on exit")
 	}
 
 	log.Info("Running Arbitrum nitro node", "revision", vcsRevision, "vcs.time", vcsTime)
 
+	// the sequencer's own user-facing errors, which aren't redacted with --rpc.error-detail=generic
+	genericconf.PassThroughRpcErrors(
+		execution.ErrNoSequencer, // also ErrSequencerRoleSwitched
+		execution.ErrRetrySequencer,
+		execution.ErrQueuedTxExpired,
+		execution.ErrSenderNotWhitelisted,
+		txpool.ErrOversizedData, // also OversizedTxError
+	)
+	nodeConfig.Rpc.Apply()
+
 	if nodeConfig.Node.Dangerous.NoL1Listener {
 		nodeConfig.Node.ParentChainReader.Enable = false
 		nodeConfig.Node.BatchPoster.Enable = false
@@ -217,6 +272,10 @@ func mainImpl() int {
 	} else {
 		nodeConfig.Node.ParentChainReader.Enable = true
 	}
+	if nodeConfig.ReadOnlyArchive {
+		log.Info("running as a read-only archive, the chain will not advance")
+		applyReadOnlyArchive(nodeConfig)
+	}
 
 	if nodeConfig.Node.Sequencer.Enable {
 		if nodeConfig.Node.ForwardingTargetF() != "" {
@@ -239,6 +298,11 @@ func mainImpl() int {
 	sequencerNeedsKey := (nodeConfig.Node.Sequencer.Enable && !nodeConfig.Node.Feed.Output.DisableSigning) || nodeConfig.Node.BatchPoster.Enable
 	validatorNeedsKey := nodeConfig.Node.Staker.OnlyCreateWalletContract || nodeConfig.Node.Staker.Enable && !strings.EqualFold(nodeConfig.Node.Staker.Strategy, "watchtower")
 
+	if nodeConfig.ParentChain.ReadOnly {
+		// config validation already refused the options needing a parent chain wallet
+		log.Info("parent chain connection is read-only")
+	}
+
 	l1Wallet.ResolveDirectoryNames(nodeConfig.Persistent.Chain)
 	defaultL1WalletConfig := conf.DefaultL1WalletConfig
 	defaultL1WalletConfig.ResolveDirectoryNames(nodeConfig.Persistent.Chain)
@@ -292,7 +356,7 @@ func mainImpl() int {
 
 	combinedL2ChainInfoFile := nodeConfig.Chain.InfoFiles
 	if nodeConfig.Chain.InfoIpfsUrl != "" {
-		l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, nodeConfig.Chain.InfoIpfsUrl, nodeConfig.Chain.InfoIpfsDownloadPath)
+		l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, nodeConfig.Chain.InfoIpfsUrl, nodeConfig.Chain.InfoIpfsDownloadPath, nodeConfig.Chain.InfoMaxBytes, &nodeConfig.OutboundHTTP)
 		if err != nil {
 			log.Error("error getting chain info file from ipfs", "err", err)
 		}
@@ -320,8 +384,18 @@ func mainImpl() int {
 			nodeConfig.Node.RPC.MaxRecreateStateDepth = arbitrum.DefaultNonArchiveNodeMaxRecreateStateDepth
 		}
 	}
+	// set if the block validator is disabled at startup because its machine couldn't be loaded
+	var watchtowerFallback atomic.Bool
 	liveNodeConfig := genericconf.NewLiveConfig[*NodeConfig](args, nodeConfig, func(ctx context.Context, args []string) (*NodeConfig, error) {
 		nodeConfig, _, _, err := ParseNode(ctx, args)
+		if err == nil && nodeConfig.ReadOnlyArchive {
+			// the overrides aren't reloadable, so they're applied to reloaded configs too
+			applyReadOnlyArchive(nodeConfig)
+		}
+		if err == nil && watchtowerFallback.Load() {
+			// node.block-validator.enable can't be reloaded, so the fallback is applied to reloaded configs too
+			err = fallBackToWatchtower(nodeConfig)
+		}
 		return nodeConfig, err
 	})
 
@@ -330,6 +404,9 @@ func mainImpl() int {
 	if nodeConfig.Node.ParentChainReader.Enable {
 		confFetcher := func() *rpcclient.ClientConfig { return &liveNodeConfig.Get().ParentChain.Connection }
 		rpcClient := rpcclient.NewRpcClient(confFetcher, nil)
+		if nodeConfig.ParentChain.ReadOnly {
+			rpcClient.SetReadOnly()
+		}
 		err := rpcClient.Start(ctx)
 		if err != nil {
 			log.Crit("couldn't connect to L1", "err", err)
@@ -340,12 +417,15 @@ func mainImpl() int {
 			log.Crit("couldn't read L1 chainid", "err", err)
 		}
 		if l1ChainId.Uint64() != nodeConfig.ParentChain.ID {
-			log.Crit("L1 chainID doesn't fit config", "found", l1ChainId.Uint64(), "expected", nodeConfig.ParentChain.ID)
+			if !nodeConfig.ParentChain.Dangerous.AllowChainIdMismatch {
+				log.Crit("L1 chainID doesn't fit config", "found", l1ChainId.Uint64(), "expected", nodeConfig.ParentChain.ID)
+			}
+			log.Error("L1 chainID doesn't fit config, continuing because parent-chain.dangerous.allow-chainid-mismatch is set", "found", l1ChainId.Uint64(), "expected", nodeConfig.ParentChain.ID)
 		}
 
 		log.Info("connected to l1 chain", "l1url", nodeConfig.ParentChain.Connection.URL, "l1chainid", nodeConfig.ParentChain.ID)
 
-		rollupAddrs, err = chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson)
+		rollupAddrs, err = chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson, nodeConfig.Chain.InfoMaxSources, nodeConfig.Chain.InfoMaxBytes)
 		if err != nil {
 			log.Crit("error getting rollup addresses", "err", err)
 		}
@@ -363,11 +443,11 @@ func mainImpl() int {
 		}
 
 		// Just create validator smart wallet if needed then exit
-		deployInfo, err := chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson)
+		deployInfo, err := chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson, nodeConfig.Chain.InfoMaxSources, nodeConfig.Chain.InfoMaxBytes)
 		if err != nil {
 			log.Crit("error getting rollup addresses config", "err", err)
 		}
-		addr, err := staker.GetValidatorWalletContract(ctx, deployInfo.ValidatorWalletCreator, int64(deployInfo.DeployedAt), l1TransactionOptsValidator, l1Reader, true)
+		addr, err := staker.CreateValidatorWalletContract(ctx, &nodeConfig.Node.Staker.WalletCreation, deployInfo.ValidatorWalletCreator, int64(deployInfo.DeployedAt), l1TransactionOptsValidator, l1Reader)
 		if err != nil {
 			log.Crit("error creating validator wallet contract", "error", err, "address", l1TransactionOptsValidator.From.Hex())
 		}
@@ -380,18 +460,39 @@ func mainImpl() int {
 		nodeConfig.Node.TxLookupLimit = 0
 	}
 
-	resourcemanager.Init(&nodeConfig.Node.ResourceMgmt)
+	fatalErrChan := make(chan error, 10)
+
+	resourcemanager.Init(func() *resourcemanager.Config { return &liveNodeConfig.Get().Node.ResourceMgmt }, fatalErrChan)
 
 	var sameProcessValidationNodeEnabled bool
 	if nodeConfig.Node.BlockValidator.Enable && (nodeConfig.Node.BlockValidator.ValidationServer.URL == "self" || nodeConfig.Node.BlockValidator.ValidationServer.URL == "self-auth") {
 		sameProcessValidationNodeEnabled = true
 		valnode.EnsureValidationExposedViaAuthRPC(&stackConf)
+		if nodeConfig.Node.BlockValidator.ValidationServer.URL == "self-auth" {
+			if err := valnode.CheckValidationExposedViaAuthRPC(&stackConf, &nodeConfig.Validation); err != nil {
+				if nodeConfig.Validation.AuthRPCCheck != valnode.AuthRPCCheckWarn {
+					log.Error("same-process validation can't be reached over the auth RPC", "err", err)
+					return shutdown.startupFailed(err)
+				}
+				log.Warn("same-process validation can't be reached over the auth RPC", "err", err)
+			}
+		}
+	}
+	endpoints, err := listenEndpoints(&stackConf, nodeConfig)
+	if err != nil {
+		log.Error("cannot start node", "err", err)
+		return shutdown.startupFailed(err)
+	}
+	if err := waitForPorts(ctx, endpoints, &nodeConfig.PortConflict); err != nil {
+		log.Error("cannot start node", "err", err)
+		return shutdown.startupFailed(err)
 	}
 	stack, err := node.New(&stackConf)
 	if err != nil {
 		flag.Usage()
 		log.Crit("failed to initialize geth stack", "err", err)
 	}
+	nodeConfig.ClientVersion.RegisterClientVersion(stack, "nitro")
 	{
 		devAddr, err := addUnlockWallet(stack.AccountManager(), l2DevWallet)
 		if err != nil {
@@ -403,12 +504,14 @@ func mainImpl() int {
 		}
 	}
 
-	if err := startMetrics(nodeConfig); err != nil {
+	if err := startMetrics(ctx, nodeConfig); err != nil {
 		log.Error("Starting metrics: %v", err)
-		return 1
+		return shutdown.startupFailed(err)
 	}
 
 	var deferFuncs []func()
+	// the part of deferFuncs that closes the rpc apis, which the node doesn't close itself
+	var apiDeferFuncs []func()
 	defer func() {
 		for i := range deferFuncs {
 			deferFuncs[i]()
@@ -423,28 +526,35 @@ func mainImpl() int {
 	if err != nil {
 		flag.Usage()
 		log.Error("error initializing database", "err", err)
-		return 1
+		return shutdown.startupFailed(err)
 	}
 
 	arbDb, err := stack.OpenDatabase("arbitrumdata", 0, 0, "", false)
 	deferFuncs = append(deferFuncs, func() { closeDb(arbDb, "arbDb") })
 	if err != nil {
 		log.Error("failed to open database", "err", err)
-		return 1
+		return shutdown.startupFailed(err)
+	}
+
+	if err := execution.CheckChainArbOSVersion(l2BlockChain, &nodeConfig.Node.ArbOSVersionCheck); err != nil {
+		if nodeConfig.Node.ArbOSVersionCheck.Action == execution.ArbOSVersionCheckRefuse {
+			log.Error("refusing to start, upgrade the node or set --node.arbos-version-check.action="+execution.ArbOSVersionCheckWarn+" to run anyway", "err", err)
+			return shutdown.startupFailed(err)
+		}
+		log.Error("running with an unsupported ArbOS version, the node stops once it reaches a message it can't execute", "err", err)
 	}
 
 	if nodeConfig.Init.ThenQuit && nodeConfig.Init.ResetToMessage < 0 {
+		shutdown.setReason(ShutdownReasonThenQuit, nil)
 		return 0
 	}
 
 	if l2BlockChain.Config().ArbitrumChainParams.DataAvailabilityCommittee && !nodeConfig.Node.DataAvailability.Enable {
 		flag.Usage()
 		log.Error("a data availability service must be configured for this chain (see the --node.data-availability family of options)")
-		return 1
+		return shutdown.startupFailed(errors.New("no data availability service configured for a data availability committee chain"))
 	}
 
-	fatalErrChan := make(chan error, 10)
-
 	var valNode *valnode.ValidationNode
 	if sameProcessValidationNodeEnabled {
 		valNode, err = valnode.CreateValidationNode(
@@ -452,7 +562,21 @@ func mainImpl() int {
 			stack,
 			fatalErrChan,
 		)
-		if err != nil {
+		if err == nil {
+			err = checkValidationMachines(valNode.CheckMachine, &nodeConfig.Node.BlockValidator)
+		}
+		if err != nil && isMachineLoadError(err) {
+			valNode = nil
+			if err := handleMachineLoadFailure(nodeConfig, err); err != nil {
+				log.Error("couldn't load the validation machine", "err", err)
+				return shutdown.startupFailed(err)
+			}
+			watchtowerFallback.Store(true)
+		} else if err != nil {
+			if nodeConfig.Node.BlockValidator.RequireValidationNode {
+				log.Error("couldn't init required validation node", "err", err)
+				return shutdown.startupFailed(err)
+			}
 			valNode = nil
 			log.Warn("couldn't init validation node", "err", err)
 		}
@@ -474,12 +598,77 @@ func mainImpl() int {
 	)
 	if err != nil {
 		log.Error("failed to create node", "err", err)
-		return 1
+		return shutdown.startupFailed(err)
+	}
+	var stateRange *arbnode.StateRangeFinder
+	if nodeConfig.Rpc.PrunedStateDetails && !nodeConfig.Node.Caching.Archive {
+		stateRange = arbnode.NewStateRangeFinder(l2BlockChain)
+	}
+	if nodeConfig.Rpc.ArchiveFallbackUrl != "" || stateRange != nil {
+		// registered after the node's eth apis, so these methods override them
+		historicalState, err := arbnode.NewHistoricalStateAPI(nodeConfig.Rpc.ArchiveFallbackUrl, stateRange, currentNode.EthAPIs())
+		if err != nil {
+			log.Error("failed to connect to archive fallback", "err", err)
+			return shutdown.startupFailed(err)
+		}
+		deferFuncs = append(deferFuncs, historicalState.Close)
+		apiDeferFuncs = append(apiDeferFuncs, historicalState.Close)
+		stack.RegisterAPIs([]rpc.API{{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   historicalState,
+			Public:    true,
+		}})
+	}
+	if nodeConfig.Rpc.ReceiptCacheSize > 0 {
+		// registered after the node's eth apis, so its eth_getTransactionReceipt overrides theirs
+		receiptCache, err := arbnode.NewReceiptCacheAPI(nodeConfig.Rpc.ReceiptCacheSize, nodeConfig.Rpc.ReceiptCacheTTL, l2BlockChain, currentNode.EthAPIs())
+		if err != nil {
+			log.Error("failed to create receipt cache", "err", err)
+			return shutdown.startupFailed(err)
+		}
+		deferFuncs = append(deferFuncs, receiptCache.Close)
+		apiDeferFuncs = append(apiDeferFuncs, receiptCache.Close)
+		stack.RegisterAPIs([]rpc.API{{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   receiptCache,
+			Public:    true,
+		}})
+	}
+	if nodeConfig.Rpc.GasPriceOracle.Enable {
+		// registered after the node's eth apis, so its eth_gasPrice and eth_maxPriorityFeePerGas override theirs
+		stack.RegisterAPIs([]rpc.API{{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   arbnode.NewGasPriceOracleAPI(&nodeConfig.Rpc.GasPriceOracle, l2BlockChain),
+			Public:    true,
+		}})
 	}
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace:     "arbdebug",
+		Version:       "1.0",
+		Service:       &ConfigAPI{config: liveNodeConfig.Get},
+		Public:        false,
+		Authenticated: true,
+	}, {
+		Namespace:     "arbdebug",
+		Version:       "1.0",
+		Service:       &genericconf.LogLevelAPI{},
+		Public:        false,
+		Authenticated: true,
+	}, {
+		Namespace:     "arbdebug",
+		Version:       "1.0",
+		Service:       &genericconf.RecentErrorsAPI{},
+		Public:        false,
+		Authenticated: true,
+	}})
 	liveNodeConfig.SetOnReloadHook(func(oldCfg *NodeConfig, newCfg *NodeConfig) error {
-		if err := genericconf.InitLog(newCfg.LogType, log.Lvl(newCfg.LogLevel), &newCfg.FileLogging, pathResolver(nodeConfig.Persistent.LogDir)); err != nil {
+		if err := genericconf.InitLog(newCfg.LogType, log.Lvl(newCfg.LogLevel), &newCfg.FileLogging, pathResolver(nodeConfig.Persistent.LogDir), nodeName); err != nil {
 			return fmt.Errorf("failed to re-init logging: %w", err)
 		}
+		genericconf.SetMaxInFlightRequests(newCfg.Rpc.MaxInFlightRequests)
 		return currentNode.OnConfigReload(&oldCfg.Node, &newCfg.Node)
 	})
 
@@ -496,13 +685,27 @@ func mainImpl() int {
 			if err != nil {
 				panic(err)
 			}
+		} else if nodeConfig.Init.Force {
+			log.Warn("skipping dev-init compatibility check of existing database due to --init.force")
+		} else {
+			err = currentNode.TxStreamer.CheckFakeInitMessage()
+			if err == nil {
+				err = validateDevInit(l2BlockChain, &nodeConfig.Init)
+			}
+			if err != nil {
+				log.Error("existing database is incompatible with the requested dev-init parameters, use a fresh data directory or --init.force to override", "err", err)
+				return shutdown.startupFailed(err)
+			}
 		}
 	}
 	gqlConf := nodeConfig.GraphQL
 	if gqlConf.Enable {
 		if err := graphql.New(stack, currentNode.Execution.Backend.APIBackend(), currentNode.Execution.FilterSystem, gqlConf.CORSDomain, gqlConf.VHosts); err != nil {
-			log.Error("failed to register the GraphQL service", "err", err)
-			return 1
+			if !gqlConf.Optional {
+				log.Error("failed to register the GraphQL service", "err", err)
+				return shutdown.startupFailed(err)
+			}
+			log.Warn("failed to register the GraphQL service, continuing without it", "err", err)
 		}
 	}
 
@@ -513,14 +716,43 @@ func mainImpl() int {
 		} else {
 			log.Info("validation node started")
 		}
+		if err == nil && nodeConfig.Node.BlockValidator.RequireValidationNode {
+			err = valNode.CheckHealth(ctx)
+			if err != nil {
+				fatalErrChan <- fmt.Errorf("required validation node is unhealthy, not starting node: %w", err)
+			}
+		}
 	}
 	if err == nil {
 		err = currentNode.Start(ctx)
 		if err != nil {
-			fatalErrChan <- fmt.Errorf("error starting node: %w", err)
+			fatalErrChan <- fmt.Errorf("error starting node: %w", explainStartError(err, endpoints))
+		}
+		// replace the previous deferFuncs closing the database and blockchain, StopAndWait closes them.
+		// the rpc apis are still closed, after the node stops.
+		deferFuncs = append([]func(){func() { currentNode.StopAndWait() }}, apiDeferFuncs...)
+	}
+	if err == nil && nodeConfig.SummaryLog.Interval > 0 {
+		summary := newActivitySummary(currentNode, l2BlockChain, nodeConfig.SummaryLog.Interval)
+		summary.Start(ctx)
+		defer summary.StopAndWait()
+	}
+	if err == nil && valNode != nil && nodeConfig.Node.BlockValidator.ValidationServer.URL == "self-auth" {
+		if authErr := valnode.CheckValidationReachableViaAuthRPC(ctx, stack); authErr != nil {
+			if nodeConfig.Validation.AuthRPCCheck != valnode.AuthRPCCheckWarn {
+				err = authErr
+				fatalErrChan <- fmt.Errorf("same-process validation isn't reachable over the auth RPC: %w", err)
+			} else {
+				log.Warn("same-process validation isn't reachable over the auth RPC", "err", authErr)
+			}
+		}
+	}
+	if err == nil && limitedIPC != nil {
+		err = limitedIPC.Start(ctx)
+		if err != nil {
+			fatalErrChan <- fmt.Errorf("error starting IPC endpoint: %w", err)
 		}
-		// remove previous deferFuncs, StopAndWait closes database and blockchain.
-		deferFuncs = []func(){func() { currentNode.StopAndWait() }}
+		defer limitedIPC.StopAndWait()
 	}
 
 	sigint := make(chan os.Signal, 1)
@@ -529,12 +761,22 @@ func mainImpl() int {
 	exitCode := 0
 
 	if err == nil && nodeConfig.Init.ResetToMessage > 0 {
-		err = currentNode.TxStreamer.ReorgTo(arbutil.MessageIndex(nodeConfig.Init.ResetToMessage))
+		resetCount := arbutil.MessageIndex(nodeConfig.Init.ResetToMessage)
+		err = checkNodeResetToMessage(ctx, &nodeConfig.Init, currentNode, resetCount)
+		if err == nil {
+			err = currentNode.TxStreamer.ReorgTo(resetCount)
+		}
 		if err != nil {
-			fatalErrChan <- fmt.Errorf("error reseting message: %w", err)
+			err = fmt.Errorf("error reseting message: %w", err)
+			fatalErrChan <- err
 			exitCode = 1
 		}
 		if nodeConfig.Init.ThenQuit {
+			if err != nil {
+				shutdown.setReason(ShutdownReasonFatalError, err)
+			} else {
+				shutdown.setReason(ShutdownReasonResetComplete, nil)
+			}
 			close(sigint)
 
 			return exitCode
@@ -546,8 +788,10 @@ func mainImpl() int {
 		log.Error("shutting down due to fatal error", "err", err)
 		defer log.Error("shut down due to fatal error", "err", err)
 		exitCode = 1
+		shutdown.setReason(ShutdownReasonFatalError, err)
 	case <-sigint:
 		log.Info("shutting down because of sigint")
+		shutdown.setReason(ShutdownReasonSigint, nil)
 	}
 
 	// cause future ctrl+c's to panic
@@ -557,43 +801,69 @@ func mainImpl() int {
 }
 
 type NodeConfig struct {
-	Conf          genericconf.ConfConfig          `koanf:"conf" reload:"hot"`
-	Node          arbnode.Config                  `koanf:"node" reload:"hot"`
-	Validation    valnode.Config                  `koanf:"validation" reload:"hot"`
-	ParentChain   conf.L1Config                   `koanf:"parent-chain" reload:"hot"`
-	Chain         conf.L2Config                   `koanf:"chain"`
-	LogLevel      int                             `koanf:"log-level" reload:"hot"`
-	LogType       string                          `koanf:"log-type" reload:"hot"`
-	FileLogging   genericconf.FileLoggingConfig   `koanf:"file-logging" reload:"hot"`
-	Persistent    conf.PersistentConfig           `koanf:"persistent"`
-	HTTP          genericconf.HTTPConfig          `koanf:"http"`
-	WS            genericconf.WSConfig            `koanf:"ws"`
-	IPC           genericconf.IPCConfig           `koanf:"ipc"`
-	Auth          genericconf.AuthRPCConfig       `koanf:"auth"`
-	GraphQL       genericconf.GraphQLConfig       `koanf:"graphql"`
-	Metrics       bool                            `koanf:"metrics"`
-	MetricsServer genericconf.MetricsServerConfig `koanf:"metrics-server"`
-	PProf         bool                            `koanf:"pprof"`
-	PprofCfg      genericconf.PProf               `koanf:"pprof-cfg"`
-	Init          InitConfig                      `koanf:"init"`
-	Rpc           genericconf.RpcConfig           `koanf:"rpc"`
+	Conf               genericconf.ConfConfig          `koanf:"conf" reload:"hot"`
+	Node               arbnode.Config                  `koanf:"node" reload:"hot"`
+	Validation         valnode.Config                  `koanf:"validation" reload:"hot"`
+	ParentChain        conf.L1Config                   `koanf:"parent-chain" reload:"hot"`
+	Chain              conf.L2Config                   `koanf:"chain"`
+	LogLevel           int                             `koanf:"log-level" reload:"hot"`
+	LogType            string                          `koanf:"log-type" reload:"hot"`
+	NodeName           string                          `koanf:"node-name"`
+	FileLogging        genericconf.FileLoggingConfig   `koanf:"file-logging" reload:"hot"`
+	Persistent         conf.PersistentConfig           `koanf:"persistent"`
+	HTTP               genericconf.HTTPConfig          `koanf:"http"`
+	WS                 genericconf.WSConfig            `koanf:"ws"`
+	IPC                genericconf.IPCConfig           `koanf:"ipc"`
+	Auth               genericconf.AuthRPCConfig       `koanf:"auth"`
+	GraphQL            genericconf.GraphQLConfig       `koanf:"graphql"`
+	Metrics            bool                            `koanf:"metrics"`
+	MetricsServer      genericconf.MetricsServerConfig `koanf:"metrics-server"`
+	PProf              bool                            `koanf:"pprof"`
+	PprofCfg           genericconf.PProf               `koanf:"pprof-cfg"`
+	Init               InitConfig                      `koanf:"init"`
+	Rpc                genericconf.RpcConfig           `koanf:"rpc" reload:"hot"`
+	OutboundHTTP       genericconf.OutboundHTTPConfig  `koanf:"outbound-http"`
+	DumpSchema         bool                            `koanf:"dump-config-schema"`
+	StrictDeprecations bool                            `koanf:"strict-deprecations"`
+	ReadOnlyArchive    bool                            `koanf:"read-only-archive"`
+	PortConflict       PortConflictConfig              `koanf:"port-conflict"`
+	Performance        genericconf.PerformanceConfig   `koanf:"performance"`
+	ShutdownRecord     ShutdownRecordConfig            `koanf:"shutdown-record"`
+	SummaryLog         SummaryLogConfig                `koanf:"summary-log"`
+	ClientVersion      genericconf.ClientVersionConfig `koanf:"client-version"`
 }
 
 var NodeConfigDefault = NodeConfig{
-	Conf:          genericconf.ConfConfigDefault,
-	Node:          arbnode.ConfigDefault,
-	ParentChain:   conf.L1ConfigDefault,
-	Chain:         conf.L2ConfigDefault,
-	LogLevel:      int(log.LvlInfo),
-	LogType:       "plaintext",
-	Persistent:    conf.PersistentConfigDefault,
-	HTTP:          genericconf.HTTPConfigDefault,
-	WS:            genericconf.WSConfigDefault,
-	IPC:           genericconf.IPCConfigDefault,
-	Metrics:       false,
-	MetricsServer: genericconf.MetricsServerConfigDefault,
-	PProf:         false,
-	PprofCfg:      genericconf.PProfDefault,
+	Conf:               genericconf.ConfConfigDefault,
+	Node:               arbnode.ConfigDefault,
+	Validation:         valnode.DefaultValidationConfig,
+	ParentChain:        conf.L1ConfigDefault,
+	Chain:              conf.L2ConfigDefault,
+	LogLevel:           int(log.LvlInfo),
+	LogType:            "plaintext",
+	NodeName:           "",
+	FileLogging:        genericconf.DefaultFileLoggingConfig,
+	Persistent:         conf.PersistentConfigDefault,
+	HTTP:               genericconf.HTTPConfigDefault,
+	WS:                 genericconf.WSConfigDefault,
+	IPC:                genericconf.IPCConfigDefault,
+	Auth:               genericconf.AuthRPCConfigDefault,
+	GraphQL:            genericconf.GraphQLConfigDefault,
+	Metrics:            false,
+	MetricsServer:      genericconf.MetricsServerConfigDefault,
+	PProf:              false,
+	PprofCfg:           genericconf.PProfDefault,
+	Init:               InitConfigDefault,
+	Rpc:                genericconf.DefaultRpcConfig,
+	OutboundHTTP:       genericconf.OutboundHTTPConfigDefault,
+	DumpSchema:         false,
+	StrictDeprecations: false,
+	ReadOnlyArchive:    false,
+	PortConflict:       PortConflictConfigDefault,
+	Performance:        genericconf.PerformanceConfigDefault,
+	ShutdownRecord:     ShutdownRecordConfigDefault,
+	SummaryLog:         SummaryLogConfigDefault,
+	ClientVersion:      genericconf.ClientVersionConfigDefault,
 }
 
 func NodeConfigAddOptions(f *flag.FlagSet) {
@@ -604,6 +874,7 @@ func NodeConfigAddOptions(f *flag.FlagSet) {
 	conf.L2ConfigAddOptions("chain", f)
 	f.Int("log-level", NodeConfigDefault.LogLevel, "log level")
 	f.String("log-type", NodeConfigDefault.LogType, "log type (plaintext or json)")
+	f.String("node-name", NodeConfigDefault.NodeName, "name identifying this node in logs and metrics (defaults to the hostname)")
 	genericconf.FileLoggingConfigAddOptions("file-logging", f)
 	conf.PersistentConfigAddOptions("persistent", f)
 	genericconf.HTTPConfigAddOptions("http", f)
@@ -618,6 +889,15 @@ func NodeConfigAddOptions(f *flag.FlagSet) {
 
 	InitConfigAddOptions("init", f)
 	genericconf.RpcConfigAddOptions("rpc", f)
+	genericconf.OutboundHTTPConfigAddOptions("outbound-http", f)
+	f.Bool("dump-config-schema", NodeConfigDefault.DumpSchema, "print all configuration keys with their types, defaults and whether they can be hot reloaded as JSON, then exit")
+	f.Bool("strict-deprecations", NodeConfigDefault.StrictDeprecations, "fail to start instead of warning when deprecated options are used")
+	f.Bool("read-only-archive", NodeConfigDefault.ReadOnlyArchive, "serve a frozen copy of the chain from an existing database, without parent chain access, sequencing or accepting transactions")
+	PortConflictConfigAddOptions("port-conflict", f)
+	genericconf.PerformanceConfigAddOptions("performance", f)
+	ShutdownRecordConfigAddOptions("shutdown-record", f)
+	SummaryLogConfigAddOptions("summary-log", f)
+	genericconf.ClientVersionConfigAddOptions("client-version", f)
 }
 
 func (c *NodeConfig) ResolveDirectoryNames() error {
@@ -637,42 +917,151 @@ func (c *NodeConfig) ShallowClone() *NodeConfig {
 	return config
 }
 
+func dumpConfigSchema(f *flag.FlagSet) error {
+	schema, err := json.MarshalIndent(genericconf.ConfigSchema(NodeConfigDefault, f), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(schema))
+	os.Exit(0)
+	return fmt.Errorf("Unreachable")
+}
+
+// applyReadOnlyArchive disables everything that would advance the chain or accept transactions for --read-only-archive
+func applyReadOnlyArchive(nodeConfig *NodeConfig) {
+	nodeConfig.Node.ParentChainReader.Enable = false
+	nodeConfig.Node.BatchPoster.Enable = false
+	nodeConfig.Node.DelayedSequencer.Enable = false
+	nodeConfig.Node.BlockValidator.Enable = false
+	nodeConfig.Node.MessagePruner.Enable = false
+	nodeConfig.Node.Feed.Input.URL = []string{}
+	// transactions are rejected rather than forwarded
+	nodeConfig.Node.ForwardingTarget = "null"
+}
+
 func (c *NodeConfig) CanReload(new *NodeConfig) error {
-	var check func(node, other reflect.Value, path string)
 	var err error
-
-	check = func(node, value reflect.Value, path string) {
-		if node.Kind() != reflect.Struct {
-			return
+	configs := []reflect.Value{reflect.ValueOf(c).Elem(), reflect.ValueOf(new).Elem()}
+	genericconf.WalkConfig(configs, func(field genericconf.ConfigField, values []reflect.Value) bool {
+		hot := field.Field.Tag.Get("reload") == "hot"
+		if !hot && !reflect.DeepEqual(values[0].Interface(), values[1].Interface()) {
+			err = &genericconf.IllegalChangeError{Path: field.Path}
+			return false
 		}
+		return true
+	})
+	return err
+}
 
-		for i := 0; i < node.NumField(); i++ {
-			fieldTy := node.Type().Field(i)
-			if !fieldTy.IsExported() {
-				continue
-			}
-			hot := fieldTy.Tag.Get("reload") == "hot"
-			dot := path + "." + fieldTy.Name
-
-			first := node.Field(i).Interface()
-			other := value.Field(i).Interface()
-
-			if !hot && !reflect.DeepEqual(first, other) {
-				err = fmt.Errorf("illegal change to %v%v%v", colors.Red, dot, colors.Clear)
-			} else {
-				check(node.Field(i), value.Field(i), dot)
-			}
-		}
+// MigrateDeprecatedOptions applies deprecated options to their replacements and warns about each of them,
+// or with strict-deprecations returns an error with the migration hints instead
+func (c *NodeConfig) MigrateDeprecatedOptions() error {
+	var hints []string
+	if c.Node.Archive {
+		hints = append(hints, "--node.archive has been deprecated. Please use --node.caching.archive instead.")
+		c.Node.Caching.Archive = true
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	if c.StrictDeprecations {
+		return fmt.Errorf("deprecated options used with --strict-deprecations: %v", strings.Join(hints, " "))
 	}
+	for _, hint := range hints {
+		log.Warn(hint)
+	}
+	return nil
+}
 
-	check(reflect.ValueOf(c).Elem(), reflect.ValueOf(new).Elem(), "config")
-	return err
+// parentChainWriters lists the enabled options which need a parent chain wallet
+func (c *NodeConfig) parentChainWriters() []string {
+	var writers []string
+	if c.Node.BatchPoster.Enable {
+		writers = append(writers, "--node.batch-poster.enable")
+	}
+	if c.Node.Sequencer.Enable && !c.Node.Feed.Output.DisableSigning {
+		// the feed is signed with the parent chain wallet
+		writers = append(writers, "--node.sequencer.enable without --node.feed.output.disable-signing")
+	}
+	if c.Node.Staker.Enable && !strings.EqualFold(c.Node.Staker.Strategy, "watchtower") {
+		writers = append(writers, "--node.staker.enable with strategy "+c.Node.Staker.Strategy)
+	}
+	if c.Node.Staker.OnlyCreateWalletContract {
+		writers = append(writers, "--node.staker.only-create-wallet-contract")
+	}
+	if c.ParentChain.Wallet.OnlyCreateKey {
+		writers = append(writers, "--parent-chain.wallet.only-create-key")
+	}
+	if c.Node.BatchPoster.ParentChainWallet.OnlyCreateKey {
+		writers = append(writers, "--node.batch-poster.parent-chain-wallet.only-create-key")
+	}
+	if c.Node.Staker.ParentChainWallet.OnlyCreateKey {
+		writers = append(writers, "--node.staker.parent-chain-wallet.only-create-key")
+	}
+	return writers
 }
 
 func (c *NodeConfig) Validate() error {
 	if err := c.ParentChain.Validate(); err != nil {
 		return err
 	}
+	if err := c.Chain.Validate(); err != nil {
+		return err
+	}
+	if err := c.Validation.Validate(); err != nil {
+		return err
+	}
+	if err := c.Rpc.Validate(); err != nil {
+		return err
+	}
+	if err := c.OutboundHTTP.Validate(); err != nil {
+		return err
+	}
+	if err := c.Init.Validate(); err != nil {
+		return err
+	}
+	if err := c.PortConflict.Validate(); err != nil {
+		return err
+	}
+	if err := c.Performance.Validate(); err != nil {
+		return err
+	}
+	if err := c.ShutdownRecord.Validate(); err != nil {
+		return err
+	}
+	if err := c.SummaryLog.Validate(); err != nil {
+		return err
+	}
+	if err := c.ClientVersion.Validate(); err != nil {
+		return err
+	}
+	if c.ParentChain.ReadOnly {
+		if writers := c.parentChainWriters(); len(writers) > 0 {
+			return fmt.Errorf("--parent-chain.read-only cannot be used with components sending parent chain transactions or needing a parent chain wallet: %v", strings.Join(writers, ", "))
+		}
+	}
+	if c.ReadOnlyArchive {
+		if c.Node.Sequencer.Enable || c.Node.BatchPoster.Enable || c.Node.Staker.Enable || c.Node.SeqCoordinator.Enable {
+			return errors.New("--read-only-archive cannot be used with the sequencer, batch poster, staker or sequencer coordinator")
+		}
+		if c.Init.Force || c.Init.Url != "" || c.Init.ImportFile != "" || c.Init.DevInit || c.Init.Empty || c.Init.Prune != "" || c.Init.ResetToMessage >= 0 {
+			return errors.New("--read-only-archive serves an existing database and cannot be used with init options")
+		}
+		if c.Persistent.InMemory {
+			return errors.New("--read-only-archive cannot be used with --persistent.in-memory")
+		}
+	}
+	if c.Persistent.InMemory {
+		if c.Node.Caching.Archive || c.Node.Archive {
+			return errors.New("--persistent.in-memory is incompatible with archive mode")
+		}
+		if c.Init.Url != "" {
+			return errors.New("--persistent.in-memory cannot be initialized from a database snapshot (--init.url)")
+		}
+		if c.Init.Prune != "" {
+			return errors.New("--persistent.in-memory is incompatible with --init.prune")
+		}
+	}
 	return c.Node.Validate()
 }
 
@@ -690,6 +1079,13 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 		return nil, nil, nil, err
 	}
 
+	// the schema doesn't depend on the chain, so don't require a complete configuration
+	if k.Bool("dump-config-schema") {
+		if err := dumpConfigSchema(f); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	l2ChainId := k.Int64("chain.id")
 	l2ChainName := k.String("chain.name")
 	l2ChainInfoIpfsUrl := k.String("chain.info-ipfs-url")
@@ -699,6 +1095,16 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	}
 	l2ChainInfoFiles := k.Strings("chain.info-files")
 	l2ChainInfoJson := k.String("chain.info-json")
+	if l2ChainInfoJson == stdinArg {
+		// stdin can only be consumed once
+		if k.String("init.import-file") == stdinArg {
+			return nil, nil, nil, errors.New("--chain.info-json and --init.import-file cannot both be read from stdin")
+		}
+		l2ChainInfoJson, err = readChainInfoFromStdin()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	chainFound, err := applyChainParameters(ctx, k, uint64(l2ChainId), l2ChainName, l2ChainInfoFiles, l2ChainInfoJson, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath)
 	if err != nil {
 		return nil, nil, nil, err
@@ -713,10 +1119,12 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	if err := confighelpers.EndCommonParse(k, &nodeConfig); err != nil {
 		return nil, nil, nil, err
 	}
+	// the command line still holds the stdin marker, keep what was actually read
+	nodeConfig.Chain.InfoJson = l2ChainInfoJson
 
 	// Don't print wallet passwords
 	if nodeConfig.Conf.Dump {
-		err = confighelpers.DumpConfig(k, map[string]interface{}{
+		err = confighelpers.DumpConfig(k, f, map[string]interface{}{
 			"parent-chain.wallet.password":    "",
 			"parent-chain.wallet.private-key": "",
 			"chain.dev-wallet.password":       "",
@@ -753,20 +1161,51 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	nodeConfig.Rpc.Apply()
 	return &nodeConfig, &l1Wallet, &l2DevWallet, nil
 }
 
+// stdinArg in place of a file or json config value means it's read from standard input
+const stdinArg = "-"
+
+var (
+	stdinChainInfoOnce sync.Once
+	stdinChainInfo     string
+	stdinChainInfoErr  error
+)
+
+// readChainInfoFromStdin reads the chain info from stdin the first time it's called, and returns the same
+// chain info on later calls, as ParseNode runs again on every config reload and stdin can only be consumed once
+func readChainInfoFromStdin() (string, error) {
+	stdinChainInfoOnce.Do(func() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			stdinChainInfoErr = fmt.Errorf("error reading chain info from stdin: %w", err)
+			return
+		}
+		stdinChainInfo = string(data)
+	})
+	return stdinChainInfo, stdinChainInfoErr
+}
+
 func applyChainParameters(ctx context.Context, k *koanf.Koanf, chainId uint64, chainName string, l2ChainInfoFiles []string, l2ChainInfoJson string, l2ChainInfoIpfsUrl string, l2ChainInfoIpfsDownloadPath string) (bool, error) {
+	maxSources := k.Int("chain.info-max-sources")
+	maxBytes := k.Int64("chain.info-max-bytes")
 	combinedL2ChainInfoFiles := l2ChainInfoFiles
 	if l2ChainInfoIpfsUrl != "" {
-		l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath)
+		// the outbound http config isn't applied yet
+		outbound := genericconf.OutboundHTTPConfig{
+			Proxy:              k.String("outbound-http.proxy"),
+			CABundle:           k.String("outbound-http.ca-bundle"),
+			InsecureSkipVerify: k.Bool("outbound-http.insecure-skip-verify"),
+			IpfsGateway:        k.String("outbound-http.ipfs-gateway"),
+		}
+		l2ChainInfoIpfsFile, err := util.GetL2ChainInfoIpfsFile(ctx, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath, maxBytes, &outbound)
 		if err != nil {
 			log.Error("error getting l2 chain info file from ipfs", "err", err)
 		}
 		combinedL2ChainInfoFiles = append(combinedL2ChainInfoFiles, l2ChainInfoIpfsFile)
 	}
-	chainInfo, err := chaininfo.ProcessChainInfo(chainId, chainName, combinedL2ChainInfoFiles, l2ChainInfoJson)
+	chainInfo, err := chaininfo.ProcessChainInfoWithLimits(chainId, chainName, combinedL2ChainInfoFiles, l2ChainInfoJson, maxSources, maxBytes)
 	if err != nil {
 		return false, err
 	}
@@ -775,7 +1214,7 @@ func applyChainParameters(ctx context.Context, k *koanf.Koanf, chainId uint64, c
 		parentChainIsArbitrum = *chainInfo.ParentChainIsArbitrum
 	} else {
 		log.Warn("Chain information parentChainIsArbitrum field missing, in the future this will be required", "chainId", chainId, "parentChainId", chainInfo.ParentChainId)
-		_, err := chaininfo.ProcessChainInfo(chainInfo.ParentChainId, "", combinedL2ChainInfoFiles, "")
+		_, err := chaininfo.ProcessChainInfoWithLimits(chainInfo.ParentChainId, "", combinedL2ChainInfoFiles, "", maxSources, maxBytes)
 		if err == nil {
 			parentChainIsArbitrum = true
 		}
@@ -799,6 +1238,15 @@ func applyChainParameters(ctx context.Context, k *koanf.Koanf, chainId uint64, c
 	if !chainInfo.HasGenesisState {
 		chainDefaults["init.empty"] = true
 	}
+	if chainInfo.GasPriceOracle != nil {
+		chainDefaults["rpc.gas-price-oracle.enable"] = true
+		if chainInfo.GasPriceOracle.Blocks != 0 {
+			chainDefaults["rpc.gas-price-oracle.blocks"] = chainInfo.GasPriceOracle.Blocks
+		}
+		if chainInfo.GasPriceOracle.Percentile != nil {
+			chainDefaults["rpc.gas-price-oracle.percentile"] = *chainInfo.GasPriceOracle.Percentile
+		}
+	}
 	if parentChainIsArbitrum {
 		l2MaxTxSize := execution.DefaultSequencerConfig.MaxTxDataSize
 		bufferSpace := 5000
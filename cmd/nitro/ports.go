@@ -0,0 +1,180 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+type PortConflictConfig struct {
+	Retries    int           `koanf:"retries"`
+	RetryDelay time.Duration `koanf:"retry-delay"`
+}
+
+var PortConflictConfigDefault = PortConflictConfig{
+	Retries:    0,
+	RetryDelay: 5 * time.Second,
+}
+
+func PortConflictConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".retries", PortConflictConfigDefault.Retries, "how many times to check again if a port the node listens on is already in use, e.g. by a previous instance still shutting down")
+	f.Duration(prefix+".retry-delay", PortConflictConfigDefault.RetryDelay, "how long to wait before checking again if a port is already in use")
+}
+
+func (c *PortConflictConfig) Validate() error {
+	if c.Retries < 0 {
+		return errors.New("port-conflict.retries must not be negative")
+	}
+	if c.Retries > 0 && c.RetryDelay <= 0 {
+		return errors.New("port-conflict.retry-delay must be positive when retrying")
+	}
+	return nil
+}
+
+// listenEndpoint is a TCP address the node will listen on
+type listenEndpoint struct {
+	name string
+	host string
+	port int
+}
+
+func (e listenEndpoint) addr() string {
+	return net.JoinHostPort(e.host, strconv.Itoa(e.port))
+}
+
+// PortConflictError reports an endpoint whose port is already in use
+type PortConflictError struct {
+	Endpoint string
+	Addr     string
+	Port     int
+	Err      error
+}
+
+func (e *PortConflictError) Error() string {
+	return fmt.Sprintf("%s port %d is already in use (listening on %s), stop the process using it or configure another port: %v", e.Endpoint, e.Port, e.Addr, e.Err)
+}
+
+func (e *PortConflictError) Unwrap() error {
+	return e.Err
+}
+
+func isAddressInUse(err error) bool {
+	// some wrappers don't keep the error chain, so fall back to the message
+	return errors.Is(err, syscall.EADDRINUSE) || (err != nil && strings.Contains(err.Error(), "address already in use"))
+}
+
+// sameListenHost is true if listening on both hosts would conflict, also when either listens on all interfaces
+func sameListenHost(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	unspecified := func(host string) bool {
+		ip := net.ParseIP(host)
+		return host == "" || (ip != nil && ip.IsUnspecified())
+	}
+	return unspecified(a) || unspecified(b)
+}
+
+// listenEndpoints lists the TCP endpoints the node opens, skipping disabled and random (0) ports.
+// It fails if two endpoints are configured with the same port, as one of them couldn't be listened on.
+func listenEndpoints(stackConf *node.Config, nodeConfig *NodeConfig) ([]listenEndpoint, error) {
+	var endpoints []listenEndpoint
+	var collision error
+	add := func(name string, host string, port int) {
+		if port == 0 || collision != nil {
+			return
+		}
+		for _, existing := range endpoints {
+			// geth serves HTTP and WS on one listener if they share the address
+			if existing.name == "http" && name == "ws" && existing.host == host && existing.port == port {
+				return
+			}
+			if existing.port == port && sameListenHost(existing.host, host) {
+				collision = fmt.Errorf("%s and %s are both configured to listen on port %d (%s and %s), configure another port for one of them", existing.name, name, port, existing.addr(), net.JoinHostPort(host, strconv.Itoa(port)))
+				return
+			}
+		}
+		endpoints = append(endpoints, listenEndpoint{name, host, port})
+	}
+	if stackConf.HTTPHost != "" {
+		add("http", stackConf.HTTPHost, stackConf.HTTPPort)
+	}
+	if stackConf.WSHost != "" {
+		add("ws", stackConf.WSHost, stackConf.WSPort)
+	}
+	// the node always registers authenticated apis, so the auth endpoint is always opened
+	add("auth", stackConf.AuthAddr, stackConf.AuthPort)
+	if nodeConfig.Metrics {
+		add("metrics", nodeConfig.MetricsServer.Addr, nodeConfig.MetricsServer.Port)
+	}
+	if nodeConfig.PProf {
+		add("pprof", nodeConfig.PprofCfg.Addr, nodeConfig.PprofCfg.Port)
+	}
+	if statusPage := &nodeConfig.Node.StatusPage; statusPage.Enable {
+		add("status-page", statusPage.Addr, int(statusPage.Port))
+	}
+	return endpoints, collision
+}
+
+// findPortConflict returns the first endpoint that can't be listened on, or nil if all are free
+func findPortConflict(endpoints []listenEndpoint) error {
+	for _, endpoint := range endpoints {
+		listener, err := net.Listen("tcp", endpoint.addr())
+		if err != nil {
+			if isAddressInUse(err) {
+				return &PortConflictError{
+					Endpoint: endpoint.name,
+					Addr:     endpoint.addr(),
+					Port:     endpoint.port,
+					Err:      err,
+				}
+			}
+			return fmt.Errorf("cannot listen on %s address %s: %w", endpoint.name, endpoint.addr(), err)
+		}
+		if err := listener.Close(); err != nil {
+			return fmt.Errorf("closing test listener for %s address %s: %w", endpoint.name, endpoint.addr(), err)
+		}
+	}
+	return nil
+}
+
+// waitForPorts checks the endpoints can be listened on, retrying port conflicts as configured
+func waitForPorts(ctx context.Context, endpoints []listenEndpoint, config *PortConflictConfig) error {
+	for attempt := 0; ; attempt++ {
+		err := findPortConflict(endpoints)
+		var conflict *PortConflictError
+		if err == nil || !errors.As(err, &conflict) || attempt >= config.Retries {
+			return err
+		}
+		log.Warn("port already in use, retrying", "endpoint", conflict.Endpoint, "addr", conflict.Addr, "retry", attempt+1, "of", config.Retries, "delay", config.RetryDelay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.RetryDelay):
+		}
+	}
+}
+
+// explainStartError names the conflicting endpoint if the node failed to start on a port already in use
+func explainStartError(err error, endpoints []listenEndpoint) error {
+	if !isAddressInUse(err) {
+		return err
+	}
+	if conflict := findPortConflict(endpoints); conflict != nil {
+		return conflict
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/node"
+)
+
+func TestPortConflict(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Require(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	endpoints := []listenEndpoint{{"http", "127.0.0.1", port}}
+
+	err = waitForPorts(context.Background(), endpoints, &PortConflictConfig{})
+	var conflict *PortConflictError
+	if !errors.As(err, &conflict) {
+		Fail(t, "expected a port conflict, got", err)
+	}
+	if conflict.Endpoint != "http" || conflict.Port != port {
+		Fail(t, "unexpected port conflict", conflict)
+	}
+
+	// the port is freed while retrying
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = listener.Close()
+	}()
+	config := &PortConflictConfig{
+		Retries:    100,
+		RetryDelay: 10 * time.Millisecond,
+	}
+	Require(t, waitForPorts(context.Background(), endpoints, config))
+}
+
+func TestListenEndpointCollision(t *testing.T) {
+	nodeConfig := NodeConfigDefault
+	nodeConfig.Node.StatusPage.Enable = true
+	stackConf := node.DefaultConfig
+	nodeConfig.HTTP.Apply(&stackConf)
+	nodeConfig.WS.Apply(&stackConf)
+	nodeConfig.Auth.Apply(&stackConf)
+	endpoints, err := listenEndpoints(&stackConf, &nodeConfig)
+	Require(t, err, "default ports collide")
+	foundStatusPage := false
+	for _, endpoint := range endpoints {
+		if endpoint.name == "status-page" {
+			foundStatusPage = true
+		}
+	}
+	if !foundStatusPage {
+		Fail(t, "status page missing from listen endpoints", endpoints)
+	}
+
+	nodeConfig.Node.StatusPage.Addr = "0.0.0.0"
+	nodeConfig.Node.StatusPage.Port = uint64(stackConf.AuthPort)
+	if _, err := listenEndpoints(&stackConf, &nodeConfig); err == nil {
+		Fail(t, "status page on the auth port not reported as a collision")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	ShutdownReasonSigint        = "sigint"
+	ShutdownReasonFatalError    = "fatal-error"
+	ShutdownReasonThenQuit      = "then-quit"
+	ShutdownReasonResetComplete = "reset-complete"
+	// the node exited with an error before it started running
+	ShutdownReasonStartupError = "startup-error"
+	// the node exited successfully after a one-off task, e.g. creating a key
+	ShutdownReasonTaskComplete = "task-complete"
+)
+
+type ShutdownRecordConfig struct {
+	File           string        `koanf:"file"`
+	WebhookUrl     string        `koanf:"webhook-url"`
+	WebhookTimeout time.Duration `koanf:"webhook-timeout"`
+}
+
+var ShutdownRecordConfigDefault = ShutdownRecordConfig{
+	File:           "",
+	WebhookUrl:     "",
+	WebhookTimeout: 5 * time.Second,
+}
+
+func ShutdownRecordConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".file", ShutdownRecordConfigDefault.File, "write a JSON record of why the node exited to this file (empty to disable)")
+	f.String(prefix+".webhook-url", ShutdownRecordConfigDefault.WebhookUrl, "POST a JSON record of why the node exited to this url (empty to disable)")
+	f.Duration(prefix+".webhook-timeout", ShutdownRecordConfigDefault.WebhookTimeout, "how long to wait for the shutdown webhook to respond")
+}
+
+func (c *ShutdownRecordConfig) Validate() error {
+	if c.WebhookUrl != "" {
+		if _, err := url.ParseRequestURI(c.WebhookUrl); err != nil {
+			return fmt.Errorf("invalid shutdown-record.webhook-url: %w", err)
+		}
+		if c.WebhookTimeout <= 0 {
+			return errors.New("shutdown-record.webhook-timeout must be positive")
+		}
+	}
+	return nil
+}
+
+type ShutdownRecord struct {
+	Reason   string    `json:"reason"`
+	Error    string    `json:"error,omitempty"`
+	ExitCode int       `json:"exitCode"`
+	Time     time.Time `json:"time"`
+}
+
+// shutdownRecorder collects why mainImpl is returning, so it can be reported once the node has stopped
+type shutdownRecorder struct {
+	config *ShutdownRecordConfig
+	reason string
+	err    error
+}
+
+// setConfig must be called once the config is parsed, before that nothing is reported
+func (r *shutdownRecorder) setConfig(config *ShutdownRecordConfig) {
+	r.config = config
+}
+
+func (r *shutdownRecorder) setReason(reason string, err error) {
+	r.reason = reason
+	r.err = err
+}
+
+// startupFailed records err as why the node failed to start, and returns the exit code for it
+func (r *shutdownRecorder) startupFailed(err error) int {
+	r.setReason(ShutdownReasonStartupError, err)
+	return 1
+}
+
+func (r *shutdownRecorder) record(exitCode int) ShutdownRecord {
+	record := ShutdownRecord{
+		Reason:   r.reason,
+		ExitCode: exitCode,
+		Time:     time.Now().UTC(),
+	}
+	if r.err != nil {
+		record.Error = r.err.Error()
+	}
+	if record.Reason == "" {
+		if exitCode == 0 {
+			record.Reason = ShutdownReasonTaskComplete
+		} else {
+			record.Reason = ShutdownReasonStartupError
+		}
+	}
+	return record
+}
+
+// report writes the shutdown record to the configured file and webhook, only logging failures as the node is exiting anyway
+func (r *shutdownRecorder) report(exitCode int) {
+	if r.config == nil || (r.config.File == "" && r.config.WebhookUrl == "") {
+		return
+	}
+	data, err := json.Marshal(r.record(exitCode))
+	if err != nil {
+		log.Error("failed to encode shutdown record", "err", err)
+		return
+	}
+	if r.config.File != "" {
+		if err := writeFileAtomically(r.config.File, append(data, '\n')); err != nil {
+			log.Error("failed to write shutdown record", "file", r.config.File, "err", err)
+		}
+	}
+	if r.config.WebhookUrl != "" {
+		if err := postShutdownRecord(r.config.WebhookUrl, r.config.WebhookTimeout, data); err != nil {
+			log.Error("failed to send shutdown record", "url", r.config.WebhookUrl, "err", err)
+		}
+	}
+}
+
+// writeFileAtomically makes sure readers never see a partially written record
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func postShutdownRecord(webhookUrl string, timeout time.Duration, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookUrl, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %v", resp.Status)
+	}
+	return nil
+}
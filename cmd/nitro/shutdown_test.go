@@ -0,0 +1,60 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShutdownRecord(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		Require(t, err)
+		received <- body
+	}))
+	defer server.Close()
+
+	config := ShutdownRecordConfigDefault
+	config.File = filepath.Join(t.TempDir(), "shutdown.json")
+	config.WebhookUrl = server.URL
+	Require(t, config.Validate())
+
+	recorder := &shutdownRecorder{}
+	recorder.setConfig(&config)
+	recorder.setReason(ShutdownReasonFatalError, errors.New("something broke"))
+	recorder.report(1)
+
+	data, err := os.ReadFile(config.File)
+	Require(t, err)
+	var record ShutdownRecord
+	Require(t, json.Unmarshal(data, &record))
+	if record.Reason != ShutdownReasonFatalError || record.Error != "something broke" || record.ExitCode != 1 {
+		Fail(t, "unexpected shutdown record", record)
+	}
+	var posted ShutdownRecord
+	Require(t, json.Unmarshal(<-received, &posted))
+	if posted.Reason != record.Reason || posted.Error != record.Error {
+		Fail(t, "webhook received a different shutdown record", posted)
+	}
+
+	// without a reason, the exit code tells if startup failed
+	if reason := (&shutdownRecorder{}).record(1).Reason; reason != ShutdownReasonStartupError {
+		Fail(t, "unexpected reason for failed startup", reason)
+	}
+	startupRecorder := &shutdownRecorder{}
+	if exitCode := startupRecorder.startupFailed(errors.New("port in use")); exitCode != 1 {
+		Fail(t, "unexpected exit code for failed startup", exitCode)
+	}
+	if record := startupRecorder.record(1); record.Reason != ShutdownReasonStartupError || record.Error != "port in use" {
+		Fail(t, "startup failure recorded without its error", record)
+	}
+}
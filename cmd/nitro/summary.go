@@ -0,0 +1,124 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type SummaryLogConfig struct {
+	Interval time.Duration `koanf:"interval"`
+}
+
+var SummaryLogConfigDefault = SummaryLogConfig{
+	Interval: 0,
+}
+
+func SummaryLogConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Duration(prefix+".interval", SummaryLogConfigDefault.Interval, "how often to log a one-line summary of the node's activity (0 = disabled)")
+}
+
+func (c *SummaryLogConfig) Validate() error {
+	if c.Interval < 0 {
+		return errors.New("summary-log.interval must not be negative")
+	}
+	return nil
+}
+
+// activitySummary logs the state of the node's main components in a single line,
+// which is easier to aggregate across many nodes than the individual metrics
+type activitySummary struct {
+	stopwaiter.StopWaiter
+	node     *arbnode.Node
+	chain    *core.BlockChain
+	interval time.Duration
+
+	lastErrors   int64
+	lastWarnings int64
+}
+
+func newActivitySummary(node *arbnode.Node, chain *core.BlockChain, interval time.Duration) *activitySummary {
+	return &activitySummary{
+		node:         node,
+		chain:        chain,
+		interval:     interval,
+		lastErrors:   genericconf.LoggedErrorsCount(),
+		lastWarnings: genericconf.LoggedWarningsCount(),
+	}
+}
+
+// fields returns the summary as log context, only including the components the node runs
+func (s *activitySummary) fields() []interface{} {
+	fields := []interface{}{"block", s.chain.CurrentBlock().Number}
+	msgCount, err := s.node.TxStreamer.GetMessageCount()
+	if err == nil {
+		fields = append(fields, "msgCount", msgCount)
+		// messages received but not executed yet
+		if head, err := s.node.Execution.ExecEngine.HeadMessageNumber(); err == nil {
+			var lag arbutil.MessageIndex
+			if head+1 < msgCount {
+				lag = msgCount - head - 1
+			}
+			fields = append(fields, "syncLag", lag)
+		}
+	}
+	fields = append(fields, "synced", s.node.SyncMonitor.Synced())
+	if s.node.InboxReader != nil {
+		seen := s.node.InboxReader.GetLastSeenBatchCount()
+		_, processed := s.node.InboxReader.GetLastReadBlockAndBatchCount()
+		var lag uint64
+		if seen > processed {
+			lag = seen - processed
+		}
+		fields = append(fields, "batchLag", lag)
+	}
+	if s.node.BatchPoster != nil {
+		if last := s.node.BatchPoster.LastPosted(); last != nil {
+			fields = append(fields, "lastBatchPostAge", time.Since(last.Time).Truncate(time.Second))
+		} else {
+			fields = append(fields, "lastBatchPostAge", "none")
+		}
+	}
+	if s.node.BroadcastClients != nil {
+		fields = append(fields, "feedInputsConnected", s.node.BroadcastClients.Connected(), "feedInputs", s.node.BroadcastClients.Count())
+	}
+	if s.node.BroadcastServer != nil {
+		fields = append(fields, "feedClients", s.node.BroadcastServer.ClientCount())
+	}
+	errorsCount := genericconf.LoggedErrorsCount()
+	warningsCount := genericconf.LoggedWarningsCount()
+	fields = append(fields, "errors", errorsCount-s.lastErrors, "warnings", warningsCount-s.lastWarnings)
+	s.lastErrors = errorsCount
+	s.lastWarnings = warningsCount
+	return fields
+}
+
+// Start logs a summary every interval until stopped
+func (s *activitySummary) Start(ctxIn context.Context) {
+	s.StopWaiter.Start(ctxIn, s)
+	s.LaunchThread(func(ctx context.Context) {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				log.Info("node summary", s.fields()...)
+			}
+		}
+	})
+}
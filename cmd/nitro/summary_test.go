@@ -0,0 +1,105 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/execution"
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/statetransfer"
+)
+
+// newSummaryTestNode is a node with only the components every node runs, whose transaction streamer isn't started
+func newSummaryTestNode(t *testing.T) *activitySummary {
+	t.Helper()
+	initReader := statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{})
+	bc, err := execution.WriteOrTestBlockChain(rawdb.NewMemoryDatabase(), nil, initReader, params.ArbitrumDevTestChainConfig(), arbostypes.TestInitMessage, 0, 0)
+	Require(t, err)
+	t.Cleanup(bc.Stop)
+	execEngine, err := execution.NewExecutionEngine(bc)
+	Require(t, err)
+	streamerConfig := func() *arbnode.TransactionStreamerConfig { return &arbnode.DefaultTransactionStreamerConfig }
+	streamer, err := arbnode.NewTransactionStreamer(rawdb.NewMemoryDatabase(), bc.Config(), execEngine, nil, make(chan error, 1), streamerConfig)
+	Require(t, err)
+	Require(t, streamer.AddFakeInitMessage())
+	node := &arbnode.Node{
+		TxStreamer:  streamer,
+		Execution:   &execution.ExecutionNode{ExecEngine: execEngine},
+		SyncMonitor: arbnode.NewSyncMonitor(&arbnode.DefaultSyncMonitorConfig, nil),
+	}
+	return newActivitySummary(node, bc, time.Millisecond)
+}
+
+func summaryFields(t *testing.T, summary *activitySummary) map[string]string {
+	t.Helper()
+	fields := summary.fields()
+	if len(fields)%2 != 0 {
+		Fail(t, "summary fields aren't key value pairs", fields)
+	}
+	values := make(map[string]string)
+	for i := 0; i < len(fields); i += 2 {
+		values[fields[i].(string)] = fmt.Sprint(fields[i+1])
+	}
+	return values
+}
+
+func TestActivitySummaryFields(t *testing.T) {
+	summary := newSummaryTestNode(t)
+	fields := summaryFields(t, summary)
+	expected := map[string]string{
+		"block":    "0",
+		"msgCount": "1",
+		"syncLag":  "0",
+		"synced":   "false",
+		"errors":   "0",
+		"warnings": "0",
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			Fail(t, "summary field", key, "is", fields[key], "expected", value)
+		}
+	}
+	// components the node doesn't run aren't summarized
+	for _, key := range []string{"batchLag", "lastBatchPostAge", "feedInputsConnected", "feedInputs", "feedClients"} {
+		if value, ok := fields[key]; ok {
+			Fail(t, "summary of a component the node doesn't run", key, value)
+		}
+	}
+
+	// a message received but not executed yet, as the streamer isn't started
+	var requestId common.Hash
+	requestId[0] = 1
+	message := arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:      arbostypes.L1MessageType_L2Message,
+				RequestId: &requestId,
+			},
+			L2msg: []byte{arbos.L2MessageKind_Heartbeat},
+		},
+		DelayedMessagesRead: 1,
+	}
+	Require(t, summary.node.TxStreamer.AddMessages(1, false, []arbostypes.MessageWithMetadata{message}))
+	fields = summaryFields(t, summary)
+	if fields["msgCount"] != "2" || fields["syncLag"] != "1" || fields["block"] != "0" {
+		Fail(t, "unexpected summary of an unexecuted message", fields)
+	}
+}
+
+func TestActivitySummaryStops(t *testing.T) {
+	summary := newSummaryTestNode(t)
+	summary.Start(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	summary.StopAndWait()
+}
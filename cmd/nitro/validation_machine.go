@@ -0,0 +1,74 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+// isMachineLoadError returns true if err is caused by a missing or invalid validation machine
+func isMachineLoadError(err error) bool {
+	var artifactErr *server_common.MachineArtifactError
+	return errors.Is(err, server_common.ErrMachineNotFound) || errors.As(err, &artifactErr)
+}
+
+// configuredModuleRoots returns the module roots the block validator is configured to validate with,
+// except the one read from the chain, which isn't known before the node starts
+func configuredModuleRoots(config *staker.BlockValidatorConfig) ([]common.Hash, error) {
+	var roots []common.Hash
+	for _, root := range []string{config.CurrentModuleRoot, config.PendingUpgradeModuleRoot} {
+		switch root {
+		case "", "current":
+		case "latest":
+			roots = append(roots, common.Hash{})
+		default:
+			hash := common.HexToHash(root)
+			if hash == (common.Hash{}) {
+				return nil, fmt.Errorf("invalid module root %#v", root)
+			}
+			roots = append(roots, hash)
+		}
+	}
+	return roots, nil
+}
+
+// checkValidationMachines verifies the machines the block validator is configured with can be loaded
+func checkValidationMachines(checkMachine func(common.Hash) error, config *staker.BlockValidatorConfig) error {
+	roots, err := configuredModuleRoots(config)
+	if err != nil {
+		return err
+	}
+	for _, root := range roots {
+		if err := checkMachine(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fallBackToWatchtower disables block validation, leaving the staker (if enabled) to only watch the chain
+func fallBackToWatchtower(nodeConfig *NodeConfig) error {
+	nodeConfig.Node.BlockValidator.Enable = false
+	if !nodeConfig.Node.Staker.Enable {
+		return nil
+	}
+	nodeConfig.Node.Staker.Strategy = "Watchtower"
+	return nodeConfig.Node.Staker.Validate()
+}
+
+// handleMachineLoadFailure applies the configured machine-load-failure policy, returning an error if startup should abort
+func handleMachineLoadFailure(nodeConfig *NodeConfig, err error) error {
+	if nodeConfig.Node.BlockValidator.MachineLoadFailure != staker.MachineLoadFailureWatchtower {
+		return fmt.Errorf("%w (check --validation.wasm.root-path and the module roots in --node.block-validator, or set --node.block-validator.machine-load-failure=%v to continue without validating)", err, staker.MachineLoadFailureWatchtower)
+	}
+	log.Error("validation machine failed to load, disabling the block validator and continuing as a watchtower", "err", err)
+	return fallBackToWatchtower(nodeConfig)
+}
@@ -0,0 +1,53 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+func TestValidationMachineLoadFailure(t *testing.T) {
+	rootPath := t.TempDir()
+	latest := common.HexToHash("0x01")
+	Require(t, os.MkdirAll(filepath.Join(rootPath, "latest"), 0755))
+	Require(t, os.WriteFile(filepath.Join(rootPath, "latest", "module-root.txt"), []byte(latest.Hex()), 0600))
+	Require(t, os.WriteFile(filepath.Join(rootPath, "latest", "machine.wavm.br"), []byte{}, 0600))
+	locator, err := server_common.NewMachineLocator(rootPath)
+	Require(t, err)
+	checkMachine := func(root common.Hash) error {
+		return locator.CheckMachine(root, "machine.wavm.br")
+	}
+
+	config := staker.DefaultBlockValidatorConfig
+	config.CurrentModuleRoot = "latest"
+	config.PendingUpgradeModuleRoot = latest.Hex()
+	Require(t, checkValidationMachines(checkMachine, &config))
+
+	config.PendingUpgradeModuleRoot = common.HexToHash("0x02").Hex()
+	err = checkValidationMachines(checkMachine, &config)
+	if !isMachineLoadError(err) {
+		Fail(t, "expected a machine load error for a missing module root, got", err)
+	}
+
+	nodeConfig := NodeConfigDefault
+	nodeConfig.Node.BlockValidator = config
+	nodeConfig.Node.BlockValidator.Enable = true
+	if handleMachineLoadFailure(&nodeConfig, err) == nil {
+		Fail(t, "expected startup to abort by default")
+	}
+	nodeConfig.Node.BlockValidator.MachineLoadFailure = staker.MachineLoadFailureWatchtower
+	nodeConfig.Node.Staker.Enable = true
+	nodeConfig.Node.Staker.Strategy = "MakeNodes"
+	Require(t, handleMachineLoadFailure(&nodeConfig, err))
+	if nodeConfig.Node.BlockValidator.Enable || nodeConfig.Node.Staker.ValidatorRequired() {
+		Fail(t, "block validation still enabled after falling back to watchtower")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	VerifyDbWarn  = "warn"
+	VerifyDbAbort = "abort"
+)
+
+// verifyChainDb runs a bounded consistency check of the chain database: the head block and
+// its recent ancestors must be fully present and linked, and the freezer must agree with the key-value store.
+// It returns the problems found, and doesn't modify the database.
+func verifyChainDb(chainDb ethdb.Database, depth uint64) []string {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problem := fmt.Sprintf(format, args...)
+		log.Error("database verification problem", "problem", problem)
+		problems = append(problems, problem)
+	}
+
+	headHash := rawdb.ReadHeadBlockHash(chainDb)
+	if headHash == (common.Hash{}) {
+		report("head block hash missing")
+		return problems
+	}
+	headNumber := rawdb.ReadHeaderNumber(chainDb, headHash)
+	if headNumber == nil {
+		report("number of head block %v missing", headHash)
+		return problems
+	}
+	headHeaderHash := rawdb.ReadHeadHeaderHash(chainDb)
+	if headHeaderNumber := rawdb.ReadHeaderNumber(chainDb, headHeaderHash); headHeaderNumber == nil {
+		report("head header %v missing", headHeaderHash)
+	} else if *headHeaderNumber < *headNumber {
+		report("head header %v is behind head block %v", *headHeaderNumber, *headNumber)
+	}
+	log.Info("verifying database", "head", *headNumber, "headHash", headHash, "depth", depth)
+
+	// walk back from the head block, checking each block is canonical, complete and linked to its parent
+	hash := headHash
+	number := *headNumber
+	stateFound := false
+	for checked := uint64(0); checked <= depth; checked++ {
+		if canonical := rawdb.ReadCanonicalHash(chainDb, number); canonical != hash {
+			report("canonical hash of block %v is %v, expected %v", number, canonical, hash)
+		}
+		header := rawdb.ReadHeader(chainDb, hash, number)
+		if header == nil {
+			report("header of block %v (%v) missing", number, hash)
+			break
+		}
+		if !rawdb.HasBody(chainDb, hash, number) {
+			report("body of block %v (%v) missing", number, hash)
+		}
+		if !rawdb.HasReceipts(chainDb, hash, number) {
+			report("receipts of block %v (%v) missing", number, hash)
+		}
+		if !stateFound {
+			if _, err := state.New(header.Root, state.NewDatabase(chainDb), nil); err == nil {
+				stateFound = true
+				if number != *headNumber {
+					log.Warn("head block state missing, blocks will be re-executed from the latest available state", "head", *headNumber, "state", number)
+				}
+			}
+		}
+		if number == 0 {
+			break
+		}
+		hash = header.ParentHash
+		number--
+	}
+	if !stateFound {
+		log.Warn("no state found for recently checked blocks, blocks will be re-executed from an older state", "head", *headNumber, "depth", depth)
+	}
+
+	if _, err := chainDb.AncientDatadir(); err != nil {
+		// e.g. an in-memory database
+		log.Info("database has no freezer, skipping freezer verification", "err", err)
+		if len(problems) == 0 {
+			log.Info("database verification passed", "head", *headNumber)
+		}
+		return problems
+	}
+	ancients, err := chainDb.Ancients()
+	if err != nil {
+		report("failed to read freezer head: %v", err)
+		return problems
+	}
+	tail, err := chainDb.Tail()
+	if err != nil {
+		report("failed to read freezer tail: %v", err)
+		return problems
+	}
+	if tail > ancients {
+		report("freezer tail %v is past freezer head %v", tail, ancients)
+	}
+	if ancients > 0 {
+		lastFrozen := ancients - 1
+		if lastFrozen > *headNumber {
+			report("freezer contains block %v beyond head block %v", lastFrozen, *headNumber)
+		}
+		frozenHash := rawdb.ReadCanonicalHash(chainDb, lastFrozen)
+		if frozenHash == (common.Hash{}) {
+			report("canonical hash of last frozen block %v missing", lastFrozen)
+		} else if rawdb.ReadHeader(chainDb, frozenHash, lastFrozen) == nil {
+			report("header of last frozen block %v missing", lastFrozen)
+		} else if ancients <= *headNumber {
+			nextHash := rawdb.ReadCanonicalHash(chainDb, ancients)
+			if next := rawdb.ReadHeader(chainDb, nextHash, ancients); next == nil {
+				report("header of first block %v after the freezer missing", ancients)
+			} else if next.ParentHash != frozenHash {
+				report("block %v after the freezer doesn't link to last frozen block %v", ancients, lastFrozen)
+			}
+		}
+	}
+	if len(problems) == 0 {
+		log.Info("database verification passed", "head", *headNumber, "ancients", ancients, "tail", tail)
+	}
+	return problems
+}
@@ -0,0 +1,88 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// writeTestChain writes a canonical chain of blocks with bodies and receipts, without state
+func writeTestChain(t *testing.T, blocks uint64) (ethdb.Database, []common.Hash) {
+	t.Helper()
+	chainDb := rawdb.NewMemoryDatabase()
+	var hashes []common.Hash
+	parent := common.Hash{}
+	for number := uint64(0); number < blocks; number++ {
+		block := types.NewBlockWithHeader(&types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: common.Big1,
+		})
+		rawdb.WriteBlock(chainDb, block)
+		rawdb.WriteReceipts(chainDb, block.Hash(), number, types.Receipts{})
+		rawdb.WriteCanonicalHash(chainDb, block.Hash(), number)
+		hashes = append(hashes, block.Hash())
+		parent = block.Hash()
+	}
+	rawdb.WriteHeadHeaderHash(chainDb, parent)
+	rawdb.WriteHeadBlockHash(chainDb, parent)
+	return chainDb, hashes
+}
+
+func requireProblem(t *testing.T, problems []string, expected string) {
+	t.Helper()
+	for _, problem := range problems {
+		if strings.Contains(problem, expected) {
+			return
+		}
+	}
+	Fail(t, "expected database problem", expected, "got", problems)
+}
+
+func TestVerifyChainDb(t *testing.T) {
+	chainDb, _ := writeTestChain(t, 10)
+	if problems := verifyChainDb(chainDb, 128); len(problems) != 0 {
+		Fail(t, "problems found in a consistent database", problems)
+	}
+
+	chainDb, hashes := writeTestChain(t, 10)
+	rawdb.DeleteBody(chainDb, hashes[7], 7)
+	requireProblem(t, verifyChainDb(chainDb, 128), "body of block 7")
+
+	chainDb, hashes = writeTestChain(t, 10)
+	rawdb.DeleteReceipts(chainDb, hashes[8], 8)
+	requireProblem(t, verifyChainDb(chainDb, 128), "receipts of block 8")
+
+	// block 5 isn't the parent of block 6 in the canonical chain
+	chainDb, _ = writeTestChain(t, 10)
+	rawdb.WriteCanonicalHash(chainDb, common.HexToHash("0x1234"), 5)
+	requireProblem(t, verifyChainDb(chainDb, 128), "canonical hash of block 5")
+
+	// problems below the checked depth aren't found
+	chainDb, hashes = writeTestChain(t, 10)
+	rawdb.DeleteBody(chainDb, hashes[2], 2)
+	if problems := verifyChainDb(chainDb, 3); len(problems) != 0 {
+		Fail(t, "problems found below the verified depth", problems)
+	}
+}
+
+func TestVerifyDbConfig(t *testing.T) {
+	for _, verifyDb := range []string{"", VerifyDbWarn, VerifyDbAbort} {
+		config := InitConfigDefault
+		config.VerifyDb = verifyDb
+		Require(t, config.Validate())
+	}
+	config := InitConfigDefault
+	config.VerifyDb = "repair"
+	if config.Validate() == nil {
+		Fail(t, "invalid init.verify-db accepted")
+	}
+}
@@ -40,7 +40,7 @@ func printSampleUsage(progname string) {
 // Checks metrics and PProf flag, runs them if enabled.
 // Note: they are separate so one can enable/disable them as they wish, the only
 // requirement is that they can't run on the same address and port.
-func startMetrics(cfg *relay.Config) error {
+func startMetrics(ctx context.Context, cfg *relay.Config) error {
 	mAddr := fmt.Sprintf("%v:%v", cfg.MetricsServer.Addr, cfg.MetricsServer.Port)
 	pAddr := fmt.Sprintf("%v:%v", cfg.PprofCfg.Addr, cfg.PprofCfg.Port)
 	if cfg.Metrics && !metrics.Enabled {
@@ -56,6 +56,9 @@ func startMetrics(cfg *relay.Config) error {
 	if cfg.PProf {
 		genericconf.StartPprof(pAddr)
 	}
+	if err := genericconf.StartPeriodicProfiles(ctx, &cfg.PprofCfg.PeriodicProfiles); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -91,7 +94,7 @@ func startup() error {
 		return err
 	}
 
-	if err := startMetrics(relayConfig); err != nil {
+	if err := startMetrics(ctx, relayConfig); err != nil {
 		return err
 	}
 
@@ -222,7 +222,7 @@ func main() {
 			}
 		} else {
 			log.Info("Falling back to hardcoded chain config.")
-			chainConfig, err = chaininfo.GetChainConfig(chainId, "", genesisBlockNum, []string{}, "")
+			chainConfig, err = chaininfo.GetChainConfig(chainId, "", genesisBlockNum, []string{}, "", 0, 0)
 			if err != nil {
 				panic(err)
 			}
@@ -251,7 +251,7 @@ func main() {
 		chainConfig := initMessage.ChainConfig
 		if chainConfig == nil {
 			log.Info("No chain config in the init message. Falling back to hardcoded chain config.")
-			chainConfig, err = chaininfo.GetChainConfig(initMessage.ChainId, "", 0, []string{}, "")
+			chainConfig, err = chaininfo.GetChainConfig(initMessage.ChainId, "", 0, []string{}, "", 0, 0)
 			if err != nil {
 				panic(err)
 			}
@@ -2,19 +2,39 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/ipfshelper"
 )
 
-func GetL2ChainInfoIpfsFile(ctx context.Context, l2ChainInfoIpfsUrl string, l2ChainInfoIpfsDownloadPath string) (string, error) {
+// GetL2ChainInfoIpfsFile downloads the chain info file, refusing files larger than maxBytes before downloading them (0 = no limit).
+// If outbound-http.ipfs-gateway is set, the file is fetched from the gateway through the outbound proxy.
+func GetL2ChainInfoIpfsFile(ctx context.Context, l2ChainInfoIpfsUrl string, l2ChainInfoIpfsDownloadPath string, maxBytes int64, outbound *genericconf.OutboundHTTPConfig) (string, error) {
+	if outbound.IpfsGateway != "" {
+		client, err := outbound.Client()
+		if err != nil {
+			return "", err
+		}
+		log.Info("Downloading l2 info file via IPFS gateway", "url", l2ChainInfoIpfsUrl, "gateway", outbound.IpfsGateway)
+		l2ChainInfoFile, err := ipfshelper.DownloadFileFromGateway(ctx, client, outbound.IpfsGateway, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath, maxBytes)
+		if errors.Is(err, ipfshelper.ErrFileTooLarge) {
+			return "", fmt.Errorf("%w: %w (see --chain.info-max-bytes)", chaininfo.ErrChainInfoTooLarge, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to download file from IPFS gateway: %w", err)
+		}
+		return l2ChainInfoFile, nil
+	}
 	ipfsNode, err := ipfshelper.CreateIpfsHelper(ctx, l2ChainInfoIpfsDownloadPath, false, []string{}, ipfshelper.DefaultIpfsProfiles)
 	if err != nil {
 		return "", err
 	}
 	log.Info("Downloading l2 info file via IPFS", "url", l2ChainInfoIpfsDownloadPath)
-	l2ChainInfoFile, downloadErr := ipfsNode.DownloadFile(ctx, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath)
+	l2ChainInfoFile, downloadErr := downloadL2ChainInfoIpfsFile(ctx, ipfsNode, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath, maxBytes)
 	closeErr := ipfsNode.Close()
 	if downloadErr != nil {
 		if closeErr != nil {
@@ -27,3 +47,16 @@ func GetL2ChainInfoIpfsFile(ctx context.Context, l2ChainInfoIpfsUrl string, l2Ch
 	}
 	return l2ChainInfoFile, nil
 }
+
+func downloadL2ChainInfoIpfsFile(ctx context.Context, ipfsNode *ipfshelper.IpfsHelper, l2ChainInfoIpfsUrl string, l2ChainInfoIpfsDownloadPath string, maxBytes int64) (string, error) {
+	if maxBytes > 0 {
+		size, err := ipfsNode.CumulativeSize(ctx, l2ChainInfoIpfsUrl)
+		if err != nil {
+			return "", err
+		}
+		if size > maxBytes {
+			return "", fmt.Errorf("%w: the ipfs file has up to %v bytes, at most %v allowed (see --chain.info-max-bytes)", chaininfo.ErrChainInfoTooLarge, size, maxBytes)
+		}
+	}
+	return ipfsNode.DownloadFile(ctx, l2ChainInfoIpfsUrl, l2ChainInfoIpfsDownloadPath)
+}
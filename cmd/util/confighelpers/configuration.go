@@ -4,10 +4,14 @@
 package confighelpers
 
 import (
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/json"
@@ -47,7 +51,8 @@ func ApplyOverrides(f *flag.FlagSet, k *koanf.Koanf) error {
 		}
 	}
 
-	// Local config file overrides S3 config file
+	// Local config files override S3 config file, and are applied in order so that later files override earlier ones.
+	// Command line options and environment variables are re-applied after each file so they keep precedence.
 	configFiles := k.Strings("conf.file")
 	for _, configFile := range configFiles {
 		if len(configFile) > 0 {
@@ -160,9 +165,30 @@ func BeginCommonParse(f *flag.FlagSet, args []string) (*koanf.Koanf, error) {
 		return nil, err
 	}
 
+	if k.Bool("conf.strict") {
+		if err := CheckUnknownKeys(f, k); err != nil {
+			return nil, err
+		}
+	}
+
 	return k, nil
 }
 
+// CheckUnknownKeys returns an error listing every loaded configuration key that isn't a known flag
+func CheckUnknownKeys(f *flag.FlagSet, k *koanf.Koanf) error {
+	var unknown []string
+	for _, key := range k.Keys() {
+		if f.Lookup(key) == nil {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown configuration keys: %s", strings.Join(unknown, ", "))
+}
+
 func EndCommonParse(k *koanf.Koanf, config interface{}) error {
 	decoderConfig := mapstructure.DecoderConfig{
 		ErrorUnused: true,
@@ -182,7 +208,7 @@ func EndCommonParse(k *koanf.Koanf, config interface{}) error {
 	return nil
 }
 
-func DumpConfig(k *koanf.Koanf, extraOverrideFields map[string]interface{}) error {
+func DumpConfig(k *koanf.Koanf, f *flag.FlagSet, extraOverrideFields map[string]interface{}) error {
 	overrideFields := map[string]interface{}{"conf.dump": false}
 
 	// Don't keep printing configuration file
@@ -190,17 +216,120 @@ func DumpConfig(k *koanf.Koanf, extraOverrideFields map[string]interface{}) erro
 		overrideFields[k] = v
 	}
 
-	err := k.Load(confmap.Provider(overrideFields, "."), nil)
-	if err != nil {
-		return fmt.Errorf("error removing extra parameters before dump: %w", err)
+	if k.Bool("conf.dump-redacted-only") {
+		var err error
+		k, err = RedactedOnlyConfig(k, extraOverrideFields)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := k.Load(confmap.Provider(overrideFields, "."), nil)
+		if err != nil {
+			return fmt.Errorf("error removing extra parameters before dump: %w", err)
+		}
 	}
 
-	c, err := k.Marshal(koanfjson.Parser())
+	c, err := FormatConfig(k, f, k.String("conf.dump-format"), k.Bool("conf.dump-omit-defaults"))
 	if err != nil {
-		return fmt.Errorf("unable to marshal config file to JSON: %w", err)
+		return err
 	}
 
 	fmt.Println(string(c))
 	os.Exit(0)
 	return fmt.Errorf("Unreachable")
 }
+
+// FormatConfig serializes the configuration in the given dump format ("json", "json-pretty" or "flat").
+// If omitDefaults is set, only values differing from the flag defaults are included.
+func FormatConfig(k *koanf.Koanf, f *flag.FlagSet, format string, omitDefaults bool) ([]byte, error) {
+	if omitDefaults {
+		k = withoutDefaults(k, f)
+	}
+	switch format {
+	case "", "json":
+		c, err := k.Marshal(koanfjson.Parser())
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal config file to JSON: %w", err)
+		}
+		return c, nil
+	case "json-pretty":
+		c, err := stdjson.MarshalIndent(k.Raw(), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal config file to JSON: %w", err)
+		}
+		return c, nil
+	case "flat":
+		// koanf returns keys sorted, so one key per line gives a stable, diffable output
+		var lines []string
+		for _, key := range k.Keys() {
+			value, err := stdjson.Marshal(k.Get(key))
+			if err != nil {
+				return nil, fmt.Errorf("unable to marshal config value %v: %w", key, err)
+			}
+			lines = append(lines, key+"="+string(value))
+		}
+		return []byte(strings.Join(lines, "\n")), nil
+	default:
+		return nil, fmt.Errorf("invalid conf.dump-format %#v, expected \"json\", \"json-pretty\" or \"flat\"", format)
+	}
+}
+
+const redactedConfigValue = "REDACTED"
+
+// RedactedOnlyConfig returns a config holding only the given redacted keys, set to a placeholder where k has a value,
+// so that a dump tells which secrets are set without printing them.
+func RedactedOnlyConfig(k *koanf.Koanf, redactedFields map[string]interface{}) (*koanf.Koanf, error) {
+	fields := make(map[string]interface{}, len(redactedFields))
+	for key := range redactedFields {
+		value := ""
+		if k.String(key) != "" {
+			value = redactedConfigValue
+		}
+		fields[key] = value
+	}
+	out := koanf.New(".")
+	if err := out.Load(confmap.Provider(fields, "."), nil); err != nil {
+		return nil, fmt.Errorf("error loading redacted parameters for dump: %w", err)
+	}
+	return out, nil
+}
+
+// withoutDefaults returns a copy of k holding only the keys whose values differ from the flag defaults.
+// Keys that aren't known flags are always kept.
+func withoutDefaults(k *koanf.Koanf, f *flag.FlagSet) *koanf.Koanf {
+	out := k.Copy()
+	for _, key := range k.Keys() {
+		fl := f.Lookup(key)
+		if fl != nil && configValueString(k.Get(key), fl.Value.Type()) == fl.DefValue {
+			out.Delete(key)
+		}
+	}
+	return out
+}
+
+// configValueString renders a loaded config value the way pflag renders the default of a flag of the given type
+func configValueString(value interface{}, flagType string) string {
+	switch v := value.(type) {
+	case []string:
+		return "[" + strings.Join(v, ",") + "]"
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, part := range v {
+			parts = append(parts, configValueString(part, ""))
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	case float64:
+		// numbers from JSON config files are decoded as floats
+		if flagType == "duration" {
+			return time.Duration(v).String()
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if flagType == "duration" {
+			if d, err := time.ParseDuration(v); err == nil {
+				return d.String()
+			}
+		}
+	}
+	return fmt.Sprint(value)
+}
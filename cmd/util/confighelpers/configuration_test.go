@@ -0,0 +1,87 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package confighelpers
+
+import (
+	"testing"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+func TestFormatConfigOmitDefaults(t *testing.T) {
+	f := flag.NewFlagSet("", flag.ContinueOnError)
+	f.String("a.name", "default", "")
+	f.Duration("a.timeout", time.Minute, "")
+	f.StringSlice("b.list", []string{"x", "y"}, "")
+	f.Uint64("b.count", 100, "")
+
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	confString := `{"a":{"timeout":"60s"},"b":{"count":100,"list":["x","z"]}}`
+	k, err := BeginCommonParse(f, []string{"--conf.string", confString, "--a.name", "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat, err := FormatConfig(k, f, "flat", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `a.name="other"` + "\n" + `b.list=["x","z"]` + "\n" + `conf.string="{\"a\":{\"timeout\":\"60s\"},\"b\":{\"count\":100,\"list\":[\"x\",\"z\"]}}"`
+	if string(flat) != expected {
+		t.Fatalf("unexpected flat dump:\n%v", string(flat))
+	}
+
+	minimal, err := FormatConfig(k, f, "json", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"a":{"name":"other"},"b":{"list":["x","z"]},"conf":{"string":"{\"a\":{\"timeout\":\"60s\"},\"b\":{\"count\":100,\"list\":[\"x\",\"z\"]}}"}}`
+	if string(minimal) != expected {
+		t.Fatalf("unexpected minimal dump:\n%v", string(minimal))
+	}
+
+	if _, err := FormatConfig(k, f, "yaml", false); err == nil {
+		t.Fatal("expected invalid format to be rejected")
+	}
+}
+
+func TestFormatConfigRedactedOnly(t *testing.T) {
+	f := flag.NewFlagSet("", flag.ContinueOnError)
+	f.String("wallet.password", "", "")
+	f.String("wallet.private-key", "", "")
+	f.String("wallet.account", "", "")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := BeginCommonParse(f, []string{"--wallet.password", "secret", "--wallet.account", "0x1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted, err := RedactedOnlyConfig(k, map[string]interface{}{
+		"wallet.password":    "",
+		"wallet.private-key": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, err := FormatConfig(redacted, f, "flat", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `wallet.password="REDACTED"` + "\n" + `wallet.private-key=""`
+	if string(flat) != expected {
+		t.Fatalf("unexpected redacted dump:\n%v", string(flat))
+	}
+
+	minimal, err := FormatConfig(redacted, f, "json", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(minimal) != `{"wallet":{"password":"REDACTED"}}` {
+		t.Fatalf("unexpected minimal redacted dump:\n%v", string(minimal))
+	}
+}
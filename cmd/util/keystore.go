@@ -6,6 +6,8 @@ package util
 import (
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -24,9 +26,13 @@ import (
 
 func OpenWallet(description string, walletConfig *genericconf.WalletConfig, chainId *big.Int) (*bind.TransactOpts, signature.DataSignerFunc, error) {
 	if walletConfig.PrivateKey != "" {
-		privateKey, err := crypto.HexToECDSA(walletConfig.PrivateKey)
+		hexKey, err := walletConfig.ResolvePrivateKey()
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, fmt.Errorf("%s wallet: %w", description, err)
+		}
+		privateKey, err := crypto.HexToECDSA(hexKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s wallet: invalid private key: %w", description, err)
 		}
 		var txOpts *bind.TransactOpts
 		if chainId != nil {
@@ -53,7 +59,11 @@ func OpenWallet(description string, walletConfig *genericconf.WalletConfig, chai
 		return nil, nil, err
 	}
 	if walletConfig.OnlyCreateKey {
-		log.Info(fmt.Sprintf("Wallet key created with address %s, backup wallet (%s) and remove --%s.wallet.only-create-key to run normally", account.Address.Hex(), walletConfig.Pathname, description))
+		if walletConfig.BackupDir != "" {
+			log.Info(fmt.Sprintf("Wallet key created with address %s in %s and backed up to %s, remove --%s.wallet.only-create-key to run normally", account.Address.Hex(), account.URL.Path, walletConfig.BackupDir, description))
+		} else {
+			log.Info(fmt.Sprintf("Wallet key created with address %s, backup wallet (%s) and remove --%s.wallet.only-create-key to run normally", account.Address.Hex(), walletConfig.Pathname, description))
+		}
 		return nil, nil, nil
 	}
 
@@ -98,7 +108,17 @@ func openKeystore(ks *keystore.KeyStore, description string, walletConfig *gener
 
 	if creatingNew {
 		a, err := ks.NewAccount(password)
-		return &a, err
+		if err != nil {
+			return nil, err
+		}
+		if walletConfig.BackupDir != "" {
+			backupPath, err := backupKeyFile(a, walletConfig.BackupDir)
+			if err != nil {
+				return nil, fmt.Errorf("created wallet key %s but failed to back it up: %w", a.URL.Path, err)
+			}
+			log.Info("Wallet key backed up", "address", a.Address.Hex(), "keyfile", a.URL.Path, "backup", backupPath)
+		}
+		return &a, nil
 	}
 
 	var account accounts.Account
@@ -130,6 +150,34 @@ func openKeystore(ks *keystore.KeyStore, description string, walletConfig *gener
 	return &account, nil
 }
 
+// backupKeyFile copies the encrypted key file of a newly created account into backupDir
+func backupKeyFile(account accounts.Account, backupDir string) (string, error) {
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+	backupPath := filepath.Join(backupDir, filepath.Base(account.URL.Path))
+	file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(keyJSON)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(backupPath)
+		return "", err
+	}
+	return backupPath, nil
+}
+
 func readPass() (string, error) {
 	bytePassword, err := term.ReadPassword(syscall.Stdin)
 	if err != nil {
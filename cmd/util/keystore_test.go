@@ -4,7 +4,10 @@
 package util
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -211,3 +214,30 @@ func TestExistingKeystoreAccountName(t *testing.T) {
 	// Unit test doesn't like unflushed output
 	fmt.Printf("\n")
 }
+
+func TestNewKeystoreBackup(t *testing.T) {
+	walletConf := genericconf.WalletConfigDefault
+	walletConf.Pathname = t.TempDir()
+	walletConf.BackupDir = filepath.Join(t.TempDir(), "backup")
+	walletConf.OnlyCreateKey = true
+	walletConf.Password = "foo"
+
+	_, account, err := openTestKeystore("test", &walletConf, readPass)
+	if err != nil {
+		t.Fatalf("openTestKeystore() unexpected error: %v", err)
+	}
+	keyJSON, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupJSON, err := os.ReadFile(filepath.Join(walletConf.BackupDir, filepath.Base(account.URL.Path)))
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if !bytes.Equal(keyJSON, backupJSON) {
+		t.Fatal("backup differs from key file")
+	}
+	if _, err := keystore.DecryptKey(backupJSON, walletConf.Password); err != nil {
+		t.Fatalf("failed to decrypt backup: %v", err)
+	}
+}
@@ -27,14 +27,18 @@ import (
 )
 
 type AggregatorConfig struct {
-	Enable        bool   `koanf:"enable"`
-	AssumedHonest int    `koanf:"assumed-honest"`
-	Backends      string `koanf:"backends"`
+	Enable           bool          `koanf:"enable"`
+	AssumedHonest    int           `koanf:"assumed-honest"`
+	Backends         string        `koanf:"backends"`
+	StoreQuorum      int           `koanf:"store-quorum"`
+	StragglerTimeout time.Duration `koanf:"straggler-timeout"`
 }
 
 var DefaultAggregatorConfig = AggregatorConfig{
-	AssumedHonest: 0,
-	Backends:      "",
+	AssumedHonest:    0,
+	Backends:         "",
+	StoreQuorum:      0,
+	StragglerTimeout: 0,
 }
 
 var BatchToDasFailed = errors.New("unable to batch to DAS")
@@ -43,6 +47,8 @@ func AggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultAggregatorConfig.Enable, "enable storage/retrieval of sequencer batch data from a list of RPC endpoints; this should only be used by the batch poster and not in combination with other DAS storage types")
 	f.Int(prefix+".assumed-honest", DefaultAggregatorConfig.AssumedHonest, "Number of assumed honest backends (H). If there are N backends, K=N+1-H valid responses are required to consider an Store request to be successful.")
 	f.String(prefix+".backends", DefaultAggregatorConfig.Backends, "JSON RPC backend configuration")
+	f.Int(prefix+".store-quorum", DefaultAggregatorConfig.StoreQuorum, "number of successful backend responses a Store request waits for, at least K=N+1-H (0 = K)")
+	f.Duration(prefix+".straggler-timeout", DefaultAggregatorConfig.StragglerTimeout, "once K backends have responded successfully, how long to wait for the rest of the store-quorum before returning (0 = wait until the request times out)")
 }
 
 type Aggregator struct {
@@ -52,6 +58,7 @@ type Aggregator struct {
 
 	// calculated fields
 	requiredServicesForStore       int
+	storeQuorum                    int
 	maxAllowedServiceStoreFailures int
 	keysetHash                     [32]byte
 	keysetBytes                    []byte
@@ -128,11 +135,21 @@ func NewAggregatorWithSeqInboxCaller(
 		bpVerifier = contracts.NewBatchPosterVerifier(seqInboxCaller)
 	}
 
+	requiredServicesForStore := len(services) + 1 - config.RPCAggregator.AssumedHonest
+	storeQuorum := config.RPCAggregator.StoreQuorum
+	if storeQuorum == 0 {
+		storeQuorum = requiredServicesForStore
+	}
+	if storeQuorum < requiredServicesForStore || storeQuorum > len(services) {
+		return nil, fmt.Errorf("invalid store-quorum %d, must be between %d and %d", storeQuorum, requiredServicesForStore, len(services))
+	}
+
 	return &Aggregator{
 		config:                         config.RPCAggregator,
 		services:                       services,
 		requestTimeout:                 config.RequestTimeout,
-		requiredServicesForStore:       len(services) + 1 - config.RPCAggregator.AssumedHonest,
+		requiredServicesForStore:       requiredServicesForStore,
+		storeQuorum:                    storeQuorum,
 		maxAllowedServiceStoreFailures: config.RPCAggregator.AssumedHonest - 1,
 		keysetHash:                     keysetHash,
 		keysetBytes:                    keysetBytes,
@@ -147,8 +164,9 @@ type storeResponse struct {
 }
 
 // Store calls Store on each backend DAS in parallel and collects responses.
-// If there were at least K responses then it aggregates the signatures and
-// signersMasks from each DAS together into the DataAvailabilityCertificate
+// If there were at least store-quorum responses (K by default), or at least K
+// responses and the straggler timeout has passed since the K-th, then it aggregates
+// the signatures and signersMasks from each DAS together into the DataAvailabilityCertificate
 // then Store returns immediately. If there were any backend Store subroutines
 // that were still running when Aggregator.Store returns, they are allowed to
 // continue running until the context is canceled (eg via TimeoutWrapper),
@@ -194,7 +212,9 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 				metrics.GetOrRegisterCounter(metricBase+"/error/all/total", nil).Inc(1)
 			}
 
+			start := time.Now()
 			cert, err := d.service.Store(storeCtx, message, timeout, sig)
+			metrics.GetOrRegisterHistogram(metricWithServiceName+"/latency", nil, metrics.NewBoundedHistogramSample()).Update(time.Since(start).Nanoseconds())
 			if err != nil {
 				incFailureMetric()
 				if errors.Is(err, context.DeadlineExceeded) {
@@ -260,11 +280,34 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 		var aggSignersMask uint64
 		var storeFailures, successfullyStoredCount int
 		var returned bool
+		var stragglerTimeout <-chan time.Time
+		returnCert := func() {
+			cd := certDetails{}
+			cd.pubKeys = append(cd.pubKeys, pubKeys...)
+			cd.sigs = append(cd.sigs, sigs...)
+			cd.aggSignersMask = aggSignersMask
+			certDetailsChan <- cd
+			returned = true
+		}
 		for i := 0; i < len(a.services); i++ {
 
 			select {
 			case <-ctx.Done():
-				break
+				if !returned {
+					certDetailsChan <- certDetails{err: fmt.Errorf("aggregator store interrupted after %d successful responses: %w", successfullyStoredCount, ctx.Err())}
+					returned = true
+				}
+				return
+			case <-stragglerTimeout:
+				stragglerTimeout = nil
+				if !returned {
+					log.Info("das.Aggregator: not waiting for slow backends", "successes", successfullyStoredCount, "quorum", a.storeQuorum)
+					metrics.GetOrRegisterCounter("arb/das/rpc/aggregator/store/straggler_timeout/total", nil).Inc(1)
+					returnCert()
+				}
+				// no response was received
+				i--
+				continue
 			case r := <-responses:
 				if r.err != nil {
 					storeFailures++
@@ -283,13 +326,13 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 			// running until all responses are received (or the context is canceled)
 			// in order to produce accurate logs/metrics.
 			if !returned {
-				if successfullyStoredCount >= a.requiredServicesForStore {
-					cd := certDetails{}
-					cd.pubKeys = append(cd.pubKeys, pubKeys...)
-					cd.sigs = append(cd.sigs, sigs...)
-					cd.aggSignersMask = aggSignersMask
-					certDetailsChan <- cd
-					returned = true
+				allResponded := i == len(a.services)-1
+				if successfullyStoredCount >= a.storeQuorum || (successfullyStoredCount >= a.requiredServicesForStore && allResponded) {
+					returnCert()
+				} else if successfullyStoredCount >= a.requiredServicesForStore {
+					if stragglerTimeout == nil && a.config.StragglerTimeout > 0 {
+						stragglerTimeout = time.After(a.config.StragglerTimeout)
+					}
 				} else if storeFailures > a.maxAllowedServiceStoreFailures {
 					cd := certDetails{}
 					cd.err = fmt.Errorf("aggregator failed to store message to at least %d out of %d DASes (assuming %d are honest). %w", a.requiredServicesForStore, len(a.services), a.config.AssumedHonest, BatchToDasFailed)
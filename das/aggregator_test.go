@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/bits"
 	"math/rand"
 	"os"
 	"strconv"
@@ -288,3 +289,57 @@ func TestDAS_AtLeastHStorageFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestDAS_StoreQuorumStragglerTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	numBackendDAS := 4
+	injectedFailures := &randomBagOfFailures{t: t, failures: []failureType{success, success, success, tooSlow}}
+	var backends []ServiceDetails
+	for i := 0; i < numBackendDAS; i++ {
+		privKey, err := blsSignatures.GeneratePrivKeyString()
+		Require(t, err)
+
+		config := DataAvailabilityConfig{
+			Enable: true,
+			Key: KeyConfig{
+				PrivKey: privKey,
+			},
+			ParentChainNodeURL: "none",
+		}
+
+		das, err := NewSignAfterStoreDASWriter(ctx, config, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		details, err := NewServiceDetails(&WrapStore{t, injectedFailures, das}, *das.pubKey, uint64(1<<i), "service"+strconv.Itoa(i))
+		Require(t, err)
+		backends = append(backends, *details)
+	}
+
+	aggConfig := AggregatorConfig{AssumedHonest: 2, StoreQuorum: 5}
+	_, err := NewAggregator(ctx, DataAvailabilityConfig{RPCAggregator: aggConfig, ParentChainNodeURL: "none"}, backends)
+	if err == nil {
+		Fail(t, "Expected error from store quorum larger than the number of backends.")
+	}
+
+	aggConfig.StoreQuorum = numBackendDAS
+	aggConfig.StragglerTimeout = 100 * time.Millisecond
+	aggregator, err := NewAggregator(
+		ctx,
+		DataAvailabilityConfig{
+			RPCAggregator:      aggConfig,
+			ParentChainNodeURL: "none",
+			RequestTimeout:     time.Second * 10,
+		}, backends)
+	Require(t, err)
+
+	start := time.Now()
+	cert, err := aggregator.Store(ctx, []byte("It's time for you to see the fnords."), 0, []byte{})
+	Require(t, err, "Error storing message")
+	if time.Since(start) > 5*time.Second {
+		Fail(t, "Store waited for the slow backend.", time.Since(start))
+	}
+	if bits.OnesCount64(cert.SignersMask) != 3 {
+		Fail(t, "Expected 3 signers, got mask", cert.SignersMask)
+	}
+}
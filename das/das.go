@@ -53,6 +53,7 @@ type DataAvailabilityConfig struct {
 
 	RPCAggregator  AggregatorConfig              `koanf:"rpc-aggregator"`
 	RestAggregator RestfulClientAggregatorConfig `koanf:"rest-aggregator"`
+	KeysetCheck    string                        `koanf:"keyset-check"`
 
 	ParentChainNodeURL              string `koanf:"parent-chain-node-url"`
 	ParentChainConnectionAttempts   int    `koanf:"parent-chain-connection-attempts"`
@@ -69,6 +70,7 @@ var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
 	RestAggregator:                DefaultRestfulClientAggregatorConfig,
 	ParentChainConnectionAttempts: 15,
 	PanicOnError:                  false,
+	KeysetCheck:                   KeysetCheckFail,
 }
 
 func OptionalAddressFromString(s string) (*common.Address, error) {
@@ -126,6 +128,7 @@ func dataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet, r role) {
 		// These are only for batch poster
 		AggregatorConfigAddOptions(prefix+".rpc-aggregator", f)
 		f.Duration(prefix+".request-timeout", DefaultDataAvailabilityConfig.RequestTimeout, "Data Availability Service timeout duration for Store requests")
+		f.String(prefix+".keyset-check", DefaultDataAvailabilityConfig.KeysetCheck, "what to do at startup if the keyset of the rpc-aggregator backends isn't valid in the sequencer inbox or can't be read from it (\"fail\", \"warn\" or \"off\")")
 	}
 
 	// Both the Nitro node and daserver can use these options.
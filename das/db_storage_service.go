@@ -6,7 +6,9 @@ package das
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"time"
 
 	badger "github.com/dgraph-io/badger/v3"
@@ -20,14 +22,17 @@ import (
 )
 
 type LocalDBStorageConfig struct {
-	Enable                 bool   `koanf:"enable"`
-	DataDir                string `koanf:"data-dir"`
-	DiscardAfterTimeout    bool   `koanf:"discard-after-timeout"`
-	SyncFromStorageService bool   `koanf:"sync-from-storage-service"`
-	SyncToStorageService   bool   `koanf:"sync-to-storage-service"`
+	Enable                 bool            `koanf:"enable"`
+	DataDir                string          `koanf:"data-dir"`
+	DiscardAfterTimeout    bool            `koanf:"discard-after-timeout"`
+	SyncFromStorageService bool            `koanf:"sync-from-storage-service"`
+	SyncToStorageService   bool            `koanf:"sync-to-storage-service"`
+	Retention              RetentionConfig `koanf:"retention"`
 }
 
-var DefaultLocalDBStorageConfig = LocalDBStorageConfig{}
+var DefaultLocalDBStorageConfig = LocalDBStorageConfig{
+	Retention: DefaultRetentionConfig,
+}
 
 func LocalDBStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultLocalDBStorageConfig.Enable, "enable storage/retrieval of sequencer batch data from a database on the local filesystem")
@@ -35,29 +40,79 @@ func LocalDBStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".discard-after-timeout", DefaultLocalDBStorageConfig.DiscardAfterTimeout, "discard data after its expiry timeout")
 	f.Bool(prefix+".sync-from-storage-service", DefaultLocalDBStorageConfig.SyncFromStorageService, "enable db storage to be used as a source for regular sync storage")
 	f.Bool(prefix+".sync-to-storage-service", DefaultLocalDBStorageConfig.SyncToStorageService, "enable db storage to be used as a sink for regular sync storage")
+	RetentionConfigAddOptions(prefix+".retention", f)
+}
+
+func (c *LocalDBStorageConfig) Validate() error {
+	if c.Retention.Enable && c.SyncFromStorageService {
+		return errors.New("local-db-storage.retention can't be used with sync-from-storage-service, as deleting data breaks the iteration order")
+	}
+	return c.Retention.Validate()
+}
+
+// The retention index has a key per stored value, ordered by the time it was stored, with the value's size.
+// Its keys are longer than the 32 byte hashes the data is stored under.
+var (
+	retentionIndexPrefix = []byte("das-retention-index/")
+	// set once data stored before retention was enabled has been added to the index
+	retentionIndexedKey = []byte("das-retention-indexed")
+)
+
+func retentionIndexKey(storedAt time.Time, key []byte) []byte {
+	indexKey := append([]byte{}, retentionIndexPrefix...)
+	indexKey = binary.BigEndian.AppendUint64(indexKey, uint64(storedAt.UnixNano()))
+	return append(indexKey, key...)
+}
+
+func parseRetentionIndexKey(indexKey []byte) (time.Time, []byte, error) {
+	if len(indexKey) != len(retentionIndexPrefix)+8+32 {
+		return time.Time{}, nil, fmt.Errorf("invalid retention index key %x", indexKey)
+	}
+	storedAt := binary.BigEndian.Uint64(indexKey[len(retentionIndexPrefix):])
+	return time.Unix(0, int64(storedAt)), indexKey[len(retentionIndexPrefix)+8:], nil
 }
 
 type DBStorageService struct {
 	db                  *badger.DB
 	discardAfterTimeout bool
+	retention           RetentionConfig
 	dirPath             string
 	stopWaiter          stopwaiter.StopWaiterSafe
 }
 
-func NewDBStorageService(ctx context.Context, dirPath string, discardAfterTimeout bool) (StorageService, error) {
-	db, err := badger.Open(badger.DefaultOptions(dirPath))
+func NewDBStorageService(ctx context.Context, config *LocalDBStorageConfig) (StorageService, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	db, err := badger.Open(badger.DefaultOptions(config.DataDir))
 	if err != nil {
 		return nil, err
 	}
 
 	ret := &DBStorageService{
 		db:                  db,
-		discardAfterTimeout: discardAfterTimeout,
-		dirPath:             dirPath,
+		discardAfterTimeout: config.DiscardAfterTimeout,
+		retention:           config.Retention,
+		dirPath:             config.DataDir,
 	}
 	if err := ret.stopWaiter.Start(ctx, ret); err != nil {
 		return nil, err
 	}
+	if ret.retention.Enable {
+		// before anything new is stored, which is indexed as it's stored
+		if err := ret.indexExisting(time.Now()); err != nil {
+			return nil, err
+		}
+		err = ret.stopWaiter.CallIterativelySafe(func(ctx context.Context) time.Duration {
+			if err := ret.prune(time.Now()); err != nil {
+				log.Error("error pruning local db storage", "dir", ret.dirPath, "err", err)
+			}
+			return ret.retention.PruneInterval
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	err = ret.stopWaiter.LaunchThreadSafe(func(myCtx context.Context) {
 		ticker := time.NewTicker(5 * time.Minute)
 		defer ticker.Stop()
@@ -112,14 +167,142 @@ func (dbs *DBStorageService) Put(ctx context.Context, data []byte, timeout uint6
 	logPut("das.DBStorageService.Put", data, timeout, dbs)
 
 	return dbs.db.Update(func(txn *badger.Txn) error {
-		e := badger.NewEntry(dastree.HashBytes(data), data)
+		key := dastree.HashBytes(data)
+		e := badger.NewEntry(key, data)
+		var index *badger.Entry
+		if dbs.retention.Enable {
+			// data stored again keeps its original index entry
+			_, err := txn.Get(key)
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				index = badger.NewEntry(retentionIndexKey(time.Now(), key), binary.BigEndian.AppendUint64(nil, uint64(len(data))))
+			} else if err != nil {
+				return err
+			}
+		}
 		if dbs.discardAfterTimeout {
-			e = e.WithTTL(time.Until(time.Unix(int64(timeout), 0)))
+			ttl := time.Until(time.Unix(int64(timeout), 0))
+			e = e.WithTTL(ttl)
+			if index != nil {
+				index = index.WithTTL(ttl)
+			}
+		}
+		if index != nil {
+			if err := txn.SetEntry(index); err != nil {
+				return err
+			}
 		}
 		return txn.SetEntry(e)
 	})
 }
 
+// indexExisting adds data stored before retention was enabled to the retention index, as if it was stored now
+func (dbs *DBStorageService) indexExisting(now time.Time) error {
+	err := dbs.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(retentionIndexedKey)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, badger.ErrKeyNotFound) {
+		return err
+	}
+	batch := dbs.db.NewWriteBatch()
+	defer batch.Cancel()
+	indexed := 0
+	err = dbs.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{})
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if len(item.Key()) != 32 {
+				continue
+			}
+			index := badger.NewEntry(retentionIndexKey(now, item.KeyCopy(nil)), binary.BigEndian.AppendUint64(nil, uint64(item.ValueSize())))
+			if expiresAt := item.ExpiresAt(); expiresAt != 0 {
+				index = index.WithTTL(time.Until(time.Unix(int64(expiresAt), 0)))
+			}
+			if err := batch.SetEntry(index); err != nil {
+				return err
+			}
+			indexed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := batch.Set(retentionIndexedKey, []byte{1}); err != nil {
+		return err
+	}
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+	log.Info("added previously stored data to the local db storage retention index", "dir", dbs.dirPath, "entries", indexed)
+	return nil
+}
+
+// prune deletes the oldest data as the retention config requires, using the retention index
+func (dbs *DBStorageService) prune(now time.Time) error {
+	var entries []storedEntry
+	err := dbs.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{
+			PrefetchValues: true,
+			PrefetchSize:   100,
+			Prefix:         retentionIndexPrefix,
+		})
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			indexKey := item.KeyCopy(nil)
+			storedAt, _, err := parseRetentionIndexKey(indexKey)
+			if err != nil {
+				return err
+			}
+			size, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if len(size) != 8 {
+				return fmt.Errorf("invalid retention index value %x", size)
+			}
+			entries = append(entries, storedEntry{
+				key:      indexKey,
+				storedAt: storedAt,
+				size:     binary.BigEndian.Uint64(size),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	prune, remaining := selectForPruning(entries, &dbs.retention, now)
+	batch := dbs.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, entry := range prune {
+		_, key, err := parseRetentionIndexKey(entry.key)
+		if err != nil {
+			return err
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		if err := batch.Delete(entry.key); err != nil {
+			return err
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+	localDBRetentionMetrics.evicted.Inc(int64(len(prune)))
+	localDBRetentionMetrics.storedBytes.Update(int64(remaining))
+	if len(prune) > 0 {
+		log.Info("pruned local db storage", "dir", dbs.dirPath, "deleted", len(prune), "remainingBytes", remaining)
+	}
+	return nil
+}
+
 func (dbs *DBStorageService) putKeyValue(ctx context.Context, key common.Hash, value []byte) error {
 	return dbs.db.Update(func(txn *badger.Txn) error {
 		e := badger.NewEntry(key.Bytes(), value)
@@ -136,6 +319,9 @@ func (dbs *DBStorageService) Close(ctx context.Context) error {
 }
 
 func (dbs *DBStorageService) ExpirationPolicy(ctx context.Context) (arbstate.ExpirationPolicy, error) {
+	if dbs.retention.Enable {
+		return arbstate.DiscardAfterArchiveTimeout, nil
+	}
 	if dbs.discardAfterTimeout {
 		return arbstate.DiscardAfterDataTimeout, nil
 	}
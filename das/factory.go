@@ -28,7 +28,7 @@ func CreatePersistentStorageService(
 	storageServices := make([]StorageService, 0, 10)
 	var lifecycleManager LifecycleManager
 	if config.LocalDBStorage.Enable {
-		s, err := NewDBStorageService(ctx, config.LocalDBStorage.DataDir, config.LocalDBStorage.DiscardAfterTimeout)
+		s, err := NewDBStorageService(ctx, &config.LocalDBStorage)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -45,7 +45,7 @@ func CreatePersistentStorageService(
 	}
 
 	if config.LocalFileStorage.Enable {
-		s, err := NewLocalFileStorageService(config.LocalFileStorage.DataDir)
+		s, err := NewLocalFileStorageService(ctx, &config.LocalFileStorage)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -160,11 +160,14 @@ func CreateBatchPosterDAS(
 	}
 	// Done checking config requirements
 
-	var daWriter DataAvailabilityServiceWriter
-	daWriter, err := NewRPCAggregator(ctx, *config)
+	aggregator, err := NewRPCAggregator(ctx, *config)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if err := checkAggregatorKeyset(ctx, config, aggregator, l1Reader, sequencerInboxAddr); err != nil {
+		return nil, nil, nil, err
+	}
+	var daWriter DataAvailabilityServiceWriter = aggregator
 	if dataSigner != nil {
 		// In some tests the batch poster does not sign Store requests
 		daWriter, err = NewStoreSigningDAS(daWriter, dataSigner)
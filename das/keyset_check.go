@@ -0,0 +1,105 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+)
+
+var ErrKeysetNotValid = errors.New("DAS keyset isn't valid in the sequencer inbox")
+
+const (
+	KeysetCheckFail = "fail"
+	KeysetCheckWarn = "warn"
+	KeysetCheckOff  = "off"
+)
+
+func validateKeysetCheck(keysetCheck string) error {
+	switch keysetCheck {
+	case KeysetCheckFail, KeysetCheckWarn, KeysetCheckOff:
+		return nil
+	default:
+		return fmt.Errorf("invalid data-availability.keyset-check %#v, expected \"fail\", \"warn\" or \"off\"", keysetCheck)
+	}
+}
+
+// keysetCaller is the part of the sequencer inbox that tells which keysets are valid
+type keysetCaller interface {
+	IsValidKeysetHash(opts *bind.CallOpts, ksHash [32]byte) (bool, error)
+	GetKeysetCreationBlock(opts *bind.CallOpts, ksHash [32]byte) (*big.Int, error)
+}
+
+// CheckKeysetOnChain returns an error wrapping ErrKeysetNotValid, explaining why, if keysetHash isn't a valid keyset in the sequencer inbox
+func CheckKeysetOnChain(ctx context.Context, seqInboxCaller *bridgegen.SequencerInboxCaller, keysetHash [32]byte) error {
+	return checkKeysetOnChain(ctx, seqInboxCaller, keysetHash)
+}
+
+func checkKeysetOnChain(ctx context.Context, seqInboxCaller keysetCaller, keysetHash [32]byte) error {
+	opts := &bind.CallOpts{Context: ctx}
+	valid, err := seqInboxCaller.IsValidKeysetHash(opts, keysetHash)
+	if err != nil {
+		return err
+	}
+	if valid {
+		return nil
+	}
+	// the creation block is kept when a keyset is invalidated
+	creationBlock, err := seqInboxCaller.GetKeysetCreationBlock(opts, keysetHash)
+	if err != nil || creationBlock.Sign() == 0 {
+		return fmt.Errorf("%w: keyset %v was never added", ErrKeysetNotValid, hexutil.Encode(keysetHash[:]))
+	}
+	return fmt.Errorf("%w: keyset %v added in parent chain block %v has been invalidated", ErrKeysetNotValid, hexutil.Encode(keysetHash[:]), creationBlock)
+}
+
+// checkAggregatorKeyset checks the keyset of the rpc-aggregator backends can be used to post batches, as configured by keyset-check
+func checkAggregatorKeyset(ctx context.Context, config *DataAvailabilityConfig, aggregator *Aggregator, l1client arbutil.L1Interface, seqInboxAddress common.Address) error {
+	keysetCheck := config.KeysetCheck
+	if keysetCheck == "" {
+		keysetCheck = DefaultDataAvailabilityConfig.KeysetCheck
+	}
+	if err := validateKeysetCheck(keysetCheck); err != nil {
+		return err
+	}
+	if keysetCheck == KeysetCheckOff {
+		return nil
+	}
+	seqInboxCaller, err := bridgegen.NewSequencerInboxCaller(seqInboxAddress, l1client)
+	if err != nil {
+		return err
+	}
+	return checkKeyset(ctx, keysetCheck, seqInboxCaller, aggregator.keysetHash)
+}
+
+func checkKeyset(ctx context.Context, keysetCheck string, seqInboxCaller keysetCaller, keysetHash [32]byte) error {
+	err := checkKeysetOnChain(ctx, seqInboxCaller, keysetHash)
+	if err == nil {
+		log.Info("DAS keyset of the rpc-aggregator backends is valid in the sequencer inbox", "keysetHash", hexutil.Encode(keysetHash[:]))
+		return nil
+	}
+	if !errors.Is(err, ErrKeysetNotValid) {
+		// a keyset that can't be read can't be checked either
+		err = fmt.Errorf("failed to read DAS keyset %v from the sequencer inbox: %w", hexutil.Encode(keysetHash[:]), err)
+		if keysetCheck == KeysetCheckWarn {
+			log.Warn("couldn't check the DAS keyset of the rpc-aggregator backends", "err", err)
+			return nil
+		}
+		return fmt.Errorf("%w, check the parent chain connection and sequencer inbox address (keyset-check can be set to \"warn\")", err)
+	}
+	if keysetCheck == KeysetCheckWarn {
+		log.Warn("batches posted with the rpc-aggregator backends' certificates will be rejected, check data-availability.rpc-aggregator.backends and assumed-honest", "err", err)
+		return nil
+	}
+	return fmt.Errorf("%w, check data-availability.rpc-aggregator.backends and assumed-honest (keyset-check can be set to \"warn\")", err)
+}
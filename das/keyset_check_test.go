@@ -0,0 +1,66 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// testKeysetCaller is a sequencer inbox with the given keysets, mapped to their creation block, and which keysets are valid
+type testKeysetCaller struct {
+	creationBlocks map[[32]byte]int64
+	valid          map[[32]byte]bool
+	err            error
+}
+
+func (c *testKeysetCaller) IsValidKeysetHash(opts *bind.CallOpts, ksHash [32]byte) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.valid[ksHash], nil
+}
+
+func (c *testKeysetCaller) GetKeysetCreationBlock(opts *bind.CallOpts, ksHash [32]byte) (*big.Int, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return big.NewInt(c.creationBlocks[ksHash]), nil
+}
+
+func TestCheckKeyset(t *testing.T) {
+	ctx := context.Background()
+	if DefaultDataAvailabilityConfig.KeysetCheck != KeysetCheckFail {
+		Fail(t, "keyset mismatch doesn't abort startup by default")
+	}
+
+	validKeyset := [32]byte{1}
+	invalidatedKeyset := [32]byte{2}
+	missingKeyset := [32]byte{3}
+	caller := &testKeysetCaller{
+		creationBlocks: map[[32]byte]int64{validKeyset: 10, invalidatedKeyset: 20},
+		valid:          map[[32]byte]bool{validKeyset: true},
+	}
+	Require(t, checkKeyset(ctx, KeysetCheckFail, caller, validKeyset))
+	for _, keyset := range [][32]byte{invalidatedKeyset, missingKeyset} {
+		err := checkKeyset(ctx, KeysetCheckFail, caller, keyset)
+		if !errors.Is(err, ErrKeysetNotValid) {
+			Fail(t, "keyset", keyset, "not valid on chain not rejected", err)
+		}
+		Require(t, checkKeyset(ctx, KeysetCheckWarn, caller, keyset))
+	}
+
+	// a keyset that can't be read from the sequencer inbox can't be checked
+	readErr := errors.New("execution reverted")
+	unreadable := &testKeysetCaller{err: readErr}
+	err := checkKeyset(ctx, KeysetCheckFail, unreadable, validKeyset)
+	if !errors.Is(err, readErr) || errors.Is(err, ErrKeysetNotValid) {
+		Fail(t, "unreadable keyset not rejected", err)
+	}
+	Require(t, checkKeyset(ctx, KeysetCheckWarn, unreadable, validKeyset))
+}
@@ -17,19 +17,22 @@ import (
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/das/dastree"
 	"github.com/offchainlabs/nitro/util/pretty"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
 	flag "github.com/spf13/pflag"
 	"golang.org/x/sys/unix"
 )
 
 type LocalFileStorageConfig struct {
-	Enable                 bool   `koanf:"enable"`
-	DataDir                string `koanf:"data-dir"`
-	SyncFromStorageService bool   `koanf:"sync-from-storage-service"`
-	SyncToStorageService   bool   `koanf:"sync-to-storage-service"`
+	Enable                 bool            `koanf:"enable"`
+	DataDir                string          `koanf:"data-dir"`
+	SyncFromStorageService bool            `koanf:"sync-from-storage-service"`
+	SyncToStorageService   bool            `koanf:"sync-to-storage-service"`
+	Retention              RetentionConfig `koanf:"retention"`
 }
 
 var DefaultLocalFileStorageConfig = LocalFileStorageConfig{
-	DataDir: "",
+	DataDir:   "",
+	Retention: DefaultRetentionConfig,
 }
 
 func LocalFileStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -37,17 +40,97 @@ func LocalFileStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".data-dir", DefaultLocalFileStorageConfig.DataDir, "local data directory")
 	f.Bool(prefix+".sync-from-storage-service", DefaultLocalFileStorageConfig.SyncFromStorageService, "enable local storage to be used as a source for regular sync storage")
 	f.Bool(prefix+".sync-to-storage-service", DefaultLocalFileStorageConfig.SyncToStorageService, "enable local storage to be used as a sink for regular sync storage")
+	RetentionConfigAddOptions(prefix+".retention", f)
+}
+
+func (c *LocalFileStorageConfig) Validate() error {
+	if c.Retention.Enable && c.SyncFromStorageService {
+		return errors.New("local-file-storage.retention can't be used with sync-from-storage-service, as deleting data breaks the iteration order")
+	}
+	return c.Retention.Validate()
 }
 
 type LocalFileStorageService struct {
-	dataDir string
+	dataDir    string
+	retention  RetentionConfig
+	stopWaiter stopwaiter.StopWaiterSafe
 }
 
-func NewLocalFileStorageService(dataDir string) (StorageService, error) {
+func NewLocalFileStorageService(ctx context.Context, config *LocalFileStorageConfig) (StorageService, error) {
+	dataDir := config.DataDir
 	if unix.Access(dataDir, unix.W_OK|unix.R_OK) != nil {
 		return nil, fmt.Errorf("couldn't start LocalFileStorageService, directory '%s' must be readable and writeable", dataDir)
 	}
-	return &LocalFileStorageService{dataDir: dataDir}, nil
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	s := &LocalFileStorageService{
+		dataDir:   dataDir,
+		retention: config.Retention,
+	}
+	if err := s.stopWaiter.Start(ctx, s); err != nil {
+		return nil, err
+	}
+	if s.retention.Enable {
+		err := s.stopWaiter.CallIterativelySafe(func(ctx context.Context) time.Duration {
+			if err := s.prune(time.Now()); err != nil {
+				log.Error("error pruning local file storage", "dir", s.dataDir, "err", err)
+			}
+			return s.retention.PruneInterval
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// isStoredDataFile excludes temporary and unrelated files from pruning
+func isStoredDataFile(name string) bool {
+	if len(name) == 64 {
+		_, err := DecodeStorageServiceKey(name)
+		return err == nil
+	}
+	key, err := base32.StdEncoding.DecodeString(name)
+	return err == nil && len(key) == 32
+}
+
+// prune deletes the oldest files as the retention config requires, by modification time
+func (s *LocalFileStorageService) prune(now time.Time) error {
+	dirEntries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return err
+	}
+	var entries []storedEntry
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() || !isStoredDataFile(dirEntry.Name()) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		entries = append(entries, storedEntry{
+			key:      []byte(dirEntry.Name()),
+			storedAt: info.ModTime(),
+			size:     uint64(info.Size()),
+		})
+	}
+	prune, remaining := selectForPruning(entries, &s.retention, now)
+	for _, entry := range prune {
+		err := os.Remove(s.dataDir + "/" + string(entry.key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		localFileRetentionMetrics.evicted.Inc(1)
+	}
+	localFileRetentionMetrics.storedBytes.Update(int64(remaining))
+	if len(prune) > 0 {
+		log.Info("pruned local file storage", "dir", s.dataDir, "deleted", len(prune), "remainingBytes", remaining)
+	}
+	return nil
 }
 
 func (s *LocalFileStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
@@ -127,10 +210,13 @@ func (s *LocalFileStorageService) Sync(ctx context.Context) error {
 }
 
 func (s *LocalFileStorageService) Close(ctx context.Context) error {
-	return nil
+	return s.stopWaiter.StopAndWait()
 }
 
 func (s *LocalFileStorageService) ExpirationPolicy(ctx context.Context) (arbstate.ExpirationPolicy, error) {
+	if s.retention.Enable {
+		return arbstate.DiscardAfterArchiveTimeout, nil
+	}
 	return arbstate.KeepForever, nil
 }
 
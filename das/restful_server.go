@@ -14,6 +14,8 @@ import (
 	"strings"
 	"time"
 
+	flag "github.com/spf13/pflag"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -30,25 +32,55 @@ var (
 	restGetByHashDurationHistogram  = metrics.NewRegisteredHistogram("arb/das/rest/getbyhash/duration", nil, metrics.NewBoundedHistogramSample())
 )
 
+type RestfulServerHeadersConfig struct {
+	CacheMaxAge        time.Duration `koanf:"cache-max-age"`
+	DefaultCacheMaxAge time.Duration `koanf:"default-cache-max-age"`
+	CorsAllowedOrigins []string      `koanf:"cors-allowed-origins"`
+}
+
+var DefaultRestfulServerHeadersConfig = RestfulServerHeadersConfig{
+	CacheMaxAge:        28 * 24 * time.Hour, // data is immutable by hash, so it can be cached for long
+	DefaultCacheMaxAge: time.Second,         // used to reduce DOS possibility
+	CorsAllowedOrigins: []string{},
+}
+
+func RestfulServerHeadersConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Duration(prefix+".cache-max-age", DefaultRestfulServerHeadersConfig.CacheMaxAge, "how long clients and proxies may cache data successfully retrieved by hash")
+	f.Duration(prefix+".default-cache-max-age", DefaultRestfulServerHeadersConfig.DefaultCacheMaxAge, "how long clients and proxies may cache all other responses, including errors")
+	f.StringSlice(prefix+".cors-allowed-origins", DefaultRestfulServerHeadersConfig.CorsAllowedOrigins, "origins allowed to make cross-origin requests (\"*\" to allow any, empty to not send CORS headers)")
+}
+
+func (c *RestfulServerHeadersConfig) Validate() error {
+	if c.CacheMaxAge < 0 || c.DefaultCacheMaxAge < 0 {
+		return errors.New("REST server cache max age must not be negative")
+	}
+	return nil
+}
+
 type RestfulDasServer struct {
 	server               *http.Server
+	headers              RestfulServerHeadersConfig
 	daReader             arbstate.DataAvailabilityReader
 	daHealthChecker      DataAvailabilityServiceHealthChecker
 	httpServerExitedChan chan interface{}
 	httpServerError      error
 }
 
-func NewRestfulDasServer(address string, port uint64, restServerTimeouts genericconf.HTTPServerTimeoutConfig, daReader arbstate.DataAvailabilityReader, daHealthChecker DataAvailabilityServiceHealthChecker) (*RestfulDasServer, error) {
+func NewRestfulDasServer(address string, port uint64, restServerTimeouts genericconf.HTTPServerTimeoutConfig, restServerHeaders RestfulServerHeadersConfig, daReader arbstate.DataAvailabilityReader, daHealthChecker DataAvailabilityServiceHealthChecker) (*RestfulDasServer, error) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", address, port))
 	if err != nil {
 		return nil, err
 	}
-	return NewRestfulDasServerOnListener(listener, restServerTimeouts, daReader, daHealthChecker)
+	return NewRestfulDasServerOnListener(listener, restServerTimeouts, restServerHeaders, daReader, daHealthChecker)
 }
 
-func NewRestfulDasServerOnListener(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, daReader arbstate.DataAvailabilityReader, daHealthChecker DataAvailabilityServiceHealthChecker) (*RestfulDasServer, error) {
+func NewRestfulDasServerOnListener(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, restServerHeaders RestfulServerHeadersConfig, daReader arbstate.DataAvailabilityReader, daHealthChecker DataAvailabilityServiceHealthChecker) (*RestfulDasServer, error) {
 
+	if err := restServerHeaders.Validate(); err != nil {
+		return nil, err
+	}
 	ret := &RestfulDasServer{
+		headers:              restServerHeaders,
 		daReader:             daReader,
 		daHealthChecker:      daHealthChecker,
 		httpServerExitedChan: make(chan interface{}),
@@ -80,14 +112,57 @@ type RestfulDasServerResponse struct {
 
 var cacheControlKey = http.CanonicalHeaderKey("cache-control")
 
-const cacheControlValueDefault = "public, max-age=1"                                 // cache for up to 1 second (Used to reduce DOS possibility)
-const cacheControlValueForSuccessfulGetByHash = "public, max-age=2419200, immutable" // cache for up to 28 days
 const healthRequestPath = "/health"
 const expirationPolicyRequestPath = "/expiration-policy/"
 const getByHashRequestPath = "/get-by-hash/"
 
+func cacheControlValue(maxAge time.Duration, immutable bool) string {
+	value := fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second))
+	if immutable {
+		value += ", immutable"
+	}
+	return value
+}
+
+// setCorsHeaders returns true if the request was a CORS preflight request which has been answered
+func (rds *RestfulDasServer) setCorsHeaders(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(rds.headers.CorsAllowedOrigins) == 0 {
+		return false
+	}
+	allowed := ""
+	for _, allowedOrigin := range rds.headers.CorsAllowedOrigins {
+		if allowedOrigin == "*" {
+			allowed = "*"
+			break
+		}
+		if strings.EqualFold(allowedOrigin, origin) {
+			allowed = origin
+		}
+	}
+	if allowed == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowed)
+	if allowed != "*" {
+		w.Header().Add("Vary", "Origin")
+	}
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	if requestHeaders := r.Header.Get("Access-Control-Request-Headers"); requestHeaders != "" {
+		w.Header().Set("Access-Control-Allow-Headers", requestHeaders)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
 func (rds *RestfulDasServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header()[cacheControlKey] = []string{cacheControlValueDefault}
+	w.Header()[cacheControlKey] = []string{cacheControlValue(rds.headers.DefaultCacheMaxAge, false)}
+	if rds.setCorsHeaders(w, r) {
+		return
+	}
 	requestPath := path.Clean(r.URL.Path)
 	log.Debug("Got request", "requestPath", requestPath)
 	switch {
@@ -177,13 +252,14 @@ func (rds *RestfulDasServer) GetByHashHandler(w http.ResponseWriter, r *http.Req
 	response.Data = string(encodedResponseData)
 	restGetByHashReturnedBytesGauge.Inc(int64(len(response.Data)))
 
+	// headers must be set before the body is written
+	w.Header()[cacheControlKey] = []string{cacheControlValue(rds.headers.CacheMaxAge, true)}
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.Header()[cacheControlKey] = []string{cacheControlValueForSuccessfulGetByHash}
 	success = true
 }
 
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -28,7 +30,7 @@ func NewRestfulDasServerOnRandomPort(address string, storageService StorageServi
 	if !ok {
 		return nil, 0, errors.New("attempt to listen on TCP returned non-TCP address")
 	}
-	rds, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, storageService, storageService)
+	rds, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulServerHeadersConfig, storageService, storageService)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -68,3 +70,57 @@ func TestRestfulClientServer(t *testing.T) {
 	err = server.Shutdown()
 	Require(t, err)
 }
+
+func TestRestfulServerHeaders(t *testing.T) {
+	initTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMemoryBackedStorageService(ctx)
+	data := []byte("Testing restful server headers.")
+	err := storage.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix()))
+	Require(t, err)
+
+	headers := DefaultRestfulServerHeadersConfig
+	headers.CacheMaxAge = time.Hour
+	headers.CorsAllowedOrigins = []string{"https://example.com"}
+	server := &RestfulDasServer{headers: headers, daReader: storage, daHealthChecker: storage}
+
+	request := func(method string, path string, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	found := request(http.MethodGet, getByHashRequestPath+dastree.Hash(data).Hex(), "https://example.com")
+	if cacheControl := found.Header().Get("Cache-Control"); cacheControl != "public, max-age=3600, immutable" {
+		Fail(t, "unexpected cache control for data found by hash", cacheControl)
+	}
+	if allowOrigin := found.Header().Get("Access-Control-Allow-Origin"); allowOrigin != "https://example.com" {
+		Fail(t, "allowed origin not returned", allowOrigin)
+	}
+
+	missing := request(http.MethodGet, getByHashRequestPath+dastree.Hash([]byte("absent data")).Hex(), "https://other.com")
+	if missing.Code != http.StatusNotFound {
+		Fail(t, "expected a 404, got", missing.Code)
+	}
+	if cacheControl := missing.Header().Get("Cache-Control"); cacheControl != "public, max-age=1" {
+		Fail(t, "unexpected cache control for missing data", cacheControl)
+	}
+	if allowOrigin := missing.Header().Get("Access-Control-Allow-Origin"); allowOrigin != "" {
+		Fail(t, "disallowed origin got CORS headers", allowOrigin)
+	}
+
+	preflight := request(http.MethodOptions, getByHashRequestPath, "https://example.com")
+	if preflight.Code != http.StatusNoContent || preflight.Header().Get("Access-Control-Allow-Methods") == "" {
+		Fail(t, "preflight request not answered", preflight.Code)
+	}
+}
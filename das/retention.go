@@ -0,0 +1,102 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	flag "github.com/spf13/pflag"
+)
+
+type RetentionConfig struct {
+	Enable        bool          `koanf:"enable"`
+	MaxAge        time.Duration `koanf:"max-age"`
+	MaxSize       uint64        `koanf:"max-size"`
+	MinAge        time.Duration `koanf:"min-age"`
+	PruneInterval time.Duration `koanf:"prune-interval"`
+}
+
+var DefaultRetentionConfig = RetentionConfig{
+	Enable:        false,
+	MaxAge:        0,
+	MaxSize:       0,
+	MinAge:        time.Hour * 24 * 15,
+	PruneInterval: time.Hour,
+}
+
+func RetentionConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultRetentionConfig.Enable, "periodically delete old data from local storage")
+	f.Duration(prefix+".max-age", DefaultRetentionConfig.MaxAge, "delete data stored longer ago than this (0 to not limit by age)")
+	f.Uint64(prefix+".max-size", DefaultRetentionConfig.MaxSize, "delete the oldest data once the stored data exceeds this many bytes (0 to not limit by size)")
+	f.Duration(prefix+".min-age", DefaultRetentionConfig.MinAge, "never delete data stored more recently than this, even over max-size, as it may still be needed (should cover the batch poster's das-retention-period)")
+	f.Duration(prefix+".prune-interval", DefaultRetentionConfig.PruneInterval, "how often to check for data to delete")
+}
+
+func (c *RetentionConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.MaxAge == 0 && c.MaxSize == 0 {
+		return errors.New("retention enabled without a max-age or max-size")
+	}
+	if c.MaxAge != 0 && c.MaxAge < c.MinAge {
+		return errors.New("retention max-age must not be less than min-age")
+	}
+	if c.PruneInterval <= 0 {
+		return errors.New("retention prune-interval must be positive")
+	}
+	return nil
+}
+
+type retentionMetrics struct {
+	storedBytes metrics.Gauge
+	evicted     metrics.Counter
+}
+
+func newRetentionMetrics(name string) retentionMetrics {
+	prefix := "arb/das/" + name + "/retention"
+	return retentionMetrics{
+		storedBytes: metrics.NewRegisteredGauge(prefix+"/storedbytes", nil),
+		evicted:     metrics.NewRegisteredCounter(prefix+"/evicted", nil),
+	}
+}
+
+var (
+	localDBRetentionMetrics   = newRetentionMetrics("localdb")
+	localFileRetentionMetrics = newRetentionMetrics("localfile")
+)
+
+type storedEntry struct {
+	key      []byte
+	storedAt time.Time
+	size     uint64
+}
+
+// selectForPruning returns the entries the retention config wants deleted, and the size of the remaining ones
+func selectForPruning(entries []storedEntry, config *RetentionConfig, now time.Time) ([]storedEntry, uint64) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].storedAt.Before(entries[j].storedAt) })
+	var totalSize uint64
+	for _, entry := range entries {
+		totalSize += entry.size
+	}
+	var prune []storedEntry
+	for _, entry := range entries {
+		age := now.Sub(entry.storedAt)
+		if age < config.MinAge {
+			// the remaining entries are newer
+			break
+		}
+		tooOld := config.MaxAge != 0 && age > config.MaxAge
+		tooLarge := config.MaxSize != 0 && totalSize > config.MaxSize
+		if !tooOld && !tooLarge {
+			break
+		}
+		prune = append(prune, entry)
+		totalSize -= entry.size
+	}
+	return prune, totalSize
+}
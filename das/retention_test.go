@@ -0,0 +1,109 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func TestSelectForPruning(t *testing.T) {
+	now := time.Now()
+	entries := []storedEntry{
+		{key: []byte("new"), storedAt: now.Add(-time.Hour), size: 10},
+		{key: []byte("old"), storedAt: now.Add(-3 * time.Hour), size: 10},
+		{key: []byte("older"), storedAt: now.Add(-4 * time.Hour), size: 10},
+	}
+	config := &RetentionConfig{
+		MaxSize: 15,
+		MinAge:  2 * time.Hour,
+	}
+	// the min age keeps the newest entry even though it's over the max size
+	prune, remaining := selectForPruning(entries, config, now)
+	if len(prune) != 2 || string(prune[0].key) != "older" || string(prune[1].key) != "old" || remaining != 10 {
+		t.Fatal("unexpected entries pruned by size", prune, remaining)
+	}
+
+	config = &RetentionConfig{
+		MaxAge: 3*time.Hour + time.Minute,
+	}
+	prune, remaining = selectForPruning(entries, config, now)
+	if len(prune) != 1 || string(prune[0].key) != "older" || remaining != 20 {
+		t.Fatal("unexpected entries pruned by age", prune, remaining)
+	}
+}
+
+func TestLocalFileStorageRetention(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultLocalFileStorageConfig
+	config.Enable = true
+	config.DataDir = t.TempDir()
+	config.Retention = RetentionConfig{
+		Enable:        true,
+		MaxAge:        time.Hour,
+		PruneInterval: time.Hour,
+	}
+	storageService, err := NewLocalFileStorageService(ctx, &config)
+	Require(t, err)
+	defer func() {
+		Require(t, storageService.Close(ctx))
+	}()
+	s := storageService.(*LocalFileStorageService)
+
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	oldData, newData := []byte("old data"), []byte("new data")
+	Require(t, s.Put(ctx, oldData, timeout))
+	Require(t, s.Put(ctx, newData, timeout))
+	old := time.Now().Add(-2 * time.Hour)
+	Require(t, os.Chtimes(s.dataDir+"/"+EncodeStorageServiceKey(dastree.Hash(oldData)), old, old))
+
+	Require(t, s.prune(time.Now()))
+	if _, err := s.GetByHash(ctx, dastree.Hash(oldData)); !errors.Is(err, ErrNotFound) {
+		t.Fatal("old data not pruned", err)
+	}
+	if _, err := s.GetByHash(ctx, dastree.Hash(newData)); err != nil {
+		t.Fatal("new data pruned", err)
+	}
+}
+
+func TestDBStorageRetention(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultLocalDBStorageConfig
+	config.Enable = true
+	config.DataDir = t.TempDir()
+	config.Retention = RetentionConfig{
+		Enable:        true,
+		MaxSize:       10,
+		PruneInterval: time.Hour,
+	}
+	storageService, err := NewDBStorageService(ctx, &config)
+	Require(t, err)
+	defer func() {
+		Require(t, storageService.Close(ctx))
+	}()
+	s := storageService.(*DBStorageService)
+
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	oldData, newData := []byte("old data"), []byte("new data")
+	Require(t, s.Put(ctx, oldData, timeout))
+	time.Sleep(time.Millisecond)
+	Require(t, s.Put(ctx, newData, timeout))
+
+	Require(t, s.prune(time.Now()))
+	if _, err := s.GetByHash(ctx, dastree.Hash(oldData)); !errors.Is(err, ErrNotFound) {
+		t.Fatal("old data not pruned", err)
+	}
+	if _, err := s.GetByHash(ctx, dastree.Hash(newData)); err != nil {
+		t.Fatal("new data pruned", err)
+	}
+}
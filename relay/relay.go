@@ -6,13 +6,17 @@ package relay
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
+	"net/http"
 	"time"
 
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcastclient"
 	"github.com/offchainlabs/nitro/broadcastclients"
@@ -30,6 +34,9 @@ type Relay struct {
 	broadcaster                 *broadcaster.Broadcaster
 	confirmedSequenceNumberChan chan arbutil.MessageIndex
 	messageChan                 chan broadcaster.BroadcastFeedMessage
+	feedOutputConfig            wsbroadcastserver.BroadcasterConfigFetcher
+	rpcConfig                   RPCConfig
+	rpcListener                 net.Listener
 }
 
 type MessageQueue struct {
@@ -69,11 +76,14 @@ func NewRelay(config *Config, feedErrChan chan error) (*Relay, error) {
 	dataSignerErr := func([]byte) ([]byte, error) {
 		return nil, errors.New("relay attempted to sign feed message")
 	}
+	feedOutputConfig := func() *wsbroadcastserver.BroadcasterConfig { return &config.Node.Feed.Output }
 	return &Relay{
-		broadcaster:                 broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &config.Node.Feed.Output }, config.Chain.ID, feedErrChan, dataSignerErr),
+		broadcaster:                 broadcaster.NewBroadcaster(feedOutputConfig, config.Chain.ID, feedErrChan, dataSignerErr),
 		broadcastClients:            clients,
 		confirmedSequenceNumberChan: confirmedSequenceNumberListener,
 		messageChan:                 q.queue,
+		feedOutputConfig:            feedOutputConfig,
+		rpcConfig:                   config.RPC,
 	}, nil
 }
 
@@ -91,6 +101,13 @@ func (r *Relay) Start(ctx context.Context) error {
 		return errors.New("broadcast unable to start")
 	}
 
+	if r.rpcConfig.Enable {
+		err = r.startRPCServer()
+		if err != nil {
+			return err
+		}
+	}
+
 	r.broadcastClients.Start(ctx)
 
 	var lastConfirmed arbutil.MessageIndex
@@ -129,10 +146,52 @@ func (r *Relay) Start(ctx context.Context) error {
 	return nil
 }
 
+// startRPCServer serves the feed output admin API, the relay has no other RPC server
+func (r *Relay) startRPCServer() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", r.rpcConfig.Addr, r.rpcConfig.Port))
+	if err != nil {
+		return fmt.Errorf("relay rpc server unable to listen: %w", err)
+	}
+	r.rpcListener = listener
+	rpcServer := rpc.NewServer()
+	err = rpcServer.RegisterName("arbdebug", arbnode.NewFeedOutputAPI(r.broadcaster, r.feedOutputConfig))
+	if err != nil {
+		return err
+	}
+	timeouts := r.rpcConfig.ServerTimeouts
+	srv := &http.Server{
+		Handler:           rpcServer,
+		ReadTimeout:       timeouts.ReadTimeout,
+		ReadHeaderTimeout: timeouts.ReadHeaderTimeout,
+		WriteTimeout:      timeouts.WriteTimeout,
+		IdleTimeout:       timeouts.IdleTimeout,
+	}
+	r.LaunchThread(func(ctx context.Context) {
+		err := srv.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("relay rpc server failed", "err", err)
+		}
+	})
+	r.LaunchThread(func(ctx context.Context) {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+		rpcServer.Stop()
+	})
+	return nil
+}
+
 func (r *Relay) GetListenerAddr() net.Addr {
 	return r.broadcaster.ListenerAddr()
 }
 
+// GetRPCListenerAddr returns the address of the relay's rpc server, or nil if it's disabled
+func (r *Relay) GetRPCListenerAddr() net.Addr {
+	if r.rpcListener == nil {
+		return nil
+	}
+	return r.rpcListener.Addr()
+}
+
 func (r *Relay) StopAndWait() {
 	r.StopWaiter.StopAndWait()
 	r.broadcastClients.StopAndWait()
@@ -150,6 +209,7 @@ type Config struct {
 	PprofCfg      genericconf.PProf               `koanf:"pprof-cfg"`
 	Node          NodeConfig                      `koanf:"node"`
 	Queue         int                             `koanf:"queue"`
+	RPC           RPCConfig                       `koanf:"rpc"`
 }
 
 var ConfigDefault = Config{
@@ -163,6 +223,7 @@ var ConfigDefault = Config{
 	PprofCfg:      genericconf.PProfDefault,
 	Node:          NodeConfigDefault,
 	Queue:         1024,
+	RPC:           RPCConfigDefault,
 }
 
 func ConfigAddOptions(f *flag.FlagSet) {
@@ -176,6 +237,29 @@ func ConfigAddOptions(f *flag.FlagSet) {
 	genericconf.PProfAddOptions("pprof-cfg", f)
 	NodeConfigAddOptions("node", f)
 	f.Int("queue", ConfigDefault.Queue, "queue for incoming messages from sequencer")
+	RPCConfigAddOptions("rpc", f)
+}
+
+// RPCConfig configures the relay's admin RPC server, which serves the feed output stats
+type RPCConfig struct {
+	Enable         bool                                `koanf:"enable"`
+	Addr           string                              `koanf:"addr"`
+	Port           uint64                              `koanf:"port"`
+	ServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
+}
+
+var RPCConfigDefault = RPCConfig{
+	Enable:         false,
+	Addr:           "localhost",
+	Port:           9643,
+	ServerTimeouts: genericconf.HTTPServerTimeoutConfigDefault,
+}
+
+func RPCConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", RPCConfigDefault.Enable, "enable the admin JSON-RPC server serving the arbdebug feed output API over http")
+	f.String(prefix+".addr", RPCConfigDefault.Addr, "admin JSON-RPC server listening interface")
+	f.Uint64(prefix+".port", RPCConfigDefault.Port, "admin JSON-RPC server listening port")
+	genericconf.HTTPServerTimeoutConfigAddOptions(prefix+".server-timeouts", f)
 }
 
 type NodeConfig struct {
@@ -218,7 +302,7 @@ func ParseRelay(_ context.Context, args []string) (*Config, error) {
 	}
 
 	if relayConfig.Conf.Dump {
-		err = confighelpers.DumpConfig(k, map[string]interface{}{})
+		err = confighelpers.DumpConfig(k, f, map[string]interface{}{})
 		if err != nil {
 			return nil, err
 		}
@@ -32,8 +32,23 @@ var (
 	validatorFailedValidationsCounter = metrics.NewRegisteredCounter("arb/validator/validations/failed", nil)
 	validatorMsgCountCurrentBatch     = metrics.NewRegisteredGauge("arb/validator/msg_count_current_batch", nil)
 	validatorMsgCountValidatedGauge   = metrics.NewRegisteredGauge("arb/validator/msg_count_validated", nil)
+	validatorDisagreementsCounter     = metrics.NewRegisteredCounter("arb/validator/validations/disagreement", nil)
+	validatorLastValidatedAgeGauge    = metrics.NewRegisteredGauge("arb/validator/last_validated_age", nil)
+	validatorRunningValidationsGauge  = metrics.NewRegisteredGauge("arb/validator/validations/running", nil)
+	validatorQueuedValidationsGauge   = metrics.NewRegisteredGauge("arb/validator/validations/queued", nil)
+	validatorMismatchCounter          = metrics.NewRegisteredCounter("arb/validator/validations/mismatch", nil)
+	validatorReexecutionsCounter      = metrics.NewRegisteredCounter("arb/validator/validations/reexecuted", nil)
+	validatorLagGauge                 = metrics.NewRegisteredGauge("arb/validator/lag", nil)
+	validatorLagExceededCounter       = metrics.NewRegisteredCounter("arb/validator/lag/exceeded", nil)
+	validatorLagThrottlingGauge       = metrics.NewRegisteredGauge("arb/validator/lag/throttling", nil)
+	validatorModuleRootMissingGauge   = metrics.NewRegisteredGauge("arb/validator/module_root/missing", nil)
+	// blocks held back by max-concurrent-validations and validation-queue-size, each counted once
+	validatorDeferredLaunchCounter = metrics.NewRegisteredCounter("arb/validator/validations/deferred/concurrency", nil)
+	validatorDeferredRecordCounter = metrics.NewRegisteredCounter("arb/validator/validations/deferred/queue", nil)
 )
 
+var ErrValidationLagging = errors.New("validation fell too far behind the chain head")
+
 type BlockValidator struct {
 	stopwaiter.StopWaiter
 	*StatelessBlockValidator
@@ -57,6 +72,33 @@ type BlockValidator struct {
 	// only from logger thread
 	lastValidInfoPrinted *GlobalStateValidatedInfo
 
+	// only from lag checking thread
+	lagExceededSince time.Time // zero while validation keeps up
+	lagLastLogged    time.Time
+	lagThrottling    bool
+
+	// set before Start, slowed down while validation lags behind with validation-lag-policy throttle
+	throttler BlockProductionThrottler
+
+	// set by validation thread, can be read by anyone
+	lastValidatedTime atomic.Int64 // unix nanoseconds, 0 if nothing was validated since startup
+
+	// set by validation thread, read by record thread
+	runningValidations atomic.Int64
+
+	// only from record thread
+	recordDeferredAt arbutil.MessageIndex // one past the last block held back by validation-queue-size
+
+	// only from validation thread
+	mismatchPos          arbutil.MessageIndex
+	mismatchReexecutions uint64
+	reexecuteFrom        *arbutil.MessageIndex
+	availableModuleRoot  common.Hash // the last current module root a machine was found for
+	moduleRootCheckedAt  time.Time
+	moduleRootMissing    bool // whether the last check found no machine for the current module root
+	// one past the last block held back by max-concurrent-validations
+	launchDeferredAt arbutil.MessageIndex
+
 	// can be read (atomic.Load) by anyone holding reorg-read
 	// written (atomic.Set) by appropriate thread or (any way) holding reorg-write
 	createdA    uint64
@@ -77,19 +119,105 @@ type BlockValidator struct {
 }
 
 type BlockValidatorConfig struct {
-	Enable                   bool                          `koanf:"enable"`
-	ValidationServer         rpcclient.ClientConfig        `koanf:"validation-server" reload:"hot"`
-	ValidationPoll           time.Duration                 `koanf:"validation-poll" reload:"hot"`
-	PrerecordedBlocks        uint64                        `koanf:"prerecorded-blocks" reload:"hot"`
-	ForwardBlocks            uint64                        `koanf:"forward-blocks" reload:"hot"`
-	CurrentModuleRoot        string                        `koanf:"current-module-root"`         // TODO(magic) requires reinitialization on hot reload
-	PendingUpgradeModuleRoot string                        `koanf:"pending-upgrade-module-root"` // TODO(magic) requires StatelessBlockValidator recreation on hot reload
-	FailureIsFatal           bool                          `koanf:"failure-is-fatal" reload:"hot"`
-	Dangerous                BlockValidatorDangerousConfig `koanf:"dangerous"`
+	Enable                    bool                          `koanf:"enable"`
+	ValidationServer          rpcclient.ClientConfig        `koanf:"validation-server" reload:"hot"`
+	SecondaryValidationServer rpcclient.ClientConfig        `koanf:"secondary-validation-server"`
+	ValidationPoll            time.Duration                 `koanf:"validation-poll" reload:"hot"`
+	PrerecordedBlocks         uint64                        `koanf:"prerecorded-blocks" reload:"hot"`
+	ForwardBlocks             uint64                        `koanf:"forward-blocks" reload:"hot"`
+	CurrentModuleRoot         string                        `koanf:"current-module-root"`         // TODO(magic) requires reinitialization on hot reload
+	PendingUpgradeModuleRoot  string                        `koanf:"pending-upgrade-module-root"` // TODO(magic) requires StatelessBlockValidator recreation on hot reload
+	FailureIsFatal            bool                          `koanf:"failure-is-fatal" reload:"hot"`
+	StaleThreshold            time.Duration                 `koanf:"stale-threshold" reload:"hot"`
+	RequireValidationNode     bool                          `koanf:"require-validation-node"`
+	MachineLoadFailure        string                        `koanf:"machine-load-failure"`
+	ChainModuleRootAhead      string                        `koanf:"chain-module-root-ahead"`
+	MaxConcurrentValidations  uint64                        `koanf:"max-concurrent-validations" reload:"hot"`
+	ValidationQueueSize       uint64                        `koanf:"validation-queue-size" reload:"hot"`
+	MismatchPolicy            string                        `koanf:"mismatch-policy" reload:"hot"`
+	MismatchMaxReexecutions   uint64                        `koanf:"mismatch-max-reexecutions" reload:"hot"`
+	MaxValidationLag          uint64                        `koanf:"max-validation-lag" reload:"hot"`
+	ValidationLagPolicy       string                        `koanf:"validation-lag-policy" reload:"hot"`
+	ValidationLagThrottle     time.Duration                 `koanf:"validation-lag-throttle" reload:"hot"`
+	Dangerous                 BlockValidatorDangerousConfig `koanf:"dangerous"`
 }
 
 func (c *BlockValidatorConfig) Validate() error {
-	return c.ValidationServer.Validate()
+	if err := c.ValidationServer.Validate(); err != nil {
+		return err
+	}
+	switch c.MismatchPolicy {
+	case MismatchPolicyDefault, MismatchPolicyHalt, MismatchPolicyReexecute:
+	default:
+		return fmt.Errorf("invalid mismatch-policy %#v, expected %v, %v or %v", c.MismatchPolicy, MismatchPolicyDefault, MismatchPolicyHalt, MismatchPolicyReexecute)
+	}
+	switch c.ValidationLagPolicy {
+	case ValidationLagPolicyLog, ValidationLagPolicyThrottle, ValidationLagPolicyFatal:
+	default:
+		return fmt.Errorf("invalid validation-lag-policy %#v, expected %v, %v or %v", c.ValidationLagPolicy, ValidationLagPolicyLog, ValidationLagPolicyThrottle, ValidationLagPolicyFatal)
+	}
+	if c.ValidationLagThrottle < 0 {
+		return fmt.Errorf("invalid validation-lag-throttle %v, must not be negative", c.ValidationLagThrottle)
+	}
+	if c.RequireValidationNode && c.ValidationServer.URL != "self" && c.ValidationServer.URL != "self-auth" {
+		return errors.New("require-validation-node is only supported with a same-process validation node (validation-server url \"self\" or \"self-auth\")")
+	}
+	switch c.MachineLoadFailure {
+	case MachineLoadFailureAbort:
+	case MachineLoadFailureWatchtower:
+		if c.RequireValidationNode {
+			return fmt.Errorf("machine-load-failure %v can't be combined with require-validation-node", MachineLoadFailureWatchtower)
+		}
+	default:
+		return fmt.Errorf("invalid machine-load-failure %#v, expected %v or %v", c.MachineLoadFailure, MachineLoadFailureAbort, MachineLoadFailureWatchtower)
+	}
+	switch c.ChainModuleRootAhead {
+	case ChainModuleRootAheadAbort, ChainModuleRootAheadObserve:
+	default:
+		return fmt.Errorf("invalid chain-module-root-ahead %#v, expected %v or %v", c.ChainModuleRootAhead, ChainModuleRootAheadAbort, ChainModuleRootAheadObserve)
+	}
+	return c.SecondaryValidationServer.Validate()
+}
+
+const (
+	// abort startup if the same-process validation node's machine is missing or invalid
+	MachineLoadFailureAbort = "abort"
+	// disable the block validator and only watch the chain, as if the staker strategy was watchtower
+	MachineLoadFailureWatchtower = "watchtower"
+)
+
+const (
+	// fail when the chain's wasm module root is neither the current nor the pending one
+	ChainModuleRootAheadAbort = "abort"
+	// keep running without validating until a machine for the chain's wasm module root is available
+	ChainModuleRootAheadObserve = "observe"
+)
+
+// how often to check if a machine was installed for the current module root, while there's none
+const moduleRootRecheckInterval = time.Minute
+
+const (
+	// a state mismatch is handled like any other validation failure, see failure-is-fatal
+	MismatchPolicyDefault = "default"
+	// a state mismatch is always fatal
+	MismatchPolicyHalt = "halt"
+	// a state mismatch rolls execution back to the last validated block and re-executes from there
+	MismatchPolicyReexecute = "reexecute"
+)
+
+const (
+	// validation lagging behind is logged and reported in metrics
+	ValidationLagPolicyLog = "log"
+	// additionally, block production is slowed down until validation catches up, if this node produces blocks
+	ValidationLagPolicyThrottle = "throttle"
+	// additionally, validation lagging behind is a fatal error
+	ValidationLagPolicyFatal = "fatal"
+)
+
+// BlockProductionThrottler is implemented by the sequencer, which the block validator
+// slows down while validation lags behind, to give it a chance to catch up.
+type BlockProductionThrottler interface {
+	SetExtraBlockDelay(delay time.Duration)
 }
 
 type BlockValidatorDangerousConfig struct {
@@ -101,12 +229,24 @@ type BlockValidatorConfigFetcher func() *BlockValidatorConfig
 func BlockValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultBlockValidatorConfig.Enable, "enable block-by-block validation")
 	rpcclient.RPCClientAddOptions(prefix+".validation-server", f, &DefaultBlockValidatorConfig.ValidationServer)
+	rpcclient.RPCClientAddOptions(prefix+".secondary-validation-server", f, &DefaultBlockValidatorConfig.SecondaryValidationServer)
 	f.Duration(prefix+".validation-poll", DefaultBlockValidatorConfig.ValidationPoll, "poll time to check validations")
 	f.Uint64(prefix+".forward-blocks", DefaultBlockValidatorConfig.ForwardBlocks, "prepare entries for up to that many blocks ahead of validation (small footprint)")
 	f.Uint64(prefix+".prerecorded-blocks", DefaultBlockValidatorConfig.PrerecordedBlocks, "record that many blocks ahead of validation (larger footprint)")
 	f.String(prefix+".current-module-root", DefaultBlockValidatorConfig.CurrentModuleRoot, "current wasm module root ('current' read from chain, 'latest' from machines/latest dir, or provide hash)")
 	f.String(prefix+".pending-upgrade-module-root", DefaultBlockValidatorConfig.PendingUpgradeModuleRoot, "pending upgrade wasm module root to additionally validate (hash, 'latest' or empty)")
 	f.Bool(prefix+".failure-is-fatal", DefaultBlockValidatorConfig.FailureIsFatal, "failing a validation is treated as a fatal error")
+	f.Duration(prefix+".stale-threshold", DefaultBlockValidatorConfig.StaleThreshold, "report validation as stale, and the status page /healthz as unhealthy, if no block was validated for this long (0 to disable)")
+	f.Bool(prefix+".require-validation-node", DefaultBlockValidatorConfig.RequireValidationNode, "abort startup if the same-process validation node (validation-server url \"self\" or \"self-auth\") fails to start or isn't healthy")
+	f.String(prefix+".machine-load-failure", DefaultBlockValidatorConfig.MachineLoadFailure, "what to do if the same-process validation node's wasm machine is missing or doesn't match its module root: \"abort\" (abort startup) or \"watchtower\" (disable the block validator and continue with the watchtower staker strategy)")
+	f.String(prefix+".chain-module-root-ahead", DefaultBlockValidatorConfig.ChainModuleRootAhead, "what to do if the chain's wasm module root changes to one this node has no machine for, e.g. while staging an upgrade: \"abort\" (fail) or \"observe\" (keep following the chain without validating until the machine is installed)")
+	f.Uint64(prefix+".max-concurrent-validations", DefaultBlockValidatorConfig.MaxConcurrentValidations, "maximum number of blocks being validated at once, on top of the validation servers' own limits (0 = no additional limit)")
+	f.Uint64(prefix+".validation-queue-size", DefaultBlockValidatorConfig.ValidationQueueSize, "maximum number of recorded blocks waiting for a validation slot, on top of prerecorded-blocks (0 = no additional limit)")
+	f.String(prefix+".mismatch-policy", DefaultBlockValidatorConfig.MismatchPolicy, "what to do when validation computes a different state than execution: \"default\" (handled like other failures, see failure-is-fatal), \"halt\" (always fatal) or \"reexecute\" (roll execution back to the last validated block and re-execute)")
+	f.Uint64(prefix+".mismatch-max-reexecutions", DefaultBlockValidatorConfig.MismatchMaxReexecutions, "with mismatch-policy reexecute, number of re-executions of the same block before the mismatch is treated as fatal")
+	f.Uint64(prefix+".max-validation-lag", DefaultBlockValidatorConfig.MaxValidationLag, "number of executed messages validation can fall behind before it's handled according to validation-lag-policy (0 = no limit)")
+	f.String(prefix+".validation-lag-policy", DefaultBlockValidatorConfig.ValidationLagPolicy, "what to do when validation falls more than max-validation-lag messages behind: \"log\" (log and report in metrics), \"throttle\" (also slow down this node's sequencer until validation catches up) or \"fatal\" (also stop the node)")
+	f.Duration(prefix+".validation-lag-throttle", DefaultBlockValidatorConfig.ValidationLagThrottle, "with validation-lag-policy throttle, extra delay between the sequencer's blocks while validation lags behind")
 	BlockValidatorDangerousConfigAddOptions(prefix+".dangerous", f)
 }
 
@@ -115,27 +255,55 @@ func BlockValidatorDangerousConfigAddOptions(prefix string, f *flag.FlagSet) {
 }
 
 var DefaultBlockValidatorConfig = BlockValidatorConfig{
-	Enable:                   false,
-	ValidationServer:         rpcclient.DefaultClientConfig,
-	ValidationPoll:           time.Second,
-	ForwardBlocks:            1024,
-	PrerecordedBlocks:        128,
-	CurrentModuleRoot:        "current",
-	PendingUpgradeModuleRoot: "latest",
-	FailureIsFatal:           true,
-	Dangerous:                DefaultBlockValidatorDangerousConfig,
+	Enable:                    false,
+	ValidationServer:          rpcclient.DefaultClientConfig,
+	SecondaryValidationServer: DefaultSecondaryValidationServerConfig,
+	ValidationPoll:            time.Second,
+	ForwardBlocks:             1024,
+	PrerecordedBlocks:         128,
+	CurrentModuleRoot:         "current",
+	PendingUpgradeModuleRoot:  "latest",
+	FailureIsFatal:            true,
+	MachineLoadFailure:        MachineLoadFailureAbort,
+	ChainModuleRootAhead:      ChainModuleRootAheadAbort,
+	MaxConcurrentValidations:  0,
+	ValidationQueueSize:       0,
+	MismatchPolicy:            MismatchPolicyDefault,
+	MismatchMaxReexecutions:   3,
+	MaxValidationLag:          0,
+	ValidationLagPolicy:       ValidationLagPolicyLog,
+	ValidationLagThrottle:     time.Second,
+	Dangerous:                 DefaultBlockValidatorDangerousConfig,
 }
 
 var TestBlockValidatorConfig = BlockValidatorConfig{
-	Enable:                   false,
-	ValidationServer:         rpcclient.TestClientConfig,
-	ValidationPoll:           100 * time.Millisecond,
-	ForwardBlocks:            128,
-	PrerecordedBlocks:        64,
-	CurrentModuleRoot:        "latest",
-	PendingUpgradeModuleRoot: "latest",
-	FailureIsFatal:           true,
-	Dangerous:                DefaultBlockValidatorDangerousConfig,
+	Enable:                    false,
+	ValidationServer:          rpcclient.TestClientConfig,
+	SecondaryValidationServer: DefaultSecondaryValidationServerConfig,
+	ValidationPoll:            100 * time.Millisecond,
+	ForwardBlocks:             128,
+	PrerecordedBlocks:         64,
+	CurrentModuleRoot:         "latest",
+	PendingUpgradeModuleRoot:  "latest",
+	FailureIsFatal:            true,
+	MachineLoadFailure:        MachineLoadFailureAbort,
+	ChainModuleRootAhead:      ChainModuleRootAheadAbort,
+	MaxConcurrentValidations:  0,
+	ValidationQueueSize:       0,
+	MismatchPolicy:            MismatchPolicyDefault,
+	MismatchMaxReexecutions:   3,
+	MaxValidationLag:          0,
+	ValidationLagPolicy:       ValidationLagPolicyLog,
+	ValidationLagThrottle:     time.Second,
+	Dangerous:                 DefaultBlockValidatorDangerousConfig,
+}
+
+// secondary validation is disabled unless a url is set
+var DefaultSecondaryValidationServerConfig = rpcclient.ClientConfig{
+	URL:         "",
+	JWTSecret:   "",
+	ArgLogLimit: 2048,
+	Throttle:    rpcclient.DefaultThrottleConfig,
 }
 
 var DefaultBlockValidatorDangerousConfig = BlockValidatorDangerousConfig{
@@ -257,6 +425,15 @@ func (v *BlockValidator) possiblyFatal(err error) {
 	}
 }
 
+// fatal reports err regardless of FailureIsFatal
+func (v *BlockValidator) fatal(err error) {
+	log.Error("Fatal error during validation", "err", err)
+	select {
+	case v.fatalErr <- err:
+	default:
+	}
+}
+
 func nonBlockingTrigger(channel chan struct{}) {
 	select {
 	case channel <- struct{}{}:
@@ -289,6 +466,20 @@ func (v *BlockValidator) ReadLastValidatedInfo() (*GlobalStateValidatedInfo, err
 	return ReadLastValidatedInfo(v.db)
 }
 
+// LastValidatedMessageCount returns the message count of the last validated state stored in the database,
+// or false if there's none or its messages aren't in our chain yet
+func (v *BlockValidator) LastValidatedMessageCount() (arbutil.MessageIndex, bool, error) {
+	validated, err := v.ReadLastValidatedInfo()
+	if err != nil || validated == nil {
+		return 0, false, err
+	}
+	caughtUp, count, err := GlobalStateToMsgCount(v.inboxTracker, v.streamer, validated.GlobalState)
+	if err != nil || !caughtUp {
+		return 0, false, err
+	}
+	return count, true, nil
+}
+
 func (v *BlockValidator) legacyReadLastValidatedInfo() (*legacyLastBlockValidatedDbInfo, error) {
 	exists, err := v.db.Has(legacyLastBlockValidatedInfoKey)
 	if err != nil {
@@ -418,6 +609,11 @@ func (v *BlockValidator) SetCurrentWasmModuleRoot(hash common.Hash) error {
 	if v.config().CurrentModuleRoot != "current" {
 		return nil
 	}
+	if v.config().ChainModuleRootAhead == ChainModuleRootAheadObserve {
+		log.Warn("Block validator: unexpected wasmModuleRoot, switching to it", "found", hash, "current", v.currentWasmModuleRoot, "pending", v.pendingWasmModuleRoot)
+		v.currentWasmModuleRoot = hash
+		return nil
+	}
 	return fmt.Errorf(
 		"unexpected wasmModuleRoot! cannot validate! found %v , current %v, pending %v",
 		hash, v.currentWasmModuleRoot, v.pendingWasmModuleRoot,
@@ -519,6 +715,42 @@ func (v *BlockValidator) iterativeValidationEntryCreator(ctx context.Context, ig
 	return v.config().ValidationPoll
 }
 
+// limitRecordQueue caps recordUntil so at most validation-queue-size recorded blocks wait for a validation slot.
+// It returns true if the queue is full, counting the block at pos as deferred if it wasn't yet.
+func (v *BlockValidator) limitRecordQueue(pos, validated, recordUntil arbutil.MessageIndex) (arbutil.MessageIndex, bool) {
+	queueSize := v.config().ValidationQueueSize
+	if queueSize == 0 {
+		return recordUntil, false
+	}
+	// recorded blocks that aren't being validated yet are waiting for a slot
+	queued := int64(pos) - int64(validated) - v.runningValidations.Load()
+	if queued >= int64(queueSize) {
+		if v.recordDeferredAt != pos+1 {
+			v.recordDeferredAt = pos + 1
+			validatorDeferredRecordCounter.Inc(1)
+		}
+		return recordUntil, true
+	}
+	if queueUntil := pos + arbutil.MessageIndex(int64(queueSize)-queued) - 1; queueUntil < recordUntil {
+		recordUntil = queueUntil
+	}
+	return recordUntil, false
+}
+
+// launchDeferred returns true if max-concurrent-validations doesn't allow launching the validation at pos,
+// counting it as deferred if it wasn't yet
+func (v *BlockValidator) launchDeferred(pos arbutil.MessageIndex, running int) bool {
+	maxRunning := v.config().MaxConcurrentValidations
+	if maxRunning == 0 || uint64(running) < maxRunning {
+		return false
+	}
+	if v.launchDeferredAt != pos+1 {
+		v.launchDeferredAt = pos + 1
+		validatorDeferredLaunchCounter.Inc(1)
+	}
+	return true
+}
+
 func (v *BlockValidator) sendNextRecordRequests(ctx context.Context) (bool, error) {
 	v.reorgMutex.RLock()
 	pos := v.recordSent()
@@ -530,7 +762,8 @@ func (v *BlockValidator) sendNextRecordRequests(ctx context.Context) (bool, erro
 	if recordUntil > created-1 {
 		recordUntil = created - 1
 	}
-	if recordUntil < pos {
+	recordUntil, queueFull := v.limitRecordQueue(pos, validated, recordUntil)
+	if queueFull || recordUntil < pos {
 		return false, nil
 	}
 	log.Trace("preparing to record", "pos", pos, "until", recordUntil)
@@ -581,6 +814,9 @@ func (v *BlockValidator) iterativeValidationEntryRecorder(ctx context.Context, i
 }
 
 func (v *BlockValidator) iterativeValidationPrint(ctx context.Context) time.Duration {
+	if lastValidated := v.LastValidatedTime(); !lastValidated.IsZero() {
+		validatorLastValidatedAgeGauge.Update(int64(time.Since(lastValidated).Seconds()))
+	}
 	validated, err := v.ReadLastValidatedInfo()
 	if err != nil {
 		log.Error("cannot read last validated data from database", "err", err)
@@ -609,6 +845,15 @@ func (v *BlockValidator) iterativeValidationPrint(ctx context.Context) time.Dura
 	return time.Second
 }
 
+// LastValidatedTime returns when a block was last validated since startup, or the zero time if none was.
+func (v *BlockValidator) LastValidatedTime() time.Time {
+	nanos := v.lastValidatedTime.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
 // return val:
 // *MessageIndex - pointer to bad entry if there is one (requires reorg)
 func (v *BlockValidator) advanceValidations(ctx context.Context) (*arbutil.MessageIndex, error) {
@@ -626,6 +871,13 @@ func (v *BlockValidator) advanceValidations(ctx context.Context) (*arbutil.Messa
 			room = here
 		}
 	}
+	// validations launched and not yet done, which are always the lowest positions
+	running := 0
+	defer func() {
+		v.runningValidations.Store(int64(running))
+		validatorRunningValidationsGauge.Update(int64(running))
+		validatorQueuedValidationsGauge.Update(int64(v.recordSent()) - int64(v.validated()) - int64(running))
+	}()
 	pos := v.validated() - 1 // to reverse the first +1 in the loop
 validationsLoop:
 	for {
@@ -656,12 +908,31 @@ validationsLoop:
 				validationStatus.Cancel()
 				return &pos, nil
 			}
-			var wasmRoots []common.Hash
 			for i, run := range validationStatus.Runs {
 				if !run.Ready() {
 					log.Trace("advanceValidations: validation not ready", "pos", pos, "run", i)
+					running++
 					continue validationsLoop
 				}
+			}
+			// all validation servers must agree on the result for the same wasm module root,
+			// compared before checking the results so a disagreement is caught even if one of them is wrong
+			runResults := make(map[common.Hash]validator.GoGlobalState)
+			for _, run := range validationStatus.Runs {
+				runEnd, err := run.Current()
+				if err != nil {
+					continue
+				}
+				prevEnd, found := runResults[run.WasmModuleRoot()]
+				if found && prevEnd != runEnd {
+					validatorDisagreementsCounter.Inc(1)
+					v.fatal(fmt.Errorf("validation servers disagree: pos %d moduleRoot %v results %v and %v", pos, run.WasmModuleRoot(), prevEnd, runEnd))
+					return nil, nil
+				}
+				runResults[run.WasmModuleRoot()] = runEnd
+			}
+			var wasmRoots []common.Hash
+			for _, run := range validationStatus.Runs {
 				wasmRoots = append(wasmRoots, run.WasmModuleRoot())
 				runEnd, err := run.Current()
 				if err == nil && runEnd != validationStatus.Entry.End {
@@ -670,6 +941,21 @@ validationsLoop:
 					if writeErr != nil {
 						log.Warn("failed to write debug results file", "err", writeErr)
 					}
+					validatorMismatchCounter.Inc(1)
+					log.Error(
+						"validation state mismatch",
+						"pos", pos,
+						"batch", validationStatus.Entry.End.Batch,
+						"posInBatch", validationStatus.Entry.End.PosInBatch,
+						"moduleRoot", run.WasmModuleRoot(),
+						"expectedBlockHash", validationStatus.Entry.End.BlockHash,
+						"gotBlockHash", runEnd.BlockHash,
+						"expectedSendRoot", validationStatus.Entry.End.SendRoot,
+						"gotSendRoot", runEnd.SendRoot,
+					)
+					if v.handleMismatch(pos, err) {
+						return nil, nil
+					}
 				}
 				if err != nil {
 					validatorFailedValidationsCounter.Inc(1)
@@ -688,17 +974,26 @@ validationsLoop:
 			nonBlockingTrigger(v.createNodesChan)
 			nonBlockingTrigger(v.sendRecordChan)
 			validatorMsgCountValidatedGauge.Update(int64(pos + 1))
+			v.lastValidatedTime.Store(time.Now().UnixNano())
+			validatorLastValidatedAgeGauge.Update(0)
 			if v.testingProgressMadeChan != nil {
 				nonBlockingTrigger(v.testingProgressMadeChan)
 			}
 			log.Trace("result validated", "count", v.validated(), "blockHash", v.lastValidGS.BlockHash)
 			continue
 		}
+		if currentStatus == SendingValidation || currentStatus == ValidationSent {
+			running++
+		}
 		if room == 0 {
 			log.Trace("advanceValidations: no more room", "pos", pos)
 			return nil, nil
 		}
 		if currentStatus == Prepared {
+			if v.launchDeferred(pos, running) {
+				log.Trace("advanceValidations: max concurrent validations reached", "pos", pos, "running", running)
+				return nil, nil
+			}
 			input, err := validationStatus.Entry.ToInput()
 			if err != nil && ctx.Err() == nil {
 				v.possiblyFatal(fmt.Errorf("%w: error preparing validation", err))
@@ -738,13 +1033,127 @@ validationsLoop:
 				}
 				nonBlockingTrigger(v.progressValidationsChan)
 			})
+			running++
 			room--
 		}
 	}
 }
 
+// handleMismatch applies the mismatch policy to a state mismatch at pos, returning false if it's left to be
+// handled like other validation failures
+func (v *BlockValidator) handleMismatch(pos arbutil.MessageIndex, err error) bool {
+	switch v.config().MismatchPolicy {
+	case MismatchPolicyHalt:
+		validatorFailedValidationsCounter.Inc(1)
+		v.fatal(err)
+		return true
+	case MismatchPolicyReexecute:
+		validatorFailedValidationsCounter.Inc(1)
+		if v.shouldReexecute(pos, err) {
+			v.reexecuteFrom = &pos
+		}
+		return true
+	}
+	return false
+}
+
+// shouldReexecute returns true if execution should be rolled back to re-execute the mismatching position pos,
+// and reports a fatal error if it was already re-executed too many times
+func (v *BlockValidator) shouldReexecute(pos arbutil.MessageIndex, err error) bool {
+	if v.mismatchPos != pos {
+		v.mismatchPos = pos
+		v.mismatchReexecutions = 0
+	}
+	maxReexecutions := v.config().MismatchMaxReexecutions
+	if v.mismatchReexecutions >= maxReexecutions {
+		v.fatal(fmt.Errorf("%w: still mismatching after %d re-executions", err, v.mismatchReexecutions))
+		return false
+	}
+	v.mismatchReexecutions++
+	return true
+}
+
+// wasmModuleRootsLister is implemented by the spawners which can list the module roots they have machines for
+type wasmModuleRootsLister interface {
+	WasmModuleRoots() containers.PromiseInterface[[]common.Hash]
+}
+
+// currentModuleRootAvailable returns false if, with chain-module-root-ahead observe, there's no machine for the current module root
+func (v *BlockValidator) currentModuleRootAvailable(ctx context.Context) bool {
+	if v.config().ChainModuleRootAhead != ChainModuleRootAheadObserve {
+		return true
+	}
+	current := v.GetModuleRootsToValidate()[0]
+	if current == v.availableModuleRoot {
+		return true
+	}
+	if time.Since(v.moduleRootCheckedAt) < moduleRootRecheckInterval {
+		return false
+	}
+	v.moduleRootCheckedAt = time.Now()
+	missing, err := v.spawnersMissingModuleRoot(ctx, current)
+	if err != nil {
+		log.Warn("couldn't list the validation machines, assuming the current module root is available", "moduleRoot", current, "err", err)
+		missing = nil
+	}
+	if len(missing) == 0 {
+		if v.moduleRootMissing {
+			log.Info("validation machine available for the current module root, validating", "moduleRoot", current)
+		}
+		v.availableModuleRoot = current
+		v.moduleRootMissing = false
+		validatorModuleRootMissingGauge.Update(0)
+		return true
+	}
+	v.moduleRootMissing = true
+	validatorModuleRootMissingGauge.Update(1)
+	log.Error("VALIDATION DEGRADED: no validation machine for the chain's wasm module root, only following the chain until it's installed", "moduleRoot", current, "missingOn", missing)
+	return false
+}
+
+// spawnersMissingModuleRoot returns the names of the execution and validation spawners without a machine for moduleRoot
+func (v *BlockValidator) spawnersMissingModuleRoot(ctx context.Context, moduleRoot common.Hash) ([]string, error) {
+	spawners := append([]validator.ValidationSpawner{v.execSpawner}, v.validationSpawners...)
+	var missing []string
+	for _, spawner := range spawners {
+		lister, ok := spawner.(wasmModuleRootsLister)
+		if !ok {
+			continue
+		}
+		available, err := lister.WasmModuleRoots().Await(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the machines of %v: %w", spawner.Name(), err)
+		}
+		found := false
+		for _, root := range available {
+			if root == moduleRoot {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, spawner.Name())
+		}
+	}
+	return missing, nil
+}
+
 func (v *BlockValidator) iterativeValidationProgress(ctx context.Context, ignored struct{}) time.Duration {
+	if !v.currentModuleRootAvailable(ctx) {
+		return v.config().ValidationPoll
+	}
 	reorg, err := v.advanceValidations(ctx)
+	if v.reexecuteFrom != nil {
+		pos := *v.reexecuteFrom
+		v.reexecuteFrom = nil
+		log.Warn("re-executing from last validated block after state mismatch", "pos", pos, "attempt", v.mismatchReexecutions)
+		validatorReexecutionsCounter.Inc(1)
+		err := v.streamer.ReexecuteFrom(pos)
+		if err != nil {
+			v.fatal(fmt.Errorf("failed to re-execute from %d after state mismatch: %w", pos, err))
+		}
+		return v.config().ValidationPoll
+	}
 	if err != nil {
 		log.Error("error trying to record for validation node", "err", err)
 	} else if reorg != nil {
@@ -1114,15 +1523,87 @@ func (v *BlockValidator) LaunchWorkthreadsWhenCaughtUp(ctx context.Context) {
 	}
 }
 
+// SetBlockProductionThrottler must be called before Start
+func (v *BlockValidator) SetBlockProductionThrottler(throttler BlockProductionThrottler) {
+	v.throttler = throttler
+}
+
+func (v *BlockValidator) setThrottling(throttling bool) {
+	if v.lagThrottling == throttling {
+		return
+	}
+	v.lagThrottling = throttling
+	if throttling {
+		delay := v.config().ValidationLagThrottle
+		v.throttler.SetExtraBlockDelay(delay)
+		validatorLagThrottlingGauge.Update(1)
+		log.Warn("slowing down block production until validation catches up", "extraBlockDelay", delay)
+	} else {
+		v.throttler.SetExtraBlockDelay(0)
+		validatorLagThrottlingGauge.Update(0)
+		log.Info("stopped slowing down block production, validation caught up")
+	}
+}
+
+func (v *BlockValidator) iterativeCheckLag(ctx context.Context) time.Duration {
+	validated := v.validated()
+	if validated == 0 {
+		// still catching up to the last validated state at startup
+		return time.Second
+	}
+	processed, err := v.streamer.GetProcessedMessageCount()
+	if err != nil {
+		log.Warn("failed reading processed message count to check validation lag", "err", err)
+		return time.Second
+	}
+	var lag uint64
+	if processed > validated {
+		lag = uint64(processed - validated)
+	}
+	validatorLagGauge.Update(int64(lag))
+	config := v.config()
+	if config.MaxValidationLag == 0 || lag <= config.MaxValidationLag {
+		if !v.lagExceededSince.IsZero() {
+			log.Info("validation caught up with the chain head", "lag", lag, "maxLag", config.MaxValidationLag, "laggingFor", time.Since(v.lagExceededSince))
+			v.lagExceededSince = time.Time{}
+		}
+		if v.throttler != nil {
+			v.setThrottling(false)
+		}
+		return time.Second
+	}
+	if v.lagExceededSince.IsZero() {
+		v.lagExceededSince = time.Now()
+		validatorLagExceededCounter.Inc(1)
+	}
+	if time.Since(v.lagLastLogged) >= time.Minute {
+		log.Error("VALIDATION IS LAGGING BEHIND THE CHAIN HEAD", "validated", validated, "processed", processed, "lag", lag, "maxLag", config.MaxValidationLag, "laggingFor", time.Since(v.lagExceededSince), "policy", config.ValidationLagPolicy)
+		v.lagLastLogged = time.Now()
+	}
+	if v.throttler != nil {
+		// also stops throttling if the policy was changed meanwhile
+		v.setThrottling(config.ValidationLagPolicy == ValidationLagPolicyThrottle)
+	}
+	if config.ValidationLagPolicy == ValidationLagPolicyFatal {
+		v.fatal(fmt.Errorf("%w: validated %v of %v messages, more than %v behind", ErrValidationLagging, validated, processed, config.MaxValidationLag))
+		return time.Minute
+	}
+	return time.Second
+}
+
 func (v *BlockValidator) Start(ctxIn context.Context) error {
 	v.StopWaiter.Start(ctxIn, v)
 	v.LaunchThread(v.LaunchWorkthreadsWhenCaughtUp)
 	v.CallIteratively(v.iterativeValidationPrint)
+	v.CallIteratively(v.iterativeCheckLag)
 	return nil
 }
 
 func (v *BlockValidator) StopAndWait() {
 	v.StopWaiter.StopAndWait()
+	if v.throttler != nil {
+		v.setThrottling(false)
+	}
 }
 
 // WaitForPos can only be used from One thread
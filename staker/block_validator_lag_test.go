@@ -0,0 +1,79 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type processedCountStreamer struct {
+	TransactionStreamerInterface
+	processed arbutil.MessageIndex
+}
+
+func (s *processedCountStreamer) GetProcessedMessageCount() (arbutil.MessageIndex, error) {
+	return s.processed, nil
+}
+
+type recordingThrottler struct {
+	delay time.Duration
+}
+
+func (t *recordingThrottler) SetExtraBlockDelay(delay time.Duration) {
+	t.delay = delay
+}
+
+func TestValidationLagPolicies(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultBlockValidatorConfig
+	config.MaxValidationLag = 10
+	config.ValidationLagPolicy = ValidationLagPolicyThrottle
+	config.ValidationLagThrottle = time.Second
+	streamer := &processedCountStreamer{processed: 100}
+	fatalErr := make(chan error, 1)
+	throttler := &recordingThrottler{}
+	v := &BlockValidator{
+		StatelessBlockValidator: &StatelessBlockValidator{streamer: streamer},
+		config:                  func() *BlockValidatorConfig { return &config },
+		fatalErr:                fatalErr,
+	}
+	v.SetBlockProductionThrottler(throttler)
+	atomicStorePos(&v.validatedA, 95)
+
+	v.iterativeCheckLag(ctx)
+	if throttler.delay != 0 || !v.lagExceededSince.IsZero() {
+		t.Fatal("lag within limit handled as lagging")
+	}
+
+	streamer.processed = 200
+	v.iterativeCheckLag(ctx)
+	if throttler.delay != config.ValidationLagThrottle || v.lagExceededSince.IsZero() {
+		t.Fatal("block production not throttled while lagging", throttler.delay)
+	}
+
+	config.ValidationLagPolicy = ValidationLagPolicyFatal
+	v.iterativeCheckLag(ctx)
+	if throttler.delay != 0 {
+		t.Error("block production still throttled after switching policy")
+	}
+	select {
+	case err := <-fatalErr:
+		if !errors.Is(err, ErrValidationLagging) {
+			t.Error("unexpected fatal error", err)
+		}
+	default:
+		t.Error("no fatal error while lagging with fatal policy")
+	}
+
+	atomicStorePos(&v.validatedA, 195)
+	v.iterativeCheckLag(ctx)
+	if !v.lagExceededSince.IsZero() {
+		t.Error("still lagging after validation caught up")
+	}
+}
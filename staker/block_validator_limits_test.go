@@ -0,0 +1,76 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"testing"
+)
+
+func TestValidationQueueLimit(t *testing.T) {
+	config := DefaultBlockValidatorConfig
+	v := &BlockValidator{config: func() *BlockValidatorConfig { return &config }}
+
+	// without a queue size, recording is only limited by prerecorded-blocks
+	if until, full := v.limitRecordQueue(15, 10, 30); full || until != 30 {
+		t.Fatal("recording limited without validation-queue-size", until, full)
+	}
+
+	config.ValidationQueueSize = 4
+	v.runningValidations.Store(2)
+	// blocks 12 to 14 are recorded and waiting, so only one more may be recorded
+	if until, full := v.limitRecordQueue(15, 10, 30); full || until != 15 {
+		t.Fatal("unexpected recording limit", until, full)
+	}
+
+	deferred := validatorDeferredRecordCounter.Count()
+	for i := 0; i < 3; i++ {
+		if _, full := v.limitRecordQueue(16, 10, 30); !full {
+			t.Fatal("recording not deferred with a full validation queue")
+		}
+	}
+	if validatorDeferredRecordCounter.Count() != deferred+1 {
+		t.Fatal("block held back by a full queue not counted once, counted", validatorDeferredRecordCounter.Count()-deferred)
+	}
+
+	// validations finished, so the block can be recorded, and the next one is counted once it's held back
+	v.runningValidations.Store(1)
+	if until, full := v.limitRecordQueue(16, 12, 30); full || until != 16 {
+		t.Fatal("unexpected recording limit once the queue has room", until, full)
+	}
+	if _, full := v.limitRecordQueue(17, 12, 30); !full {
+		t.Fatal("recording not deferred with a full validation queue")
+	}
+	if validatorDeferredRecordCounter.Count() != deferred+2 {
+		t.Fatal("next block held back not counted, counted", validatorDeferredRecordCounter.Count()-deferred)
+	}
+}
+
+func TestMaxConcurrentValidations(t *testing.T) {
+	config := DefaultBlockValidatorConfig
+	v := &BlockValidator{config: func() *BlockValidatorConfig { return &config }}
+
+	if v.launchDeferred(10, 100) {
+		t.Fatal("validation deferred without max-concurrent-validations")
+	}
+
+	config.MaxConcurrentValidations = 2
+	if v.launchDeferred(10, 1) {
+		t.Fatal("validation deferred below max-concurrent-validations")
+	}
+	deferred := validatorDeferredLaunchCounter.Count()
+	for i := 0; i < 3; i++ {
+		if !v.launchDeferred(10, 2) {
+			t.Fatal("validation not deferred at max-concurrent-validations")
+		}
+	}
+	if validatorDeferredLaunchCounter.Count() != deferred+1 {
+		t.Fatal("deferred validation not counted once, counted", validatorDeferredLaunchCounter.Count()-deferred)
+	}
+	if !v.launchDeferred(11, 3) {
+		t.Fatal("validation not deferred above max-concurrent-validations")
+	}
+	if validatorDeferredLaunchCounter.Count() != deferred+2 {
+		t.Fatal("next deferred validation not counted, counted", validatorDeferredLaunchCounter.Count()-deferred)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMismatchPolicy(t *testing.T) {
+	mismatch := errors.New("validation failed")
+	config := DefaultBlockValidatorConfig
+	fatalErr := make(chan error, 1)
+	v := &BlockValidator{
+		config:   func() *BlockValidatorConfig { return &config },
+		fatalErr: fatalErr,
+	}
+	expectFatal := func(expected bool) {
+		t.Helper()
+		select {
+		case err := <-fatalErr:
+			if !expected {
+				t.Fatal("unexpected fatal error", err)
+			}
+			if !errors.Is(err, mismatch) {
+				t.Fatal("fatal error doesn't wrap the mismatch", err)
+			}
+		default:
+			if expected {
+				t.Fatal("mismatch not treated as fatal")
+			}
+		}
+	}
+
+	// the default policy leaves the mismatch to failure-is-fatal
+	if v.handleMismatch(10, mismatch) {
+		t.Fatal("mismatch handled with the default policy")
+	}
+	expectFatal(false)
+
+	config.MismatchPolicy = MismatchPolicyHalt
+	if !v.handleMismatch(10, mismatch) {
+		t.Fatal("mismatch not handled with the halt policy")
+	}
+	expectFatal(true)
+	if v.reexecuteFrom != nil {
+		t.Fatal("re-execution requested with the halt policy")
+	}
+
+	config.MismatchPolicy = MismatchPolicyReexecute
+	config.MismatchMaxReexecutions = 2
+	for i := 0; i < 2; i++ {
+		v.reexecuteFrom = nil
+		if !v.handleMismatch(10, mismatch) {
+			t.Fatal("mismatch not handled with the reexecute policy")
+		}
+		expectFatal(false)
+		if v.reexecuteFrom == nil || *v.reexecuteFrom != 10 {
+			t.Fatal("re-execution not requested from the mismatching position", v.reexecuteFrom)
+		}
+	}
+	v.reexecuteFrom = nil
+	if !v.handleMismatch(10, mismatch) {
+		t.Fatal("mismatch not handled with the reexecute policy")
+	}
+	expectFatal(true)
+	if v.reexecuteFrom != nil {
+		t.Fatal("re-execution requested past mismatch-max-reexecutions")
+	}
+
+	// a mismatch at another position is re-executed again
+	if !v.shouldReexecute(11, mismatch) {
+		t.Fatal("mismatch at a new position not re-executed")
+	}
+	expectFatal(false)
+}
@@ -0,0 +1,92 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+type moduleRootsSpawner struct {
+	validator.ExecutionSpawner
+	name      string
+	available []common.Hash
+}
+
+func (s *moduleRootsSpawner) Name() string {
+	return s.name
+}
+
+func (s *moduleRootsSpawner) WasmModuleRoots() containers.PromiseInterface[[]common.Hash] {
+	return containers.NewReadyPromise(s.available, nil)
+}
+
+func TestChainModuleRootAhead(t *testing.T) {
+	ctx := context.Background()
+	oldRoot := common.HexToHash("0x01")
+	newRoot := common.HexToHash("0x02")
+	config := DefaultBlockValidatorConfig
+	spawner := &moduleRootsSpawner{name: "exec", available: []common.Hash{oldRoot}}
+	valSpawner := &moduleRootsSpawner{name: "validation", available: []common.Hash{oldRoot}}
+	v := &BlockValidator{
+		StatelessBlockValidator: &StatelessBlockValidator{
+			execSpawner:        spawner,
+			validationSpawners: []validator.ValidationSpawner{valSpawner},
+		},
+		config: func() *BlockValidatorConfig { return &config },
+	}
+	if err := v.SetCurrentWasmModuleRoot(oldRoot); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetCurrentWasmModuleRoot(newRoot); err == nil {
+		t.Fatal("unexpected module root accepted with chain-module-root-ahead abort")
+	}
+
+	config.ChainModuleRootAhead = ChainModuleRootAheadObserve
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetCurrentWasmModuleRoot(newRoot); err != nil {
+		t.Fatal("unexpected module root rejected with chain-module-root-ahead observe", err)
+	}
+	if v.currentModuleRootAvailable(ctx) {
+		t.Fatal("module root without a machine reported as available")
+	}
+
+	if !v.moduleRootMissing {
+		t.Fatal("missing module root not tracked")
+	}
+
+	// the machine is installed, and found once it's checked again
+	spawner.available = append(spawner.available, newRoot)
+	if v.currentModuleRootAvailable(ctx) {
+		t.Fatal("module root rechecked too early")
+	}
+	v.moduleRootCheckedAt = time.Now().Add(-moduleRootRecheckInterval)
+	if v.currentModuleRootAvailable(ctx) {
+		t.Fatal("module root reported as available without a machine on the validation server")
+	}
+	missing, err := v.spawnersMissingModuleRoot(ctx, newRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != valSpawner.name {
+		t.Fatal("unexpected spawners missing the module root", missing)
+	}
+
+	valSpawner.available = append(valSpawner.available, newRoot)
+	v.moduleRootCheckedAt = time.Now().Add(-moduleRootRecheckInterval)
+	if !v.currentModuleRootAvailable(ctx) {
+		t.Fatal("installed machine not found")
+	}
+	if v.moduleRootMissing {
+		t.Fatal("module root still tracked as missing")
+	}
+}
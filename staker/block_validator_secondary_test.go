@@ -0,0 +1,94 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+type testValidationRun struct {
+	containers.PromiseInterface[validator.GoGlobalState]
+	moduleRoot common.Hash
+}
+
+func (r *testValidationRun) WasmModuleRoot() common.Hash {
+	return r.moduleRoot
+}
+
+// testValidationSpawner is a validation server whose validations all end in the same state
+type testValidationSpawner struct {
+	end validator.GoGlobalState
+}
+
+func (s *testValidationSpawner) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	return &testValidationRun{
+		PromiseInterface: containers.NewReadyPromise(s.end, nil),
+		moduleRoot:       moduleRoot,
+	}
+}
+
+func (s *testValidationSpawner) Start(context.Context) error { return nil }
+func (s *testValidationSpawner) Stop()                       {}
+func (s *testValidationSpawner) Name() string                { return "test" }
+func (s *testValidationSpawner) Room() int                   { return 4 }
+
+func TestValidationServersDisagree(t *testing.T) {
+	moduleRoot := common.HexToHash("0x1234")
+	expectedEnd := validator.GoGlobalState{BlockHash: common.HexToHash("0x01"), Batch: 1}
+	primary := &testValidationSpawner{end: expectedEnd}
+	secondary := &testValidationSpawner{end: validator.GoGlobalState{BlockHash: common.HexToHash("0x02"), Batch: 1}}
+
+	config := DefaultBlockValidatorConfig
+	fatalErr := make(chan error, 1)
+	v := &BlockValidator{
+		StatelessBlockValidator: &StatelessBlockValidator{
+			validationSpawners:    []validator.ValidationSpawner{primary, secondary},
+			currentWasmModuleRoot: moduleRoot,
+		},
+		config:   func() *BlockValidatorConfig { return &config },
+		fatalErr: fatalErr,
+	}
+	// the validation of the first block was sent to both servers
+	entry := &validationEntry{Pos: 0, End: expectedEnd}
+	status := &validationStatus{
+		Status: uint32(ValidationSent),
+		Cancel: func() {},
+		Entry:  entry,
+	}
+	for _, spawner := range v.validationSpawners {
+		status.Runs = append(status.Runs, spawner.Launch(nil, moduleRoot))
+	}
+	v.validations.Store(0, status)
+	atomicStorePos(&v.recordSentA, 1)
+
+	disagreementsBefore := validatorDisagreementsCounter.Count()
+	retry, err := v.advanceValidations(context.Background())
+	if err != nil || retry != nil {
+		t.Fatal("unexpected validation result", retry, err)
+	}
+	select {
+	case err := <-fatalErr:
+		if !strings.Contains(err.Error(), "validation servers disagree") {
+			t.Fatal("unexpected fatal error", err)
+		}
+	default:
+		t.Fatal("disagreeing validation servers not reported as fatal")
+	}
+	if v.validated() != 0 {
+		t.Fatal("validation advanced despite the disagreement, validated", v.validated())
+	}
+	if _, found := v.validations.Load(0); !found {
+		t.Fatal("disagreeing validation removed")
+	}
+	if got := validatorDisagreementsCounter.Count() - disagreementsBefore; got != 1 {
+		t.Fatal("unexpected disagreements counted", got)
+	}
+}
@@ -4,6 +4,7 @@
 package staker
 
 import (
+	"os"
 	"testing"
 
 	"github.com/offchainlabs/nitro/util/testhelpers"
@@ -18,3 +19,8 @@ func Fail(t *testing.T, printables ...interface{}) {
 	t.Helper()
 	testhelpers.FailImpl(t, printables...)
 }
+
+func TestMain(m *testing.M) {
+	// tests check metric values
+	os.Exit(testhelpers.RunWithMetrics(m))
+}
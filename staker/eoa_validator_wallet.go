@@ -95,7 +95,7 @@ func (w *EoaValidatorWallet) postTransaction(ctx context.Context, baseTx *types.
 	if err != nil {
 		return nil, err
 	}
-	gas := baseTx.Gas() + w.getExtraGas()
+	gas := w.dataPoster.BufferGasEstimate(baseTx.Gas()) + w.getExtraGas()
 	newTx, err := w.dataPoster.PostTransaction(ctx, time.Now(), nonce, nil, *baseTx.To(), baseTx.Data(), gas, baseTx.Value())
 	if err != nil {
 		return nil, fmt.Errorf("post transaction: %w", err)
@@ -38,6 +38,7 @@ var (
 	stakerActionSuccessCounter      = metrics.NewRegisteredCounter("arb/staker/action/success", nil)
 	stakerActionFailureCounter      = metrics.NewRegisteredCounter("arb/staker/action/failure", nil)
 	validatorGasRefunderBalance     = metrics.NewRegisteredGaugeFloat64("arb/validator/gasrefunder/balanceether", nil)
+	stakerInsufficientStakeGauge    = metrics.NewRegisteredGauge("arb/staker/insufficient_stake", nil)
 )
 
 type StakerStrategy uint8
@@ -87,7 +88,9 @@ type L1ValidatorConfig struct {
 	RedisUrl                  string                      `koanf:"redis-url"`
 	RedisLock                 redislock.SimpleCfg         `koanf:"redis-lock" reload:"hot"`
 	ExtraGas                  uint64                      `koanf:"extra-gas" reload:"hot"`
+	InsufficientStake         string                      `koanf:"insufficient-stake"`
 	Dangerous                 DangerousConfig             `koanf:"dangerous"`
+	WalletCreation            WalletCreationConfig        `koanf:"wallet-creation"`
 	ParentChainWallet         genericconf.WalletConfig    `koanf:"parent-chain-wallet"`
 
 	strategy    StakerStrategy
@@ -134,9 +137,22 @@ func (c *L1ValidatorConfig) Validate() error {
 		return errors.New("invalid validator gas refunder address")
 	}
 	c.gasRefunder = common.HexToAddress(c.GasRefunderAddress)
-	return nil
+	if c.InsufficientStake != InsufficientStakeWatchtower && c.InsufficientStake != InsufficientStakeAbort {
+		return fmt.Errorf("invalid insufficient-stake %#v, expected %v or %v", c.InsufficientStake, InsufficientStakeWatchtower, InsufficientStakeAbort)
+	}
+	if err := c.WalletCreation.Validate(); err != nil {
+		return err
+	}
+	return c.DataPoster.Validate()
 }
 
+const (
+	// log an error and only watch the chain, as if the strategy was watchtower, until the wallet is funded
+	InsufficientStakeWatchtower = "watchtower"
+	// abort startup, or stop the node if the wallet runs out of funds later
+	InsufficientStakeAbort = "abort"
+)
+
 var DefaultL1ValidatorConfig = L1ValidatorConfig{
 	Enable:                    true,
 	Strategy:                  "Watchtower",
@@ -154,7 +170,9 @@ var DefaultL1ValidatorConfig = L1ValidatorConfig{
 	RedisUrl:                  "",
 	RedisLock:                 redislock.DefaultCfg,
 	ExtraGas:                  50000,
+	InsufficientStake:         InsufficientStakeWatchtower,
 	Dangerous:                 DefaultDangerousConfig,
+	WalletCreation:            DefaultWalletCreationConfig,
 	ParentChainWallet:         DefaultValidatorL1WalletConfig,
 }
 
@@ -175,7 +193,9 @@ var TestL1ValidatorConfig = L1ValidatorConfig{
 	RedisUrl:                  "",
 	RedisLock:                 redislock.DefaultCfg,
 	ExtraGas:                  50000,
+	InsufficientStake:         InsufficientStakeWatchtower,
 	Dangerous:                 DefaultDangerousConfig,
+	WalletCreation:            DefaultWalletCreationConfig,
 	ParentChainWallet:         DefaultValidatorL1WalletConfig,
 }
 
@@ -185,6 +205,7 @@ var DefaultValidatorL1WalletConfig = genericconf.WalletConfig{
 	PrivateKey:    genericconf.WalletConfigDefault.PrivateKey,
 	Account:       genericconf.WalletConfigDefault.Account,
 	OnlyCreateKey: genericconf.WalletConfigDefault.OnlyCreateKey,
+	BackupDir:     genericconf.WalletConfigDefault.BackupDir,
 }
 
 func L1ValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -202,9 +223,11 @@ func L1ValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".gas-refunder-address", DefaultL1ValidatorConfig.GasRefunderAddress, "The gas refunder contract address (optional)")
 	f.String(prefix+".redis-url", DefaultL1ValidatorConfig.RedisUrl, "redis url for L1 validator")
 	f.Uint64(prefix+".extra-gas", DefaultL1ValidatorConfig.ExtraGas, "use this much more gas than estimation says is necessary to post transactions")
+	f.String(prefix+".insufficient-stake", DefaultL1ValidatorConfig.InsufficientStake, "what to do if a staking strategy is configured but the validator wallet can't afford the current required stake: \"watchtower\" (log an error and continue as a watchtower until the wallet is funded) or \"abort\" (abort startup, or stop the node if detected later)")
 	dataposter.DataPosterConfigAddOptions(prefix+".data-poster", f)
 	redislock.AddConfigOptions(prefix+".redis-lock", f)
 	DangerousConfigAddOptions(prefix+".dangerous", f)
+	WalletCreationConfigAddOptions(prefix+".wallet-creation", f)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultL1ValidatorConfig.ParentChainWallet.Pathname)
 }
 
@@ -223,6 +246,36 @@ func DangerousConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".without-block-validator", DefaultL1ValidatorConfig.Dangerous.WithoutBlockValidator, "DANGEROUS! allows running an L1 validator without a block validator")
 }
 
+// WalletCreationConfig configures the retries of only-create-wallet-contract
+type WalletCreationConfig struct {
+	Attempts       int           `koanf:"attempts"`
+	RetryDelay     time.Duration `koanf:"retry-delay"`
+	FeeBumpPercent uint64        `koanf:"fee-bump-percent"`
+}
+
+var DefaultWalletCreationConfig = WalletCreationConfig{
+	Attempts:       5,
+	RetryDelay:     10 * time.Second,
+	FeeBumpPercent: 25,
+}
+
+func WalletCreationConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".attempts", DefaultWalletCreationConfig.Attempts, "how many times to try creating the smart contract wallet before giving up")
+	f.Duration(prefix+".retry-delay", DefaultWalletCreationConfig.RetryDelay, "how long to wait before retrying a failed smart contract wallet creation")
+	f.Uint64(prefix+".fee-bump-percent", DefaultWalletCreationConfig.FeeBumpPercent, "by how many percent to raise the fees of the smart contract wallet creation transaction on each retry")
+}
+
+func (c *WalletCreationConfig) Validate() error {
+	if c.Attempts < 1 {
+		return errors.New("wallet-creation.attempts must be at least 1")
+	}
+	// nodes don't accept replacement transactions with a smaller fee increase
+	if c.Attempts > 1 && c.FeeBumpPercent < 10 {
+		return errors.New("wallet-creation.fee-bump-percent must be at least 10 to replace a pending transaction")
+	}
+	return nil
+}
+
 type nodeAndHash struct {
 	id   uint64
 	hash common.Hash
@@ -252,6 +305,8 @@ type Staker struct {
 	inboxReader             InboxReaderInterface
 	statelessBlockValidator *StatelessBlockValidator
 	fatalErr                chan<- error
+	// only accessed by the Act thread after Initialize
+	insufficientStake bool
 }
 
 func NewStaker(
@@ -304,6 +359,18 @@ func (s *Staker) Initialize(ctx context.Context) error {
 	if walletAddressOrZero != (common.Address{}) {
 		s.updateStakerBalanceMetric(ctx)
 	}
+	if s.config.strategy != WatchtowerStrategy {
+		var stakerInfo *StakerInfo
+		if walletAddressOrZero != (common.Address{}) {
+			stakerInfo, err = s.rollup.StakerInfo(ctx, walletAddressOrZero)
+			if err != nil {
+				return fmt.Errorf("error getting own staker (%v) info: %w", walletAddressOrZero, err)
+			}
+		}
+		if err := s.checkStakeFunds(ctx, stakerInfo != nil); err != nil {
+			return err
+		}
+	}
 	if s.blockValidator != nil && s.config.StartValidationFromStaked {
 		latestStaked, _, err := s.validatorUtils.LatestStaked(&s.baseCallOpts, s.rollupAddress, walletAddressOrZero)
 		if err != nil {
@@ -370,6 +437,13 @@ func (s *Staker) getLatestStakedState(ctx context.Context, staker common.Address
 	return latestStaked, count, &globalState, nil
 }
 
+// LatestStakedMessageCount returns the message count of our latest staked assertion, or of the latest
+// confirmed one without a wallet. It's 0 if that assertion isn't in our chain yet.
+func (s *Staker) LatestStakedMessageCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	_, count, _, err := s.getLatestStakedState(ctx, s.wallet.AddressOrZero())
+	return count, err
+}
+
 func (s *Staker) StopAndWait() {
 	s.StopWaiter.StopAndWait()
 	if s.Strategy() != WatchtowerStrategy {
@@ -607,6 +681,17 @@ func (s *Staker) Act(ctx context.Context) (*types.Transaction, error) {
 	}
 
 	effectiveStrategy := s.config.strategy
+	if effectiveStrategy != WatchtowerStrategy {
+		if err := s.checkStakeFunds(ctx, rawInfo != nil); err != nil {
+			if s.fatalErr != nil {
+				s.fatalErr <- err
+			}
+			return nil, err
+		}
+		if s.insufficientStake {
+			effectiveStrategy = WatchtowerStrategy
+		}
+	}
 	nodesLinear, err := s.validatorUtils.AreUnresolvedNodesLinear(callOpts, s.rollupAddress)
 	if err != nil {
 		return nil, fmt.Errorf("error checking for rollup assertion fork: %w", err)
@@ -1011,6 +1096,75 @@ func (s *Staker) Rollup() *RollupWatcher {
 	return s.rollup
 }
 
+// stakeFunds returns the current required stake and how much of it the wallet can put down,
+// including the balance of a contract wallet, but not accounting for gas
+func (s *Staker) stakeFunds(ctx context.Context) (*big.Int, *big.Int, error) {
+	required, err := s.rollup.CurrentRequiredStake(s.getCallOpts(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting current required stake: %w", err)
+	}
+	available := big.NewInt(0)
+	txSender := s.wallet.TxSenderAddress()
+	if txSender != nil {
+		balance, err := s.client.BalanceAt(ctx, *txSender, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting balance of validator tx sender %v: %w", *txSender, err)
+		}
+		available.Add(available, balance)
+	}
+	if wallet := s.wallet.Address(); wallet != nil && (txSender == nil || *wallet != *txSender) {
+		balance, err := s.client.BalanceAt(ctx, *wallet, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting balance of validator wallet %v: %w", *wallet, err)
+		}
+		available.Add(available, balance)
+	}
+	return required, available, nil
+}
+
+// checkStakeFunds makes sure an unstaked validator can afford to stake, and handles it as configured if not.
+// It only returns an error if the node should stop.
+func (s *Staker) checkStakeFunds(ctx context.Context, staked bool) error {
+	if staked {
+		s.setInsufficientStake(false)
+		return nil
+	}
+	required, available, err := s.stakeFunds(ctx)
+	if err != nil {
+		log.Warn("error checking if validator can afford to stake", "err", err)
+		return nil
+	}
+	return s.handleStakeFunds(required, available)
+}
+
+// handleStakeFunds applies the insufficient-stake policy if available doesn't cover the required stake
+func (s *Staker) handleStakeFunds(required *big.Int, available *big.Int) error {
+	if available.Cmp(required) >= 0 {
+		if s.insufficientStake {
+			log.Info("validator wallet can now afford the required stake", "required", required, "available", available)
+		}
+		s.setInsufficientStake(false)
+		return nil
+	}
+	if s.config.InsufficientStake == InsufficientStakeAbort {
+		return fmt.Errorf("validator wallet can't afford the required stake of %v wei, only %v wei available (insufficient-stake is %v)", required, available, InsufficientStakeAbort)
+	}
+	if !s.insufficientStake {
+		log.Error("validator wallet can't afford the required stake, continuing as a watchtower until it's funded", "strategy", s.config.Strategy, "required", required, "available", available, "txSender", s.wallet.TxSenderAddress(), "wallet", s.wallet.Address())
+	}
+	s.setInsufficientStake(true)
+	return nil
+}
+
+func (s *Staker) setInsufficientStake(insufficient bool) {
+	s.insufficientStake = insufficient
+	if insufficient {
+		stakerInsufficientStakeGauge.Update(1)
+	} else {
+		stakerInsufficientStakeGauge.Update(0)
+	}
+}
+
 func (s *Staker) updateStakerBalanceMetric(ctx context.Context) {
 	txSenderAddress := s.wallet.TxSenderAddress()
 	if txSenderAddress == nil {
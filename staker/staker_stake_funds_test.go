@@ -0,0 +1,80 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type stakeFundsTestWallet struct {
+	ValidatorWalletInterface
+	address common.Address
+}
+
+func (w *stakeFundsTestWallet) Address() *common.Address {
+	return &w.address
+}
+
+func (w *stakeFundsTestWallet) TxSenderAddress() *common.Address {
+	return &w.address
+}
+
+func TestHandleStakeFunds(t *testing.T) {
+	required := big.NewInt(100)
+	newStaker := func(policy string) *Staker {
+		config := DefaultL1ValidatorConfig
+		config.Strategy = "MakeNodes"
+		config.InsufficientStake = policy
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return &Staker{
+			L1Validator: &L1Validator{wallet: &stakeFundsTestWallet{address: common.HexToAddress("0x01")}},
+			config:      config,
+		}
+	}
+
+	for _, policy := range []string{InsufficientStakeWatchtower, InsufficientStakeAbort} {
+		s := newStaker(policy)
+		if err := s.handleStakeFunds(required, big.NewInt(100)); err != nil {
+			t.Fatal("sufficient funds rejected with insufficient-stake", policy, err)
+		}
+		if s.insufficientStake {
+			t.Fatal("sufficient funds reported as insufficient with insufficient-stake", policy)
+		}
+	}
+
+	s := newStaker(InsufficientStakeWatchtower)
+	if err := s.handleStakeFunds(required, big.NewInt(99)); err != nil {
+		t.Fatal("insufficient funds stopped the node with insufficient-stake watchtower", err)
+	}
+	if !s.insufficientStake {
+		t.Fatal("insufficient funds not tracked with insufficient-stake watchtower")
+	}
+	// funding the wallet resumes the configured strategy, as does being staked already
+	if err := s.handleStakeFunds(required, big.NewInt(150)); err != nil {
+		t.Fatal(err)
+	}
+	if s.insufficientStake {
+		t.Fatal("still insufficient after funding the wallet")
+	}
+	if err := s.handleStakeFunds(required, big.NewInt(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkStakeFunds(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+	if s.insufficientStake {
+		t.Fatal("staked validator reported as having insufficient funds")
+	}
+
+	s = newStaker(InsufficientStakeAbort)
+	if err := s.handleStakeFunds(required, big.NewInt(99)); err == nil {
+		t.Fatal("insufficient funds accepted with insufficient-stake abort")
+	}
+}
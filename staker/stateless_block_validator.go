@@ -75,6 +75,7 @@ type TransactionStreamerInterface interface {
 	ResultAtCount(count arbutil.MessageIndex) (*execution.MessageResult, error)
 	PauseReorgs()
 	ResumeReorgs()
+	ReexecuteFrom(count arbutil.MessageIndex) error
 }
 
 type InboxReaderInterface interface {
@@ -243,11 +244,16 @@ func NewStatelessBlockValidator(
 	valConfFetcher := func() *rpcclient.ClientConfig { return &config().ValidationServer }
 	valClient := server_api.NewValidationClient(valConfFetcher, stack)
 	execClient := server_api.NewExecutionClient(valConfFetcher, stack)
+	validationSpawners := []validator.ValidationSpawner{valClient}
+	if config().SecondaryValidationServer.URL != "" {
+		secondaryConfFetcher := func() *rpcclient.ClientConfig { return &config().SecondaryValidationServer }
+		validationSpawners = append(validationSpawners, server_api.NewValidationClient(secondaryConfFetcher, stack))
+	}
 	validator := &StatelessBlockValidator{
 		config:             config(),
 		execSpawner:        execClient,
 		recorder:           recorder,
-		validationSpawners: []validator.ValidationSpawner{valClient},
+		validationSpawners: validationSpawners,
 		inboxReader:        inboxReader,
 		inboxTracker:       inbox,
 		streamer:           streamer,
@@ -344,7 +344,7 @@ func (v *ContractValidatorWallet) estimateGas(ctx context.Context, value *big.In
 	if err != nil {
 		return 0, fmt.Errorf("estimating gas: %w", err)
 	}
-	return g + v.getExtraGas(), nil
+	return v.dataPoster.BufferGasEstimate(g) + v.getExtraGas(), nil
 }
 
 func (v *ContractValidatorWallet) TimeoutChallenges(ctx context.Context, challenges []uint64) (*types.Transaction, error) {
@@ -482,3 +482,86 @@ func GetValidatorWalletContract(
 	log.Info("created validator smart contract wallet", "address", ev.WalletAddress)
 	return &ev.WalletAddress, nil
 }
+
+// CreateValidatorWalletContract is GetValidatorWalletContract creating the wallet if it's missing, retrying up to config.Attempts times.
+// A retry replaces a creation transaction of an earlier attempt that's still pending, with fees bumped by config.FeeBumpPercent,
+// and an earlier attempt whose transaction was eventually included is found by the log search.
+func CreateValidatorWalletContract(
+	ctx context.Context,
+	config *WalletCreationConfig,
+	validatorWalletFactoryAddr common.Address,
+	fromBlock int64,
+	transactAuth *bind.TransactOpts,
+	l1Reader L1ReaderInterface,
+) (*common.Address, error) {
+	client := l1Reader.Client()
+	opts := *transactAuth
+	var err error
+	for attempt := 1; attempt <= config.Attempts; attempt++ {
+		if attempt > 1 {
+			log.Warn("failed to create validator smart contract wallet, retrying", "attempt", attempt-1, "attempts", config.Attempts, "err", err)
+			timer := time.NewTimer(config.RetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		var nonce uint64
+		// the nonce of the latest block, so a pending transaction of an earlier attempt is replaced
+		nonce, err = client.NonceAt(ctx, opts.From, nil)
+		if err != nil {
+			continue
+		}
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		err = setWalletCreationFees(ctx, client, &opts, attempt > 1, config.FeeBumpPercent)
+		if err != nil {
+			continue
+		}
+		log.Info("creating validator smart contract wallet", "attempt", attempt, "nonce", nonce, "gasFeeCap", opts.GasFeeCap, "gasTipCap", opts.GasTipCap, "gasPrice", opts.GasPrice)
+		var addr *common.Address
+		addr, err = GetValidatorWalletContract(ctx, validatorWalletFactoryAddr, fromBlock, &opts, l1Reader, true)
+		if err == nil {
+			return addr, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to create validator smart contract wallet in %v attempts: %w", config.Attempts, err)
+}
+
+// setWalletCreationFees sets the fees of opts to the currently suggested ones,
+// and if bump is set, to at least feeBumpPercent more than the fees it had before.
+func setWalletCreationFees(ctx context.Context, client arbutil.L1Interface, opts *bind.TransactOpts, bump bool, feeBumpPercent uint64) error {
+	bumped := func(suggested *big.Int, previous *big.Int) *big.Int {
+		if !bump || previous == nil {
+			return suggested
+		}
+		// rounded up, so replacing the previous attempt is never rejected as underpriced
+		minimum := arbmath.BigDivByUint(arbmath.BigAddByUint(arbmath.BigMulByUint(previous, 100+feeBumpPercent), 99), 100)
+		return arbmath.BigMax(suggested, minimum)
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if header.BaseFee == nil {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return err
+		}
+		opts.GasPrice = bumped(gasPrice, opts.GasPrice)
+		return nil
+	}
+	tipCap, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return err
+	}
+	feeCap := arbmath.BigAdd(arbmath.BigMulByUint(header.BaseFee, 2), tipCap)
+	opts.GasPrice = nil
+	opts.GasTipCap = bumped(tipCap, opts.GasTipCap)
+	opts.GasFeeCap = arbmath.BigMax(bumped(feeCap, opts.GasFeeCap), opts.GasTipCap)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type walletFeesTestClient struct {
+	arbutil.L1Interface
+	baseFee  *big.Int // nil before London
+	gasPrice *big.Int
+	tipCap   *big.Int
+}
+
+func (c *walletFeesTestClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: c.baseFee}, nil
+}
+
+func (c *walletFeesTestClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.gasPrice, nil
+}
+
+func (c *walletFeesTestClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return c.tipCap, nil
+}
+
+// atLeastBumped fails unless value is at least percent more than previous
+func atLeastBumped(t *testing.T, name string, value *big.Int, previous *big.Int, percent uint64) {
+	t.Helper()
+	minimum := new(big.Int).Mul(previous, new(big.Int).SetUint64(100+percent))
+	if new(big.Int).Mul(value, big.NewInt(100)).Cmp(minimum) < 0 {
+		t.Fatal(name, value, "bumped less than", percent, "percent over", previous)
+	}
+}
+
+func TestSetWalletCreationFeesLegacy(t *testing.T) {
+	ctx := context.Background()
+	client := &walletFeesTestClient{gasPrice: big.NewInt(1005)}
+	opts := &bind.TransactOpts{}
+	if err := setWalletCreationFees(ctx, client, opts, false, 10); err != nil {
+		t.Fatal(err)
+	}
+	if opts.GasPrice.Int64() != 1005 || opts.GasFeeCap != nil || opts.GasTipCap != nil {
+		t.Fatal("unexpected legacy fees", opts.GasPrice, opts.GasFeeCap, opts.GasTipCap)
+	}
+
+	// the suggestion didn't change, so the retry bumps the previous price, rounding up
+	if err := setWalletCreationFees(ctx, client, opts, true, 10); err != nil {
+		t.Fatal(err)
+	}
+	atLeastBumped(t, "gas price", opts.GasPrice, big.NewInt(1005), 10)
+	if opts.GasPrice.Int64() != 1106 {
+		t.Fatal("unexpected bumped gas price", opts.GasPrice)
+	}
+
+	// a higher suggestion is used as is
+	client.gasPrice = big.NewInt(5000)
+	if err := setWalletCreationFees(ctx, client, opts, true, 10); err != nil {
+		t.Fatal(err)
+	}
+	if opts.GasPrice.Int64() != 5000 {
+		t.Fatal("higher suggested gas price not used", opts.GasPrice)
+	}
+}
+
+func TestSetWalletCreationFeesDynamic(t *testing.T) {
+	ctx := context.Background()
+	client := &walletFeesTestClient{baseFee: big.NewInt(100), tipCap: big.NewInt(3)}
+	opts := &bind.TransactOpts{GasPrice: big.NewInt(1)}
+	if err := setWalletCreationFees(ctx, client, opts, false, 10); err != nil {
+		t.Fatal(err)
+	}
+	if opts.GasPrice != nil || opts.GasTipCap.Int64() != 3 || opts.GasFeeCap.Int64() != 203 {
+		t.Fatal("unexpected dynamic fees", opts.GasPrice, opts.GasFeeCap, opts.GasTipCap)
+	}
+
+	for _, percent := range []uint64{10, 25} {
+		previousTip, previousFeeCap := opts.GasTipCap, opts.GasFeeCap
+		if err := setWalletCreationFees(ctx, client, opts, true, percent); err != nil {
+			t.Fatal(err)
+		}
+		atLeastBumped(t, "tip cap", opts.GasTipCap, previousTip, percent)
+		atLeastBumped(t, "fee cap", opts.GasFeeCap, previousFeeCap, percent)
+		if opts.GasFeeCap.Cmp(opts.GasTipCap) < 0 {
+			t.Fatal("fee cap", opts.GasFeeCap, "below tip cap", opts.GasTipCap)
+		}
+	}
+
+	// a risen base fee is followed, the tip is still bumped
+	client.baseFee = big.NewInt(1000)
+	previousTip := opts.GasTipCap
+	if err := setWalletCreationFees(ctx, client, opts, true, 10); err != nil {
+		t.Fatal(err)
+	}
+	atLeastBumped(t, "tip cap", opts.GasTipCap, previousTip, 10)
+	if opts.GasFeeCap.Cmp(big.NewInt(2000)) < 0 {
+		t.Fatal("fee cap", opts.GasFeeCap, "not following the base fee")
+	}
+}
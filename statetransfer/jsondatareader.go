@@ -73,8 +73,13 @@ func NewJsonInitDataReader(filepath string) (InitDataReader, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewJsonInitDataReaderFromBytes(data, path.Dir(filepath))
+}
+
+// NewJsonInitDataReaderFromBytes parses init data already in memory, resolving referenced list files relative to basePath
+func NewJsonInitDataReaderFromBytes(data []byte, basePath string) (InitDataReader, error) {
 	reader := JsonInitDataReader{
-		basePath: path.Dir(filepath),
+		basePath: basePath,
 	}
 	if err := json.Unmarshal(data, &reader.data); err != nil {
 		return nil, err
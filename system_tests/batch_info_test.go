@@ -0,0 +1,64 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/offchainlabs/nitro/arbnode"
+)
+
+func TestBatchInfoForBlock(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l2info, node, l2client, l1info, _, l1client, l1stack := createTestNodeOnL1(t, ctx, true)
+	defer requireClose(t, l1stack)
+	defer node.StopAndWait()
+	rpcClient, err := node.Stack.Attach()
+	Require(t, err)
+
+	l2info.GenerateAccount("User2")
+	tx := l2info.PrepareTx("Owner", "User2", l2info.TransferGas, big.NewInt(1e12), nil)
+	Require(t, l2client.SendTransaction(ctx, tx))
+	receipt, err := EnsureTxSucceeded(ctx, l2client, tx)
+	Require(t, err)
+	blockNum := receipt.BlockNumber.Uint64()
+
+	var info arbnode.BatchInfo
+	for i := 0; ; i++ {
+		err = rpcClient.CallContext(ctx, &info, "arb_batchInfoForBlock", hexutil.Uint64(blockNum))
+		if err == nil {
+			break
+		}
+		if i >= 60 {
+			Fatal(t, "block never posted in a batch", err)
+		}
+		// advance the parent chain so the batch gets posted and read
+		SendWaitTestTransactions(t, ctx, l1client, []*types.Transaction{
+			l1info.PrepareTx("Faucet", "User", 30000, big.NewInt(1e12), nil),
+		})
+		time.Sleep(100 * time.Millisecond)
+	}
+	if blockNum < info.FirstBlock || blockNum >= info.FirstBlock+info.BlockCount {
+		Fatal(t, "batch", info.SequenceNumber, "doesn't contain block", blockNum, "first block", info.FirstBlock, "block count", info.BlockCount)
+	}
+	l1Receipt, err := l1client.TransactionReceipt(ctx, info.TxHash)
+	Require(t, err)
+	if l1Receipt.BlockNumber.Uint64() != info.ParentChainBlock {
+		Fatal(t, "batch posted in parent chain block", l1Receipt.BlockNumber, "but reported", info.ParentChainBlock)
+	}
+
+	var bySeqNum arbnode.BatchInfo
+	Require(t, rpcClient.CallContext(ctx, &bySeqNum, "arb_batchInfo", hexutil.Uint64(info.SequenceNumber)))
+	if bySeqNum != info {
+		Fatal(t, "batch info by sequence number", bySeqNum, "differs from", info)
+	}
+}
@@ -210,3 +210,36 @@ func TestBatchPosterKeepsUp(t *testing.T) {
 		fmt.Printf("backlog: %v message\n", haveMessages-postedMessages)
 	}
 }
+
+func TestBatchPosterFeedConsistency(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conf := arbnode.ConfigDefaultL1Test()
+	conf.Feed.Output = *newBroadcasterConfigTest()
+	conf.BatchPoster.CheckFeedConsistency = true
+	l2info, nodeA, l2clientA, l1info, _, _, l1stack := createTestNodeOnL1WithConfig(t, ctx, true, conf, nil, nil)
+	defer requireClose(t, l1stack)
+	defer nodeA.StopAndWait()
+
+	// the second node only reads batches from the parent chain
+	l2clientB, nodeB := Create2ndNode(t, ctx, nodeA, l1stack, l1info, &l2info.ArbInitData, nil)
+	defer nodeB.StopAndWait()
+
+	l2info.GenerateAccount("User2")
+	for i := 0; i < 5; i++ {
+		tx := l2info.PrepareTx("Owner", "User2", l2info.TransferGas, big.NewInt(1e12), nil)
+		err := l2clientA.SendTransaction(ctx, tx)
+		Require(t, err)
+		_, err = EnsureTxSucceeded(ctx, l2clientA, tx)
+		Require(t, err)
+		_, err = EnsureTxSucceededWithTimeout(ctx, l2clientB, tx, time.Second*30)
+		Require(t, err)
+	}
+	if _, ok := nodeA.BroadcastServer.RecordedMessage(1); !ok {
+		Fatal(t, "feed messages not recorded for the consistency check")
+	}
+	AssertMetric(t, "arb/batchposter/feedcheck/checked", func(checked int64) bool { return checked > 0 })
+	AssertMetric(t, "arb/batchposter/feedcheck/mismatch", func(mismatches int64) bool { return mismatches == 0 })
+}
@@ -0,0 +1,62 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbos/l2pricing"
+	"github.com/offchainlabs/nitro/solgen/go/mocksgen"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+)
+
+func TestSequencerBlockGasLimitChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l2info, node, client := CreateTestL2WithConfig(t, ctx, nil, arbnode.ConfigDefaultL2Test(), true)
+	defer node.StopAndWait()
+
+	l2info.GenerateAccount("User")
+	ownerOpts := l2info.GetDefaultTransactOpts("Owner", ctx)
+	arbOwner, err := precompilesgen.NewArbOwner(common.HexToAddress("0x70"), client)
+	Require(t, err)
+
+	setLimit := func(limit uint64) {
+		t.Helper()
+		tx, err := arbOwner.SetMaxTxGasLimit(&ownerOpts, limit)
+		Require(t, err)
+		_, err = EnsureTxSucceeded(ctx, client, tx)
+		Require(t, err)
+		if got := node.Execution.ExecEngine.BlockGasLimit(); got != limit {
+			Fatal(t, "execution engine has block gas limit", got, "expected", limit)
+		}
+	}
+
+	// deploying needs more than the lowered limit allows
+	deployOpts := l2info.GetDefaultTransactOpts("Owner", ctx)
+	deployOpts.GasLimit = 3_000_000
+	const lowLimit = 60_000
+
+	setLimit(lowLimit)
+	TransferBalance(t, "Owner", "User", big.NewInt(1), l2info, client, ctx)
+	_, tx, _, err := mocksgen.DeploySimple(&deployOpts, client)
+	Require(t, err)
+	receipt, err := WaitForTx(ctx, client, tx.Hash(), defaultTxTimeout)
+	Require(t, err)
+	if receipt.Status != types.ReceiptStatusFailed {
+		Fatal(t, "deployment exceeding the block gas limit succeeded")
+	}
+
+	setLimit(l2pricing.InitialPerBlockGasLimitV6)
+	_, tx, _, err = mocksgen.DeploySimple(&deployOpts, client)
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+}
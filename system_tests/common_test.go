@@ -8,16 +8,20 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/offchainlabs/nitro/arbnode/execution"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l2pricing"
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/blsSignatures"
@@ -42,6 +46,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -231,6 +236,213 @@ func SendUnsignedTxViaL1(
 	return receipt
 }
 
+// RetryableSubmission describes a retryable ticket created through the L1 inbox.
+// Unset refund addresses default to the sender, and unset fee fields to values suitable for tests.
+type RetryableSubmission struct {
+	To                common.Address
+	CallValue         *big.Int
+	Deposit           *big.Int
+	MaxSubmissionCost *big.Int
+	Beneficiary       common.Address
+	GasLimit          uint64
+	MaxFeePerGas      *big.Int
+	Data              []byte
+}
+
+type RetryableTicket struct {
+	Id                common.Hash
+	Timeout           uint64
+	SubmissionReceipt *types.Receipt
+	// AutoRedeemTxHash is zero if the submission didn't schedule an auto-redeem
+	AutoRedeemTxHash common.Hash
+}
+
+// SubmitRetryableViaL1 creates a retryable ticket from sender's L1 account, waits for it to be
+// delivered to L2 and returns the ticket along with the L2 receipt of its submission.
+func SubmitRetryableViaL1(
+	t *testing.T,
+	ctx context.Context,
+	l1info *BlockchainTestInfo,
+	l1client arbutil.L1Interface,
+	l2client arbutil.L1Interface,
+	sender string,
+	submission RetryableSubmission,
+) *RetryableTicket {
+	t.Helper()
+	delayedInboxContract, err := bridgegen.NewInbox(l1info.GetAddress("Inbox"), l1client)
+	Require(t, err)
+	delayedBridge, err := arbnode.NewDelayedBridge(l1client, l1info.GetAddress("Bridge"), 0)
+	Require(t, err)
+
+	usertxopts := l1info.GetDefaultTransactOpts(sender, ctx)
+	if submission.CallValue == nil {
+		submission.CallValue = common.Big0
+	}
+	if submission.Deposit == nil {
+		submission.Deposit = arbmath.BigMul(big.NewInt(1e12), big.NewInt(1e12))
+	}
+	if submission.MaxSubmissionCost == nil {
+		submission.MaxSubmissionCost = big.NewInt(1e16)
+	}
+	if submission.Beneficiary == (common.Address{}) {
+		submission.Beneficiary = usertxopts.From
+	}
+	if submission.MaxFeePerGas == nil {
+		submission.MaxFeePerGas = big.NewInt(l2pricing.InitialBaseFeeWei * 2)
+	}
+	usertxopts.Value = submission.Deposit
+
+	l1tx, err := delayedInboxContract.CreateRetryableTicket(
+		&usertxopts,
+		submission.To,
+		submission.CallValue,
+		submission.MaxSubmissionCost,
+		submission.Beneficiary,
+		submission.Beneficiary,
+		arbmath.UintToBig(submission.GasLimit),
+		submission.MaxFeePerGas,
+		submission.Data,
+	)
+	Require(t, err)
+	l1Receipt, err := EnsureTxSucceeded(ctx, l1client, l1tx)
+	Require(t, err)
+
+	// sending l1 messages creates l1 blocks.. make enough to get that delayed inbox message in
+	for i := 0; i < 30; i++ {
+		SendWaitTestTransactions(t, ctx, l1client, []*types.Transaction{
+			l1info.PrepareTx("Faucet", "Faucet", 30000, big.NewInt(1e12), nil),
+		})
+	}
+
+	chainId, err := l2client.ChainID(ctx)
+	Require(t, err)
+	messages, err := delayedBridge.LookupMessagesInRange(ctx, l1Receipt.BlockNumber, l1Receipt.BlockNumber, nil)
+	Require(t, err)
+	var submissionTx *types.Transaction
+	for _, message := range messages {
+		if message.Message.Header.Kind != arbostypes.L1MessageType_SubmitRetryable {
+			continue
+		}
+		txs, err := arbos.ParseL2Transactions(message.Message, chainId, nil)
+		Require(t, err)
+		for _, tx := range txs {
+			if tx.Type() == types.ArbitrumSubmitRetryableTxType {
+				if submissionTx != nil {
+					Fatal(t, "found multiple retryable submissions in L1 block", l1Receipt.BlockNumber)
+				}
+				submissionTx = tx
+			}
+		}
+	}
+	if submissionTx == nil {
+		Fatal(t, "didn't find retryable submission in L1 block", l1Receipt.BlockNumber)
+	}
+	receipt, err := EnsureTxSucceeded(ctx, l2client, submissionTx)
+	Require(t, err)
+
+	ticket := &RetryableTicket{
+		Id:                submissionTx.Hash(),
+		SubmissionReceipt: receipt,
+	}
+	for _, log := range receipt.Logs {
+		if log.Address != arbos.ArbRetryableTxAddress || log.Topics[0] != arbos.RedeemScheduledEventID {
+			continue
+		}
+		event := &precompilesgen.ArbRetryableTxRedeemScheduled{}
+		Require(t, util.ParseRedeemScheduledLog(event, log))
+		ticket.AutoRedeemTxHash = event.RetryTxHash
+	}
+	arbRetryableTx, err := precompilesgen.NewArbRetryableTx(arbos.ArbRetryableTxAddress, l2client)
+	Require(t, err)
+	timeout, err := arbRetryableTx.GetTimeout(&bind.CallOpts{Context: ctx}, ticket.Id)
+	if err == nil {
+		ticket.Timeout = timeout.Uint64()
+	}
+	return ticket
+}
+
+// WaitForRetryableAutoRedeem returns the receipt of the ticket's auto-redeem, which may have failed.
+func WaitForRetryableAutoRedeem(t *testing.T, ctx context.Context, l2client arbutil.L1Interface, ticket *RetryableTicket) *types.Receipt {
+	t.Helper()
+	if ticket.AutoRedeemTxHash == (common.Hash{}) {
+		Fatal(t, "retryable", ticket.Id, "has no scheduled auto-redeem")
+	}
+	receipt, err := WaitForTx(ctx, l2client, ticket.AutoRedeemTxHash, time.Second*5)
+	Require(t, err)
+	return receipt
+}
+
+// RedeemRetryable manually redeems the ticket from the given L2 account and returns the receipt of the retry.
+func RedeemRetryable(t *testing.T, ctx context.Context, l2info *BlockchainTestInfo, l2client arbutil.L1Interface, redeemer string, ticket *RetryableTicket) *types.Receipt {
+	t.Helper()
+	arbRetryableTx, err := precompilesgen.NewArbRetryableTx(arbos.ArbRetryableTxAddress, l2client)
+	Require(t, err)
+	txOpts := l2info.GetDefaultTransactOpts(redeemer, ctx)
+	tx, err := arbRetryableTx.Redeem(&txOpts, ticket.Id)
+	Require(t, err)
+	receipt, err := EnsureTxSucceeded(ctx, l2client, tx)
+	Require(t, err)
+	var retryTxHash common.Hash
+	for _, log := range receipt.Logs {
+		if log.Address != arbos.ArbRetryableTxAddress || log.Topics[0] != arbos.RedeemScheduledEventID {
+			continue
+		}
+		event := &precompilesgen.ArbRetryableTxRedeemScheduled{}
+		Require(t, util.ParseRedeemScheduledLog(event, log))
+		retryTxHash = event.RetryTxHash
+	}
+	if retryTxHash == (common.Hash{}) {
+		Fatal(t, "redeem of", ticket.Id, "didn't schedule a retry")
+	}
+	receipt, err = WaitForTx(ctx, l2client, retryTxHash, time.Second*5)
+	Require(t, err)
+	return receipt
+}
+
+func retryableExists(t *testing.T, ctx context.Context, l2client arbutil.L1Interface, ticketId common.Hash) bool {
+	t.Helper()
+	arbRetryableTx, err := precompilesgen.NewArbRetryableTx(arbos.ArbRetryableTxAddress, l2client)
+	Require(t, err)
+	_, err = arbRetryableTx.GetTimeout(&bind.CallOpts{Context: ctx}, ticketId)
+	if err == nil {
+		return true
+	}
+	if err.Error() != "execution reverted: error NoTicketWithID()" {
+		Fatal(t, "unexpected error looking up retryable", ticketId, err)
+	}
+	return false
+}
+
+// RequireRetryableCreated checks that the ticket exists and hasn't timed out.
+func RequireRetryableCreated(t *testing.T, ctx context.Context, l2client arbutil.L1Interface, ticket *RetryableTicket) {
+	t.Helper()
+	if !retryableExists(t, ctx, l2client, ticket.Id) {
+		Fatal(t, "retryable", ticket.Id, "doesn't exist")
+	}
+	header, err := l2client.HeaderByNumber(ctx, nil)
+	Require(t, err)
+	if header.Time >= ticket.Timeout {
+		Fatal(t, "retryable", ticket.Id, "timed out at", ticket.Timeout, "latest block time", header.Time)
+	}
+}
+
+// RequireRetryableRedeemed checks that redeemReceipt is a successful retry of the ticket and that the ticket was deleted.
+func RequireRetryableRedeemed(t *testing.T, ctx context.Context, l2client arbutil.L1Interface, ticket *RetryableTicket, redeemReceipt *types.Receipt) {
+	t.Helper()
+	if redeemReceipt.Status != types.ReceiptStatusSuccessful {
+		Fatal(t, "redeem of retryable", ticket.Id, "failed")
+	}
+	tx, _, err := l2client.TransactionByHash(ctx, redeemReceipt.TxHash)
+	Require(t, err)
+	retryTx, ok := tx.GetInner().(*types.ArbitrumRetryTx)
+	if !ok || retryTx.TicketId != ticket.Id {
+		Fatal(t, "transaction", redeemReceipt.TxHash, "isn't a retry of", ticket.Id)
+	}
+	if retryableExists(t, ctx, l2client, ticket.Id) {
+		Fatal(t, "retryable", ticket.Id, "still exists after being redeemed")
+	}
+}
+
 func GetBaseFee(t *testing.T, client client, ctx context.Context) *big.Int {
 	header, err := client.HeaderByNumber(ctx, nil)
 	Require(t, err)
@@ -489,6 +701,7 @@ func DeployOnTestL1(
 		l1info.GetAddress("Sequencer"),
 		0,
 		arbnode.GenerateRollupConfig(false, locator.LatestWasmModuleRoot(), l1info.GetAddress("RollupOwner"), chainConfig, serializedChainConfig, common.Address{}),
+		0,
 	)
 	Require(t, err)
 	l1info.SetContract("Bridge", addresses.Bridge)
@@ -560,6 +773,57 @@ func createTestNodeOnL1(
 	return createTestNodeOnL1WithConfig(t, ctx, isSequencer, nil, nil, nil)
 }
 
+// ChainConfigBuilder produces variants of the dev test chain configs, e.g.
+// NewChainConfigBuilder().WithDAS(true).WithArbOSVersion(10).Build()
+type ChainConfigBuilder struct {
+	das     bool
+	changes []func(*params.ArbitrumChainParams)
+}
+
+func NewChainConfigBuilder() *ChainConfigBuilder {
+	return &ChainConfigBuilder{}
+}
+
+// WithDAS picks between params.ArbitrumDevTestDASChainConfig and params.ArbitrumDevTestChainConfig,
+// which also differ in chain id
+func (b *ChainConfigBuilder) WithDAS(enable bool) *ChainConfigBuilder {
+	b.das = enable
+	return b
+}
+
+func (b *ChainConfigBuilder) WithArbOSVersion(version uint64) *ChainConfigBuilder {
+	b.changes = append(b.changes, func(p *params.ArbitrumChainParams) { p.InitialArbOSVersion = version })
+	return b
+}
+
+// WithChainOwner sets the initial chain owner, which also receives the network fees
+func (b *ChainConfigBuilder) WithChainOwner(owner common.Address) *ChainConfigBuilder {
+	b.changes = append(b.changes, func(p *params.ArbitrumChainParams) { p.InitialChainOwner = owner })
+	return b
+}
+
+func (b *ChainConfigBuilder) WithDebugPrecompiles(allow bool) *ChainConfigBuilder {
+	b.changes = append(b.changes, func(p *params.ArbitrumChainParams) { p.AllowDebugPrecompiles = allow })
+	return b
+}
+
+func (b *ChainConfigBuilder) WithGenesisBlockNum(num uint64) *ChainConfigBuilder {
+	b.changes = append(b.changes, func(p *params.ArbitrumChainParams) { p.GenesisBlockNum = num })
+	return b
+}
+
+// Build returns a new chain config on every call, so the builder can be reused for several nodes
+func (b *ChainConfigBuilder) Build() *params.ChainConfig {
+	config := params.ArbitrumDevTestChainConfig()
+	if b.das {
+		config = params.ArbitrumDevTestDASChainConfig()
+	}
+	for _, change := range b.changes {
+		change(&config.ArbitrumChainParams)
+	}
+	return config
+}
+
 func createTestNodeOnL1WithConfig(
 	t *testing.T,
 	ctx context.Context,
@@ -680,6 +944,52 @@ func CreateTestL2WithConfig(
 	return l2info, currentNode, client
 }
 
+// GetArbOSVersion returns the ArbOS version of the latest block, as reported by ArbSys.
+func GetArbOSVersion(t *testing.T, ctx context.Context, client client) uint64 {
+	t.Helper()
+	arbSys, err := precompilesgen.NewArbSys(types.ArbSysAddress, client)
+	Require(t, err)
+	version, err := arbSys.ArbOSVersion(&bind.CallOpts{Context: ctx})
+	Require(t, err)
+	// ArbSys reports versions offset by 55, as Nitro starts at version 56
+	return version.Uint64() - 55
+}
+
+func RequireArbOSVersion(t *testing.T, ctx context.Context, client client, expected uint64) {
+	t.Helper()
+	if version := GetArbOSVersion(t, ctx, client); version != expected {
+		Fatal(t, "unexpected ArbOS version", version, "expected", expected)
+	}
+}
+
+// UpgradeArbOS makes owner a chain owner via ArbDebug, schedules an immediate upgrade to newVersion,
+// then sends transactions until a block running newVersion has been produced.
+func UpgradeArbOS(t *testing.T, ctx context.Context, l2info info, client client, owner string, newVersion uint64) {
+	t.Helper()
+	auth := l2info.GetDefaultTransactOpts(owner, ctx)
+	arbDebug, err := precompilesgen.NewArbDebug(common.HexToAddress("0xff"), client)
+	Require(t, err)
+	tx, err := arbDebug.BecomeChainOwner(&auth)
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+	arbOwner, err := precompilesgen.NewArbOwner(common.HexToAddress("0x70"), client)
+	Require(t, err)
+	tx, err = arbOwner.ScheduleArbOSUpgrade(&auth, newVersion, 0)
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+
+	// the upgrade happens at the start of the next block
+	for i := 0; i < 10; i++ {
+		if GetArbOSVersion(t, ctx, client) == newVersion {
+			return
+		}
+		TransferBalance(t, owner, owner, common.Big0, l2info, client, ctx)
+	}
+	Fatal(t, "ArbOS wasn't upgraded to version", newVersion, "current version", GetArbOSVersion(t, ctx, client))
+}
+
 func StartWatchChanErr(t *testing.T, ctx context.Context, feedErrChan chan error, node *arbnode.Node) {
 	go func() {
 		select {
@@ -694,6 +1004,127 @@ func StartWatchChanErr(t *testing.T, ctx context.Context, feedErrChan chan error
 	}()
 }
 
+// FatalErrorCollector gathers the errors sent to a node's fatal error channel, for tests that check
+// for them at defined points instead of failing asynchronously like StartWatchChanErr.
+// Errors are only read from the channel when checked, so every error sent before a check is seen by it.
+type FatalErrorCollector struct {
+	fatalErrChan chan error
+	mutex        sync.Mutex
+	errs         []error
+}
+
+func NewFatalErrorCollector(fatalErrChan chan error) *FatalErrorCollector {
+	return &FatalErrorCollector{fatalErrChan: fatalErrChan}
+}
+
+// collect must be called with the mutex held
+func (c *FatalErrorCollector) collect() {
+	for {
+		select {
+		case err := <-c.fatalErrChan:
+			c.errs = append(c.errs, err)
+		default:
+			return
+		}
+	}
+}
+
+// Errors returns the fatal errors received so far, not including those consumed by ExpectFatalError
+func (c *FatalErrorCollector) Errors() []error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.collect()
+	return append([]error{}, c.errs...)
+}
+
+func (c *FatalErrorCollector) AssertNoFatalErrors(t *testing.T) {
+	t.Helper()
+	if errs := c.Errors(); len(errs) > 0 {
+		Fatal(t, "unexpected fatal errors:", errs)
+	}
+}
+
+// ExpectFatalError waits for a fatal error accepted by matcher and consumes it, failing the test if none arrives in time
+func (c *FatalErrorCollector) ExpectFatalError(t *testing.T, matcher func(error) bool) error {
+	t.Helper()
+	timeout := time.NewTimer(10 * time.Second)
+	defer timeout.Stop()
+	for {
+		c.mutex.Lock()
+		c.collect()
+		for i, err := range c.errs {
+			if matcher(err) {
+				c.errs = append(c.errs[:i], c.errs[i+1:]...)
+				c.mutex.Unlock()
+				return err
+			}
+		}
+		c.mutex.Unlock()
+		select {
+		case <-timeout.C:
+			Fatal(t, "expected fatal error not received, got:", c.Errors())
+			return nil
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// FatalErrorIs returns a matcher for ExpectFatalError accepting errors wrapping target
+func FatalErrorIs(target error) func(error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// FatalErrorContains returns a matcher for ExpectFatalError accepting errors with substr in their message
+func FatalErrorContains(substr string) func(error) bool {
+	return func(err error) bool {
+		return strings.Contains(err.Error(), substr)
+	}
+}
+
+// ReadMetric returns the current value of a registered metric: an int64 count for counters, meters and timers,
+// an int64 or float64 value for gauges, or a snapshot of a histogram
+func ReadMetric(t *testing.T, name string) interface{} {
+	t.Helper()
+	switch metric := metrics.DefaultRegistry.Get(name).(type) {
+	case nil:
+		Fatal(t, "metric", name, "isn't registered")
+	case metrics.Counter:
+		return metric.Count()
+	case metrics.Gauge:
+		return metric.Value()
+	case metrics.GaugeFloat64:
+		return metric.Value()
+	case metrics.Meter:
+		return metric.Count()
+	case metrics.Timer:
+		return metric.Count()
+	case metrics.Histogram:
+		return metric.Snapshot()
+	default:
+		Fatal(t, "metric", name, "has unsupported type", fmt.Sprintf("%T", metric))
+	}
+	return nil
+}
+
+// AssertMetric fails the test if the metric's current value, as returned by ReadMetric, doesn't satisfy predicate.
+// Disabled metrics always read as zero, so the test fails if metrics aren't enabled.
+func AssertMetric[V any](t *testing.T, name string, predicate func(V) bool) {
+	t.Helper()
+	if !metrics.Enabled {
+		Fatal(t, "metrics are disabled, can't check", name, "(TestMain should run the tests with testhelpers.RunWithMetrics)")
+	}
+	value := ReadMetric(t, name)
+	typed, ok := value.(V)
+	if !ok {
+		Fatal(t, "metric", name, "has value", value, "of unexpected type", fmt.Sprintf("%T", value))
+	}
+	if !predicate(typed) {
+		Fatal(t, "unexpected value", value, "of metric", name)
+	}
+}
+
 func Require(t *testing.T, err error, text ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, text...)
@@ -815,7 +1246,7 @@ func setupConfigWithDAS(
 	t *testing.T, ctx context.Context, dasModeString string,
 ) (*params.ChainConfig, *arbnode.Config, *das.LifecycleManager, string, *blsSignatures.PublicKey) {
 	l1NodeConfigA := arbnode.ConfigDefaultL1Test()
-	chainConfig := params.ArbitrumDevTestChainConfig()
+	chainConfigBuilder := NewChainConfigBuilder()
 	var dbPath string
 	var err error
 
@@ -823,10 +1254,10 @@ func setupConfigWithDAS(
 	switch dasModeString {
 	case "db":
 		enableDbStorage = true
-		chainConfig = params.ArbitrumDevTestDASChainConfig()
+		chainConfigBuilder.WithDAS(true)
 	case "files":
 		enableFileStorage = true
-		chainConfig = params.ArbitrumDevTestDASChainConfig()
+		chainConfigBuilder.WithDAS(true)
 	case "onchain":
 		enableDas = false
 	default:
@@ -871,7 +1302,7 @@ func setupConfigWithDAS(
 		Require(t, err)
 		_, err = das.StartDASRPCServerOnListener(ctx, rpcLis, genericconf.HTTPServerTimeoutConfigDefault, daReader, daWriter, daHealthChecker)
 		Require(t, err)
-		_, err = das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, daReader, daHealthChecker)
+		_, err = das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, das.DefaultRestfulServerHeadersConfig, daReader, daHealthChecker)
 		Require(t, err)
 
 		beConfigA := das.BackendConfig{
@@ -887,7 +1318,7 @@ func setupConfigWithDAS(
 		l1NodeConfigA.DataAvailability.ParentChainNodeURL = "none"
 	}
 
-	return chainConfig, l1NodeConfigA, lifecycleManager, dbPath, dasSignerKey
+	return chainConfigBuilder.Build(), l1NodeConfigA, lifecycleManager, dbPath, dasSignerKey
 }
 
 func getDeadlineTimeout(t *testing.T, defaultTimeout time.Duration) time.Duration {
@@ -926,6 +1357,23 @@ func TestMain(m *testing.M) {
 		glogger.Verbosity(log.Lvl(logLevel))
 		log.Root().SetHandler(glogger)
 	}
-	code := m.Run()
+	if txTimeoutEnv := os.Getenv("TEST_TX_TIMEOUT"); txTimeoutEnv != "" {
+		txTimeout, err := time.ParseDuration(txTimeoutEnv)
+		if err != nil || txTimeout <= 0 {
+			log.Warn("TEST_TX_TIMEOUT exists but isn't a positive duration, ignoring", "txTimeout", txTimeoutEnv)
+		} else {
+			defaultTxTimeout = txTimeout
+		}
+	}
+	if txPollIntervalEnv := os.Getenv("TEST_TX_POLL_INTERVAL"); txPollIntervalEnv != "" {
+		txPollInterval, err := time.ParseDuration(txPollIntervalEnv)
+		if err != nil || txPollInterval <= 0 {
+			log.Warn("TEST_TX_POLL_INTERVAL exists but isn't a positive duration, ignoring", "txPollInterval", txPollIntervalEnv)
+		} else {
+			maxTxPollInterval = txPollInterval
+		}
+	}
+	// tests check metric values
+	code := testhelpers.RunWithMetrics(m)
 	os.Exit(code)
 }
@@ -4,14 +4,18 @@
 package arbtest
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,14 +27,17 @@ import (
 
 	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbnode/execution"
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/das"
+	"github.com/offchainlabs/nitro/das/dastree"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/util/headerreader"
 	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/util/testhelpers"
 )
 
 func startLocalDASServer(
@@ -75,7 +82,7 @@ func startLocalDASServer(
 	Require(t, err)
 	restLis, err := net.Listen("tcp", "localhost:0")
 	Require(t, err)
-	restServer, err := das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, storageService, storageService)
+	restServer, err := das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, das.DefaultRestfulServerHeadersConfig, storageService, storageService)
 	Require(t, err)
 	beConfig := das.BackendConfig{
 		URL:                 "http://" + rpcLis.Addr().String(),
@@ -107,7 +114,7 @@ func TestDASRekey(t *testing.T) {
 	defer cancel()
 
 	// Setup L1 chain and contracts
-	chainConfig := params.ArbitrumDevTestDASChainConfig()
+	chainConfig := NewChainConfigBuilder().WithDAS(true).Build()
 	l1info, l1client, _, l1stack := createTestL1BlockChain(t, nil)
 	defer requireClose(t, l1stack)
 	feedErrChan := make(chan error, 10)
@@ -231,7 +238,7 @@ func TestDASComplexConfigAndRestMirror(t *testing.T) {
 	defer cancel()
 
 	// Setup L1 chain and contracts
-	chainConfig := params.ArbitrumDevTestDASChainConfig()
+	chainConfig := NewChainConfigBuilder().WithDAS(true).Build()
 	l1info, l1client, _, l1stack := createTestL1BlockChain(t, nil)
 	defer requireClose(t, l1stack)
 	arbSys, _ := precompilesgen.NewArbSys(types.ArbSysAddress, l1client)
@@ -277,7 +284,7 @@ func TestDASComplexConfigAndRestMirror(t *testing.T) {
 	Require(t, err)
 	restLis, err := net.Listen("tcp", "localhost:0")
 	Require(t, err)
-	restServer, err := das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, daReader, daHealthChecker)
+	restServer, err := das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, das.DefaultRestfulServerHeadersConfig, daReader, daHealthChecker)
 
 	pubkeyA := pubkey
 	authorizeDASKeyset(t, ctx, pubkeyA, l1info, l1client)
@@ -300,6 +307,7 @@ func TestDASComplexConfigAndRestMirror(t *testing.T) {
 	l1NodeConfigA.DataAvailability.RestAggregator.Enable = true
 	l1NodeConfigA.DataAvailability.RestAggregator.Urls = []string{"http://" + restLis.Addr().String()}
 	l1NodeConfigA.DataAvailability.ParentChainNodeURL = "none"
+	l1NodeConfigA.DataAvailability.KeysetCheck = das.KeysetCheckFail
 
 	dataSigner := signature.DataSignerFromPrivateKey(l1info.Accounts["Sequencer"].PrivateKey)
 
@@ -360,3 +368,191 @@ func initTest(t *testing.T) {
 		enableLogging(logLvl)
 	}
 }
+
+// failableDASBackend is a local DAS server whose RPC and REST endpoints can be made to fail,
+// to test how aggregators handle unavailable backends.
+type failableDASBackend struct {
+	failing       atomic.Bool
+	PubKey        *blsSignatures.PublicKey
+	BackendConfig das.BackendConfig
+	RestURL       string
+}
+
+// SetFailing makes all requests to the backend fail with a server error, or serves them again
+func (b *failableDASBackend) SetFailing(failing bool) {
+	b.failing.Store(failing)
+}
+
+// serveFailable serves the endpoint listening on target at a new address, failing requests while the backend is failing
+func (b *failableDASBackend) serveFailable(t *testing.T, target net.Addr) string {
+	targetURL, err := url.Parse("http://" + target.String())
+	Require(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	listener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if b.failing.Load() {
+				http.Error(w, "DAS backend failure injected by test", http.StatusServiceUnavailable)
+				return
+			}
+			proxy.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() { _ = server.Close() })
+	return "http://" + listener.Addr().String()
+}
+
+func startFailableDASBackend(t *testing.T, ctx context.Context, signerMask uint64) *failableDASBackend {
+	dataDir := t.TempDir()
+	pubkey, _, err := das.GenerateAndStoreKeys(dataDir)
+	Require(t, err)
+	config := &das.DataAvailabilityConfig{
+		Enable: true,
+		Key: das.KeyConfig{
+			KeyDir: dataDir,
+		},
+		LocalFileStorage: das.LocalFileStorageConfig{
+			Enable:  true,
+			DataDir: dataDir,
+		},
+		RequestTimeout:           5 * time.Second,
+		ParentChainNodeURL:       "none",
+		SequencerInboxAddress:    "none",
+		DisableSignatureChecking: true,
+	}
+	daReader, daWriter, daHealthChecker, lifecycleManager, err := das.CreateDAComponentsForDaserver(ctx, config, nil, nil)
+	Require(t, err)
+	t.Cleanup(func() { lifecycleManager.StopAndWaitUntil(time.Second) })
+	rpcLis, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	rpcServer, err := das.StartDASRPCServerOnListener(ctx, rpcLis, genericconf.HTTPServerTimeoutConfigDefault, daReader, daWriter, daHealthChecker)
+	Require(t, err)
+	t.Cleanup(func() { _ = rpcServer.Close() })
+	restLis, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	restServer, err := das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, das.DefaultRestfulServerHeadersConfig, daReader, daHealthChecker)
+	Require(t, err)
+	t.Cleanup(func() { _ = restServer.Shutdown() })
+
+	backend := &failableDASBackend{PubKey: pubkey}
+	backend.BackendConfig = das.BackendConfig{
+		URL:                 backend.serveFailable(t, rpcLis.Addr()),
+		PubKeyBase64Encoded: blsPubToBase64(pubkey),
+		SignerMask:          signerMask,
+	}
+	backend.RestURL = backend.serveFailable(t, restLis.Addr())
+	return backend
+}
+
+// setupConfigWithFailableDAS is like setupConfigWithDAS, but starts backendCount failable backends
+// behind the node's RPC and REST aggregators, with the given number of backends assumed honest.
+func setupConfigWithFailableDAS(
+	t *testing.T, ctx context.Context, backendCount int, assumedHonest int,
+) (*params.ChainConfig, *arbnode.Config, []*failableDASBackend) {
+	var backends []*failableDASBackend
+	var backendConfigs []das.BackendConfig
+	var restURLs []string
+	for i := 0; i < backendCount; i++ {
+		backend := startFailableDASBackend(t, ctx, 1<<i)
+		backends = append(backends, backend)
+		backendConfigs = append(backendConfigs, backend.BackendConfig)
+		restURLs = append(restURLs, backend.RestURL)
+	}
+	backendsJson, err := json.Marshal(backendConfigs)
+	Require(t, err)
+
+	nodeConfig := arbnode.ConfigDefaultL1Test()
+	nodeConfig.DataAvailability = das.DefaultDataAvailabilityConfig
+	nodeConfig.DataAvailability.Enable = true
+	nodeConfig.DataAvailability.RequestTimeout = 5 * time.Second
+	nodeConfig.DataAvailability.ParentChainNodeURL = "none"
+	nodeConfig.DataAvailability.RPCAggregator = das.AggregatorConfig{
+		Enable:        true,
+		AssumedHonest: assumedHonest,
+		Backends:      string(backendsJson),
+	}
+	nodeConfig.DataAvailability.RestAggregator = das.DefaultRestfulClientAggregatorConfig
+	nodeConfig.DataAvailability.RestAggregator.Enable = true
+	nodeConfig.DataAvailability.RestAggregator.Urls = restURLs
+	return NewChainConfigBuilder().WithDAS(true).Build(), nodeConfig, backends
+}
+
+// requireDASStore stores a new random message through the aggregator, and checks whether storing
+// succeeded as expected. It returns the stored message, or nil if storing failed.
+func requireDASStore(t *testing.T, ctx context.Context, writer das.DataAvailabilityServiceWriter, expectSuccess bool) []byte {
+	t.Helper()
+	message := testhelpers.RandomizeSlice(make([]byte, 1024))
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	cert, err := writer.Store(ctx, message, timeout, nil)
+	if !expectSuccess {
+		if err == nil {
+			Fatal(t, "DAS store succeeded, but should have failed")
+		}
+		return nil
+	}
+	Require(t, err, "DAS store failed")
+	if cert.DataHash != dastree.Hash(message) {
+		Fatal(t, "DAS certificate has the wrong data hash")
+	}
+	return message
+}
+
+// requireDASRead reads a message through the reader, and checks whether reading succeeded as expected
+func requireDASRead(t *testing.T, ctx context.Context, reader arbstate.DataAvailabilityReader, message []byte, expectSuccess bool) {
+	t.Helper()
+	readCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	data, err := reader.GetByHash(readCtx, dastree.Hash(message))
+	if !expectSuccess {
+		if err == nil {
+			Fatal(t, "DAS read succeeded, but should have failed")
+		}
+		return
+	}
+	Require(t, err, "DAS read failed")
+	if !bytes.Equal(data, message) {
+		Fatal(t, "DAS read returned the wrong data")
+	}
+}
+
+func TestDASBackendFailures(t *testing.T) {
+	initTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// two of three backends must store each message
+	_, nodeConfig, backends := setupConfigWithFailableDAS(t, ctx, 3, 2)
+	writer, err := das.NewRPCAggregator(ctx, nodeConfig.DataAvailability)
+	Require(t, err)
+	reader, err := das.NewRestfulClientAggregator(ctx, &nodeConfig.DataAvailability.RestAggregator)
+	Require(t, err)
+	reader.Start(ctx)
+	defer reader.StopAndWait()
+
+	message := requireDASStore(t, ctx, writer, true)
+	requireDASRead(t, ctx, reader, message, true)
+
+	// one failing backend is tolerated by both aggregators
+	backends[0].SetFailing(true)
+	message = requireDASStore(t, ctx, writer, true)
+	requireDASRead(t, ctx, reader, message, true)
+
+	// without quorum storing fails, but the remaining backend still serves reads
+	backends[1].SetFailing(true)
+	requireDASStore(t, ctx, writer, false)
+	requireDASRead(t, ctx, reader, message, true)
+
+	backends[2].SetFailing(true)
+	requireDASRead(t, ctx, reader, message, false)
+
+	for _, backend := range backends {
+		backend.SetFailing(false)
+	}
+	requireDASRead(t, ctx, reader, message, true)
+	requireDASStore(t, ctx, writer, true)
+}
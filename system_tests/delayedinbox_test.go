@@ -8,9 +8,11 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbos"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 )
@@ -53,3 +55,42 @@ func TestDelayInboxSimple(t *testing.T) {
 		Fatal(t, "Unexpected balance:", l2balance)
 	}
 }
+
+func TestDelayInboxChunked(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conf := arbnode.ConfigDefaultL1Test()
+	conf.DelayedSequencer.MaxMessagesPerChunk = 2
+	conf.DelayedSequencer.ChunkInterval = 10 * time.Millisecond
+	l2info, l2node, l2client, l1info, _, l1client, l1stack := createTestNodeOnL1WithConfig(t, ctx, true, conf, nil, nil)
+	defer requireClose(t, l1stack)
+	defer l2node.StopAndWait()
+
+	l2info.GenerateAccount("User2")
+
+	var delayedTxs []*types.Transaction
+	var l1Txs []*types.Transaction
+	for i := 0; i < 5; i++ {
+		delayedTx := l2info.PrepareTx("Owner", "User2", 50001, big.NewInt(1e6), nil)
+		delayedTxs = append(delayedTxs, delayedTx)
+		l1Txs = append(l1Txs, WrapL2ForDelayed(t, delayedTx, l1info, "User", 100000))
+	}
+	SendWaitTestTransactions(t, ctx, l1client, l1Txs)
+	// make enough l1 blocks to get the delayed messages finalized
+	for i := 0; i < 30; i++ {
+		SendWaitTestTransactions(t, ctx, l1client, []*types.Transaction{
+			l1info.PrepareTx("Faucet", "Faucet", 30000, big.NewInt(1e12), nil),
+		})
+	}
+	// more messages than fit in a chunk are all sequenced, without waiting for further l1 blocks
+	for _, delayedTx := range delayedTxs {
+		_, err := EnsureTxSucceeded(ctx, l2client, delayedTx)
+		Require(t, err)
+	}
+	l2balance, err := l2client.BalanceAt(ctx, l2info.GetAddress("User2"), nil)
+	Require(t, err)
+	if l2balance.Cmp(big.NewInt(5e6)) != 0 {
+		Fatal(t, "Unexpected balance:", l2balance)
+	}
+}
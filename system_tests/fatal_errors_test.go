@@ -0,0 +1,38 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFatalErrorCollector(t *testing.T) {
+	fatalErrChan := make(chan error, 10)
+	collector := NewFatalErrorCollector(fatalErrChan)
+	collector.AssertNoFatalErrors(t)
+
+	errExpected := errors.New("expected failure")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		fatalErrChan <- fmt.Errorf("validation: %w", errExpected)
+	}()
+	err := collector.ExpectFatalError(t, FatalErrorIs(errExpected))
+	if !errors.Is(err, errExpected) {
+		Fatal(t, "unexpected fatal error", err)
+	}
+	collector.AssertNoFatalErrors(t)
+
+	fatalErrChan <- errors.New("something else broke")
+	if len(collector.Errors()) != 1 {
+		Fatal(t, "fatal error sent before check not collected")
+	}
+	err = collector.ExpectFatalError(t, FatalErrorContains("else"))
+	if err == nil || err.Error() != "something else broke" {
+		Fatal(t, "unexpected fatal error", err)
+	}
+	collector.AssertNoFatalErrors(t)
+}
@@ -0,0 +1,51 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/execution"
+)
+
+func TestL1DataFeeErrors(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodeConfig := arbnode.ConfigDefaultL1Test()
+	nodeConfig.TxPreChecker.Strictness = execution.TxPreCheckerStrictnessLikelyCompatible
+	nodeConfig.TxPreChecker.L1DataFeeErrors = true
+	nodeConfig.TxPreChecker.ReportL1DataFee = true
+
+	l2info, node, l2client, _, _, _, l1stack := createTestNodeOnL1WithConfig(t, ctx, true, nodeConfig, nil, nil)
+	defer requireClose(t, l1stack)
+	defer node.StopAndWait()
+
+	l2info.GenerateAccount("User2")
+
+	// enough gas for execution, but not for the L1 data fee
+	tx := l2info.PrepareTx("Owner", "User2", params.TxGas, big.NewInt(1), nil)
+	err := l2client.SendTransaction(ctx, tx)
+	if err == nil {
+		Fatal(t, "tx without gas for the L1 data fee accepted")
+	}
+	if !strings.Contains(err.Error(), "intrinsic gas too low") || !strings.Contains(err.Error(), "L1 data fee") {
+		Fatal(t, "unexpected error", err)
+	}
+	if !strings.Contains(err.Error(), "estimated L1 data fee") {
+		Fatal(t, "L1 data fee not reported", err)
+	}
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) || dataErr.ErrorData() == nil {
+		Fatal(t, "L1 data fee missing from error data", err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+)
+
+func TestChainOwnerWatcherPausesSequencer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conf := arbnode.ConfigDefaultL2Test()
+	// the test chain's owner isn't trusted
+	conf.ChainOwnerWatcher.TrustedOwners = []string{common.HexToAddress("0x1234").Hex()}
+	conf.ChainOwnerWatcher.PauseSequencer = true
+	l2info, node, client := CreateTestL2WithConfig(t, ctx, nil, conf, true)
+	defer node.StopAndWait()
+
+	if node.Execution.OwnerWatcher == nil {
+		Fatal(t, "chain owner watcher not created")
+	}
+	l2info.GenerateAccount("User")
+	TransferBalance(t, "Owner", "User", big.NewInt(1), l2info, client, ctx)
+	if pauseChan, _ := node.Execution.Sequencer.GetPauseAndForwarder(); pauseChan != nil {
+		Fatal(t, "sequencer paused before any chain owner action")
+	}
+
+	ownerOpts := l2info.GetDefaultTransactOpts("Owner", ctx)
+	arbOwner, err := precompilesgen.NewArbOwner(common.HexToAddress("0x70"), client)
+	Require(t, err)
+	tx, err := arbOwner.SetNetworkFeeAccount(&ownerOpts, l2info.GetAddress("User"))
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if pauseChan, _ := node.Execution.Sequencer.GetPauseAndForwarder(); pauseChan != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			Fatal(t, "sequencer not paused after untrusted chain owner action")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	node.Execution.Sequencer.Activate()
+	TransferBalance(t, "Owner", "User", big.NewInt(1), l2info, client, ctx)
+}
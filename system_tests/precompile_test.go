@@ -116,3 +116,17 @@ func TestPrecompileErrorGasLeft(t *testing.T) {
 	Require(t, err)
 	assertNotAllGasConsumed(common.HexToAddress("0xff"), arbDebug.Methods["legacyError"].ID)
 }
+
+func TestArbOSVersionUpgrade(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainConfig := NewChainConfigBuilder().WithArbOSVersion(10).Build()
+	l2info, node, client, _, _, _, l1stack := createTestNodeOnL1WithConfig(t, ctx, true, nil, chainConfig, nil)
+	defer requireClose(t, l1stack)
+	defer node.StopAndWait()
+
+	RequireArbOSVersion(t, ctx, client, 10)
+	UpgradeArbOS(t, ctx, l2info, client, "Owner", 11)
+	RequireArbOSVersion(t, ctx, client, 11)
+}
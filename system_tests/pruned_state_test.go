@@ -0,0 +1,35 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/arbitrum"
+	"github.com/offchainlabs/nitro/arbnode"
+)
+
+func TestStateRangeFinderPrunedDatabase(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, bc, db, l2client, _, cancelNode := prepareNodeWithHistory(t, ctx, arbitrum.InfiniteMaxRecreateStateDepth, 32)
+	defer cancelNode()
+
+	lastBlock, err := l2client.BlockNumber(ctx)
+	Require(t, err)
+	// prune all but the genesis state, the recent states and one in between, as if written to disk at an interval
+	recentBlocks := uint64(8)
+	committedBlock := lastBlock / 2
+	removeStatesFromDb(t, bc, db, 1, committedBlock-1)
+	removeStatesFromDb(t, bc, db, committedBlock+1, lastBlock-recentBlocks)
+
+	oldest, latest := arbnode.NewStateRangeFinder(bc).AvailableRange()
+	if latest != lastBlock {
+		Fatal(t, "unexpected latest block", latest, "want", lastBlock)
+	}
+	if oldest != lastBlock-recentBlocks+1 {
+		Fatal(t, "unexpected oldest block with state", oldest, "want", lastBlock-recentBlocks+1)
+	}
+}
@@ -286,6 +286,39 @@ func TestSubmitRetryableFailThenRetry(t *testing.T) {
 	}
 }
 
+func TestRetryableLifecycleHelpers(t *testing.T) {
+	t.Parallel()
+	l2info, l1info, l2client, l1client, _, _, ctx, teardown := retryableSetup(t)
+	defer teardown()
+
+	ownerTxOpts := l2info.GetDefaultTransactOpts("Owner", ctx)
+	simpleAddr, simple := deploySimple(t, ctx, ownerTxOpts, l2client)
+	simpleABI, err := mocksgen.SimpleMetaData.GetAbi()
+	Require(t, err)
+
+	ticket := SubmitRetryableViaL1(t, ctx, l1info, l1client, l2client, "Faucet", RetryableSubmission{
+		To:          simpleAddr,
+		Beneficiary: l2info.GetAddress("Beneficiary"),
+		// send enough L2 gas for intrinsic but not compute
+		GasLimit: params.TxGas + params.TxDataNonZeroGasEIP2028*4,
+		Data:     simpleABI.Methods["incrementRedeem"].ID,
+	})
+	receipt := WaitForRetryableAutoRedeem(t, ctx, l2client, ticket)
+	if receipt.Status != types.ReceiptStatusFailed {
+		Fatal(t, "expected auto-redeem to fail")
+	}
+	RequireRetryableCreated(t, ctx, l2client, ticket)
+
+	receipt = RedeemRetryable(t, ctx, l2info, l2client, "Owner", ticket)
+	RequireRetryableRedeemed(t, ctx, l2client, ticket, receipt)
+
+	counter, err := simple.Counter(&bind.CallOpts{})
+	Require(t, err)
+	if counter != 1 {
+		Fatal(t, "Unexpected counter:", counter)
+	}
+}
+
 func TestSubmissionGasCosts(t *testing.T) {
 	t.Parallel()
 	l2info, l1info, l2client, l1client, delayedInbox, lookupL2Tx, ctx, teardown := retryableSetup(t)
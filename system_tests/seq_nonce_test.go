@@ -16,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/execution"
 	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
@@ -120,3 +121,57 @@ func TestSequencerNonceTooHighQueueFull(t *testing.T) {
 		time.Sleep(time.Millisecond * 100)
 	}
 }
+
+func TestSequencerNonceTooHighMaxGap(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := arbnode.ConfigDefaultL2Test()
+	config.Sequencer.NonceFailureCacheExpiry = time.Minute
+	config.Sequencer.NonceFailureMaxGap = 2
+	l2info, node, client := CreateTestL2WithConfig(t, ctx, nil, config, false)
+	defer node.StopAndWait()
+
+	l2info.GetInfoWithPrivKey("Owner").Nonce += 3
+	tx := l2info.PrepareTx("Owner", "Owner", l2info.TransferGas, common.Big0, nil)
+
+	before := time.Now()
+	err := client.SendTransaction(ctx, tx)
+	if err == nil {
+		Fatal(t, "No error when nonce gap was too large")
+	}
+	if !strings.Contains(err.Error(), core.ErrNonceTooHigh.Error()) {
+		Fatal(t, "Wrong error when nonce gap was too large:", err)
+	}
+	if elapsed := time.Since(before); elapsed > config.Sequencer.NonceFailureCacheExpiry/2 {
+		Fatal(t, "Transaction with too large a nonce gap was held for", elapsed)
+	}
+}
+
+func TestSequencerNonceTooHighMaxQueuedTxAge(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := arbnode.ConfigDefaultL2Test()
+	config.Sequencer.NonceFailureCacheExpiry = time.Minute
+	config.Sequencer.MaxQueuedTxAge = 500 * time.Millisecond
+	l2info, node, client := CreateTestL2WithConfig(t, ctx, nil, config, false)
+	defer node.StopAndWait()
+
+	l2info.GetInfoWithPrivKey("Owner").Nonce++
+	tx := l2info.PrepareTx("Owner", "Owner", l2info.TransferGas, common.Big0, nil)
+
+	before := time.Now()
+	err := client.SendTransaction(ctx, tx)
+	if err == nil {
+		Fatal(t, "No error when nonce was too high")
+	}
+	if !strings.Contains(err.Error(), execution.ErrQueuedTxExpired.Error()) {
+		Fatal(t, "Unexpected error for expired transaction:", err)
+	}
+	if elapsed := time.Since(before); elapsed > config.Sequencer.NonceFailureCacheExpiry/2 {
+		Fatal(t, "Transaction older than max-queued-tx-age was held for", elapsed)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021-2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbnode"
+)
+
+func TestSequencerOversizedTx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := arbnode.ConfigDefaultL2Test()
+	config.Sequencer.MaxTxDataSize = 1000
+	l2info, l2node, client := CreateTestL2WithConfig(t, ctx, nil, config, true)
+	defer l2node.StopAndWait()
+
+	l2info.GenerateAccount("User")
+
+	tx := l2info.PrepareTx("Owner", "User", l2info.TransferGas, big.NewInt(1), nil)
+	err := client.SendTransaction(ctx, tx)
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, client, tx)
+	Require(t, err)
+
+	tx = l2info.PrepareTx("Owner", "User", 100000, big.NewInt(1), make([]byte, 2000))
+	err = client.SendTransaction(ctx, tx)
+	if err == nil {
+		Fatal(t, "oversized transaction accepted")
+	}
+	if !strings.Contains(err.Error(), "transaction too large, limit 1000 bytes") {
+		Fatal(t, "unexpected error for oversized transaction", err)
+	}
+}
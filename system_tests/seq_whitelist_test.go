@@ -6,10 +6,12 @@ package arbtest
 import (
 	"context"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbnode/execution"
 )
 
 func TestSequencerWhitelist(t *testing.T) {
@@ -37,4 +39,7 @@ func TestSequencerWhitelist(t *testing.T) {
 	if err == nil {
 		Fatal(t, "transaction from user not on whitelist accepted")
 	}
+	if !strings.Contains(err.Error(), execution.ErrSenderNotWhitelisted.Error()) {
+		Fatal(t, "unexpected error for transaction from user not on whitelist", err)
+	}
 }
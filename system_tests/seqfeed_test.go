@@ -11,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
+
 	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/broadcastclient"
 	"github.com/offchainlabs/nitro/relay"
@@ -70,6 +72,17 @@ func TestSequencerFeed(t *testing.T) {
 	if l2balance.Cmp(big.NewInt(1e12)) != 0 {
 		t.Fatal("Unexpected balance:", l2balance)
 	}
+
+	rpcClient, err := nodeB.Stack.Attach()
+	Require(t, err)
+	var lag arbnode.FeedInputLagResult
+	Require(t, rpcClient.CallContext(ctx, &lag, "arb_feedInputLag"))
+	if lag.LastReceivedSequenceNumber == nil || lag.LastProcessedSequenceNumber == nil || lag.SecondsSinceLastMessage == nil {
+		t.Fatal("feed input lag missing received or processed message", lag)
+	}
+	if *lag.LastProcessedSequenceNumber < *lag.LastReceivedSequenceNumber || lag.MessagesBehind != 0 {
+		t.Fatal("node behind the feed after processing its messages", lag)
+	}
 }
 
 func TestRelayedSequencerFeed(t *testing.T) {
@@ -90,6 +103,9 @@ func TestRelayedSequencerFeed(t *testing.T) {
 	config.Node.Feed.Input = *newBroadcastClientConfigTest(port)
 	config.Node.Feed.Output = *newBroadcasterConfigTest()
 	config.Chain.ID = bigChainId.Uint64()
+	config.RPC.Enable = true
+	config.RPC.Addr = "127.0.0.1"
+	config.RPC.Port = 0
 
 	feedErrChan := make(chan error, 10)
 	currentRelay, err := relay.NewRelay(&config, feedErrChan)
@@ -122,6 +138,16 @@ func TestRelayedSequencerFeed(t *testing.T) {
 	if l2balance.Cmp(big.NewInt(1e12)) != 0 {
 		t.Fatal("Unexpected balance:", l2balance)
 	}
+
+	// the relay serves its feed output stats
+	relayRpc, err := rpc.DialContext(ctx, "http://"+currentRelay.GetRPCListenerAddr().String())
+	Require(t, err)
+	defer relayRpc.Close()
+	var stats wsbroadcastserver.FeedStats
+	Require(t, relayRpc.CallContext(ctx, &stats, "arbdebug_feedOutputStats"))
+	if stats.Subscribers != 1 || stats.TotalMessages == 0 {
+		t.Fatal("unexpected relay feed output stats", stats)
+	}
 }
 
 func testLyingSequencer(t *testing.T, dasModeStr string) {
@@ -0,0 +1,79 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbtest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/arbnode"
+)
+
+func TestStatusPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conf := arbnode.ConfigDefaultL1Test()
+	conf.StatusPage.Enable = true
+	conf.StatusPage.Port = 0
+	l2info, node, l2client, l1info, _, l1client, l1stack := createTestNodeOnL1WithConfig(t, ctx, true, conf, nil, nil)
+	defer requireClose(t, l1stack)
+	defer node.StopAndWait()
+
+	url := "http://" + node.StatusPage.Addr().String()
+	get := func(path string) (int, string) {
+		t.Helper()
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url+path, nil)
+		Require(t, err)
+		response, err := http.DefaultClient.Do(request)
+		Require(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		Require(t, err)
+		return response.StatusCode, string(body)
+	}
+
+	code, body := get("/status")
+	if code != http.StatusOK {
+		Fatal(t, "unexpected status code", code, body)
+	}
+	var status arbnode.NodeStatus
+	Require(t, json.Unmarshal([]byte(body), &status))
+	if !strings.Contains(strings.Join(status.Roles, ","), "sequencer") {
+		Fatal(t, "sequencer role missing from status", status.Roles)
+	}
+	if status.Staleness == nil || status.Staleness.BatchPosting == nil {
+		Fatal(t, "batch posting missing from status", body)
+	}
+
+	code, body = get("/")
+	if code != http.StatusOK || !strings.Contains(body, "Batch posting") {
+		Fatal(t, "unexpected status page", code, body)
+	}
+
+	// get a batch posted and read back from the parent chain, after which the node is synced
+	l2info.GenerateAccount("User2")
+	TransferBalance(t, "Owner", "User2", big.NewInt(1e12), l2info, l2client, ctx)
+	for i := 0; i < 60 && !node.SyncMonitor.Synced(); i++ {
+		// sending parent chain transactions creates parent chain blocks for the inbox reader
+		SendWaitTestTransactions(t, ctx, l1client, []*types.Transaction{
+			l1info.PrepareTx("Faucet", "User", 30000, big.NewInt(1e12), nil),
+		})
+	}
+	if !node.SyncMonitor.Synced() {
+		Fatal(t, "node not synced", node.SyncMonitor.SyncProgressMap())
+	}
+	// no stale-threshold is set, so a synced node is healthy
+	code, body = get("/healthz")
+	if code != http.StatusOK || strings.TrimSpace(body) != "ok" {
+		Fatal(t, "synced node not healthy", code, body)
+	}
+}
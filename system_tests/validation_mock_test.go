@@ -80,6 +80,10 @@ func (s *mockSpawner) LatestWasmModuleRoot() containers.PromiseInterface[common.
 	return containers.NewReadyPromise[common.Hash](mockWasmModuleRoot, nil)
 }
 
+func (s *mockSpawner) WasmModuleRoots() containers.PromiseInterface[[]common.Hash] {
+	return containers.NewReadyPromise([]common.Hash{mockWasmModuleRoot}, nil)
+}
+
 func (s *mockSpawner) WriteToFile(input *validator.ValidationInput, expOut validator.GoGlobalState, moduleRoot common.Hash) containers.PromiseInterface[struct{}] {
 	return containers.NewReadyPromise[struct{}](struct{}{}, nil)
 }
@@ -42,8 +42,8 @@ func WaitForTx(ctxinput context.Context, client arbutil.L1Interface, txhash comm
 	defer cancel()
 
 	checkInterval := timeout / 50
-	if checkInterval > time.Second {
-		checkInterval = time.Second
+	if checkInterval > maxTxPollInterval {
+		checkInterval = maxTxPollInterval
 	}
 	for {
 		receipt, err := client.TransactionReceipt(ctx, txhash)
@@ -62,7 +62,7 @@ func WaitForTx(ctxinput context.Context, client arbutil.L1Interface, txhash comm
 			}
 		}
 		// Note: time.After won't free the timer until after it expires.
-		// However, that's fine here, as checkInterval is at most a second.
+		// However, that's fine here, as checkInterval is at most maxTxPollInterval.
 		select {
 		case <-chanHead:
 		case <-time.After(checkInterval):
@@ -72,8 +72,19 @@ func WaitForTx(ctxinput context.Context, client arbutil.L1Interface, txhash comm
 	}
 }
 
+// defaults can be overridden with the TEST_TX_TIMEOUT and TEST_TX_POLL_INTERVAL environment variables
+var (
+	defaultTxTimeout  = time.Second * 5
+	maxTxPollInterval = time.Second
+)
+
+// EnsureTxSucceeded waits until the context deadline if there is one, or defaultTxTimeout otherwise
 func EnsureTxSucceeded(ctx context.Context, client arbutil.L1Interface, tx *types.Transaction) (*types.Receipt, error) {
-	return EnsureTxSucceededWithTimeout(ctx, client, tx, time.Second*5)
+	timeout := defaultTxTimeout
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		timeout = time.Until(deadline)
+	}
+	return EnsureTxSucceededWithTimeout(ctx, client, tx, timeout)
 }
 
 func EnsureTxSucceededWithTimeout(ctx context.Context, client arbutil.L1Interface, tx *types.Transaction, timeout time.Duration) (*types.Receipt, error) {
@@ -16,12 +16,22 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/rpcclient"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	flag "github.com/spf13/pflag"
 )
 
+var (
+	pollMeter           = metrics.NewRegisteredMeter("arb/headerreader/polls", nil)
+	pollIntervalGauge   = metrics.NewRegisteredGauge("arb/headerreader/poll_interval", nil)
+	missedBlocksCounter = metrics.NewRegisteredCounter("arb/headerreader/missed_blocks", nil)
+	inconsistentCounter = metrics.NewRegisteredCounter("arb/headerreader/inconsistent_headers", nil)
+	skippedCounter      = metrics.NewRegisteredCounter("arb/headerreader/skipped_headers", nil)
+)
+
 type ArbSysInterface interface {
 	ArbBlockNumber(*bind.CallOpts) (*big.Int, error)
 }
@@ -62,6 +72,42 @@ type Config struct {
 	TxTimeout            time.Duration `koanf:"tx-timeout" reload:"hot"`
 	OldHeaderTimeout     time.Duration `koanf:"old-header-timeout" reload:"hot"`
 	UseFinalityData      bool          `koanf:"use-finality-data" reload:"hot"`
+	AdaptivePoll         bool          `koanf:"adaptive-poll" reload:"hot"`
+	ExpectedBlockTime    time.Duration `koanf:"expected-block-time" reload:"hot"`
+	MinPollInterval      time.Duration `koanf:"min-poll-interval" reload:"hot"`
+	MaxPollInterval      time.Duration `koanf:"max-poll-interval" reload:"hot"`
+	InconsistentHeaders  string        `koanf:"inconsistent-headers" reload:"hot"`
+}
+
+const (
+	// use inconsistent headers like any other, only counting and logging them
+	InconsistentHeadersAccept = "accept"
+	// ignore inconsistent headers, keeping the previous header
+	InconsistentHeadersSkip = "skip"
+	// query the latest header again, and skip it if it's still inconsistent
+	InconsistentHeadersRequery = "requery"
+	// skip inconsistent headers and report the reader as failing until a consistent header arrives
+	InconsistentHeadersUnhealthy = "unhealthy"
+)
+
+func (c *Config) Validate() error {
+	if c.PollInterval <= 0 {
+		return errors.New("parent-chain-reader.poll-interval must be positive")
+	}
+	if c.AdaptivePoll {
+		if c.MinPollInterval <= 0 || c.ExpectedBlockTime <= 0 {
+			return errors.New("parent-chain-reader.min-poll-interval and expected-block-time must be positive with adaptive-poll")
+		}
+		if c.MaxPollInterval < c.MinPollInterval {
+			return errors.New("parent-chain-reader.max-poll-interval must be at least min-poll-interval")
+		}
+	}
+	switch c.InconsistentHeaders {
+	case InconsistentHeadersAccept, InconsistentHeadersSkip, InconsistentHeadersRequery, InconsistentHeadersUnhealthy:
+	default:
+		return fmt.Errorf("invalid parent-chain-reader.inconsistent-headers %q, expected %v, %v, %v or %v", c.InconsistentHeaders, InconsistentHeadersAccept, InconsistentHeadersSkip, InconsistentHeadersRequery, InconsistentHeadersUnhealthy)
+	}
+	return nil
 }
 
 type ConfigFetcher func() *Config
@@ -74,6 +120,11 @@ var DefaultConfig = Config{
 	TxTimeout:            5 * time.Minute,
 	OldHeaderTimeout:     5 * time.Minute,
 	UseFinalityData:      true,
+	AdaptivePoll:         false,
+	ExpectedBlockTime:    12 * time.Second,
+	MinPollInterval:      time.Second,
+	MaxPollInterval:      time.Minute,
+	InconsistentHeaders:  InconsistentHeadersAccept,
 }
 
 func AddOptions(prefix string, f *flag.FlagSet) {
@@ -81,17 +132,27 @@ func AddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".poll-only", DefaultConfig.PollOnly, "do not attempt to subscribe to header events")
 	f.Bool(prefix+".use-finality-data", DefaultConfig.UseFinalityData, "use l1 data about finalized/safe blocks")
 	f.Duration(prefix+".poll-interval", DefaultConfig.PollInterval, "interval when polling endpoint")
+	f.Bool(prefix+".adaptive-poll", DefaultConfig.AdaptivePoll, "instead of a fixed poll-interval, poll shortly after the next block is expected, and back off while no new block arrives")
+	f.Duration(prefix+".expected-block-time", DefaultConfig.ExpectedBlockTime, "expected time between parent chain blocks, used by adaptive-poll")
+	f.Duration(prefix+".min-poll-interval", DefaultConfig.MinPollInterval, "minimum interval between polls with adaptive-poll")
+	f.Duration(prefix+".max-poll-interval", DefaultConfig.MaxPollInterval, "maximum interval between polls with adaptive-poll")
 	f.Duration(prefix+".tx-timeout", DefaultConfig.TxTimeout, "timeout when waiting for a transaction")
 	f.Duration(prefix+".old-header-timeout", DefaultConfig.OldHeaderTimeout, "warns if the latest l1 block is at least this old")
+	f.String(prefix+".inconsistent-headers", DefaultConfig.InconsistentHeaders, "what to do with a latest header that goes back in block number or time, as served by a load balanced provider with a lagging backend: \"accept\" (only count and log it), \"skip\" (keep the previous header), \"requery\" (query again and skip if still inconsistent) or \"unhealthy\" (skip and report the reader as failing until a consistent header arrives); after several inconsistent headers in a row the new chain is accepted")
 }
 
 var TestConfig = Config{
-	Enable:           true,
-	PollOnly:         false,
-	PollInterval:     time.Millisecond * 10,
-	TxTimeout:        time.Second * 5,
-	OldHeaderTimeout: 5 * time.Minute,
-	UseFinalityData:  false,
+	Enable:              true,
+	PollOnly:            false,
+	PollInterval:        time.Millisecond * 10,
+	TxTimeout:           time.Second * 5,
+	OldHeaderTimeout:    5 * time.Minute,
+	UseFinalityData:     false,
+	AdaptivePoll:        false,
+	ExpectedBlockTime:   time.Second,
+	MinPollInterval:     time.Millisecond * 10,
+	MaxPollInterval:     time.Second,
+	InconsistentHeaders: InconsistentHeadersAccept,
 }
 
 func New(ctx context.Context, client arbutil.L1Interface, config ConfigFetcher, arbSysPrecompile ArbSysInterface) (*HeaderReader, error) {
@@ -235,6 +296,66 @@ func (s *HeaderReader) setError(err error) {
 	s.lastBroadcastErr = err
 }
 
+// after this many inconsistent headers in a row, they're accepted, as the parent chain may really have reorged
+const maxConsecutiveInconsistentHeaders = 5
+
+// headerInconsistency returns why header can't follow last on the same chain view, or an empty string if it can.
+// A reorg replacing the last block keeps its number, so only going back in number or time is inconsistent.
+func headerInconsistency(last *types.Header, header *types.Header) string {
+	if last == nil || header == nil {
+		return ""
+	}
+	if header.Number.Cmp(last.Number) < 0 {
+		return fmt.Sprintf("block number went back from %v to %v", last.Number, header.Number)
+	}
+	if header.Number.Cmp(last.Number) > 0 && header.Time < last.Time {
+		return fmt.Sprintf("timestamp went back from %v to %v in block %v", last.Time, header.Time, header.Number)
+	}
+	return ""
+}
+
+// maxThrottledPollShift caps the poll interval at 32 times its configured value while rate limited
+const maxThrottledPollShift = 5
+
+// maxUnchangedPollShift limits the exponent of the adaptive poll backoff, max-poll-interval is the actual limit
+const maxUnchangedPollShift = 16
+
+// adaptivePollInterval returns the time until the block after lastBlockTime is expected, or once it's due,
+// min-poll-interval doubled for each poll that returned no new block since
+func adaptivePollInterval(config *Config, lastBlockTime time.Time, now time.Time, unchangedPolls int) time.Duration {
+	if lastBlockTime.IsZero() {
+		return config.MinPollInterval
+	}
+	interval := lastBlockTime.Add(config.ExpectedBlockTime).Sub(now)
+	if interval <= 0 {
+		if unchangedPolls > maxUnchangedPollShift {
+			unchangedPolls = maxUnchangedPollShift
+		}
+		interval = config.MinPollInterval << unchangedPolls
+	}
+	if interval < config.MinPollInterval {
+		interval = config.MinPollInterval
+	}
+	if interval > config.MaxPollInterval {
+		interval = config.MaxPollInterval
+	}
+	return interval
+}
+
+func (s *HeaderReader) pollInterval(config *Config, unchangedPolls int) time.Duration {
+	if !config.AdaptivePoll {
+		return config.PollInterval
+	}
+	s.chanMutex.RLock()
+	lastHeader := s.lastBroadcastHeader
+	s.chanMutex.RUnlock()
+	var lastBlockTime time.Time
+	if lastHeader != nil {
+		lastBlockTime = time.Unix(int64(lastHeader.Time), 0)
+	}
+	return adaptivePollInterval(config, lastBlockTime, time.Now(), unchangedPolls)
+}
+
 func (s *HeaderReader) broadcastLoop(ctx context.Context) {
 	var clientSubscription ethereum.Subscription = nil
 	defer func() {
@@ -250,27 +371,91 @@ func (s *HeaderReader) broadcastLoop(ctx context.Context) {
 	nextSubscribeErr := time.Now().Add(-time.Second)
 	var errChannel <-chan error
 	pollOnlyOverride := false
+	// consecutive polls rejected by a rate limiting parent chain provider, each doubling the poll interval
+	throttledPolls := 0
+	// consecutive polls without a new block, used by adaptive polling
+	unchangedPolls := 0
+	var lastBlockNumber uint64
+	checkMissedBlocks := func(h *types.Header) {
+		if !h.Number.IsUint64() {
+			return
+		}
+		number := h.Number.Uint64()
+		if number == lastBlockNumber {
+			unchangedPolls++
+			return
+		}
+		if lastBlockNumber != 0 && number > lastBlockNumber+1 {
+			missedBlocksCounter.Inc(int64(number - lastBlockNumber - 1))
+			log.Debug("parent chain blocks arrived between header updates", "previous", lastBlockNumber, "number", number)
+		}
+		lastBlockNumber = number
+		unchangedPolls = 0
+	}
+	consecutiveInconsistent := 0
+	handleHeader := func(h *types.Header) {
+		s.chanMutex.RLock()
+		lastHeader := s.lastBroadcastHeader
+		s.chanMutex.RUnlock()
+		reason := headerInconsistency(lastHeader, h)
+		if reason != "" {
+			inconsistentCounter.Inc(1)
+			policy := s.config().InconsistentHeaders
+			if policy == InconsistentHeadersRequery {
+				requeried, err := s.client.HeaderByNumber(ctx, nil)
+				if err == nil && headerInconsistency(lastHeader, requeried) == "" {
+					log.Debug("parent chain provider returned an inconsistent header, using the requeried one", "reason", reason, "number", requeried.Number)
+					h = requeried
+					reason = ""
+				}
+			}
+			if reason != "" && policy != InconsistentHeadersAccept && consecutiveInconsistent < maxConsecutiveInconsistentHeaders {
+				consecutiveInconsistent++
+				skippedCounter.Inc(1)
+				log.Warn("parent chain provider returned an inconsistent header, skipping it", "reason", reason, "hash", h.Hash(), "inARow", consecutiveInconsistent)
+				if policy == InconsistentHeadersUnhealthy {
+					s.setError(fmt.Errorf("parent chain provider returned an inconsistent header: %v", reason))
+				}
+				return
+			}
+			if reason != "" {
+				log.Warn("parent chain provider returned an inconsistent header, accepting it", "reason", reason, "hash", h.Hash(), "policy", policy)
+			}
+		}
+		consecutiveInconsistent = 0
+		checkMissedBlocks(h)
+		s.possiblyBroadcast(h)
+	}
 	for {
 		if clientSubscription != nil {
 			errChannel = clientSubscription.Err()
 		} else {
 			errChannel = nil
 		}
-		timer := time.NewTimer(s.config().PollInterval)
+		interval := s.pollInterval(s.config(), unchangedPolls) << throttledPolls
+		pollIntervalGauge.Update(interval.Milliseconds())
+		timer := time.NewTimer(interval)
 		select {
 		case h := <-inputChannel:
 			log.Trace("got new header from L1", "number", h.Number, "hash", h.Hash(), "header", h)
-			s.possiblyBroadcast(h)
+			handleHeader(h)
 			timer.Stop()
 		case <-timer.C:
+			pollMeter.Mark(1)
 			h, err := s.client.HeaderByNumber(ctx, nil)
 			if err != nil {
 				s.setError(fmt.Errorf("failed reading HeaderByNumber: %w", err))
-				if !errors.Is(err, context.Canceled) {
+				if rpcclient.IsThrottled(err) {
+					if throttledPolls < maxThrottledPollShift {
+						throttledPolls++
+					}
+					log.Warn("parent chain provider rate limited header polling, slowing down", "err", err, "pollInterval", s.config().PollInterval<<throttledPolls)
+				} else if !errors.Is(err, context.Canceled) {
 					log.Warn("failed reading header", "err", err)
 				}
 			} else {
-				s.possiblyBroadcast(h)
+				throttledPolls = 0
+				handleHeader(h)
 			}
 			if !(s.config().PollOnly || pollOnlyOverride) && clientSubscription == nil {
 				clientSubscription, err = s.client.SubscribeNewHead(ctx, inputChannel)
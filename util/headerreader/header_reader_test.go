@@ -0,0 +1,60 @@
+package headerreader
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestAdaptivePollInterval(t *testing.T) {
+	config := DefaultConfig
+	config.AdaptivePoll = true
+	now := time.Unix(1000, 0)
+
+	check := func(lastBlockTime time.Time, unchangedPolls int, expected time.Duration) {
+		t.Helper()
+		if interval := adaptivePollInterval(&config, lastBlockTime, now, unchangedPolls); interval != expected {
+			t.Errorf("last block %v before, %v unchanged polls: expected interval %v got %v", now.Sub(lastBlockTime), unchangedPolls, expected, interval)
+		}
+	}
+	// nothing known yet
+	check(time.Time{}, 0, config.MinPollInterval)
+	// next block expected in 8s
+	check(now.Add(-4*time.Second), 0, 8*time.Second)
+	// next block expected in less than min-poll-interval
+	check(now.Add(-11900*time.Millisecond), 0, config.MinPollInterval)
+	// block is due, back off while it doesn't arrive
+	check(now.Add(-20*time.Second), 0, config.MinPollInterval)
+	check(now.Add(-20*time.Second), 3, 8*config.MinPollInterval)
+	check(now.Add(-20*time.Second), 100, config.MaxPollInterval)
+	// long expected block time is capped too
+	config.ExpectedBlockTime = time.Hour
+	check(now, 0, config.MaxPollInterval)
+}
+
+func TestHeaderInconsistency(t *testing.T) {
+	header := func(number int64, time uint64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Time: time}
+	}
+	last := header(100, 1200)
+	for _, test := range []struct {
+		header       *types.Header
+		inconsistent bool
+	}{
+		{header(101, 1212), false},
+		// a reorg replacing the last block
+		{header(100, 1200), false},
+		// a lagging backend
+		{header(99, 1188), true},
+		{header(101, 1100), true},
+	} {
+		if reason := headerInconsistency(last, test.header); (reason != "") != test.inconsistent {
+			t.Errorf("header %v at %v after %v at %v: unexpected inconsistency %q", test.header.Number, test.header.Time, last.Number, last.Time, reason)
+		}
+	}
+	if headerInconsistency(nil, last) != "" {
+		t.Error("first header is inconsistent")
+	}
+}
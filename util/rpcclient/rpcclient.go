@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/websocket"
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -21,18 +23,22 @@ import (
 )
 
 type ClientConfig struct {
-	URL            string        `koanf:"url"`
-	JWTSecret      string        `koanf:"jwtsecret"`
-	Timeout        time.Duration `koanf:"timeout" reload:"hot"`
-	Retries        uint          `koanf:"retries" reload:"hot"`
-	ConnectionWait time.Duration `koanf:"connection-wait"`
-	ArgLogLimit    uint          `koanf:"arg-log-limit" reload:"hot"`
-	RetryErrors    string        `koanf:"retry-errors" reload:"hot"`
+	URL            string         `koanf:"url"`
+	JWTSecret      string         `koanf:"jwtsecret"`
+	Timeout        time.Duration  `koanf:"timeout" reload:"hot"`
+	Retries        uint           `koanf:"retries" reload:"hot"`
+	ConnectionWait time.Duration  `koanf:"connection-wait"`
+	ArgLogLimit    uint           `koanf:"arg-log-limit" reload:"hot"`
+	RetryErrors    string         `koanf:"retry-errors" reload:"hot"`
+	Throttle       ThrottleConfig `koanf:"throttle"`
 
 	retryErrors *regexp.Regexp
 }
 
 func (c *ClientConfig) Validate() error {
+	if err := c.Throttle.Validate(); err != nil {
+		return err
+	}
 	if c.RetryErrors == "" {
 		c.retryErrors = nil
 		return nil
@@ -53,6 +59,7 @@ var DefaultClientConfig = ClientConfig{
 	URL:         "self-auth",
 	JWTSecret:   "",
 	ArgLogLimit: 2048,
+	Throttle:    DefaultThrottleConfig,
 }
 
 func RPCClientAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ClientConfig) {
@@ -63,6 +70,7 @@ func RPCClientAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ClientCo
 	f.Uint(prefix+".arg-log-limit", defaultConfig.ArgLogLimit, "limit size of arguments in log entries")
 	f.Uint(prefix+".retries", defaultConfig.Retries, "number of retries in case of failure(0 mean one attempt)")
 	f.String(prefix+".retry-errors", defaultConfig.RetryErrors, "Errors matching this regular expression are automatically retried")
+	ThrottleConfigAddOptions(prefix+".throttle", f, &defaultConfig.Throttle)
 }
 
 type RpcClient struct {
@@ -70,6 +78,60 @@ type RpcClient struct {
 	client    *rpc.Client
 	autoStack *node.Node
 	logId     uint64
+	transport *retryAfterTransport
+	limiter   *concurrencyLimiter
+	readOnly  bool
+}
+
+var ErrReadOnly = errors.New("rpc client is read-only")
+
+// methods a read-only client may call, which can't send transactions or use the server's keys
+var readMethods = map[string]bool{
+	"eth_blockNumber":                         true,
+	"eth_call":                                true,
+	"eth_chainId":                             true,
+	"eth_estimateGas":                         true,
+	"eth_feeHistory":                          true,
+	"eth_gasPrice":                            true,
+	"eth_getBalance":                          true,
+	"eth_getBlockByHash":                      true,
+	"eth_getBlockByNumber":                    true,
+	"eth_getBlockReceipts":                    true,
+	"eth_getBlockTransactionCountByHash":      true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getCode":                             true,
+	"eth_getFilterChanges":                    true,
+	"eth_getFilterLogs":                       true,
+	"eth_getLogs":                             true,
+	"eth_getProof":                            true,
+	"eth_getStorageAt":                        true,
+	"eth_getTransactionByBlockHashAndIndex":   true,
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getTransactionByHash":                true,
+	"eth_getTransactionCount":                 true,
+	"eth_getTransactionReceipt":               true,
+	"eth_maxPriorityFeePerGas":                true,
+	"eth_newBlockFilter":                      true,
+	"eth_newFilter":                           true,
+	"eth_subscribe":                           true,
+	"eth_syncing":                             true,
+	"eth_uninstallFilter":                     true,
+	"eth_unsubscribe":                         true,
+	"net_version":                             true,
+	"web3_clientVersion":                      true,
+}
+
+// SetReadOnly makes the client refuse all calls but those to known read methods, must be called before it is used
+func (c *RpcClient) SetReadOnly() {
+	c.readOnly = true
+}
+
+func (c *RpcClient) checkReadOnly(method string) error {
+	if c.readOnly && !readMethods[method] {
+		log.Error("refusing to send request that isn't a known read over read-only rpc client", "method", method)
+		return fmt.Errorf("%w: cannot call %v", ErrReadOnly, method)
+	}
+	return nil
 }
 
 func NewRpcClient(config ClientConfigFetcher, stack *node.Node) *RpcClient {
@@ -127,9 +189,13 @@ func (c *RpcClient) CallContext(ctx_in context.Context, result interface{}, meth
 	if c.client == nil {
 		return errors.New("not connected")
 	}
+	if err := c.checkReadOnly(method); err != nil {
+		return err
+	}
 	logId := atomic.AddUint64(&c.logId, 1)
 	log.Trace("sending RPC request", "method", method, "logId", logId, "args", limitedArgumentsMarshal{int(c.config().ArgLogLimit), args})
 	var err error
+	throttledAttempts := 0
 	for i := 0; i < int(c.config().Retries)+1; i++ {
 		if ctx_in.Err() != nil {
 			return ctx_in.Err()
@@ -142,7 +208,9 @@ func (c *RpcClient) CallContext(ctx_in context.Context, result interface{}, meth
 		} else {
 			ctx, cancelCtx = context.WithCancel(ctx_in)
 		}
-		err = c.client.CallContext(ctx, result, method, args...)
+		err = c.limited(ctx, func() error {
+			return c.client.CallContext(ctx, result, method, args...)
+		})
 		cancelCtx()
 		logger := log.Trace
 		limit := int(c.config().ArgLogLimit)
@@ -153,6 +221,12 @@ func (c *RpcClient) CallContext(ctx_in context.Context, result interface{}, meth
 		if err == nil {
 			return nil
 		}
+		if IsThrottled(err) && c.waitThrottled(ctx_in, throttledAttempts) {
+			// rate limited attempts don't use up the regular retries
+			throttledAttempts++
+			i--
+			continue
+		}
 		if errors.Is(err, context.DeadlineExceeded) {
 			continue
 		}
@@ -166,13 +240,87 @@ func (c *RpcClient) CallContext(ctx_in context.Context, result interface{}, meth
 }
 
 func (c *RpcClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
-	return c.client.BatchCallContext(ctx, b)
+	for _, elem := range b {
+		if err := c.checkReadOnly(elem.Method); err != nil {
+			return err
+		}
+	}
+	for throttledAttempts := 0; ; throttledAttempts++ {
+		err := c.limited(ctx, func() error {
+			return c.client.BatchCallContext(ctx, b)
+		})
+		if !IsThrottled(err) || !c.waitThrottled(ctx, throttledAttempts) {
+			return err
+		}
+	}
+}
+
+// limited runs call within the adaptive concurrency limit, if enabled
+func (c *RpcClient) limited(ctx context.Context, call func() error) error {
+	if c.limiter == nil || !c.config().Throttle.AdaptiveConcurrency {
+		return call()
+	}
+	if err := c.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	err := call()
+	c.limiter.release(IsThrottled(err))
+	return err
+}
+
+// waitThrottled backs off after a rate limited response, returning false if the request shouldn't be retried
+func (c *RpcClient) waitThrottled(ctx context.Context, attempt int) bool {
+	throttledCounter.Inc(1)
+	config := c.config().Throttle
+	if !config.Enable || attempt >= int(config.Retries) {
+		throttleGiveUpCount.Inc(1)
+		return false
+	}
+	backoff := config.InitialBackoff
+	for i := 0; i < attempt && backoff < config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if c.transport != nil {
+		if retryAfter := c.transport.retryAfter(); retryAfter > backoff {
+			backoff = retryAfter
+		}
+	}
+	if backoff > config.MaxBackoff {
+		backoff = config.MaxBackoff
+	}
+	log.Warn("rate limited by rpc server, backing off", "url", c.config().URL, "backoff", backoff, "attempt", attempt)
+	throttleWaitTimer.Update(backoff)
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func (c *RpcClient) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	if err := c.checkReadOnly("eth_subscribe"); err != nil {
+		return nil, err
+	}
 	return c.client.EthSubscribe(ctx, channel, args...)
 }
 
+// websocketDialer follows the proxy and TLS settings of http.DefaultTransport, like the http client does
+func websocketDialer() websocket.Dialer {
+	dialer := websocket.Dialer{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		Proxy:           http.ProxyFromEnvironment,
+	}
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+	return dialer
+}
+
 func (c *RpcClient) Start(ctx_in context.Context) error {
 	url := c.config().URL
 	jwtPath := c.config().JWTSecret
@@ -199,6 +347,7 @@ func (c *RpcClient) Start(ctx_in context.Context) error {
 			return err
 		}
 	}
+	transport := &retryAfterTransport{base: http.DefaultTransport}
 	connTimeout := time.After(c.config().ConnectionWait)
 	for {
 		var ctx context.Context
@@ -211,14 +360,18 @@ func (c *RpcClient) Start(ctx_in context.Context) error {
 		}
 		var err error
 		var client *rpc.Client
-		if jwt == nil {
-			client, err = rpc.DialContext(ctx, url)
-		} else {
-			client, err = rpc.DialOptions(ctx, url, rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(*jwt))))
+		options := []rpc.ClientOption{rpc.WithHTTPClient(&http.Client{Transport: transport}), rpc.WithWebsocketDialer(websocketDialer())}
+		if jwt != nil {
+			options = append(options, rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(*jwt))))
 		}
+		client, err = rpc.DialOptions(ctx, url, options...)
 		cancelCtx()
 		if err == nil {
 			c.client = client
+			c.transport = transport
+			if maxConcurrency := c.config().Throttle.MaxConcurrency; maxConcurrency > 0 {
+				c.limiter = newConcurrencyLimiter(int(maxConcurrency))
+			}
 			return nil
 		}
 		if strings.Contains(err.Error(), "parse") ||
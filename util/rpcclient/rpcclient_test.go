@@ -2,7 +2,11 @@ package rpcclient
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -177,6 +181,113 @@ func TestRpcClientRetry(t *testing.T) {
 	}
 }
 
+func TestRpcClientThrottled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var throttleRemaining int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&throttleRemaining, -1) >= 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"ok"}`, req.ID)
+	}))
+	defer server.Close()
+
+	config := &ClientConfig{
+		URL:      server.URL,
+		Timeout:  time.Second * 5,
+		Throttle: DefaultThrottleConfig,
+	}
+	config.Throttle.Enable = true
+	config.Throttle.InitialBackoff = time.Millisecond
+	config.Throttle.MaxBackoff = time.Millisecond * 10
+	config.Throttle.AdaptiveConcurrency = true
+	config.Throttle.MaxConcurrency = 8
+	Require(t, config.Validate())
+	client := NewRpcClient(func() *ClientConfig { return config }, nil)
+	Require(t, client.Start(ctx))
+	defer client.Close()
+
+	atomic.StoreInt64(&throttleRemaining, 3)
+	var result string
+	Require(t, client.CallContext(ctx, &result, "test_throttled"))
+	if result != "ok" {
+		Fail(t, "unexpected result", result)
+	}
+	// halved from 8 on each of the 3 throttled responses, then raised by the success
+	if limit := client.limiter.currentLimit(); limit != 2 {
+		Fail(t, "unexpected adaptive concurrency limit", limit)
+	}
+
+	atomic.StoreInt64(&throttleRemaining, int64(config.Throttle.Retries)+1)
+	err := client.CallContext(ctx, &result, "test_throttled")
+	if !IsThrottled(err) {
+		Fail(t, "expected throttled error after exhausting throttle retries, got", err)
+	}
+
+	// rate limited requests aren't retried by default
+	config.Throttle.Enable = DefaultThrottleConfig.Enable
+	atomic.StoreInt64(&throttleRemaining, 1)
+	err = client.CallContext(ctx, &result, "test_throttled")
+	if !IsThrottled(err) {
+		Fail(t, "expected throttled error with throttling disabled, got", err)
+	}
+}
+
+func TestRpcClientReadOnly(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	config := &ClientConfig{
+		URL:     "self",
+		Timeout: time.Second * 5,
+	}
+	Require(t, config.Validate())
+	client := NewRpcClient(func() *ClientConfig { return config }, createTestNode(t, ctx, 0))
+	client.SetReadOnly()
+	Require(t, client.Start(ctx))
+	// only known read methods are allowed
+	for _, method := range []string{"eth_sendRawTransaction", "eth_sendRawTransactionConditional", "test_failAtFirst"} {
+		err := client.CallContext(ctx, nil, method, "0x00")
+		if !errors.Is(err, ErrReadOnly) {
+			Fail(t, "read-only client sent", method, "err", err)
+		}
+	}
+	err := client.BatchCallContext(ctx, []rpc.BatchElem{{Method: "eth_getLogs"}, {Method: "eth_sendTransaction"}})
+	if !errors.Is(err, ErrReadOnly) {
+		Fail(t, "read-only client sent a transaction in a batch, err", err)
+	}
+	for _, method := range []string{"eth_getLogs", "eth_getBlockByNumber", "eth_call"} {
+		Require(t, client.checkReadOnly(method), "read-only client refused read method", method)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	if wait, ok := parseRetryAfter("7", now); !ok || wait != 7*time.Second {
+		Fail(t, "unexpected delay-seconds parse", wait, ok)
+	}
+	if wait, ok := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); !ok || wait != time.Minute {
+		Fail(t, "unexpected http-date parse", wait, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		Fail(t, "parsed invalid Retry-After")
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
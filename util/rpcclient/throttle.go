@@ -0,0 +1,184 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	throttledCounter    = metrics.NewRegisteredCounter("arb/rpcclient/throttle/events", nil)
+	throttleWaitTimer   = metrics.NewRegisteredTimer("arb/rpcclient/throttle/wait", nil)
+	throttleGiveUpCount = metrics.NewRegisteredCounter("arb/rpcclient/throttle/giveup", nil)
+)
+
+// rateLimitedErrorCode is the JSON-RPC error code providers use for rate limited requests
+const rateLimitedErrorCode = -32005
+
+type ThrottleConfig struct {
+	Enable              bool          `koanf:"enable" reload:"hot"`
+	Retries             uint          `koanf:"retries" reload:"hot"`
+	InitialBackoff      time.Duration `koanf:"initial-backoff" reload:"hot"`
+	MaxBackoff          time.Duration `koanf:"max-backoff" reload:"hot"`
+	AdaptiveConcurrency bool          `koanf:"adaptive-concurrency" reload:"hot"`
+	MaxConcurrency      uint          `koanf:"max-concurrency"`
+}
+
+var DefaultThrottleConfig = ThrottleConfig{
+	Enable:              false,
+	Retries:             5,
+	InitialBackoff:      time.Second,
+	MaxBackoff:          time.Minute,
+	AdaptiveConcurrency: false,
+	MaxConcurrency:      16,
+}
+
+func ThrottleConfigAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ThrottleConfig) {
+	f.Bool(prefix+".enable", defaultConfig.Enable, "back off and retry when the server rate limits requests (HTTP 429), respecting Retry-After")
+	f.Uint(prefix+".retries", defaultConfig.Retries, "number of retries of a rate limited request before returning the error")
+	f.Duration(prefix+".initial-backoff", defaultConfig.InitialBackoff, "backoff after the first rate limited response, doubled on each consecutive one")
+	f.Duration(prefix+".max-backoff", defaultConfig.MaxBackoff, "maximum time to wait before retrying a rate limited request, including waits requested by Retry-After")
+	f.Bool(prefix+".adaptive-concurrency", defaultConfig.AdaptiveConcurrency, "halve the number of concurrent requests on each rate limited response, and slowly raise it again on success")
+	f.Uint(prefix+".max-concurrency", defaultConfig.MaxConcurrency, "maximum number of concurrent requests when adaptive-concurrency is enabled")
+}
+
+func (c *ThrottleConfig) Validate() error {
+	if c.AdaptiveConcurrency && c.MaxConcurrency == 0 {
+		return errors.New("throttle.max-concurrency must be positive when adaptive-concurrency is enabled")
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		return errors.New("throttle.max-backoff must be at least throttle.initial-backoff")
+	}
+	return nil
+}
+
+// IsThrottled returns true if err is the server rate limiting the request
+func IsThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rateLimitedErrorCode {
+		return true
+	}
+	return strings.Contains(err.Error(), "429 Too Many Requests")
+}
+
+// retryAfterTransport remembers the latest Retry-After requested by the server,
+// which geth's HTTPError doesn't expose
+type retryAfterTransport struct {
+	base      http.RoundTripper
+	waitUntil int64 // unix nanoseconds
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			atomic.StoreInt64(&t.waitUntil, time.Now().Add(wait).UnixNano())
+		}
+	}
+	return resp, err
+}
+
+// retryAfter returns how much longer the server asked us to wait, if at all
+func (t *retryAfterTransport) retryAfter() time.Duration {
+	return time.Until(time.Unix(0, atomic.LoadInt64(&t.waitUntil)))
+}
+
+// parseRetryAfter parses both the delay-seconds and HTTP-date forms of the Retry-After header
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// concurrencyLimiter bounds the number of in-flight requests, halving the bound
+// when throttled and increasing it by one after a bound's worth of successes
+type concurrencyLimiter struct {
+	mutex     sync.Mutex
+	max       int
+	limit     int
+	inFlight  int
+	successes int
+	released  chan struct{}
+}
+
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		max:      max,
+		limit:    max,
+		released: make(chan struct{}),
+	}
+}
+
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mutex.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mutex.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mutex.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *concurrencyLimiter) release(throttled bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.inFlight--
+	if throttled {
+		l.successes = 0
+		if l.limit > 1 {
+			l.limit /= 2
+		}
+	} else if l.limit < l.max {
+		l.successes++
+		if l.successes >= l.limit {
+			l.successes = 0
+			l.limit++
+		}
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+func (l *concurrencyLimiter) currentLimit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.limit
+}
@@ -0,0 +1,47 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package testhelpers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// RunWithMetrics runs the tests of a package with metrics enabled, so that they can check metric values; call it from TestMain.
+// go-ethereum only enables metrics if the command line has a metrics flag when it's initialized, before packages create
+// their metrics, so without the flag the test binary is run again with it.
+func RunWithMetrics(m *testing.M) int {
+	// go-ethereum reads the flag from the command line itself
+	_ = flag.Bool("metrics", false, "enable metrics, so that tests can check their values")
+	if metrics.Enabled {
+		return m.Run()
+	}
+	for _, arg := range os.Args[1:] {
+		if arg == "-metrics" {
+			fmt.Fprintln(os.Stderr, "metrics flag given but metrics aren't enabled")
+			return 1
+		}
+	}
+	// #nosec G204
+	cmd := exec.Command(os.Args[0], append(os.Args[1:], "-metrics")...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run tests with metrics:", err)
+		return 1
+	}
+	return 0
+}
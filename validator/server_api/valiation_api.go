@@ -13,6 +13,7 @@ import (
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
 	"github.com/offchainlabs/nitro/validator/server_arb"
+	"github.com/offchainlabs/nitro/validator/server_common"
 )
 
 const Namespace string = "validation"
@@ -42,6 +43,30 @@ func NewValidationServerAPI(spawner validator.ValidationSpawner) *ValidationServ
 	return &ValidationServerAPI{spawner}
 }
 
+type WasmModuleRootsResult struct {
+	Latest    common.Hash   `json:"latest"`
+	Available []common.Hash `json:"available"`
+}
+
+type MachineLocatorAPI struct {
+	locator *server_common.MachineLocator
+}
+
+func NewMachineLocatorAPI(locator *server_common.MachineLocator) *MachineLocatorAPI {
+	return &MachineLocatorAPI{locator}
+}
+
+func (a *MachineLocatorAPI) WasmModuleRoots() (*WasmModuleRootsResult, error) {
+	available, err := a.locator.AvailableWasmModuleRoots()
+	if err != nil {
+		return nil, err
+	}
+	return &WasmModuleRootsResult{
+		Latest:    a.locator.LatestWasmModuleRoot(),
+		Available: available,
+	}, nil
+}
+
 type execRunEntry struct {
 	run      validator.ExecutionRun
 	accessed time.Time
@@ -141,6 +141,18 @@ func (c *ExecutionClient) LatestWasmModuleRoot() containers.PromiseInterface[com
 	})
 }
 
+// WasmModuleRoots lists the module roots the validation server has machines for
+func (c *ValidationClient) WasmModuleRoots() containers.PromiseInterface[[]common.Hash] {
+	return stopwaiter.LaunchPromiseThread[[]common.Hash](c, func(ctx context.Context) ([]common.Hash, error) {
+		var res WasmModuleRootsResult
+		err := c.client.CallContext(ctx, &res, Namespace+"_wasmModuleRoots")
+		if err != nil {
+			return nil, err
+		}
+		return res.Available, nil
+	})
+}
+
 func (c *ExecutionClient) WriteToFile(input *validator.ValidationInput, expOut validator.GoGlobalState, moduleRoot common.Hash) containers.PromiseInterface[struct{}] {
 	jsonInput := ValidationInputToJson(input)
 	return stopwaiter.LaunchPromiseThread[struct{}](c, func(ctx context.Context) (struct{}, error) {
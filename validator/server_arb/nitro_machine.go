@@ -29,7 +29,7 @@ func createArbMachine(ctx context.Context, locator *server_common.MachineLocator
 	log.Info("creating nitro machine", "binpath", binPath)
 	baseMachine := C.arbitrator_load_wavm_binary(cBinPath)
 	if baseMachine == nil {
-		return nil, errors.New("failed to load base machine")
+		return nil, fmt.Errorf("failed to load base machine for module root %v from %v", moduleRoot, binPath)
 	}
 	machine := machineFromPointer(baseMachine)
 	machineModuleRoot := machine.GetModuleRoot()
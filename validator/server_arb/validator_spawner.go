@@ -76,6 +76,10 @@ func (s *ArbitratorSpawner) LatestWasmModuleRoot() containers.PromiseInterface[c
 	return containers.NewReadyPromise(s.locator.LatestWasmModuleRoot(), nil)
 }
 
+func (s *ArbitratorSpawner) WasmModuleRoots() containers.PromiseInterface[[]common.Hash] {
+	return containers.NewReadyPromise(s.locator.AvailableWasmModuleRoots())
+}
+
 func (s *ArbitratorSpawner) Name() string {
 	return "arbitrator"
 }
@@ -1,7 +1,9 @@
 package server_common
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -63,7 +65,55 @@ func NewMachineLocator(rootPath string) (*MachineLocator, error) {
 			return &MachineLocator{place, latestModuleRoot}, nil
 		}
 	}
-	return nil, ErrMachineNotFound
+	return nil, fmt.Errorf("%w: no machines directory at %v, set the wasm root-path to the directory containing the machine folders", ErrMachineNotFound, strings.Join(places, ", "))
+}
+
+// MachineArtifactError identifies a machine folder or file that's missing or doesn't match its module root
+type MachineArtifactError struct {
+	ModuleRoot common.Hash
+	Path       string
+	Err        error
+}
+
+func (e *MachineArtifactError) Error() string {
+	return fmt.Sprintf("invalid validation machine for module root %v at %v: %v", e.ModuleRoot, e.Path, e.Err)
+}
+
+func (e *MachineArtifactError) Unwrap() error {
+	return e.Err
+}
+
+// CheckMachine verifies the machine folder for moduleRoot exists, contains the given files,
+// and if it has a module-root.txt, that it matches. The zero hash checks the latest machine.
+func (l MachineLocator) CheckMachine(moduleRoot common.Hash, files ...string) error {
+	if moduleRoot == (common.Hash{}) {
+		moduleRoot = l.latest
+		if moduleRoot == (common.Hash{}) {
+			path := filepath.Join(l.rootPath, "latest", "module-root.txt")
+			return &MachineArtifactError{moduleRoot, path, fmt.Errorf("%w: no latest module root found", ErrMachineNotFound)}
+		}
+	}
+	dir := l.GetMachinePath(moduleRoot)
+	if _, err := os.Stat(dir); err != nil {
+		return &MachineArtifactError{moduleRoot, dir, fmt.Errorf("%w: %v", ErrMachineNotFound, err)}
+	}
+	rootPath := filepath.Join(dir, "module-root.txt")
+	fileBytes, err := os.ReadFile(rootPath)
+	if err == nil {
+		found := common.HexToHash(strings.TrimSpace(string(fileBytes)))
+		if found != moduleRoot {
+			return &MachineArtifactError{moduleRoot, rootPath, fmt.Errorf("folder contains the machine for module root %v", found)}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return &MachineArtifactError{moduleRoot, rootPath, err}
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err != nil {
+			return &MachineArtifactError{moduleRoot, path, fmt.Errorf("%w: %v", ErrMachineNotFound, err)}
+		}
+	}
+	return nil
 }
 
 func (l MachineLocator) GetMachinePath(moduleRoot common.Hash) string {
@@ -81,3 +131,40 @@ func (l MachineLocator) LatestWasmModuleRoot() common.Hash {
 func (l MachineLocator) RootPath() string {
 	return l.rootPath
 }
+
+// AvailableWasmModuleRoots returns the latest module root followed by every other
+// module root that has a machine folder under the root path
+func (l MachineLocator) AvailableWasmModuleRoots() ([]common.Hash, error) {
+	entries, err := os.ReadDir(l.rootPath)
+	if err != nil {
+		return nil, err
+	}
+	var roots []common.Hash
+	seen := make(map[common.Hash]bool)
+	addRoot := func(root common.Hash) {
+		if root == (common.Hash{}) || seen[root] {
+			return
+		}
+		seen[root] = true
+		roots = append(roots, root)
+	}
+	addRoot(l.latest)
+	for _, entry := range entries {
+		dir := filepath.Join(l.rootPath, entry.Name())
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		fileBytes, err := os.ReadFile(filepath.Join(dir, "module-root.txt"))
+		if err == nil {
+			addRoot(common.HexToHash(strings.TrimSpace(string(fileBytes))))
+			continue
+		}
+		name := entry.Name()
+		if len(name) == 2+2*common.HashLength && strings.HasPrefix(name, "0x") {
+			if _, err := hex.DecodeString(name[2:]); err == nil {
+				addRoot(common.HexToHash(name))
+			}
+		}
+	}
+	return roots, nil
+}
@@ -0,0 +1,57 @@
+package server_common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestAvailableWasmModuleRoots(t *testing.T) {
+	rootPath := t.TempDir()
+	latest := common.HexToHash("0x01")
+	named := common.HexToHash("0x02")
+	byHash := common.HexToHash("0x03")
+
+	writeRoot := func(dir string, root common.Hash) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(rootPath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(rootPath, dir, "module-root.txt"), []byte(root.Hex()+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeRoot("latest", latest)
+	writeRoot("consensus-v1", named)
+	writeRoot(latest.String(), latest)
+	if err := os.MkdirAll(filepath.Join(rootPath, byHash.String()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(rootPath, "unrelated"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	locator, err := NewMachineLocator(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locator.LatestWasmModuleRoot() != latest {
+		t.Fatal("unexpected latest module root", locator.LatestWasmModuleRoot())
+	}
+	roots, err := locator.AvailableWasmModuleRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 3 || roots[0] != latest {
+		t.Fatal("unexpected module roots", roots)
+	}
+	found := make(map[common.Hash]bool)
+	for _, root := range roots {
+		found[root] = true
+	}
+	if !found[named] || !found[byHash] {
+		t.Fatal("missing module roots", roots)
+	}
+}
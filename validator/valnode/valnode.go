@@ -2,13 +2,17 @@ package valnode
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/offchainlabs/nitro/validator"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
 	flag "github.com/spf13/pflag"
 
+	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/validator/server_api"
 	"github.com/offchainlabs/nitro/validator/server_arb"
 	"github.com/offchainlabs/nitro/validator/server_common"
@@ -27,33 +31,50 @@ var DefaultWasmConfig = WasmConfig{
 	RootPath: "",
 }
 
+const (
+	// AuthRPCCheckAbort stops the node if same-process validation isn't reachable over the auth RPC
+	AuthRPCCheckAbort = "abort"
+	// AuthRPCCheckWarn only logs a warning, for setups that reach the validation API some other way
+	AuthRPCCheckWarn = "warn"
+)
+
 type Config struct {
-	UseJit     bool                               `koanf:"use-jit"`
-	ApiAuth    bool                               `koanf:"api-auth"`
-	ApiPublic  bool                               `koanf:"api-public"`
-	Arbitrator server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
-	Jit        server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
-	Wasm       WasmConfig                         `koanf:"wasm"`
+	UseJit       bool                               `koanf:"use-jit"`
+	ApiAuth      bool                               `koanf:"api-auth"`
+	ApiPublic    bool                               `koanf:"api-public"`
+	Arbitrator   server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
+	Jit          server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
+	Wasm         WasmConfig                         `koanf:"wasm"`
+	AuthRPCCheck string                             `koanf:"auth-rpc-check"`
+}
+
+func (c *Config) Validate() error {
+	if c.AuthRPCCheck != AuthRPCCheckAbort && c.AuthRPCCheck != AuthRPCCheckWarn {
+		return fmt.Errorf("invalid validation.auth-rpc-check %q, must be %q or %q", c.AuthRPCCheck, AuthRPCCheckAbort, AuthRPCCheckWarn)
+	}
+	return nil
 }
 
 type ValidationConfigFetcher func() *Config
 
 var DefaultValidationConfig = Config{
-	UseJit:     true,
-	Jit:        server_jit.DefaultJitSpawnerConfig,
-	ApiAuth:    true,
-	ApiPublic:  false,
-	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
-	Wasm:       DefaultWasmConfig,
+	UseJit:       true,
+	Jit:          server_jit.DefaultJitSpawnerConfig,
+	ApiAuth:      true,
+	ApiPublic:    false,
+	Arbitrator:   server_arb.DefaultArbitratorSpawnerConfig,
+	Wasm:         DefaultWasmConfig,
+	AuthRPCCheck: AuthRPCCheckAbort,
 }
 
 var TestValidationConfig = Config{
-	UseJit:     true,
-	Jit:        server_jit.DefaultJitSpawnerConfig,
-	ApiAuth:    false,
-	ApiPublic:  true,
-	Arbitrator: server_arb.DefaultArbitratorSpawnerConfig,
-	Wasm:       DefaultWasmConfig,
+	UseJit:       true,
+	Jit:          server_jit.DefaultJitSpawnerConfig,
+	ApiAuth:      false,
+	ApiPublic:    true,
+	Arbitrator:   server_arb.DefaultArbitratorSpawnerConfig,
+	Wasm:         DefaultWasmConfig,
+	AuthRPCCheck: AuthRPCCheckWarn,
 }
 
 func ValidationConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -63,12 +84,15 @@ func ValidationConfigAddOptions(prefix string, f *flag.FlagSet) {
 	server_arb.ArbitratorSpawnerConfigAddOptions(prefix+".arbitrator", f)
 	server_jit.JitSpawnerConfigAddOptions(prefix+".jit", f)
 	WasmConfigAddOptions(prefix+".wasm", f)
+	f.String(prefix+".auth-rpc-check", DefaultValidationConfig.AuthRPCCheck, "what to do at startup if same-process validation isn't reachable over the auth RPC (abort or warn)")
 }
 
 type ValidationNode struct {
-	config     ValidationConfigFetcher
-	arbSpawner *server_arb.ArbitratorSpawner
-	jitSpawner *server_jit.JitSpawner
+	config       ValidationConfigFetcher
+	locator      *server_common.MachineLocator
+	machineFiles []string
+	arbSpawner   *server_arb.ArbitratorSpawner
+	jitSpawner   *server_jit.JitSpawner
 }
 
 func EnsureValidationExposedViaAuthRPC(stackConf *node.Config) {
@@ -84,12 +108,70 @@ func EnsureValidationExposedViaAuthRPC(stackConf *node.Config) {
 	}
 }
 
+// CheckValidationExposedViaAuthRPC returns an error if the stack config won't serve the validation API over the auth RPC.
+// It's meant to be run after EnsureValidationExposedViaAuthRPC, to catch settings that silently break the wiring.
+func CheckValidationExposedViaAuthRPC(stackConf *node.Config, config *Config) error {
+	if !config.ApiAuth {
+		return errors.New("the validation API isn't an authenticated API (--validation.api-auth is false)")
+	}
+	if stackConf.AuthAddr == "" {
+		return errors.New("the auth RPC has no listening address (--auth.addr)")
+	}
+	if stackConf.AuthPort < 0 || stackConf.AuthPort > 65535 {
+		return fmt.Errorf("invalid auth RPC port %v (--auth.port)", stackConf.AuthPort)
+	}
+	found := false
+	for _, module := range stackConf.AuthModules {
+		if module == server_api.Namespace {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("the %v API isn't offered over the auth RPC (--auth.api)", server_api.Namespace)
+	}
+	if stackConf.JWTSecret == "" {
+		return errors.New("the auth RPC has no JWT secret (--auth.jwtsecret)")
+	}
+	return nil
+}
+
+// CheckValidationReachableViaAuthRPC connects to the started stack's auth RPC the way a "self-auth" validation client does,
+// and returns an error if the validation API can't be called there.
+func CheckValidationReachableViaAuthRPC(ctx context.Context, stack *node.Node) error {
+	jwt, err := signature.LoadSigningKey(stack.JWTPath())
+	if err != nil {
+		return fmt.Errorf("failed to load auth RPC JWT secret: %w", err)
+	}
+	if jwt == nil {
+		return errors.New("the auth RPC has no JWT secret")
+	}
+	url := stack.WSAuthEndpoint()
+	client, err := rpc.DialOptions(ctx, url, rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(*jwt))))
+	if err != nil {
+		return fmt.Errorf("failed to connect to auth RPC at %v: %w", url, err)
+	}
+	defer client.Close()
+	var name string
+	if err := client.CallContext(ctx, &name, server_api.Namespace+"_name"); err != nil {
+		return fmt.Errorf("validation API not reachable over auth RPC at %v: %w", url, err)
+	}
+	return nil
+}
+
 func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Node, fatalErrChan chan error) (*ValidationNode, error) {
 	config := configFetcher()
 	locator, err := server_common.NewMachineLocator(config.Wasm.RootPath)
 	if err != nil {
 		return nil, err
 	}
+	machineFiles := []string{server_arb.DefaultArbitratorMachineConfig.WavmBinaryPath}
+	if config.UseJit {
+		machineFiles = append(machineFiles, server_jit.DefaultJitMachineConfig.ProverBinPath)
+	}
+	if err := locator.CheckMachine(common.Hash{}, machineFiles...); err != nil {
+		return nil, err
+	}
 	arbConfigFetcher := func() *server_arb.ArbitratorSpawnerConfig {
 		return &configFetcher().Arbitrator
 	}
@@ -116,10 +198,21 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 		Service:       serverAPI,
 		Public:        config.ApiPublic,
 		Authenticated: config.ApiAuth,
+	}, {
+		Namespace:     server_api.Namespace,
+		Version:       "1.0",
+		Service:       server_api.NewMachineLocatorAPI(locator),
+		Public:        config.ApiPublic,
+		Authenticated: config.ApiAuth,
 	}}
 	stack.RegisterAPIs(valAPIs)
 
-	return &ValidationNode{configFetcher, arbSpawner, jitSpawner}, nil
+	return &ValidationNode{configFetcher, locator, machineFiles, arbSpawner, jitSpawner}, nil
+}
+
+// CheckMachine returns a MachineArtifactError if the machine for moduleRoot is missing or invalid
+func (v *ValidationNode) CheckMachine(moduleRoot common.Hash) error {
+	return v.locator.CheckMachine(moduleRoot, v.machineFiles...)
 }
 
 func (v *ValidationNode) Start(ctx context.Context) error {
@@ -134,6 +227,28 @@ func (v *ValidationNode) Start(ctx context.Context) error {
 	return nil
 }
 
+// CheckHealth returns an error if the validation node isn't able to validate,
+// i.e. it has no machine to validate with or no room for validations.
+func (v *ValidationNode) CheckHealth(ctx context.Context) error {
+	root, err := v.arbSpawner.LatestWasmModuleRoot().Await(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest wasm module root: %w", err)
+	}
+	if root == (common.Hash{}) {
+		return errors.New("no wasm module root found")
+	}
+	spawners := []validator.ValidationSpawner{v.arbSpawner}
+	if v.jitSpawner != nil {
+		spawners = append(spawners, v.jitSpawner)
+	}
+	for _, spawner := range spawners {
+		if spawner.Room() <= 0 {
+			return fmt.Errorf("%s spawner has no room for validations", spawner.Name())
+		}
+	}
+	return nil
+}
+
 func (v *ValidationNode) GetExec() validator.ExecutionSpawner {
 	return v.arbSpawner
 }
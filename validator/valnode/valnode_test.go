@@ -0,0 +1,43 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package valnode
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestCheckValidationExposedViaAuthRPC(t *testing.T) {
+	validStackConf := func() *node.Config {
+		return &node.Config{
+			AuthAddr:    "127.0.0.1",
+			AuthPort:    8549,
+			AuthModules: []string{"eth"},
+			JWTSecret:   "/tmp/jwtsecret",
+		}
+	}
+	config := DefaultValidationConfig
+	stackConf := validStackConf()
+	EnsureValidationExposedViaAuthRPC(stackConf)
+	testhelpers.RequireImpl(t, CheckValidationExposedViaAuthRPC(stackConf, &config))
+
+	for name, breakConfig := range map[string]func(*node.Config, *Config){
+		"no auth api":  func(_ *node.Config, c *Config) { c.ApiAuth = false },
+		"no auth addr": func(s *node.Config, _ *Config) { s.AuthAddr = "" },
+		"bad port":     func(s *node.Config, _ *Config) { s.AuthPort = 70000 },
+		"no module":    func(s *node.Config, _ *Config) { s.AuthModules = []string{"eth"} },
+		"no jwt":       func(s *node.Config, _ *Config) { s.JWTSecret = "" },
+	} {
+		config := DefaultValidationConfig
+		stackConf := validStackConf()
+		EnsureValidationExposedViaAuthRPC(stackConf)
+		breakConfig(stackConf, &config)
+		if CheckValidationExposedViaAuthRPC(stackConf, &config) == nil {
+			testhelpers.FailImpl(t, "broken auth RPC wiring accepted:", name)
+		}
+	}
+}
@@ -157,7 +157,7 @@ func (cc *ClientConnection) readRequest(ctx context.Context, timeout time.Durati
 	var data []byte
 	var opCode ws.OpCode
 	var err error
-	data, opCode, err = ReadData(ctx, cc.conn, nil, timeout, ws.StateServerSide, cc.compression, cc.flateReader)
+	data, opCode, err = ReadData(ctx, cc.conn, nil, timeout, ws.StateServerSide, cc.compression, cc.flateReader, 0)
 	return data, opCode, err
 }
 
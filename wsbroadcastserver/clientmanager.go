@@ -12,6 +12,7 @@ import (
 	"io"
 	"net"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -37,8 +38,24 @@ var (
 	clientsTotalFailedUpgradeCounter  = metrics.NewRegisteredCounter("arb/feed/clients/failed/upgrade", nil)
 	clientsTotalFailedWorkerCounter   = metrics.NewRegisteredCounter("arb/feed/clients/failed/worker", nil)
 	clientsDurationHistogram          = metrics.NewRegisteredHistogram("arb/feed/clients/duration", nil, metrics.NewBoundedHistogramSample())
+	broadcastMessagesCounter          = metrics.NewRegisteredCounter("arb/feed/broadcast/messages", nil)
+	broadcastBytesCounter             = metrics.NewRegisteredCounter("arb/feed/broadcast/bytes", nil)
+	broadcastMessagesRateGauge        = metrics.NewRegisteredGaugeFloat64("arb/feed/broadcast/messages_per_second", nil)
+	broadcastBytesRateGauge           = metrics.NewRegisteredGaugeFloat64("arb/feed/broadcast/bytes_per_second", nil)
 )
 
+// throughputInterval is how often the broadcast rates are recomputed
+const throughputInterval = 10 * time.Second
+
+// FeedStats describes the current subscribers and recent throughput of the feed output
+type FeedStats struct {
+	Subscribers       int32   `json:"subscribers"`
+	MessagesPerSecond float64 `json:"messagesPerSecond"`
+	BytesPerSecond    float64 `json:"bytesPerSecond"`
+	TotalMessages     uint64  `json:"totalMessages"`
+	TotalBytes        uint64  `json:"totalBytes"`
+}
+
 // CatchupBuffer is a Protocol-specific client catch-up logic can be injected using this interface
 type CatchupBuffer interface {
 	OnRegisterClient(*ClientConnection) (error, int, time.Duration)
@@ -61,6 +78,15 @@ type ClientManager struct {
 	flateWriter   *flate.Writer
 
 	connectionLimiter *ConnectionLimiter
+
+	// messagesSent and bytesSent are only written by the broadcast thread
+	messagesSent      uint64
+	bytesSent         uint64
+	throughputMutex   sync.Mutex
+	lastMessagesSent  uint64
+	lastBytesSent     uint64
+	messagesPerSecond float64
+	bytesPerSecond    float64
 }
 
 type ClientConnectionAction struct {
@@ -188,6 +214,36 @@ func (cm *ClientManager) ClientCount() int32 {
 	return atomic.LoadInt32(&cm.clientCount)
 }
 
+// Stats returns the subscriber count and the broadcast rates measured over the last throughputInterval
+func (cm *ClientManager) Stats() FeedStats {
+	cm.throughputMutex.Lock()
+	defer cm.throughputMutex.Unlock()
+	return FeedStats{
+		Subscribers:       cm.ClientCount(),
+		MessagesPerSecond: cm.messagesPerSecond,
+		BytesPerSecond:    cm.bytesPerSecond,
+		TotalMessages:     atomic.LoadUint64(&cm.messagesSent),
+		TotalBytes:        atomic.LoadUint64(&cm.bytesSent),
+	}
+}
+
+func (cm *ClientManager) updateThroughput(elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	messages := atomic.LoadUint64(&cm.messagesSent)
+	sent := atomic.LoadUint64(&cm.bytesSent)
+
+	cm.throughputMutex.Lock()
+	defer cm.throughputMutex.Unlock()
+	cm.messagesPerSecond = float64(messages-cm.lastMessagesSent) / elapsed.Seconds()
+	cm.bytesPerSecond = float64(sent-cm.lastBytesSent) / elapsed.Seconds()
+	cm.lastMessagesSent = messages
+	cm.lastBytesSent = sent
+	broadcastMessagesRateGauge.Update(cm.messagesPerSecond)
+	broadcastBytesRateGauge.Update(cm.bytesPerSecond)
+}
+
 // Broadcast sends batch item to all clients.
 func (cm *ClientManager) Broadcast(bm interface{}) {
 	if cm.Stopped() {
@@ -213,6 +269,9 @@ func (cm *ClientManager) doBroadcast(bm interface{}) ([]*ClientConnection, error
 		return nil, err
 	}
 
+	atomic.AddUint64(&cm.messagesSent, 1)
+	broadcastMessagesCounter.Inc(1)
+
 	sendQueueTooLargeCount := 0
 	clientDeleteList := make([]*ClientConnection, 0, len(cm.clientPtrMap))
 	for client := range cm.clientPtrMap {
@@ -236,6 +295,8 @@ func (cm *ClientManager) doBroadcast(bm interface{}) ([]*ClientConnection, error
 		}
 		select {
 		case client.out <- data:
+			atomic.AddUint64(&cm.bytesSent, uint64(len(data)))
+			broadcastBytesCounter.Inc(int64(len(data)))
 		default:
 			// Queue for client too backed up, disconnect instead of blocking on channel send
 			sendQueueTooLargeCount++
@@ -337,6 +398,9 @@ func (cm *ClientManager) Start(parentCtx context.Context) {
 		pingTimer := time.NewTimer(cm.config().Ping)
 		var clientDeleteList []*ClientConnection
 		defer pingTimer.Stop()
+		throughputTicker := time.NewTicker(throughputInterval)
+		defer throughputTicker.Stop()
+		lastThroughputUpdate := time.Now()
 		for {
 			select {
 			case <-ctx.Done():
@@ -358,6 +422,9 @@ func (cm *ClientManager) Start(parentCtx context.Context) {
 			case <-pingTimer.C:
 				clientDeleteList = cm.verifyClients()
 				pingTimer.Reset(cm.config().Ping)
+			case now := <-throughputTicker.C:
+				cm.updateThroughput(now.Sub(lastThroughputUpdate))
+				lastThroughputUpdate = now
 			}
 
 			if len(clientDeleteList) > 0 {
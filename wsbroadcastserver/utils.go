@@ -23,6 +23,10 @@ func init() {
 	wsflate.ExtensionNameBytes = append([]byte("Arbitrum-"), wsflate.ExtensionNameBytes...)
 }
 
+// ErrMessageTooLarge is returned by ReadData for messages over the size limit.
+// The rest of the message isn't read, so the connection can't be used any more.
+var ErrMessageTooLarge = errors.New("websocket message too large")
+
 type chainedReader struct {
 	readers []io.Reader
 }
@@ -74,7 +78,9 @@ func NewFlateReader() *wsflate.Reader {
 	})
 }
 
-func ReadData(ctx context.Context, conn net.Conn, earlyFrameData io.Reader, timeout time.Duration, state ws.State, compression bool, flateReader *wsflate.Reader) ([]byte, ws.OpCode, error) {
+// ReadData reads the next data message from conn. If maxSize is positive, a message (after decompression)
+// over maxSize bytes isn't buffered, and ErrMessageTooLarge is returned instead.
+func ReadData(ctx context.Context, conn net.Conn, earlyFrameData io.Reader, timeout time.Duration, state ws.State, compression bool, flateReader *wsflate.Reader, maxSize int64) ([]byte, ws.OpCode, error) {
 	if compression {
 		state |= ws.StateExtended
 	}
@@ -133,15 +139,21 @@ func ReadData(ctx context.Context, conn net.Conn, earlyFrameData io.Reader, time
 			}
 			continue
 		}
-		var data []byte
+		var source io.Reader = &reader
 		if msg.IsCompressed() {
 			if !compression {
 				return nil, 0, errors.New("Received compressed frame even though compression is disabled")
 			}
 			flateReader.Reset(&reader)
-			data, err = io.ReadAll(flateReader)
-		} else {
-			data, err = io.ReadAll(&reader)
+			source = flateReader
+		}
+		if maxSize > 0 {
+			// read one byte past the limit to tell if the message is over it
+			source = io.LimitReader(source, maxSize+1)
+		}
+		data, err := io.ReadAll(source)
+		if err == nil && maxSize > 0 && int64(len(data)) > maxSize {
+			return nil, header.OpCode, ErrMessageTooLarge
 		}
 
 		return data, header.OpCode, err
@@ -0,0 +1,53 @@
+// Copyright 2023, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package wsbroadcastserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+// readTestMessage writes payload as a server message to a connection and reads it back with ReadData
+func readTestMessage(t *testing.T, payload []byte, maxSize int64) ([]byte, error) {
+	t.Helper()
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		// unblocks once the client stops reading, as the rest of an oversized message isn't read
+		defer server.Close()
+		_ = wsutil.WriteServerMessage(server, ws.OpText, payload)
+	}()
+	data, _, err := ReadData(context.Background(), client, nil, time.Second, ws.StateClientSide, false, nil, maxSize)
+	return data, err
+}
+
+func TestReadDataMaxSize(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 100)
+
+	data, err := readTestMessage(t, payload, 100)
+	testhelpers.RequireImpl(t, err)
+	if !bytes.Equal(data, payload) {
+		testhelpers.FailImpl(t, "message at the size limit not read, got", len(data), "bytes")
+	}
+
+	data, err = readTestMessage(t, payload, 0)
+	testhelpers.RequireImpl(t, err)
+	if !bytes.Equal(data, payload) {
+		testhelpers.FailImpl(t, "message not read without a size limit, got", len(data), "bytes")
+	}
+
+	data, err = readTestMessage(t, payload, 99)
+	if !errors.Is(err, ErrMessageTooLarge) {
+		testhelpers.FailImpl(t, "message over the size limit not rejected, got", len(data), "bytes and error", err)
+	}
+}
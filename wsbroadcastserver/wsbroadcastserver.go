@@ -55,6 +55,7 @@ type BroadcasterConfig struct {
 	MaxSendQueue       int                     `koanf:"max-send-queue" reload:"hot"`  // reloaded value will affect only new connections
 	RequireVersion     bool                    `koanf:"require-version" reload:"hot"` // reloaded value will affect only future upgrades to websocket
 	DisableSigning     bool                    `koanf:"disable-signing"`
+	SigningScheme      string                  `koanf:"signing-scheme" reload:"hot"`
 	LogConnect         bool                    `koanf:"log-connect"`
 	LogDisconnect      bool                    `koanf:"log-disconnect"`
 	EnableCompression  bool                    `koanf:"enable-compression" reload:"hot"`  // if reloaded to false will cause disconnection of clients with enabled compression on next broadcast
@@ -62,6 +63,23 @@ type BroadcasterConfig struct {
 	LimitCatchup       bool                    `koanf:"limit-catchup" reload:"hot"`
 	ConnectionLimits   ConnectionLimiterConfig `koanf:"connection-limits" reload:"hot"`
 	ClientDelay        time.Duration           `koanf:"client-delay" reload:"hot"`
+	SignerRotation     SignerRotationConfig    `koanf:"signer-rotation" reload:"hot"`
+}
+
+// SignerRotationConfig limits where the feed signer rotation RPCs may load a new signing key from
+type SignerRotationConfig struct {
+	KeyDir  string   `koanf:"key-dir" reload:"hot"`
+	KeyEnvs []string `koanf:"key-envs" reload:"hot"`
+}
+
+var DefaultSignerRotationConfig = SignerRotationConfig{
+	KeyDir:  "",
+	KeyEnvs: []string{},
+}
+
+func SignerRotationConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".key-dir", DefaultSignerRotationConfig.KeyDir, "directory the feed signer rotation RPCs may load key and keystore files from (rotating from files is disabled if empty)")
+	f.StringSlice(prefix+".key-envs", DefaultSignerRotationConfig.KeyEnvs, "environment variables the feed signer rotation RPCs may read private keys and keystore passwords from")
 }
 
 func (bc *BroadcasterConfig) Validate() error {
@@ -88,6 +106,7 @@ func BroadcasterConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".max-send-queue", DefaultBroadcasterConfig.MaxSendQueue, "maximum number of messages allowed to accumulate before client is disconnected")
 	f.Bool(prefix+".require-version", DefaultBroadcasterConfig.RequireVersion, "don't connect if client version not present")
 	f.Bool(prefix+".disable-signing", DefaultBroadcasterConfig.DisableSigning, "don't sign feed messages")
+	f.String(prefix+".signing-scheme", DefaultBroadcasterConfig.SigningScheme, "scheme to sign feed messages with, \"ecdsa\" (understood by all nodes) or \"ecdsa-feed-domain\" (only switch to it once all feed clients accept it)")
 	f.Bool(prefix+".log-connect", DefaultBroadcasterConfig.LogConnect, "log every client connect")
 	f.Bool(prefix+".log-disconnect", DefaultBroadcasterConfig.LogDisconnect, "log every client disconnect")
 	f.Bool(prefix+".enable-compression", DefaultBroadcasterConfig.EnableCompression, "enable per message deflate compression support")
@@ -95,6 +114,7 @@ func BroadcasterConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".limit-catchup", DefaultBroadcasterConfig.LimitCatchup, "only supply catchup buffer if requested sequence number is reasonable")
 	ConnectionLimiterConfigAddOptions(prefix+".connection-limits", f)
 	f.Duration(prefix+".client-delay", DefaultBroadcasterConfig.ClientDelay, "delay the first messages sent to each client by this amount")
+	SignerRotationConfigAddOptions(prefix+".signer-rotation", f)
 }
 
 var DefaultBroadcasterConfig = BroadcasterConfig{
@@ -112,6 +132,7 @@ var DefaultBroadcasterConfig = BroadcasterConfig{
 	MaxSendQueue:       4096,
 	RequireVersion:     false,
 	DisableSigning:     true,
+	SigningScheme:      "ecdsa",
 	LogConnect:         false,
 	LogDisconnect:      false,
 	EnableCompression:  true,
@@ -119,6 +140,7 @@ var DefaultBroadcasterConfig = BroadcasterConfig{
 	LimitCatchup:       false,
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
+	SignerRotation:     DefaultSignerRotationConfig,
 }
 
 var DefaultTestBroadcasterConfig = BroadcasterConfig{
@@ -136,6 +158,7 @@ var DefaultTestBroadcasterConfig = BroadcasterConfig{
 	MaxSendQueue:       4096,
 	RequireVersion:     false,
 	DisableSigning:     false,
+	SigningScheme:      "ecdsa",
 	LogConnect:         false,
 	LogDisconnect:      false,
 	EnableCompression:  true,
@@ -143,6 +166,7 @@ var DefaultTestBroadcasterConfig = BroadcasterConfig{
 	LimitCatchup:       false,
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
+	SignerRotation:     DefaultSignerRotationConfig,
 }
 
 type WSBroadcastServer struct {
@@ -522,6 +546,10 @@ func (s *WSBroadcastServer) ClientCount() int32 {
 	return s.clientManager.ClientCount()
 }
 
+func (s *WSBroadcastServer) Stats() FeedStats {
+	return s.clientManager.Stats()
+}
+
 // writeDeadliner is a wrapper around net.Conn that sets write deadlines before
 // every Write() call.
 type writeDeadliner struct {